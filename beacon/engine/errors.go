@@ -82,6 +82,12 @@ var (
 	InvalidParams            = &EngineAPIError{code: -32602, msg: "Invalid parameters"}
 	UnsupportedFork          = &EngineAPIError{code: -38005, msg: "Unsupported fork"}
 
+	// ConsensusRetired is returned by networks switching from PoS to PoA
+	// consensus, once the local chain head is past the transition or when a
+	// call concerns a block past it. The consensus client has no say over the
+	// chain from then on and should be stopped.
+	ConsensusRetired = &EngineAPIError{code: -38100, msg: "PoS consensus retired"}
+
 	STATUS_INVALID         = ForkChoiceResponse{PayloadStatus: PayloadStatusV1{Status: INVALID}, PayloadID: nil}
 	STATUS_SYNCING         = ForkChoiceResponse{PayloadStatus: PayloadStatusV1{Status: SYNCING}, PayloadID: nil}
 	INVALID_TERMINAL_BLOCK = PayloadStatusV1{Status: INVALID, LatestValidHash: &common.Hash{}}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/history"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -60,13 +61,17 @@ var (
 			utils.CachePreimagesFlag,
 			utils.OverrideOsaka,
 			utils.OverrideVerkle,
+			utils.HybridTransitionBlockFlag,
+			utils.HybridStrictFlag,
 		}, utils.DatabaseFlags),
 		Description: `
 The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument. If the genesis configures a PoS to PoA
+transition, it is validated like 'geth hybrid check-genesis' and the extraData
+expected in the transition checkpoint block is printed.`,
 	}
 	dumpGenesisCommand = &cli.Command{
 		Action:    dumpGenesis,
@@ -116,7 +121,7 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.LogNoHistoryFlag,
 			utils.LogExportCheckpointsFlag,
 			utils.StateHistoryFlag,
-		}, utils.DatabaseFlags, debug.Flags),
+		}, utils.DatabaseFlags, utils.HybridVerifyFlags, debug.Flags),
 		Before: func(ctx *cli.Context) error {
 			flags.MigrateGlobalFlags(ctx)
 			return debug.Setup(ctx)
@@ -127,20 +132,25 @@ containing multiple RLP-encoded blocks, or multiple files can be given.
 
 If only one file is used, an import error will result in the entire import process failing. If
 multiple files are processed, the import process will continue even if an individual RLP file fails
-to import successfully.`,
+to import successfully.
+
+On PoS to PoA transition networks, every block is verified by the consensus engine of its era and
+the blocks per era, the PoA sealers seen and the hash of the transition block are printed at the end.`,
 	}
 	exportCommand = &cli.Command{
 		Action:    exportChain,
 		Name:      "export",
 		Usage:     "Export blockchain into file",
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
-		Flags:     slices.Concat([]cli.Flag{utils.CacheFlag}, utils.DatabaseFlags),
+		Flags:     slices.Concat([]cli.Flag{utils.CacheFlag}, utils.DatabaseFlags, utils.HybridVerifyFlags),
 		Description: `
 Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped. On PoS to PoA transition networks, the blocks per
+era, the PoA sealers seen and the hash of the transition block
+of the exported range are printed at the end.`,
 	}
 	importHistoryCommand = &cli.Command{
 		Action:    importHistory,
@@ -254,16 +264,17 @@ func initGenesis(ctx *cli.Context) error {
 	if len(genesisPath) == 0 {
 		utils.Fatalf("invalid path to genesis file")
 	}
-	file, err := os.Open(genesisPath)
+	data, err := os.ReadFile(genesisPath)
 	if err != nil {
 		utils.Fatalf("Failed to read genesis file: %v", err)
 	}
-	defer file.Close()
-
 	genesis := new(core.Genesis)
-	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+	if err := json.Unmarshal(data, genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	if genesis.Config != nil && genesis.Config.PoSToPoATransitionBlock != nil {
+		checkTransitionGenesis(ctx, genesis, data)
+	}
 	// Open and initialise both full and light databases
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -277,6 +288,10 @@ func initGenesis(ctx *cli.Context) error {
 		v := ctx.Uint64(utils.OverrideVerkle.Name)
 		overrides.OverrideVerkle = &v
 	}
+	if ctx.IsSet(utils.HybridTransitionBlockFlag.Name) {
+		v := ctx.Uint64(utils.HybridTransitionBlockFlag.Name)
+		overrides.OverrideTransitionBlock = &v
+	}
 
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
@@ -296,6 +311,36 @@ func initGenesis(ctx *cli.Context) error {
 	return nil
 }
 
+// checkTransitionGenesis validates the genesis of a PoS to PoA transition network
+// before it is written, and reports the checkpoint extraData the transition
+// block is expected to carry.
+func checkTransitionGenesis(ctx *cli.Context, genesis *core.Genesis, data []byte) {
+	issues, err := hybrid.CheckGenesis(data, ctx.Bool(utils.HybridStrictFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid hybrid genesis file: %v", err)
+	}
+	var failures int
+	for _, issue := range issues {
+		if issue.Warning {
+			log.Warn("Hybrid genesis issue", "field", issue.Field, "issue", issue.Message)
+			continue
+		}
+		log.Error("Hybrid genesis issue", "field", issue.Field, "issue", issue.Message)
+		failures++
+	}
+	if failures > 0 {
+		utils.Fatalf("Hybrid genesis file has %d error(s), run 'geth hybrid check-genesis' for details", failures)
+	}
+	config := genesis.Config
+	if len(config.PoAInitialSigners) == 0 {
+		log.Warn("No initial PoA signers configured, the placeholder defaults apply", "block", config.PoSToPoATransitionBlock)
+		return
+	}
+	log.Info("Derived transition checkpoint", "block", config.PoSToPoATransitionBlock,
+		"signers", len(config.PoAInitialSigners),
+		"extraData", hexutil.Encode(hybrid.CheckpointExtra(config.PoAInitialSigners)))
+}
+
 func dumpGenesis(ctx *cli.Context) error {
 	// check if there is a testnet preset enabled
 	var genesis *core.Genesis
@@ -382,6 +427,7 @@ func importChain(ctx *cli.Context) error {
 			}
 		}
 	}
+	printEraStats(chain, 0, chain.CurrentBlock().Number.Uint64())
 	chain.Stop()
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
@@ -425,32 +471,54 @@ func exportChain(ctx *cli.Context) error {
 	defer db.Close()
 	start := time.Now()
 
-	var err error
-	fp := ctx.Args().First()
+	var (
+		err   error
+		fp    = ctx.Args().First()
+		first = uint64(0)
+		last  = chain.CurrentBlock().Number.Uint64()
+	)
 	if ctx.Args().Len() < 3 {
 		err = utils.ExportChain(chain, fp)
 	} else {
 		// This can be improved to allow for numbers larger than 9223372036854775807
-		first, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
-		last, lerr := strconv.ParseInt(ctx.Args().Get(2), 10, 64)
+		f, ferr := strconv.ParseInt(ctx.Args().Get(1), 10, 64)
+		l, lerr := strconv.ParseInt(ctx.Args().Get(2), 10, 64)
 		if ferr != nil || lerr != nil {
 			utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
 		}
-		if first < 0 || last < 0 {
+		if f < 0 || l < 0 {
 			utils.Fatalf("Export error: block number must be greater than 0\n")
 		}
-		if head := chain.CurrentSnapBlock(); uint64(last) > head.Number.Uint64() {
-			utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.Number.Uint64())
+		first, last = uint64(f), uint64(l)
+		if head := chain.CurrentSnapBlock(); last > head.Number.Uint64() {
+			utils.Fatalf("Export error: block number %d larger than head block %d\n", last, head.Number.Uint64())
 		}
-		err = utils.ExportAppendChain(chain, fp, uint64(first), uint64(last))
+		err = utils.ExportAppendChain(chain, fp, first, last)
 	}
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
 	fmt.Printf("Export done in %v\n", time.Since(start))
+	printEraStats(chain, first, last)
 	return nil
 }
 
+// printEraStats prints the blocks per consensus era and the PoA sealers of the
+// given range of the chain, if it transitions from PoS to PoA consensus.
+func printEraStats(chain *core.BlockChain, first, last uint64) {
+	engine, ok := chain.Engine().(*hybrid.Hybrid)
+	if !ok || first > last {
+		return
+	}
+	stats, err := engine.EraStats(chain, first, last)
+	if err != nil {
+		log.Error("Failed to collect era statistics", "err", err)
+		return
+	}
+	fmt.Println()
+	fmt.Print(stats)
+}
+
 func importHistory(ctx *cli.Context) error {
 	if ctx.Args().Len() != 1 {
 		utils.Fatalf("usage: %s", ctx.Command.ArgsUsage)
@@ -231,6 +231,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		v := ctx.Uint64(utils.OverrideVerkle.Name)
 		cfg.Eth.OverrideVerkle = &v
 	}
+	if ctx.IsSet(utils.HybridTransitionBlockFlag.Name) {
+		v := ctx.Uint64(utils.HybridTransitionBlockFlag.Name)
+		cfg.Eth.OverrideTransitionBlock = &v
+	}
 
 	// Start metrics export if enabled
 	utils.SetupMetrics(&cfg.Metrics)
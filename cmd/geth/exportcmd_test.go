@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -43,3 +44,38 @@ func TestExport(t *testing.T) {
 		t.Fatalf("wrong content exported")
 	}
 }
+
+// TestExportEraStats tests that "geth export" prints the consensus era statistics
+// of PoS to PoA transition networks.
+func TestExportEraStats(t *testing.T) {
+	t.Parallel()
+	genesis := `{
+		"config": {
+			"chainId": 15,
+			"homesteadBlock": 0,
+			"eip150Block": 0,
+			"eip155Block": 0,
+			"eip158Block": 0,
+			"byzantiumBlock": 0,
+			"constantinopleBlock": 0,
+			"petersburgBlock": 0,
+			"terminalTotalDifficulty": 0,
+			"clique": {"period": 5, "epoch": 30000},
+			"posToPoaTransitionBlock": 10,
+			"poaInitialSigners": ["0x02f0d131f1f97aef08aec6e3291b957d9efe7105"]
+		},
+		"difficulty": "0",
+		"gasLimit": "8000000",
+		"alloc": {}
+	}`
+	datadir := t.TempDir()
+	json := filepath.Join(datadir, "genesis.json")
+	if err := os.WriteFile(json, []byte(genesis), 0600); err != nil {
+		t.Fatalf("failed to write genesis file: %v", err)
+	}
+	runGeth(t, "--datadir", datadir, "init", json).WaitExit()
+
+	geth := runGeth(t, "--datadir", datadir, "--hybrid.strict=false", "export", filepath.Join(datadir, "export.rlp"))
+	geth.ExpectRegexp(`(?s)Consensus eras of blocks 0-0:\s+PoS blocks:\s+1\s+PoA blocks:\s+0\s+Transition block: 10 \(outside the range\)\s+Sealers seen:\s+0`)
+	geth.ExpectExit()
+}
@@ -0,0 +1,459 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/filtermaps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	hybridFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First block of the range (default = 1)",
+	}
+	hybridToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last block of the range (default = head block)",
+	}
+	hybridWindowFlag = &cli.Uint64Flag{
+		Name:  "window",
+		Usage: "Number of blocks on each side of the transition block",
+		Value: 1024,
+	}
+
+	hybridCommand = &cli.Command{
+		Name:  "hybrid",
+		Usage: "A set of commands for PoS to PoA transition networks",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "check-genesis",
+				Usage:     "Validate the genesis file of a PoS to PoA transition network",
+				ArgsUsage: "<genesisPath>",
+				Action:    checkHybridGenesis,
+				Flags:     []cli.Flag{utils.HybridStrictFlag},
+				Description: `
+geth hybrid check-genesis <genesisPath>
+
+The genesis file is checked for the mistakes that most often prevent a hybrid
+network from launching: a malformed clique extraData, transition fields that
+are inconsistent with the clique period and epoch, a missing terminal total
+difficulty and initial signers that are not checksummed. Unknown chain config
+fields, which geth would otherwise silently ignore, are reported as well. With
+--hybrid.strict, missing initial signers are reported as an error instead of a
+warning, as the engine refuses the placeholder defaults in strict mode.
+`,
+			},
+			{
+				Name:      "checkpoint-extra",
+				Usage:     "Print the checkpoint extraData for a list of signers",
+				ArgsUsage: "<signer> [<signer>...]",
+				Action:    checkpointExtra,
+				Description: `
+geth hybrid checkpoint-extra <signer> [<signer>...]
+
+Prints the extraData of a clique checkpoint block listing the given signers in
+order, built the same way as the transition block. External tooling and other
+clients can compare their expectations against it byte for byte.
+`,
+			},
+			{
+				Name:      "export-plan",
+				Usage:     "Print the effective transition configuration as JSON",
+				ArgsUsage: "[<genesisPath>]",
+				Action:    exportPlan,
+				Flags:     slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth hybrid export-plan [<genesisPath>]
+
+Prints the transition plan of the chain in the database, or of the given genesis
+file, as a JSON document: the transition block, the initial signers and their
+checkpoint extraData, the clique period and epoch, and the gas ceilings and
+protocol activations resolved to absolute blocks. Once the transition block is
+part of the local chain, its hash is pinned in the plan too.
+
+Plans exported on different validators must be identical, which is easily
+verified by comparing them byte for byte.
+`,
+			},
+			{
+				Name:      "wizard",
+				Usage:     "Interactively create the genesis and signer files of a new hybrid network",
+				ArgsUsage: "[<outputDir>]",
+				Action:    hybridNetworkWizard,
+				Description: `
+geth hybrid wizard [<outputDir>]
+
+Walks through the settings of a new PoS to PoA transition network: the chain
+ID, the clique period and epoch, the PoS era parameters, the transition block
+and the initial signers. It writes the genesis file, a signer file usable with
+--hybrid.signerfile and, if enode URLs of the signer nodes are given, a config
+file section connecting them statically. Existing files are never overwritten.
+`,
+			},
+			{
+				Name:      "fixture",
+				Usage:     "Generate a chain crossing the transition for import by test nodes",
+				ArgsUsage: "[<outputDir>]",
+				Action:    hybridFixture,
+				Flags:     []cli.Flag{fixtureSignersFlag, fixtureTransitionFlag, fixtureBlocksFlag},
+				Description: `
+geth hybrid fixture --signers <n> [<outputDir>]
+
+Generates, offline, a chain of an ephemeral network whose n signers are freshly
+generated: PoS blocks up to the transition block, then PoA blocks sealed in
+turn by the signers through the hybrid engine. Every block is imported into an
+in-memory chain as it is produced, and the command fails unless the hybrid
+engine accepts all of them and executes the transition.
+
+The genesis and the blocks are written to genesis.json and chain.rlp, ready for
+"geth init" and "geth import". Nothing is sent over the network; nodes only see
+the chain once it is imported. Existing files are never overwritten.
+`,
+			},
+			{
+				Name:   "replay",
+				Usage:  "Re-execute and re-verify a range of stored blocks through the hybrid engine",
+				Action: replayHybrid,
+				Flags:  slices.Concat([]cli.Flag{hybridFromFlag, hybridToFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth hybrid replay --from <block> --to <block>
+
+Every block in the range is verified by the consensus engine responsible for
+its era and re-executed on top of its parent state. The resulting state root,
+receipts and gas usage are compared against the stored block. This confirms
+that the database around the transition is internally consistent, e.g. after
+an incident. The state of each block's parent must be available.
+`,
+			},
+			{
+				Name:   "prune-snapshots",
+				Usage:  "Delete the clique snapshots of the PoS era",
+				Action: pruneHybridSnapshots,
+				Flags:  slices.Concat(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth hybrid prune-snapshots
+
+Deletes the clique snapshots persisted for blocks before the transition, e.g.
+the ones stored while shadow verifying PoS blocks. The PoA engine founds its
+snapshots on the initial signers at the block before the transition, so they
+are never read again. The transition block must be final. Running nodes prune
+the snapshots by themselves once the transition is final.
+`,
+			},
+			{
+				Name:   "reindex",
+				Usage:  "Rebuild the transaction and log indexes around the transition",
+				Action: reindexHybrid,
+				Flags:  slices.Concat([]cli.Flag{hybridWindowFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth hybrid reindex --window <blocks>
+
+Repairs the chain indexes around the transition block, e.g. after a reorg
+across the boundary left them stale. The transaction lookup entries of the
+canonical blocks within the window on each side of the transition are written
+again. The log index is reverted from the start of the window on and rendered
+again by the node at its next start.
+`,
+			},
+		},
+	}
+)
+
+// exportPlan prints the effective transition plan of the local chain or of a
+// genesis file.
+func exportPlan(ctx *cli.Context) error {
+	var (
+		config  *params.ChainConfig
+		genesis common.Hash
+		pinned  common.Hash
+	)
+	switch ctx.Args().Len() {
+	case 0:
+		stack, _ := makeConfigNode(ctx)
+		defer stack.Close()
+
+		db := utils.MakeChainDatabase(ctx, stack, true)
+		defer db.Close()
+
+		if genesis = rawdb.ReadCanonicalHash(db, 0); genesis == (common.Hash{}) {
+			return errors.New("no chain in the database, pass a genesis file instead")
+		}
+		if config = rawdb.ReadChainConfig(db, genesis); config == nil {
+			return errors.New("no chain config in the database")
+		}
+		if config.PoSToPoATransitionBlock != nil {
+			pinned = rawdb.ReadCanonicalHash(db, config.PoSToPoATransitionBlock.Uint64())
+		}
+	case 1:
+		data, err := os.ReadFile(ctx.Args().First())
+		if err != nil {
+			return fmt.Errorf("failed to read genesis file: %v", err)
+		}
+		gen := new(core.Genesis)
+		if err := json.Unmarshal(data, gen); err != nil {
+			return fmt.Errorf("invalid genesis file: %v", err)
+		}
+		if gen.Config == nil {
+			return errors.New("genesis file has no chain config")
+		}
+		config, genesis = gen.Config, gen.ToBlock().Hash()
+	default:
+		return errors.New("need at most a genesis.json file as argument")
+	}
+	plan, err := hybrid.NewPlan(config, genesis)
+	if err != nil {
+		return err
+	}
+	if pinned != (common.Hash{}) {
+		plan.TransitionHash = &pinned
+	}
+	out, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// checkHybridGenesis validates a hybrid genesis file and reports every issue.
+func checkHybridGenesis(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("need genesis.json file as the only argument")
+	}
+	data, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to read genesis file: %v", err)
+	}
+	issues, err := hybrid.CheckGenesis(data, ctx.Bool(utils.HybridStrictFlag.Name))
+	if err != nil {
+		return err
+	}
+	var failures int
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if !issue.Warning {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("genesis file has %d error(s)", failures)
+	}
+	fmt.Printf("Genesis file is valid (%d warning(s))\n", len(issues))
+	return nil
+}
+
+// checkpointExtra prints the checkpoint extraData for the given signers.
+func checkpointExtra(ctx *cli.Context) error {
+	if ctx.Args().Len() == 0 {
+		return errors.New("need at least one signer address")
+	}
+	signers := make([]common.Address, 0, ctx.Args().Len())
+	for _, arg := range ctx.Args().Slice() {
+		if !common.IsHexAddress(arg) {
+			return fmt.Errorf("invalid signer address %q", arg)
+		}
+		signers = append(signers, common.HexToAddress(arg))
+	}
+	fmt.Println(hexutil.Encode(hybrid.CheckpointExtra(signers)))
+	return nil
+}
+
+// replayHybrid re-verifies and re-executes a range of stored blocks.
+func replayHybrid(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+
+	var (
+		config = chain.Config()
+		from   = max(ctx.Uint64(hybridFromFlag.Name), 1) // genesis has nothing to replay
+		to     = chain.CurrentBlock().Number.Uint64()
+	)
+	if ctx.IsSet(hybridToFlag.Name) {
+		to = ctx.Uint64(hybridToFlag.Name)
+	}
+	if from > to {
+		return fmt.Errorf("invalid range: from %d is above to %d", from, to)
+	}
+	if config.PoSToPoATransitionBlock == nil {
+		log.Warn("Chain has no PoS to PoA transition configured")
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		verified int
+		failures int
+		missing  int
+	)
+	for number := from; number <= to; number++ {
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block %d not found", number)
+		}
+		era := "PoS"
+		if config.IsPoSToPoATransition(block.Number()) {
+			era = "PoA"
+		}
+		if err := chain.Engine().VerifyHeader(chain, block.Header()); err != nil {
+			log.Error("Block failed consensus verification", "number", number, "hash", block.Hash(), "era", era, "err", err)
+			failures++
+			continue
+		}
+		parent := chain.GetHeader(block.ParentHash(), number-1)
+		if parent == nil {
+			return fmt.Errorf("parent of block %d not found", number)
+		}
+		statedb, err := chain.StateAt(parent.Root)
+		if err != nil {
+			log.Warn("Parent state unavailable, skipping re-execution", "number", number, "root", parent.Root, "err", err)
+			missing++
+			continue
+		}
+		res, err := chain.Processor().Process(block, statedb, *chain.GetVMConfig())
+		if err == nil {
+			err = chain.Validator().ValidateState(block, statedb, res, false)
+		}
+		if err != nil {
+			log.Error("Block failed re-execution", "number", number, "hash", block.Hash(), "era", era, "err", err)
+			failures++
+			continue
+		}
+		verified++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Replaying blocks", "number", number, "remaining", to-number, "elapsed", time.Since(start))
+			logged = time.Now()
+		}
+	}
+	fmt.Printf("Replayed blocks %d-%d in %v: %d consistent, %d failed, %d skipped (missing state)\n",
+		from, to, time.Since(start), verified, failures, missing)
+	if failures > 0 {
+		return errors.New("replay found inconsistent blocks")
+	}
+	return nil
+}
+
+// reindexHybrid rebuilds the transaction index and reverts the log index around
+// the transition block.
+func reindexHybrid(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	transition := chain.Config().PoSToPoATransitionBlock
+	if transition == nil {
+		return errors.New("chain has no PoS to PoA transition configured")
+	}
+	var (
+		head   = chain.CurrentBlock()
+		window = ctx.Uint64(hybridWindowFlag.Name)
+		from   = transition.Uint64() - min(window, transition.Uint64())
+		to     = min(transition.Uint64()+window, head.Number.Uint64())
+	)
+	if from > head.Number.Uint64() {
+		return fmt.Errorf("chain head %d is before the reindexed range starting at %d", head.Number, from)
+	}
+	// Write the lookup entries of the indexed canonical transactions again,
+	// overriding the ones left pointing at blocks reorged out
+	var indexed int
+	if tail := rawdb.ReadTxIndexTail(db); tail != nil {
+		batch := db.NewBatch()
+		for number := max(from, *tail); number <= to; number++ {
+			block := chain.GetBlockByNumber(number)
+			if block == nil {
+				return fmt.Errorf("block %d not found", number)
+			}
+			rawdb.WriteTxLookupEntriesByBlock(batch, block)
+			indexed += len(block.Transactions())
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	} else {
+		log.Warn("Transaction index not initialized, skipping")
+	}
+	// Revert the log index, the node renders the reverted maps again
+	view := filtermaps.NewChainView(chain, head.Number.Uint64(), head.Hash())
+	cutoff, _ := chain.HistoryPruningCutoff()
+	fm, err := filtermaps.NewFilterMaps(db, view, cutoff, 0, filtermaps.DefaultParams, filtermaps.Config{})
+	if err != nil {
+		return err
+	}
+	fm.RevertFrom(from)
+
+	fmt.Printf("Reindexed %d transactions in blocks %d-%d, log index reverted from block %d\n", indexed, from, to, from)
+	return nil
+}
+
+// pruneHybridSnapshots deletes the clique snapshots of the PoS era.
+func pruneHybridSnapshots(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return errors.New("no chain in the database")
+	}
+	config := rawdb.ReadChainConfig(db, genesis)
+	if config == nil || !config.HasPoSToPoATransition() {
+		return errors.New("chain has no PoS to PoA transition configured")
+	}
+	transition, ok := rawdb.ReadResolvedTransition(db)
+	if !ok {
+		if config.PoSToPoATransitionBlock == nil {
+			return errors.New("transition block not reached yet")
+		}
+		transition = config.PoSToPoATransitionBlock.Uint64()
+	}
+	head := rawdb.ReadHeadHeader(db)
+	if head == nil {
+		return errors.New("no head header in the database")
+	}
+	if depth := config.TransitionConfirmations(); depth == 0 || head.Number.Uint64() < transition+depth {
+		return fmt.Errorf("transition block %d is not final at head %d, %d confirmations needed", transition, head.Number, depth)
+	}
+	start := time.Now()
+	pruned, err := hybrid.PrunePoSSnapshots(db, transition)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pruned %d PoS era clique snapshots of transition block %d in %v\n", pruned, transition, common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// initHybridChain initializes a datadir with a generated chain crossing the PoS
+// to PoA transition at block 4, up to block 8.
+func initHybridChain(t *testing.T, gcmode string) string {
+	datadir := t.TempDir()
+	runGeth(t, "hybrid", "fixture", "--signers", "3", "--transition", "4", "--blocks", "4", datadir).WaitExit()
+
+	runGeth(t, "--datadir", datadir, "--state.scheme", "hash", "init", filepath.Join(datadir, fixtureGenesisFile)).WaitExit()
+	runGeth(t, "--datadir", datadir, "--gcmode", gcmode, "import", filepath.Join(datadir, fixtureChainFile)).WaitExit()
+	return datadir
+}
+
+// TestHybridReplay tests that "geth hybrid replay" re-verifies and re-executes
+// a stored chain across the PoS to PoA transition.
+func TestHybridReplay(t *testing.T) {
+	t.Parallel()
+	datadir := initHybridChain(t, "archive")
+
+	// The whole chain is consistent, on both sides of the transition
+	geth := runGeth(t, "--datadir", datadir, "hybrid", "replay")
+	geth.ExpectRegexp(`Replayed blocks 1-8 in .*: 8 consistent, 0 failed, 0 skipped \(missing state\)`)
+	geth.ExpectExit()
+
+	geth = runGeth(t, "--datadir", datadir, "hybrid", "replay", "--from", "3", "--to", "5")
+	geth.ExpectRegexp(`Replayed blocks 3-5 in .*: 3 consistent, 0 failed, 0 skipped \(missing state\)`)
+	geth.ExpectExit()
+}
+
+// TestHybridReplayPruned tests that "geth hybrid replay" skips the blocks whose
+// parent state was pruned instead of failing them.
+func TestHybridReplayPruned(t *testing.T) {
+	t.Parallel()
+	datadir := initHybridChain(t, "full")
+
+	// Only the genesis state and the ones persisted on shutdown are available
+	geth := runGeth(t, "--datadir", datadir, "hybrid", "replay")
+	geth.ExpectRegexp(`Replayed blocks 1-8 in .*: 2 consistent, 0 failed, 6 skipped \(missing state\)`)
+	geth.ExpectExit()
+}
@@ -0,0 +1,277 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	fixtureGenesisFile = "genesis.json"
+	fixtureChainFile   = "chain.rlp"
+)
+
+var (
+	fixtureSignersFlag = &cli.IntFlag{
+		Name:  "signers",
+		Usage: "Number of PoA signers of the fixture network",
+		Value: 3,
+	}
+	fixtureTransitionFlag = &cli.Uint64Flag{
+		Name:  "transition",
+		Usage: "Transition block of the fixture network",
+		Value: 8,
+	}
+	fixtureBlocksFlag = &cli.Uint64Flag{
+		Name:  "blocks",
+		Usage: "Number of PoA blocks to produce past the transition",
+		Value: 8,
+	}
+)
+
+// fixtureNetwork is an ephemeral hybrid network whose signers are all known.
+type fixtureNetwork struct {
+	genesis *core.Genesis
+	keys    map[common.Address]*ecdsa.PrivateKey
+	signers []common.Address // Initial signers, sorted as clique orders them
+	faucet  *ecdsa.PrivateKey
+}
+
+// newFixtureNetwork creates a network of n freshly generated signers, with the
+// PoS era running up to the transition block and a funded faucet account.
+func newFixtureNetwork(n int, transition uint64) (*fixtureNetwork, error) {
+	if n < 1 {
+		return nil, errors.New("need at least one signer")
+	}
+	if transition < 1 {
+		return nil, errors.New("the transition block must follow the genesis")
+	}
+	network := &fixtureNetwork{keys: make(map[common.Address]*ecdsa.PrivateKey)}
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		network.keys[addr] = key
+		network.signers = append(network.signers, addr)
+	}
+	slices.SortFunc(network.signers, func(a, b common.Address) int { return bytes.Compare(a[:], b[:]) })
+
+	faucet, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	network.faucet = faucet
+
+	// Clique rejects the Shanghai and later forks, so the network runs the London
+	// rule set in both eras. The transition block doubles as the first epoch.
+	config := *params.AllCliqueProtocolChanges
+	config.ChainID = big.NewInt(1337)
+	config.TerminalTotalDifficulty = common.Big0
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: transition}
+	config.PoSToPoATransitionBlock = new(big.Int).SetUint64(transition)
+	config.PoAInitialSigners = network.signers
+
+	network.genesis = &core.Genesis{
+		Config:     &config,
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+		Alloc: types.GenesisAlloc{
+			crypto.PubkeyToAddress(faucet.PublicKey): {Balance: new(big.Int).Lsh(common.Big1, 128)},
+		},
+	}
+	return network, nil
+}
+
+// generate produces the first n blocks of the network: PoS blocks up to the
+// transition block, then PoA blocks sealed in turn by the signers through the
+// hybrid engine. Every block carries a transfer from the faucet, so that both
+// eras execute transactions. The blocks are imported into an in-memory chain
+// as they are produced, which fails generation on any block the hybrid engine
+// does not accept.
+func (network *fixtureNetwork) generate(n int) ([]*types.Block, error) {
+	var (
+		config     = network.genesis.Config
+		transition = config.PoSToPoATransitionBlock.Uint64()
+		faucet     = crypto.PubkeyToAddress(network.faucet.PublicKey)
+		signer     = types.LatestSigner(config)
+	)
+	maker, err := hybrid.NewFromChainConfig(config, rawdb.NewMemoryDatabase())
+	if err != nil {
+		return nil, err
+	}
+	defer maker.Close()
+
+	// Blocks are generated ten seconds apart, start far enough in the past for
+	// the last one not to be a future block.
+	network.genesis.Timestamp = uint64(time.Now().Unix()) - uint64(n+1)*10
+
+	var genErr error
+	_, blocks, _ := core.GenerateChainWithGenesis(network.genesis, maker, n, func(i int, gen *core.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(faucet), common.Address{0xbb}, common.Big1, params.TxGas, gen.BaseFee(), nil), signer, network.faucet)
+		if err != nil {
+			genErr = err
+			return
+		}
+		gen.AddTx(tx)
+		if gen.Number().Uint64() >= transition {
+			gen.SetDifficulty(big.NewInt(2)) // Every block is sealed by the in-turn signer
+		}
+	})
+	if genErr != nil {
+		return nil, genErr
+	}
+	db := rawdb.NewMemoryDatabase()
+	engine, err := hybrid.NewFromChainConfig(config, db)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := core.NewBlockChain(db, network.genesis, engine, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Stop()
+
+	for i, block := range blocks {
+		// Seal the PoA blocks with the in-turn signer, relinking each one to its
+		// sealed parent
+		if number := block.NumberU64(); number >= transition {
+			header := block.Header()
+			if i > 0 {
+				header.ParentHash = blocks[i-1].Hash()
+			}
+			if number%config.Clique.Epoch == 0 {
+				header.Extra = hybrid.CheckpointExtra(network.signers)
+			} else {
+				header.Extra = make([]byte, 32+crypto.SignatureLength)
+			}
+			if block, err = network.seal(engine, chain, block.WithSeal(header)); err != nil {
+				return nil, fmt.Errorf("failed to seal block %d: %v", number, err)
+			}
+			blocks[i] = block
+		}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			return nil, fmt.Errorf("failed to import block %d: %v", block.NumberU64(), err)
+		}
+	}
+	if status := engine.Status(chain.CurrentBlock().Number.Uint64()); uint64(n) >= transition && !status.Executed {
+		return nil, errors.New("transition not executed")
+	}
+	return blocks, nil
+}
+
+// seal seals the block through the engine with the key of the in-turn signer.
+func (network *fixtureNetwork) seal(engine *hybrid.Hybrid, chain *core.BlockChain, block *types.Block) (*types.Block, error) {
+	var (
+		addr = network.signers[block.NumberU64()%uint64(len(network.signers))]
+		key  = network.keys[addr]
+	)
+	err := engine.Authorize(addr, func(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, block, results, nil); err != nil {
+		return nil, err
+	}
+	select {
+	case sealed := <-results:
+		return sealed, nil
+	case <-time.After(time.Minute):
+		return nil, errors.New("sealing timed out")
+	}
+}
+
+// write stores the genesis and the blocks of the network in dir, refusing to
+// overwrite any existing file.
+func (network *fixtureNetwork) write(dir string, blocks []*types.Block) error {
+	for _, name := range []string{fixtureGenesisFile, fixtureChainFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("%s already exists in %s", name, dir)
+		}
+	}
+	genesis, err := json.MarshalIndent(network.genesis, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, fixtureGenesisFile), append(genesis, '\n'), 0644); err != nil {
+		return err
+	}
+	var chain bytes.Buffer
+	for _, block := range blocks {
+		if err := rlp.Encode(&chain, block); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, fixtureChainFile), chain.Bytes(), 0644)
+}
+
+// hybridFixture generates a chain crossing the transition on an ephemeral
+// network and writes it, along with its genesis, for import by other nodes.
+func hybridFixture(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		return errors.New("need at most the output directory as argument")
+	}
+	dir := "."
+	if ctx.Args().Len() == 1 {
+		dir = ctx.Args().First()
+	}
+	var (
+		signers    = ctx.Int(fixtureSignersFlag.Name)
+		transition = ctx.Uint64(fixtureTransitionFlag.Name)
+		extra      = ctx.Uint64(fixtureBlocksFlag.Name)
+	)
+	network, err := newFixtureNetwork(signers, transition)
+	if err != nil {
+		return err
+	}
+	blocks, err := network.generate(int(transition + extra))
+	if err != nil {
+		return err
+	}
+	if err := network.write(dir, blocks); err != nil {
+		return err
+	}
+	head := blocks[len(blocks)-1]
+	fmt.Printf("Wrote %d blocks to %s, head %d [%x], %d blocks past transition block %d\n",
+		len(blocks), dir, head.NumberU64(), head.Hash().Bytes()[:4], head.NumberU64()-transition, transition)
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// Tests that the blocks of the fixture are sealed in turn by the signers and
+// accepted by a fresh chain running the hybrid engine, across the transition.
+func TestFixtureChain(t *testing.T) {
+	network, err := newFixtureNetwork(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := network.generate(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := rawdb.NewMemoryDatabase()
+	engine, err := hybrid.NewFromChainConfig(network.genesis.Config, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := core.NewBlockChain(db, network.genesis, engine, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert: %v", blocks[n].NumberU64(), err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, blocks[len(blocks)-1].NumberU64())
+	}
+	for _, block := range blocks[3:] {
+		want := network.signers[block.NumberU64()%uint64(len(network.signers))]
+		if signer, err := engine.Author(block.Header()); err != nil || signer != want {
+			t.Errorf("block %d: signer mismatch: have %v (%v), want %v", block.NumberU64(), signer, err, want)
+		}
+	}
+}
+
+// Tests that the fixture files are not written over existing ones.
+func TestFixtureWrite(t *testing.T) {
+	network, err := newFixtureNetwork(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := network.generate(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := network.write(dir, blocks); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	if err := network.write(dir, blocks); err == nil {
+		t.Fatal("fixture written over existing files")
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+// Files written by the hybrid network wizard.
+const (
+	wizardGenesisFile     = "genesis.json"
+	wizardSignerFile      = "signers.txt"
+	wizardStaticNodesFile = "static-nodes.toml"
+)
+
+// wizardNetwork is the outcome of the hybrid network wizard.
+type wizardNetwork struct {
+	Genesis     *core.Genesis
+	Signers     []common.Address
+	StaticNodes []*enode.Node
+}
+
+// hybridWizard interactively collects the settings of a new hybrid network.
+type hybridWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// newHybridWizard creates a wizard prompting on out and reading answers from in.
+func newHybridWizard(in io.Reader, out io.Writer) *hybridWizard {
+	return &hybridWizard{in: bufio.NewReader(in), out: out}
+}
+
+// read prompts for a line of input and returns it with surrounding whitespace
+// removed. Running out of input is an error, as every question needs an answer.
+func (w *hybridWizard) read() (string, error) {
+	fmt.Fprint(w.out, "> ")
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readUint64 reads a number of at least min, returning def on an empty answer.
+func (w *hybridWizard) readUint64(def *uint64, min uint64) (uint64, error) {
+	for {
+		text, err := w.read()
+		if err != nil {
+			return 0, err
+		}
+		if text == "" && def != nil {
+			return *def, nil
+		}
+		n, err := strconv.ParseUint(text, 0, 64)
+		switch {
+		case err != nil:
+			fmt.Fprintln(w.out, "Invalid number, please retry")
+		case n < min:
+			fmt.Fprintf(w.out, "Number must be at least %d, please retry\n", min)
+		default:
+			return n, nil
+		}
+	}
+}
+
+// readYesNo reads a yes or no answer, returning def on an empty answer.
+func (w *hybridWizard) readYesNo(def bool) (bool, error) {
+	for {
+		text, err := w.read()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(text) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(w.out, "Please answer yes or no")
+	}
+}
+
+// readAddresses reads addresses one per line until an empty answer, skipping
+// duplicates. At least min addresses are required.
+func (w *hybridWizard) readAddresses(min int) ([]common.Address, error) {
+	var addrs []common.Address
+	for {
+		text, err := w.read()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case text == "" && len(addrs) >= min:
+			return addrs, nil
+		case text == "":
+			fmt.Fprintf(w.out, "At least %d address(es) required\n", min)
+		case !common.IsHexAddress(text):
+			fmt.Fprintln(w.out, "Invalid address, please retry")
+		case slices.Contains(addrs, common.HexToAddress(text)):
+			fmt.Fprintln(w.out, "Address already listed")
+		default:
+			addrs = append(addrs, common.HexToAddress(text))
+		}
+	}
+}
+
+// readEnodes reads enode URLs one per line until an empty answer.
+func (w *hybridWizard) readEnodes() ([]*enode.Node, error) {
+	var nodes []*enode.Node
+	for {
+		text, err := w.read()
+		if err != nil {
+			return nil, err
+		}
+		if text == "" {
+			return nodes, nil
+		}
+		node, err := enode.Parse(enode.ValidSchemes, text)
+		if err != nil {
+			fmt.Fprintf(w.out, "Invalid enode URL: %v, please retry\n", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// run asks for every setting of the network and assembles its genesis.
+func (w *hybridWizard) run() (*wizardNetwork, error) {
+	var (
+		period   = uint64(5)
+		epoch    = uint64(30000)
+		gasLimit = uint64(30_000_000)
+		ttd      = uint64(0)
+		depth    = uint64(params.DefaultTransitionConfirmationDepth)
+	)
+	fmt.Fprintln(w.out, "Which chain ID should the network use? It must not collide with other networks.")
+	chainID, err := w.readUint64(nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	// Clique settings, sealing the blocks after the transition
+	fmt.Fprintln(w.out)
+	fmt.Fprintf(w.out, "How many seconds should PoA blocks take? (default = %d)\n", period)
+	if period, err = w.readUint64(&period, 0); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w.out, "How many blocks should a clique epoch span? (default = %d)\n", epoch)
+	if epoch, err = w.readUint64(&epoch, 1); err != nil {
+		return nil, err
+	}
+	// PoS era settings, the network launches driven by a consensus client
+	fmt.Fprintln(w.out)
+	fmt.Fprintf(w.out, "What gas limit should the genesis block have? (default = %d)\n", gasLimit)
+	if gasLimit, err = w.readUint64(&gasLimit, params.MinGasLimit); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w.out, "At which terminal total difficulty does the PoS era start? (default = %d)\n", ttd)
+	if ttd, err = w.readUint64(&ttd, 0); err != nil {
+		return nil, err
+	}
+	// Transition settings
+	fmt.Fprintln(w.out)
+	fmt.Fprintf(w.out, "At which block should the network switch from PoS to PoA? (default = %d)\n", epoch)
+	transition, err := w.readUint64(&epoch, 1)
+	if err != nil {
+		return nil, err
+	}
+	if aligned := (transition + epoch - 1) / epoch * epoch; aligned != transition {
+		fmt.Fprintf(w.out, "Block %d is not aligned to the clique epoch, round it up to %d? (default = yes)\n", transition, aligned)
+		round, err := w.readYesNo(true)
+		if err != nil {
+			return nil, err
+		}
+		if round {
+			transition = aligned
+		}
+	}
+	fmt.Fprintf(w.out, "How many blocks must bury the transition block before it is final? (default = %d)\n", depth)
+	if depth, err = w.readUint64(&depth, 0); err != nil {
+		return nil, err
+	}
+	// Accounts sealing and funding the network
+	fmt.Fprintln(w.out)
+	fmt.Fprintln(w.out, "Which accounts are allowed to seal after the transition? (mandatory at least one)")
+	signers, err := w.readAddresses(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(signers)%2 == 0 {
+		fmt.Fprintf(w.out, "Warning: an even number of signers (%d) may deadlock votes\n", len(signers))
+	}
+	fmt.Fprintln(w.out, "Which accounts should be pre-funded? (advisable at least one)")
+	funded, err := w.readAddresses(0)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintln(w.out)
+	fmt.Fprintln(w.out, "What are the enode URLs of the signer nodes, to connect them statically? (optional)")
+	nodes, err := w.readEnodes()
+	if err != nil {
+		return nil, err
+	}
+	// Assemble the genesis. Clique rejects the Shanghai and later forks, so the
+	// network runs the London rule set in both eras.
+	config := *params.AllCliqueProtocolChanges
+	config.ChainID = new(big.Int).SetUint64(chainID)
+	config.TerminalTotalDifficulty = new(big.Int).SetUint64(ttd)
+	config.Clique = &params.CliqueConfig{Period: period, Epoch: epoch}
+	config.PoSToPoATransitionBlock = new(big.Int).SetUint64(transition)
+	config.PoAInitialSigners = signers
+	config.TransitionConfirmationDepth = &depth
+
+	genesis := &core.Genesis{
+		Config:     &config,
+		Timestamp:  uint64(time.Now().Unix()),
+		GasLimit:   gasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+		Alloc:      make(types.GenesisAlloc),
+	}
+	for _, addr := range funded {
+		genesis.Alloc[addr] = types.Account{Balance: new(big.Int).Lsh(big.NewInt(1), 256-7)}
+	}
+	return &wizardNetwork{Genesis: genesis, Signers: signers, StaticNodes: nodes}, nil
+}
+
+// write stores the files of the network in dir, refusing to overwrite any.
+func (n *wizardNetwork) write(dir string) ([]string, error) {
+	files := map[string][]byte{}
+
+	genesis, err := json.MarshalIndent(n.Genesis, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[wizardGenesisFile] = append(genesis, '\n')
+
+	signers := "# Initial PoA signers, usable with --hybrid.signerfile\n"
+	for _, signer := range n.Signers {
+		signers += signer.Hex() + "\n"
+	}
+	files[wizardSignerFile] = []byte(signers)
+
+	if len(n.StaticNodes) > 0 {
+		urls := make([]string, len(n.StaticNodes))
+		for i, node := range n.StaticNodes {
+			urls[i] = strconv.Quote(node.URLv4())
+		}
+		files[wizardStaticNodesFile] = []byte("# Static connections between the signer nodes, merge into their config files\n" +
+			"[Node.P2P]\nStaticNodes = [" + strings.Join(urls, ", ") + "]\n")
+	}
+	names := slices.Sorted(maps.Keys(files))
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil, fmt.Errorf("%s already exists in %s", name, dir)
+		}
+	}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// hybridNetworkWizard interactively creates the genesis and accompanying files
+// of a new hybrid network.
+func hybridNetworkWizard(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		return errors.New("need at most the output directory as argument")
+	}
+	dir := "."
+	if ctx.Args().Len() == 1 {
+		dir = ctx.Args().First()
+	}
+	network, err := newHybridWizard(os.Stdin, os.Stdout).run()
+	if err != nil {
+		return err
+	}
+	paths, err := network.write(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println("Wrote", path)
+	}
+	if len(network.StaticNodes) == 0 {
+		fmt.Println("Connect the signer nodes by listing their enode URLs under [Node.P2P] StaticNodes in their config files")
+	}
+	// Run the generated genesis through the same checks as check-genesis
+	data, err := os.ReadFile(filepath.Join(dir, wizardGenesisFile))
+	if err != nil {
+		return err
+	}
+	issues, err := hybrid.CheckGenesis(data, false)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+)
+
+func TestHybridWizard(t *testing.T) {
+	input := strings.Join([]string{
+		"12345", // chain ID
+		"",      // period
+		"100",   // epoch
+		"",      // gas limit
+		"abc",   // invalid terminal total difficulty
+		"0",     // terminal total difficulty
+		"250",   // transition block, not aligned to the epoch
+		"",      // round up
+		"64",    // confirmation depth
+		"0x01",  // invalid signer
+		"0x1000000000000000000000000000000000000001",
+		"0x2000000000000000000000000000000000000002",
+		"0x1000000000000000000000000000000000000001", // duplicate signer
+		"0x3000000000000000000000000000000000000003",
+		"", // end of signers
+		"0x4000000000000000000000000000000000000004", // funded account
+		"",
+		"enode://a979fb575495b8d6db44f750317d0f4622bf4c2aa3365d6af7c284339968eef29b69ad0dce72a4d8db5ebb4968de0e3bec910127f134779fbcb0cb6d3331163c@52.16.188.185:30303",
+		"",
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	network, err := newHybridWizard(strings.NewReader(input), &out).run()
+	if err != nil {
+		t.Fatalf("Wizard failed: %v\n%s", err, out.String())
+	}
+	config := network.Genesis.Config
+	if config.ChainID.Uint64() != 12345 || config.Clique.Period != 5 || config.Clique.Epoch != 100 {
+		t.Errorf("Chain settings mismatch: chain ID %v, clique %v", config.ChainID, config.Clique)
+	}
+	if config.ShanghaiTime != nil || config.CancunTime != nil {
+		t.Error("Forks unsupported by clique activated")
+	}
+	if config.PoSToPoATransitionBlock.Uint64() != 300 {
+		t.Errorf("Transition block mismatch: have %v, want 300", config.PoSToPoATransitionBlock)
+	}
+	if *config.TransitionConfirmationDepth != 64 {
+		t.Errorf("Confirmation depth mismatch: have %d, want 64", *config.TransitionConfirmationDepth)
+	}
+	if len(network.Signers) != 3 || len(config.PoAInitialSigners) != 3 {
+		t.Errorf("Signers mismatch: have %v", network.Signers)
+	}
+	if _, ok := network.Genesis.Alloc[common.HexToAddress("0x4000000000000000000000000000000000000004")]; !ok {
+		t.Error("Funded account missing from the genesis")
+	}
+	if len(network.StaticNodes) != 1 {
+		t.Errorf("Static nodes mismatch: have %d, want 1", len(network.StaticNodes))
+	}
+	// The written genesis must pass the hybrid genesis checks
+	dir := t.TempDir()
+	paths, err := network.write(dir)
+	if err != nil {
+		t.Fatalf("Failed to write network files: %v", err)
+	}
+	if len(paths) != 3 {
+		t.Errorf("Written files mismatch: have %v", paths)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, wizardGenesisFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := hybrid.CheckGenesis(data, false)
+	if err != nil {
+		t.Fatalf("Failed to check genesis: %v", err)
+	}
+	for _, issue := range issues {
+		if !issue.Warning {
+			t.Errorf("Genesis issue: %v", issue)
+		}
+	}
+	if _, err := network.write(dir); err == nil {
+		t.Error("Existing network files overwritten")
+	}
+	// Running out of answers fails instead of looping
+	if _, err := newHybridWizard(strings.NewReader("12345\n"), io.Discard).run(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected unexpected EOF, got %v", err)
+	}
+}
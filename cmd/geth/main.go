@@ -76,9 +76,12 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPoAPriceLimitFlag,
+		utils.TxPoolPoAAccountQueueFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
+		utils.BlobPoolPoADisabledFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
@@ -116,8 +119,30 @@ var (
 		utils.MinerEtherbaseFlag, // deprecated
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
+		utils.MinerPoAGasLimitFlag,
+		utils.MinerPoARecommitFlag,
+		utils.MinerPoANoEmptyFlag,
 		utils.MinerPendingFeeRecipientFlag,
 		utils.MinerNewPayloadTimeoutFlag, // deprecated
+		utils.HybridTransitionBlockFlag,
+		utils.HybridStrictFlag,
+		utils.HybridMinSignersFlag,
+		utils.HybridSignerFlag,
+		utils.HybridSignerFileFlag,
+		utils.HybridTransitionProofFlag,
+		utils.HybridCountdownWindowFlag,
+		utils.HybridShadowWindowFlag,
+		utils.HybridDualWindowFlag,
+		utils.HybridForkWindowFlag,
+		utils.HybridReorgWindowFlag,
+		utils.HybridFreezeWindowFlag,
+		utils.HybridAlertWebhookFlag,
+		utils.HybridAlertSecretFlag,
+		utils.HybridMissedSlotsFlag,
+		utils.HybridConsistencyCheckFlag,
+		utils.HybridMetricsFlag,
+		utils.HybridTransitionLogFlag,
+		utils.HybridTxIndexPoAOnlyFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
@@ -139,6 +164,8 @@ var (
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
+		utils.GpoPoABlocksFlag,
+		utils.GpoPoAMinTipFlag,
 		configFileFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
@@ -242,6 +269,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See hybridcmd.go
+		hybridCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
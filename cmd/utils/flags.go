@@ -41,6 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
@@ -427,6 +428,16 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPoAPriceLimitFlag = &cli.Uint64Flag{
+		Name:     "txpool.poa.pricelimit",
+		Usage:    "Minimum gas price tip to enforce after the PoS to PoA transition (0 = same as --txpool.pricelimit)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPoAAccountQueueFlag = &cli.Uint64Flag{
+		Name:     "txpool.poa.accountqueue",
+		Usage:    "Maximum number of non-executable transaction slots permitted per account after the PoS to PoA transition (0 = same as --txpool.accountqueue)",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -446,6 +457,11 @@ var (
 		Value:    ethconfig.Defaults.BlobPool.PriceBump,
 		Category: flags.BlobPoolCategory,
 	}
+	BlobPoolPoADisabledFlag = &cli.BoolFlag{
+		Name:     "blobpool.poa.disable",
+		Usage:    "Reject new blob transactions after the PoS to PoA transition",
+		Category: flags.BlobPoolCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -529,12 +545,137 @@ var (
 		Value:    ethconfig.Defaults.Miner.Recommit,
 		Category: flags.MinerCategory,
 	}
+	MinerPoAGasLimitFlag = &cli.Uint64Flag{
+		Name:     "miner.poa.gaslimit",
+		Usage:    "Target gas ceiling for blocks after the PoS to PoA transition (0 = same as --miner.gaslimit)",
+		Category: flags.MinerCategory,
+	}
+	MinerPoARecommitFlag = &cli.DurationFlag{
+		Name:     "miner.poa.recommit",
+		Usage:    "Time interval to recreate blocks after the PoS to PoA transition (0 = same as --miner.recommit)",
+		Category: flags.MinerCategory,
+	}
+	MinerPoANoEmptyFlag = &cli.BoolFlag{
+		Name:     "miner.poa.noempty",
+		Usage:    "Include transactions in the initial payload of blocks after the PoS to PoA transition",
+		Category: flags.MinerCategory,
+	}
 	MinerPendingFeeRecipientFlag = &cli.StringFlag{
 		Name:     "miner.pending.feeRecipient",
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
 		Category: flags.MinerCategory,
 	}
 
+	// Hybrid consensus settings
+	HybridTransitionBlockFlag = &cli.Uint64Flag{
+		Name:     "hybrid.transition.block",
+		Usage:    "Manually specify the PoS to PoA transition block, overriding the genesis setting until the transition is reached",
+		Category: flags.HybridCategory,
+	}
+	HybridStrictFlag = &cli.BoolFlag{
+		Name:     "hybrid.strict",
+		Usage:    "Refuse to start a PoS to PoA transition network with placeholder or too few initial signers, or with drifted consensus settings",
+		Value:    ethconfig.Defaults.Hybrid.Strict,
+		Category: flags.HybridCategory,
+	}
+	HybridMinSignersFlag = &cli.IntFlag{
+		Name:     "hybrid.minsigners",
+		Usage:    "Minimum number of initial PoA signers required in strict mode",
+		Value:    ethconfig.Defaults.Hybrid.MinSigners,
+		Category: flags.HybridCategory,
+	}
+	HybridCountdownWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.countdown",
+		Usage:    "Number of blocks before the transition for which the remaining blocks are logged (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.CountdownWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridShadowWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.shadowwindow",
+		Usage:    "Number of blocks before the transition in which PoS blocks are shadow verified against the PoA rules (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.ShadowWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridDualWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.dualwindow",
+		Usage:    "Number of blocks on either side of the transition verified by both engines (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.DualWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridForkWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.forkwindow",
+		Usage:    "Number of blocks on either side of the transition in which all observed branches are tracked (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.ForkWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridReorgWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.reorgwindow",
+		Usage:    "Number of blocks on either side of the transition reorgs touching are reported in detail (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.ReorgWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridFreezeWindowFlag = &cli.Uint64Flag{
+		Name:     "hybrid.freezewindow",
+		Usage:    "Number of blocks before the transition kept out of the ancient store until the transition is final (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.FreezeWindow,
+		Category: flags.HybridCategory,
+	}
+	HybridSignerFlag = &cli.StringFlag{
+		Name:     "hybrid.signer",
+		Usage:    "Comma separated 0x prefixed addresses of the local accounts sealing PoA blocks after the transition",
+		Category: flags.HybridCategory,
+	}
+	HybridSignerFileFlag = &cli.PathFlag{
+		Name:      "hybrid.signerfile",
+		Usage:     "Path to a file listing further addresses of local PoA signer accounts, one per line",
+		TakesFile: true,
+		Category:  flags.HybridCategory,
+	}
+	HybridTransitionProofFlag = &cli.PathFlag{
+		Name:      "hybrid.transition-proof",
+		Usage:     "Path to a transition record co-signed by the initial signers, bootstrapping the transition block of a fresh node",
+		TakesFile: true,
+		Category:  flags.HybridCategory,
+	}
+	HybridMetricsFlag = &cli.BoolFlag{
+		Name:     "hybrid.metrics",
+		Usage:    "Report the metrics of the hybrid consensus engine",
+		Value:    ethconfig.Defaults.Hybrid.Metrics,
+		Category: flags.HybridCategory,
+	}
+	HybridTransitionLogFlag = &cli.BoolFlag{
+		Name:     "hybrid.transitionlog",
+		Usage:    "Report a synthetic log recording the initial signers in the transition block over RPC",
+		Category: flags.HybridCategory,
+	}
+	HybridTxIndexPoAOnlyFlag = &cli.BoolFlag{
+		Name:     "hybrid.txindex.poaonly",
+		Usage:    "Index the transactions of the PoA era only, unindexing the ones before the transition",
+		Category: flags.HybridCategory,
+	}
+	HybridAlertWebhookFlag = &cli.StringFlag{
+		Name:     "hybrid.alert.webhook",
+		Usage:    "HTTPS endpoint critical transition and PoA network events are posted to",
+		Category: flags.HybridCategory,
+	}
+	HybridAlertSecretFlag = &flags.DirectoryFlag{
+		Name:     "hybrid.alert.secret",
+		Usage:    "Path to a file holding the secret the alert webhook payloads are signed with",
+		Category: flags.HybridCategory,
+	}
+	HybridConsistencyCheckFlag = &cli.DurationFlag{
+		Name:     "hybrid.consistencycheck",
+		Usage:    "Interval at which a random historical block is verified again to detect database corruption (0 = disabled)",
+		Value:    ethconfig.Defaults.Hybrid.ConsistencyCheck,
+		Category: flags.HybridCategory,
+	}
+	HybridMissedSlotsFlag = &cli.Uint64Flag{
+		Name:     "hybrid.alert.missedslots",
+		Usage:    "Number of consecutive in-turn slots a signer may miss before an alert is raised",
+		Value:    ethconfig.Defaults.Hybrid.MissedSlots,
+		Category: flags.HybridCategory,
+	}
+
 	// Account settings
 	PasswordFileFlag = &cli.PathFlag{
 		Name:      "password",
@@ -877,6 +1018,16 @@ var (
 		Value:    ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 		Category: flags.GasPriceCategory,
 	}
+	GpoPoABlocksFlag = &cli.IntFlag{
+		Name:     "gpo.poa.blocks",
+		Usage:    "Number of recent blocks to check for gas prices after the PoS to PoA transition (0 = derived from the PoA block period)",
+		Category: flags.GasPriceCategory,
+	}
+	GpoPoAMinTipFlag = &cli.Int64Flag{
+		Name:     "gpo.poa.mintip",
+		Usage:    "Lowest priority fee PoA signers include after the PoS to PoA transition, never recommending less",
+		Category: flags.GasPriceCategory,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = &cli.BoolFlag{
@@ -988,6 +1139,14 @@ var (
 		StateSchemeFlag,
 		HttpHeaderFlag,
 	}
+	// HybridVerifyFlags is the list of flags affecting how the blocks of a PoS to
+	// PoA transition network are verified by commands processing chain data.
+	HybridVerifyFlags = []cli.Flag{
+		HybridStrictFlag,
+		HybridMinSignersFlag,
+		HybridFreezeWindowFlag,
+		HybridTransitionProofFlag,
+	}
 )
 
 // default account to prefund when running Geth in dev mode
@@ -1452,6 +1611,17 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.IsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.Int64(GpoIgnoreGasPriceFlag.Name))
 	}
+	if ctx.IsSet(GpoPoABlocksFlag.Name) || ctx.IsSet(GpoPoAMinTipFlag.Name) {
+		if cfg.PoA == nil {
+			cfg.PoA = new(gasprice.EraConfig)
+		}
+		if ctx.IsSet(GpoPoABlocksFlag.Name) {
+			cfg.PoA.Blocks = ctx.Int(GpoPoABlocksFlag.Name)
+		}
+		if ctx.IsSet(GpoPoAMinTipFlag.Name) {
+			cfg.PoA.MinTip = big.NewInt(ctx.Int64(GpoPoAMinTipFlag.Name))
+		}
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
@@ -1495,6 +1665,17 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.Duration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolPoAPriceLimitFlag.Name) || ctx.IsSet(TxPoolPoAAccountQueueFlag.Name) {
+		if cfg.PoA == nil {
+			cfg.PoA = new(legacypool.EraConfig)
+		}
+		if ctx.IsSet(TxPoolPoAPriceLimitFlag.Name) {
+			cfg.PoA.PriceLimit = ctx.Uint64(TxPoolPoAPriceLimitFlag.Name)
+		}
+		if ctx.IsSet(TxPoolPoAAccountQueueFlag.Name) {
+			cfg.PoA.AccountQueue = ctx.Uint64(TxPoolPoAAccountQueueFlag.Name)
+		}
+	}
 }
 
 func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
@@ -1507,6 +1688,9 @@ func setBlobPool(ctx *cli.Context, cfg *blobpool.Config) {
 	if ctx.IsSet(BlobPoolPriceBumpFlag.Name) {
 		cfg.PriceBump = ctx.Uint64(BlobPoolPriceBumpFlag.Name)
 	}
+	if ctx.IsSet(BlobPoolPoADisabledFlag.Name) {
+		cfg.PoADisabled = ctx.Bool(BlobPoolPoADisabledFlag.Name)
+	}
 }
 
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
@@ -1529,6 +1713,100 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
 	}
+	if ctx.IsSet(MinerPoAGasLimitFlag.Name) || ctx.IsSet(MinerPoARecommitFlag.Name) || ctx.IsSet(MinerPoANoEmptyFlag.Name) {
+		if cfg.PoA == nil {
+			cfg.PoA = new(miner.EraConfig)
+		}
+		if ctx.IsSet(MinerPoAGasLimitFlag.Name) {
+			cfg.PoA.GasCeil = ctx.Uint64(MinerPoAGasLimitFlag.Name)
+		}
+		if ctx.IsSet(MinerPoARecommitFlag.Name) {
+			cfg.PoA.Recommit = ctx.Duration(MinerPoARecommitFlag.Name)
+		}
+		if ctx.IsSet(MinerPoANoEmptyFlag.Name) {
+			cfg.PoA.NoEmptyPayload = ctx.Bool(MinerPoANoEmptyFlag.Name)
+		}
+	}
+}
+
+// setHybrid applies the hybrid flags on top of the settings loaded from the
+// config file and reports every setting deviating from its built-in default,
+// along with where the value comes from.
+func setHybrid(ctx *cli.Context, cfg *ethconfig.Config) {
+	file := cfg.Hybrid
+	if ctx.IsSet(HybridStrictFlag.Name) {
+		cfg.Hybrid.Strict = ctx.Bool(HybridStrictFlag.Name)
+	}
+	if ctx.IsSet(HybridMinSignersFlag.Name) {
+		cfg.Hybrid.MinSigners = ctx.Int(HybridMinSignersFlag.Name)
+	}
+	if ctx.IsSet(HybridCountdownWindowFlag.Name) {
+		cfg.Hybrid.CountdownWindow = ctx.Uint64(HybridCountdownWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridShadowWindowFlag.Name) {
+		cfg.Hybrid.ShadowWindow = ctx.Uint64(HybridShadowWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridDualWindowFlag.Name) {
+		cfg.Hybrid.DualWindow = ctx.Uint64(HybridDualWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridForkWindowFlag.Name) {
+		cfg.Hybrid.ForkWindow = ctx.Uint64(HybridForkWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridReorgWindowFlag.Name) {
+		cfg.Hybrid.ReorgWindow = ctx.Uint64(HybridReorgWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridFreezeWindowFlag.Name) {
+		cfg.Hybrid.FreezeWindow = ctx.Uint64(HybridFreezeWindowFlag.Name)
+	}
+	if ctx.IsSet(HybridSignerFileFlag.Name) {
+		cfg.Hybrid.SignerFile = ctx.Path(HybridSignerFileFlag.Name)
+	}
+	if ctx.IsSet(HybridTransitionProofFlag.Name) {
+		cfg.Hybrid.TransitionProof = ctx.Path(HybridTransitionProofFlag.Name)
+	}
+	if ctx.IsSet(HybridMetricsFlag.Name) {
+		cfg.Hybrid.Metrics = ctx.Bool(HybridMetricsFlag.Name)
+	}
+	if ctx.IsSet(HybridTransitionLogFlag.Name) {
+		cfg.Hybrid.TransitionLog = ctx.Bool(HybridTransitionLogFlag.Name)
+	}
+	if ctx.IsSet(HybridTxIndexPoAOnlyFlag.Name) {
+		cfg.Hybrid.TxIndexPoAOnly = ctx.Bool(HybridTxIndexPoAOnlyFlag.Name)
+	}
+	if ctx.IsSet(HybridAlertWebhookFlag.Name) {
+		cfg.Hybrid.Webhook = ctx.String(HybridAlertWebhookFlag.Name)
+	}
+	if ctx.IsSet(HybridAlertSecretFlag.Name) {
+		cfg.Hybrid.WebhookSecret = ctx.String(HybridAlertSecretFlag.Name)
+	}
+	if ctx.IsSet(HybridMissedSlotsFlag.Name) {
+		cfg.Hybrid.MissedSlots = ctx.Uint64(HybridMissedSlotsFlag.Name)
+	}
+	if ctx.IsSet(HybridConsistencyCheckFlag.Name) {
+		cfg.Hybrid.ConsistencyCheck = ctx.Duration(HybridConsistencyCheckFlag.Name)
+	}
+	// The local signer defaults to the etherbase of legacy --mine setups
+	signer := ctx.String(HybridSignerFlag.Name)
+	if signer == "" && ctx.Bool(MiningEnabledFlag.Name) {
+		signer = ctx.String(MinerEtherbaseFlag.Name)
+	}
+	if signer != "" {
+		cfg.Hybrid.Signers = nil
+		for _, account := range strings.Split(signer, ",") {
+			if account = strings.TrimSpace(account); !common.IsHexAddress(account) {
+				Fatalf("-%s: invalid signer address %q", HybridSignerFlag.Name, account)
+			}
+			cfg.Hybrid.Signers = append(cfg.Hybrid.Signers, common.HexToAddress(account))
+		}
+	}
+	// Report where every setting deviating from its default comes from
+	defaults := ethconfig.Defaults.Hybrid
+	for _, change := range file.Diff(&defaults) {
+		log.Info("Hybrid setting overridden", "setting", change.Setting, "value", change.Value, "previous", change.Previous, "source", "config file")
+	}
+	for _, change := range cfg.Hybrid.Diff(&file) {
+		log.Info("Hybrid setting overridden", "setting", change.Setting, "value", change.Value, "previous", change.Previous, "source", "flag")
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1571,6 +1849,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setBlobPool(ctx, &cfg.BlobPool)
 	setMiner(ctx, &cfg.Miner)
+	setHybrid(ctx, cfg)
 	setRequiredBlocks(ctx, cfg)
 
 	// Cap the cache allowance and tune the garbage collector
@@ -1747,6 +2026,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	case ctx.Bool(DeveloperFlag.Name):
 		cfg.NetworkId = 1337
 		cfg.SyncMode = ethconfig.FullSync
+		// Developer networks may freely use placeholder signers
+		if !ctx.IsSet(HybridStrictFlag.Name) {
+			cfg.Hybrid.Strict = false
+		}
 		cfg.EnablePreimageRecording = true
 		// Create new developer account or reuse existing one
 		var (
@@ -2179,7 +2462,11 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	if err != nil {
 		Fatalf("%v", err)
 	}
-	engine, err := ethconfig.CreateConsensusEngine(config, chainDb)
+	opts := makeHybridOptions(ctx)
+	if readonly {
+		opts = append(opts, hybrid.WithReadOnly())
+	}
+	engine, err := ethconfig.CreateConsensusEngine(config, chainDb, opts...)
 	if err != nil {
 		Fatalf("%v", err)
 	}
@@ -2252,6 +2539,30 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 	return chain, chainDb
 }
 
+// makeHybridOptions returns the options of the hybrid consensus engine set by
+// the hybrid verification flags, so commands processing chain data verify the
+// blocks of each era like the node would.
+func makeHybridOptions(ctx *cli.Context) []hybrid.Option {
+	var opts []hybrid.Option
+	if ctx.IsSet(HybridStrictFlag.Name) {
+		opts = append(opts, hybrid.WithStrict(ctx.Bool(HybridStrictFlag.Name)))
+	}
+	if ctx.IsSet(HybridMinSignersFlag.Name) {
+		opts = append(opts, hybrid.WithMinSigners(ctx.Int(HybridMinSignersFlag.Name)))
+	}
+	if ctx.IsSet(HybridFreezeWindowFlag.Name) {
+		opts = append(opts, hybrid.WithFreezeWindow(ctx.Uint64(HybridFreezeWindowFlag.Name)))
+	}
+	if ctx.IsSet(HybridTransitionProofFlag.Name) {
+		proof, err := hybrid.ReadTransitionProof(ctx.Path(HybridTransitionProofFlag.Name))
+		if err != nil {
+			Fatalf("%v", err)
+		}
+		opts = append(opts, hybrid.WithTransitionProof(proof))
+	}
+	return opts
+}
+
 // MakeConsolePreloads retrieves the absolute paths for the console JavaScript
 // scripts to preload before starting.
 func MakeConsolePreloads(ctx *cli.Context) []string {
@@ -22,11 +22,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math/big"
 	"math/rand"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/lru"
@@ -53,7 +56,8 @@ const (
 
 // Clique proof-of-authority protocol constants.
 var (
-	epochLength = uint64(30000) // Default number of blocks after which to checkpoint and reset the pending votes
+	epochLength = uint64(30000)          // Default number of blocks after which to checkpoint and reset the pending votes
+	wiggleTime  = 500 * time.Millisecond // Random delay (per signer) to allow concurrent signers
 
 	extraVanity = 32                     // Fixed number of extra-data prefix bytes reserved for signer vanity
 	extraSeal   = crypto.SignatureLength // Fixed number of extra-data suffix bytes reserved for signer seal
@@ -61,6 +65,8 @@ var (
 	nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Magic nonce number to vote on adding a new signer
 	nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Magic nonce number to vote on removing a signer.
 
+	rotationMarker = []byte("clique-rot/1") // Vanity prefix announcing a key rotation, followed by the new signer
+
 	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
 
 	diffInTurn = big.NewInt(2) // Block difficulty for in-turn signatures
@@ -100,9 +106,9 @@ var (
 	// their extra-data fields.
 	errExtraSigners = errors.New("non-checkpoint block contains extra signer list")
 
-	// errInvalidCheckpointSigners is returned if a checkpoint block contains an
+	// ErrInvalidCheckpointSigners is returned if a checkpoint block contains an
 	// invalid list of signers (i.e. non divisible by 20 bytes).
-	errInvalidCheckpointSigners = errors.New("invalid signer list on checkpoint block")
+	ErrInvalidCheckpointSigners = errors.New("invalid signer list on checkpoint block")
 
 	// errMismatchingCheckpointSigners is returned if a checkpoint block contains a
 	// list of signers different than the one the local node calculated.
@@ -129,14 +135,17 @@ var (
 	// be modified via out-of-range or non-contiguous headers.
 	errInvalidVotingChain = errors.New("invalid voting chain")
 
-	// errUnauthorizedSigner is returned if a header is signed by a non-authorized entity.
-	errUnauthorizedSigner = errors.New("unauthorized signer")
+	// ErrUnauthorizedSigner is returned if a header is signed by a non-authorized entity.
+	ErrUnauthorizedSigner = errors.New("unauthorized signer")
 
-	// errRecentlySigned is returned if a header is signed by an authorized entity
+	// ErrRecentlySigned is returned if a header is signed by an authorized entity
 	// that already signed a header recently, thus is temporarily not allowed to.
-	errRecentlySigned = errors.New("recently signed")
+	ErrRecentlySigned = errors.New("recently signed")
 )
 
+// SignerFn hashes and signs the data to be signed by a backing account.
+type SignerFn func(signer accounts.Account, mimeType string, message []byte) ([]byte, error)
+
 // ecrecover extracts the Ethereum account address from a signed header.
 func ecrecover(header *types.Header, sigcache *sigLRU) (common.Address, error) {
 	// If the signature's already cached, return that
@@ -162,6 +171,15 @@ func ecrecover(header *types.Header, sigcache *sigLRU) (common.Address, error) {
 	return signer, nil
 }
 
+// rotationAnnouncement extracts the new signing key announced in the vanity of
+// the header, if any.
+func rotationAnnouncement(header *types.Header) (common.Address, bool) {
+	if len(header.Extra) < extraVanity || !bytes.HasPrefix(header.Extra, rotationMarker) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(header.Extra[len(rotationMarker):extraVanity]), true
+}
+
 // Clique is the proof-of-authority consensus engine proposed to support the
 // Ethereum testnet following the Ropsten attacks.
 type Clique struct {
@@ -173,8 +191,13 @@ type Clique struct {
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
-	signer common.Address // Ethereum address of the signing key
-	lock   sync.RWMutex   // Protects the signer and proposals fields
+	signer   common.Address // Ethereum address of the signing key
+	signFn   SignerFn       // Signer function to authorize hashes with
+	rotation common.Address // New signing key the local signer announces (zero = none)
+	lock     sync.RWMutex   // Protects the signer, rotation and proposals fields
+
+	takeover uint64           // First block sealed by clique on a chain started by another engine (0 = genesis)
+	founders []common.Address // Signers authorized at the takeover block
 
 	// The fields below are for testing only
 	fakeDiff bool // Skip difficulty verifications
@@ -201,6 +224,15 @@ func New(config *params.CliqueConfig, db ethdb.Database) *Clique {
 	}
 }
 
+// TakeOver makes clique continue a chain whose earlier blocks were produced by
+// another engine, starting at the given block with the given signers. Snapshots
+// are seeded at the parent of that block instead of being derived from the
+// blocks before it, which carry no clique votes or seals.
+func (c *Clique) TakeOver(number uint64, signers []common.Address) {
+	c.takeover = number
+	c.founders = slices.Clone(signers)
+}
+
 // Author implements consensus.Engine, returning the Ethereum address recovered
 // from the signature in the header's extra-data section.
 func (c *Clique) Author(header *types.Header) (common.Address, error) {
@@ -272,7 +304,7 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 		return errExtraSigners
 	}
 	if checkpoint && signersBytes%common.AddressLength != 0 {
-		return errInvalidCheckpointSigners
+		return ErrInvalidCheckpointSigners
 	}
 	// Ensure that the mix digest is zero as we don't have fork protection currently
 	if header.MixDigest != (common.Hash{}) {
@@ -361,8 +393,9 @@ func (c *Clique) verifyCascadingFields(chain consensus.ChainHeaderReader, header
 	}
 	// If the block is a checkpoint block, verify the signer list
 	if number%c.config.Epoch == 0 {
-		signers := make([]byte, len(snap.Signers)*common.AddressLength)
-		for i, signer := range snap.signers() {
+		checkpoint := snap.checkpointSigners()
+		signers := make([]byte, len(checkpoint)*common.AddressLength)
+		for i, signer := range checkpoint {
 			copy(signers[i*common.AddressLength:], signer[:])
 		}
 		extraSuffix := len(header.Extra) - extraSeal
@@ -395,6 +428,12 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 				break
 			}
 		}
+		// If clique took over the chain from another engine, the snapshot of the
+		// last foreign block holds the founding signers
+		if c.takeover > 0 && number == c.takeover-1 {
+			snap = newSnapshot(c.config, c.signatures, number, hash, c.founders)
+			break
+		}
 		// If we're at the genesis, snapshot the initial state. Alternatively if we're
 		// at a checkpoint block without a parent (light client CHT), or we have piled
 		// up more headers than allowed to be reorged (chain reinit from a freezer),
@@ -439,7 +478,9 @@ func (c *Clique) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 	for i := 0; i < len(headers)/2; i++ {
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
-	snap, err := snap.apply(headers)
+	snap, err := snap.apply(headers, func(number uint64) bool {
+		return chain.Config().IsPoAActivated(params.PoASignerRotation, new(big.Int).SetUint64(number))
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -480,13 +521,13 @@ func (c *Clique) verifySeal(snap *Snapshot, header *types.Header, parents []*typ
 		return err
 	}
 	if _, ok := snap.Signers[signer]; !ok {
-		return errUnauthorizedSigner
+		return ErrUnauthorizedSigner
 	}
 	for seen, recent := range snap.Recents {
 		if recent == signer {
 			// Signer is among recents, only fail if the current block doesn't shift it out
 			if limit := uint64(len(snap.Signers)/2 + 1); seen > number-limit {
-				return errRecentlySigned
+				return ErrRecentlySigned
 			}
 		}
 	}
@@ -537,7 +578,7 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	}
 
 	// Copy signer protected by mutex to avoid race condition
-	signer := c.signer
+	signer, rotation := c.signer, c.rotation
 	c.lock.RUnlock()
 
 	// Set the correct difficulty
@@ -550,9 +591,12 @@ func (c *Clique) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 	header.Extra = header.Extra[:extraVanity]
 
 	if number%c.config.Epoch == 0 {
-		for _, signer := range snap.signers() {
+		for _, signer := range snap.checkpointSigners() {
 			header.Extra = append(header.Extra, signer[:]...)
 		}
+	} else if c.announceRotation(chain, snap, number, signer, rotation) {
+		copy(header.Extra, rotationMarker)
+		copy(header.Extra[len(rotationMarker):], rotation[:])
 	}
 	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
 
@@ -595,17 +639,166 @@ func (c *Clique) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *
 
 // Authorize injects a private key into the consensus engine to mint new blocks
 // with.
-func (c *Clique) Authorize(signer common.Address) {
+func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	c.signer = signer
+	c.signFn = signFn
+}
+
+// InTurnSigner returns the signer whose turn it is to seal the block following
+// the parent.
+func (c *Clique) InTurnSigner(chain consensus.ChainHeaderReader, parent *types.Header) (common.Address, error) {
+	snap, err := c.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signers := snap.signers()
+	return signers[(parent.Number.Uint64()+1)%uint64(len(signers))], nil
+}
+
+// Signers returns the signers authorized after the given header. The header
+// itself does not need to be stored in the chain yet, its ancestors do.
+func (c *Clique) Signers(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error) {
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), []*types.Header{header})
+	if err != nil {
+		return nil, err
+	}
+	return snap.signers(), nil
+}
+
+// Snapshot returns a copy of the voting snapshot after the given header, looked
+// up like the signers by Signers.
+func (c *Clique) Snapshot(chain consensus.ChainHeaderReader, header *types.Header) (*Snapshot, error) {
+	snap, err := c.snapshot(chain, header.Number.Uint64(), header.Hash(), []*types.Header{header})
+	if err != nil {
+		return nil, err
+	}
+	return snap.copy(), nil
+}
+
+// Rotate instructs the local signer to announce a rotation of its signing key
+// to the given address in the blocks it seals, until the rotation is recorded.
+// The new key takes over at the next checkpoint, from which on it needs to be
+// authorized instead of the current one. A zero address cancels the rotation.
+func (c *Clique) Rotate(signer common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.rotation = signer
+}
+
+// announceRotation returns whether the block on top of the snapshot needs to
+// announce the key rotation of the local signer.
+func (c *Clique) announceRotation(chain consensus.ChainHeaderReader, snap *Snapshot, number uint64, signer, rotation common.Address) bool {
+	if rotation == (common.Address{}) || snap.Rotations[signer] == rotation {
+		return false
+	}
+	if _, ok := snap.Signers[signer]; !ok {
+		return false
+	}
+	return chain.Config().IsPoAActivated(params.PoASignerRotation, new(big.Int).SetUint64(number))
+}
+
+// Propose injects a new authorization proposal that the signer will attempt to
+// push through when sealing blocks.
+func (c *Clique) Propose(address common.Address, auth bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.proposals[address] = auth
+}
+
+// Discard drops a currently running proposal, stopping the signer from casting
+// further votes (either for or against).
+func (c *Clique) Discard(address common.Address) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.proposals, address)
+}
+
+// Proposals returns the current proposals the node tries to uphold and vote on.
+func (c *Clique) Proposals() map[common.Address]bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return maps.Clone(c.proposals)
 }
 
 // Seal implements consensus.Engine, attempting to create a sealed block using
 // the local signing credentials.
 func (c *Clique) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
-	panic("clique (poa) sealing not supported any more")
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+		return errors.New("sealing paused while waiting for transactions")
+	}
+	// Don't hold the signer fields for the entire sealing procedure
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+
+	if signFn == nil {
+		return errors.New("no signer function authorized")
+	}
+	// Bail out if we're unauthorized to sign a block
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if _, authorized := snap.Signers[signer]; !authorized {
+		return ErrUnauthorizedSigner
+	}
+	// If we're amongst the recent signers, wait for the next block
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			// Signer is among recents, only wait if the current block doesn't shift it out
+			if limit := uint64(len(snap.Signers)/2 + 1); number < limit || seen > number-limit {
+				return errors.New("signed recently, must wait for others")
+			}
+		}
+	}
+	// Sweet, the protocol permits us to sign the block, wait for our time
+	delay := time.Until(time.Unix(int64(header.Time), 0))
+	if header.Difficulty.Cmp(diffNoTurn) == 0 {
+		// It's not our turn explicitly to sign, delay it a bit
+		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
+		delay += time.Duration(rand.Int63n(int64(wiggle)))
+
+		log.Trace("Out-of-turn signing requested", "wiggle", common.PrettyDuration(wiggle))
+	}
+	// Sign all the things!
+	sighash, err := signFn(accounts.Account{Address: signer}, accounts.MimetypeClique, CliqueRLP(header))
+	if err != nil {
+		return err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	// Wait until sealing is terminated or delay timeout.
+	log.Trace("Waiting for slot to sign and propagate", "delay", common.PrettyDuration(delay))
+	go func() {
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+
+		select {
+		case results <- block.WithSeal(header):
+		default:
+			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
+		}
+	}()
+
+	return nil
 }
 
 // CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
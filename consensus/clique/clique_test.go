@@ -19,7 +19,9 @@ package clique
 import (
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -110,6 +112,67 @@ func TestReimportMirroredState(t *testing.T) {
 	}
 }
 
+// Tests that blocks are sealed with the authorized signer function, and that
+// the sealed blocks are accepted by the chain.
+func TestSeal(t *testing.T) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		engine = New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase())
+	)
+	genspec := &core.Genesis{
+		Config:    params.AllCliqueProtocolChanges,
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+		Alloc: map[common.Address]types.Account{
+			addr: {Balance: big.NewInt(10000000000000000)},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	copy(genspec.ExtraData[extraVanity:], addr[:])
+
+	chain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), genspec, engine, nil)
+	defer chain.Stop()
+
+	_, blocks, _ := core.GenerateChainWithGenesis(genspec, engine, 1, func(i int, block *core.BlockGen) {
+		block.SetDifficulty(diffInTurn)
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(addr), common.Address{0x00}, new(big.Int), params.TxGas, block.BaseFee(), nil), new(types.HomesteadSigner), key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTxWithChain(chain, tx)
+	})
+	header := blocks[0].Header()
+	header.Extra = make([]byte, extraVanity+extraSeal)
+	block := blocks[0].WithSeal(header)
+
+	// Sealing needs a signer function
+	results := make(chan *types.Block, 1)
+	if err := engine.Seal(chain, block, results, nil); err == nil {
+		t.Fatal("sealed without a signer function")
+	}
+	engine.Authorize(addr, func(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+		if account.Address != addr || mimeType != accounts.MimetypeClique {
+			t.Errorf("signing request mismatch: have %v %q", account.Address, mimeType)
+		}
+		return crypto.Sign(crypto.Keccak256(data), key)
+	})
+	if err := engine.Seal(chain, block, results, nil); err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	var sealed *types.Block
+	select {
+	case sealed = <-results:
+	case <-time.After(time.Second):
+		t.Fatal("sealed block not delivered")
+	}
+	if signer, err := engine.Author(sealed.Header()); err != nil || signer != addr {
+		t.Fatalf("signer mismatch: have %v (%v), want %v", signer, err, addr)
+	}
+	if _, err := chain.InsertChain(types.Blocks{sealed}); err != nil {
+		t.Fatalf("failed to insert sealed block: %v", err)
+	}
+}
+
 func TestSealHash(t *testing.T) {
 	have := SealHash(&types.Header{
 		Difficulty: new(big.Int),
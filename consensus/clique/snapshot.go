@@ -61,6 +61,8 @@ type Snapshot struct {
 	Recents map[uint64]common.Address   `json:"recents"` // Set of recent signers for spam protections
 	Votes   []*Vote                     `json:"votes"`   // List of votes cast in chronological order
 	Tally   map[common.Address]Tally    `json:"tally"`   // Current vote tally to avoid recalculating
+
+	Rotations map[common.Address]common.Address `json:"rotations,omitempty"` // Announced key rotations, applied at the next checkpoint
 }
 
 // newSnapshot creates a new snapshot with the specified startup parameters. This
@@ -107,6 +109,61 @@ func (s *Snapshot) store(db ethdb.Database) error {
 	return db.Put(append(rawdb.CliqueSnapshotPrefix, s.Hash[:]...), blob)
 }
 
+// PruneSnapshots deletes the snapshots persisted for blocks before the given
+// one, e.g. the ones of a PoS era that clique took over from, which are never
+// loaded again. It returns the number of snapshots deleted.
+func PruneSnapshots(db ethdb.KeyValueStore, before uint64) (int, error) {
+	return deleteSnapshots(db, func(number uint64) bool { return number < before })
+}
+
+// DropSnapshots deletes the snapshots persisted for blocks after the given one,
+// e.g. the ones of blocks dropped by rewinding the chain. It returns the number
+// of snapshots deleted.
+func DropSnapshots(db ethdb.KeyValueStore, after uint64) (int, error) {
+	return deleteSnapshots(db, func(number uint64) bool { return number > after })
+}
+
+// deleteSnapshots deletes the persisted snapshots whose block number matches
+// the filter, returning the number of snapshots deleted.
+func deleteSnapshots(db ethdb.KeyValueStore, match func(number uint64) bool) (int, error) {
+	it := db.NewIterator(rawdb.CliqueSnapshotPrefix, nil)
+	defer it.Release()
+
+	var (
+		batch  = db.NewBatch()
+		pruned int
+	)
+	for it.Next() {
+		if len(it.Key()) != len(rawdb.CliqueSnapshotPrefix)+common.HashLength {
+			continue
+		}
+		var snap struct {
+			Number uint64 `json:"number"`
+		}
+		if err := json.Unmarshal(it.Value(), &snap); err != nil {
+			log.Warn("Skipping undecodable clique snapshot", "key", common.Bytes2Hex(it.Key()), "err", err)
+			continue
+		}
+		if !match(snap.Number) {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return pruned, err
+		}
+		pruned++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return pruned, err
+	}
+	return pruned, batch.Write()
+}
+
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	return &Snapshot{
@@ -118,6 +175,8 @@ func (s *Snapshot) copy() *Snapshot {
 		Recents:  maps.Clone(s.Recents),
 		Votes:    slices.Clone(s.Votes),
 		Tally:    maps.Clone(s.Tally),
+
+		Rotations: maps.Clone(s.Rotations),
 	}
 }
 
@@ -166,8 +225,9 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 }
 
 // apply creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+// the original one. Key rotation announcements are only honoured in blocks for
+// which rotating reports true.
+func (s *Snapshot) apply(headers []*types.Header, rotating func(number uint64) bool) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -205,11 +265,11 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			return nil, err
 		}
 		if _, ok := snap.Signers[signer]; !ok {
-			return nil, errUnauthorizedSigner
+			return nil, ErrUnauthorizedSigner
 		}
 		for _, recent := range snap.Recents {
 			if recent == signer {
-				return nil, errRecentlySigned
+				return nil, ErrRecentlySigned
 			}
 		}
 		snap.Recents[number] = signer
@@ -276,6 +336,20 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			}
 			delete(snap.Tally, header.Coinbase)
 		}
+		// Record key rotations announced by the signer, swapping any announced
+		// ones once a checkpoint is reached
+		if number%s.config.Epoch != 0 {
+			if rotation, ok := rotationAnnouncement(header); ok && rotating != nil && rotating(number) {
+				if snap.Rotations == nil {
+					snap.Rotations = make(map[common.Address]common.Address)
+				}
+				snap.Rotations[signer] = rotation
+			}
+		} else if len(snap.Rotations) > 0 {
+			for old, rotation := range snap.rotate() {
+				log.Info("Rotated clique signer key", "number", number, "old", old, "new", rotation)
+			}
+		}
 		// If we're taking too much time (ecrecover), notify the user once a while
 		if time.Since(logged) > 8*time.Second {
 			log.Info("Reconstructing voting history", "processed", i, "total", len(headers), "elapsed", common.PrettyDuration(time.Since(start)))
@@ -301,6 +375,45 @@ func (s *Snapshot) signers() []common.Address {
 	return sigs
 }
 
+// rotate swaps the signers that announced a key rotation for their new keys and
+// clears the announcements, returning the rotations that took effect. Rotations
+// of deauthorized signers and to already authorized keys are dropped.
+func (s *Snapshot) rotate() map[common.Address]common.Address {
+	rotated := make(map[common.Address]common.Address)
+	for _, old := range slices.SortedFunc(maps.Keys(s.Rotations), common.Address.Cmp) {
+		rotation := s.Rotations[old]
+		if _, ok := s.Signers[old]; !ok {
+			continue
+		}
+		if _, ok := s.Signers[rotation]; ok {
+			continue
+		}
+		delete(s.Signers, old)
+		s.Signers[rotation] = struct{}{}
+
+		// The new key inherits the recent blocks of the old one
+		for number, recent := range s.Recents {
+			if recent == old {
+				s.Recents[number] = rotation
+			}
+		}
+		rotated[old] = rotation
+	}
+	s.Rotations = nil
+	return rotated
+}
+
+// checkpointSigners retrieves the list of signers a checkpoint block built on
+// top of the snapshot has to list, with all announced key rotations applied.
+func (s *Snapshot) checkpointSigners() []common.Address {
+	if len(s.Rotations) == 0 {
+		return s.signers()
+	}
+	snap := s.copy()
+	snap.rotate()
+	return snap.signers()
+}
+
 // inturn returns if a signer at a given block height is in-turn or not.
 func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
 	signers, offset := s.signers(), 0
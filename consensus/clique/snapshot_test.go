@@ -92,15 +92,17 @@ type testerVote struct {
 	voted      string
 	auth       bool
 	checkpoint []string
+	rotate     string // New key announced by the signer, if any
 	newbatch   bool
 }
 
 type cliqueTest struct {
-	epoch   uint64
-	signers []string
-	votes   []testerVote
-	results []string
-	failure error
+	epoch    uint64
+	rotation bool // Whether signer key rotation is activated
+	signers  []string
+	votes    []testerVote
+	results  []string
+	failure  error
 }
 
 // Tests that Clique signer voting is evaluated correctly for various simple and
@@ -344,7 +346,7 @@ func TestClique(t *testing.T) {
 			votes: []testerVote{
 				{signer: "B"},
 			},
-			failure: errUnauthorizedSigner,
+			failure: ErrUnauthorizedSigner,
 		}, {
 			// An authorized signer that signed recently should not be able to sign again
 			signers: []string{"A", "B"},
@@ -352,7 +354,7 @@ func TestClique(t *testing.T) {
 				{signer: "A"},
 				{signer: "A"},
 			},
-			failure: errRecentlySigned,
+			failure: ErrRecentlySigned,
 		}, {
 			// Recent signatures should not reset on checkpoint blocks imported in a batch
 			epoch:   3,
@@ -363,7 +365,7 @@ func TestClique(t *testing.T) {
 				{signer: "A", checkpoint: []string{"A", "B", "C"}},
 				{signer: "A"},
 			},
-			failure: errRecentlySigned,
+			failure: ErrRecentlySigned,
 		}, {
 			// Recent signatures should not reset on checkpoint blocks imported in a new
 			// batch (https://github.com/ethereum/go-ethereum/issues/17593). Whilst this
@@ -376,7 +378,63 @@ func TestClique(t *testing.T) {
 				{signer: "A", checkpoint: []string{"A", "B", "C"}},
 				{signer: "A", newbatch: true},
 			},
-			failure: errRecentlySigned,
+			failure: ErrRecentlySigned,
+		}, {
+			// Key rotation announced by a signer takes effect at the next checkpoint
+			epoch:    3,
+			rotation: true,
+			signers:  []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", rotate: "D"},
+				{signer: "B"},
+				{signer: "C", checkpoint: []string{"B", "C", "D"}},
+				{signer: "D"},
+			},
+			results: []string{"B", "C", "D"},
+		}, {
+			// Key rotation announcements are ignored unless activated
+			epoch:   3,
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", rotate: "D"},
+				{signer: "B"},
+				{signer: "C", checkpoint: []string{"A", "B", "C"}},
+			},
+			results: []string{"A", "B", "C"},
+		}, {
+			// A checkpoint ignoring a pending key rotation is rejected
+			epoch:    3,
+			rotation: true,
+			signers:  []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", rotate: "D"},
+				{signer: "B"},
+				{signer: "C", checkpoint: []string{"A", "B", "C"}},
+			},
+			failure: errMismatchingCheckpointSigners,
+		}, {
+			// The old key is deauthorized once the rotation took effect
+			epoch:    3,
+			rotation: true,
+			signers:  []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", rotate: "D"},
+				{signer: "B"},
+				{signer: "C", checkpoint: []string{"B", "C", "D"}},
+				{signer: "A"},
+			},
+			failure: ErrUnauthorizedSigner,
+		}, {
+			// Rotations onto an already authorized key are dropped
+			epoch:    3,
+			rotation: true,
+			signers:  []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", rotate: "B"},
+				{signer: "B"},
+				{signer: "C", checkpoint: []string{"A", "B", "C"}},
+			},
+			results: []string{"A", "B", "C"},
 		},
 	}
 
@@ -416,6 +474,10 @@ func (tt *cliqueTest) run(t *testing.T) {
 		Period: 1,
 		Epoch:  tt.epoch,
 	}
+	if tt.rotation {
+		config.PoSToPoATransitionBlock = new(big.Int)
+		config.PoAActivations = map[string]uint64{params.PoASignerRotation: 0}
+	}
 	genesis.Config = &config
 
 	engine := New(config.Clique, rawdb.NewMemoryDatabase())
@@ -442,6 +504,10 @@ func (tt *cliqueTest) run(t *testing.T) {
 			header.Extra = make([]byte, extraVanity+len(auths)*common.AddressLength+extraSeal)
 			accounts.checkpoint(header, auths)
 		}
+		if rotate := tt.votes[j].rotate; rotate != "" {
+			copy(header.Extra, rotationMarker)
+			copy(header.Extra[len(rotationMarker):], accounts.address(rotate).Bytes())
+		}
 		header.Difficulty = diffInTurn // Ignored, we just need a valid number
 
 		// Generate the signature, embed it into the header and the block
@@ -504,3 +570,49 @@ func (tt *cliqueTest) run(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneSnapshots(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	for _, number := range []uint64{0, 1024, 2048, 3072} {
+		snap := newSnapshot(params.AllCliqueProtocolChanges.Clique, nil, number, common.Hash{byte(number >> 8), 1}, nil)
+		if err := snap.store(db); err != nil {
+			t.Fatalf("failed to store snapshot %d: %v", number, err)
+		}
+	}
+	pruned, err := PruneSnapshots(db, 2048)
+	if err != nil {
+		t.Fatalf("failed to prune snapshots: %v", err)
+	}
+	if pruned != 2 {
+		t.Fatalf("pruned snapshot count mismatch: have %d, want 2", pruned)
+	}
+	for _, number := range []uint64{0, 1024, 2048, 3072} {
+		_, err := loadSnapshot(params.AllCliqueProtocolChanges.Clique, nil, db, common.Hash{byte(number >> 8), 1})
+		if kept := err == nil; kept != (number >= 2048) {
+			t.Errorf("snapshot %d: kept %v, want %v", number, kept, number >= 2048)
+		}
+	}
+}
+
+func TestDropSnapshots(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	for _, number := range []uint64{0, 1024, 2048, 3072} {
+		snap := newSnapshot(params.AllCliqueProtocolChanges.Clique, nil, number, common.Hash{byte(number >> 8), 1}, nil)
+		if err := snap.store(db); err != nil {
+			t.Fatalf("failed to store snapshot %d: %v", number, err)
+		}
+	}
+	dropped, err := DropSnapshots(db, 1024)
+	if err != nil {
+		t.Fatalf("failed to drop snapshots: %v", err)
+	}
+	if dropped != 2 {
+		t.Fatalf("dropped snapshot count mismatch: have %d, want 2", dropped)
+	}
+	for _, number := range []uint64{0, 1024, 2048, 3072} {
+		_, err := loadSnapshot(params.AllCliqueProtocolChanges.Clique, nil, db, common.Hash{byte(number >> 8), 1})
+		if kept := err == nil; kept != (number <= 1024) {
+			t.Errorf("snapshot %d: kept %v, want %v", number, kept, number <= 1024)
+		}
+	}
+}
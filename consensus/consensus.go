@@ -111,3 +111,43 @@ type Engine interface {
 	// Close terminates any background threads maintained by the consensus engine.
 	Close() error
 }
+
+// Transitioner is an optional interface implemented by consensus engines that
+// switch from PoS to PoA consensus at a given block.
+type Transitioner interface {
+	// UsesPoA reports whether the block with the given number is verified and
+	// built under the PoA rules.
+	UsesPoA(number uint64) bool
+
+	// TransitionBlock returns the first block verified and built under the PoA
+	// rules, or false if it is not known yet, such as while a transition
+	// triggered at runtime is pending.
+	TransitionBlock() (uint64, bool)
+}
+
+// ReorgObserver is an optional interface implemented by consensus engines that
+// want to be notified of reorganisations of the canonical chain.
+type ReorgObserver interface {
+	// ObserveReorg is called when the canonical chain switches branches at the
+	// common ancestor. The dropped and added headers are ordered from the head
+	// down to the ancestor, excluding it.
+	ObserveReorg(ancestor *types.Header, dropped, added []*types.Header)
+}
+
+// Rewinder is an optional interface implemented by consensus engines that keep
+// state derived from the canonical chain, which has to be reset when the chain
+// is rewound.
+type Rewinder interface {
+	// Rewound is called after the canonical chain was rewound to the given head,
+	// e.g. by debug_setHead or to repair the chain after a crash.
+	Rewound(head *types.Header)
+}
+
+// ForkChooser is an optional interface implemented by consensus engines that
+// prefer some branches over others, regardless of the order they are imported.
+type ForkChooser interface {
+	// ReorgNeeded reports whether the branch ending in the extern header should
+	// replace the canonical chain ending in the current one. Branches that are
+	// not preferred are kept as side chains, an error rejects them outright.
+	ReorgNeeded(current, extern *types.Header) (bool, error)
+}
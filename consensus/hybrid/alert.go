@@ -0,0 +1,191 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// DefaultMissedSlotAlert is the number of consecutive in-turn slots a signer
+// may miss before an alert is raised.
+const DefaultMissedSlotAlert = 3
+
+// AlertKind identifies the event an alert is raised for.
+type AlertKind string
+
+const (
+	// AlertTransitionArmed is raised once the chain gets close to the transition.
+	AlertTransitionArmed AlertKind = "transitionArmed"
+
+	// AlertTransitionExecuted is raised when the transition block is processed.
+	AlertTransitionExecuted AlertKind = "transitionExecuted"
+
+	// AlertMissedSlots is raised when a signer misses several in-turn slots in a row.
+	AlertMissedSlots AlertKind = "missedSlots"
+
+	// AlertBoundaryReorg is raised when blocks across the transition get replaced.
+	AlertBoundaryReorg AlertKind = "boundaryReorg"
+
+	// AlertDoubleSign is raised when a signer sealed two blocks at the same height.
+	AlertDoubleSign AlertKind = "doubleSign"
+
+	// AlertCompetingTransition is raised when different sealed blocks are seen
+	// at the transition height.
+	AlertCompetingTransition AlertKind = "competingTransition"
+
+	// AlertInconsistentBlock is raised when a historical block fails to verify
+	// again, hinting at a corrupted database.
+	AlertInconsistentBlock AlertKind = "inconsistentBlock"
+
+	// AlertEnginePanic is raised when a wrapped engine panics in a delegated
+	// call, which is failed instead.
+	AlertEnginePanic AlertKind = "enginePanic"
+)
+
+var alertCounter = metrics.NewRegisteredCounter("hybrid/alert/raised", nil)
+
+// Alert is a critical event of the transition or the PoA network that operators
+// should be notified about.
+type Alert struct {
+	Kind    AlertKind       `json:"kind"`
+	Time    uint64          `json:"time"`   // Unix time the alert was raised at
+	Number  uint64          `json:"number"` // Block number the alert relates to
+	Hash    common.Hash     `json:"hash"`   // Block hash the alert relates to
+	Signer  *common.Address `json:"signer,omitempty"`
+	Message string          `json:"message"`
+}
+
+// SlotScheduler is implemented by PoA engines that assign blocks to signers in
+// turns, such as clique.
+type SlotScheduler interface {
+	// InTurnSigner returns the signer whose turn it is to seal the block
+	// following the parent.
+	InTurnSigner(chain consensus.ChainHeaderReader, parent *types.Header) (common.Address, error)
+}
+
+// alertWatcher derives alerts from the blocks processed by the engine.
+type alertWatcher struct {
+	feed      event.Feed
+	threshold uint64 // Consecutive missed slots raising an alert
+
+	missed map[common.Address]uint64 // Consecutive in-turn slots missed per signer
+	lock   sync.Mutex
+}
+
+// SubscribeAlerts subscribes to the alerts raised by the engine. Subscribers
+// must consume alerts promptly, as block processing waits for their delivery.
+func (h *Hybrid) SubscribeAlerts(ch chan<- Alert) event.Subscription {
+	return h.alerts.feed.Subscribe(ch)
+}
+
+// raiseAlert logs the alert and delivers it to all subscribers.
+func (h *Hybrid) raiseAlert(alert Alert) {
+	alert.Time = uint64(time.Now().Unix())
+	alertCounter.Inc(1)
+
+	if alert.Kind == AlertTransitionArmed || alert.Kind == AlertTransitionExecuted {
+		log.Warn("Hybrid consensus alert", "kind", alert.Kind, "number", alert.Number, "hash", alert.Hash, "message", alert.Message)
+	} else {
+		log.Error("Hybrid consensus alert", "kind", alert.Kind, "number", alert.Number, "hash", alert.Hash, "message", alert.Message)
+	}
+	h.alerts.feed.Send(alert)
+}
+
+// watchBlock raises the alerts triggered by processing the given block.
+func (h *Hybrid) watchBlock(chain consensus.ChainHeaderReader, header *types.Header) {
+	number, hash := header.Number.Uint64(), header.Hash()
+
+	var raised []Alert
+	h.runtime.update(func(state *RuntimeState) bool {
+		var changed bool
+		if !state.Armed && number < h.transitionBlock.Load() && number+signerKeyCheckWindow >= h.transitionBlock.Load() {
+			state.Armed, changed = true, true
+			raised = append(raised, Alert{Kind: AlertTransitionArmed, Number: number, Hash: hash,
+				Message: fmt.Sprintf("transition to PoA in %d blocks", h.transitionBlock.Load()-number)})
+		}
+		switch {
+		case number == h.transitionBlock.Load() && state.Executed == (common.Hash{}):
+			state.Executed, changed = hash, true
+			raised = append(raised, Alert{Kind: AlertTransitionExecuted, Number: number, Hash: hash,
+				Message: "transition block processed, PoA consensus active"})
+
+		case number <= h.transitionBlock.Load() && state.Executed != (common.Hash{}) && hash != state.Executed:
+			raised = append(raised, Alert{Kind: AlertBoundaryReorg, Number: number, Hash: hash,
+				Message: fmt.Sprintf("block across the transition processed after transition block %x", state.Executed)})
+		}
+		return changed
+	})
+	if h.countdown > 0 && number < h.transitionBlock.Load() && number+h.countdown >= h.transitionBlock.Load() {
+		log.Info("PoS to PoA transition approaching", "number", number, "transitionBlock", h.transitionBlock.Load(), "blocksRemaining", h.transitionBlock.Load()-number)
+	}
+	if number > h.transitionBlock.Load() {
+		h.alerts.lock.Lock()
+		if alert := h.watchSlot(chain, header); alert != nil {
+			raised = append(raised, *alert)
+		}
+		h.alerts.lock.Unlock()
+	}
+
+	for _, alert := range raised {
+		h.raiseAlert(alert)
+	}
+}
+
+// watchSlot tracks the in-turn slots missed by signers, returning an alert if a
+// signer reached the threshold of consecutive misses. The lock must be held.
+func (h *Hybrid) watchSlot(chain consensus.ChainHeaderReader, header *types.Header) *Alert {
+	engine := h.poaEngine
+	if lazy, ok := engine.(*lazyEngine); ok {
+		engine = lazy.get()
+	}
+	scheduler, ok := engine.(SlotScheduler)
+	if !ok {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return nil
+	}
+	inturn, err := scheduler.InTurnSigner(chain, parent)
+	if err != nil {
+		return nil
+	}
+	if signer, err := engine.Author(header); err == nil {
+		delete(h.alerts.missed, signer)
+		if signer == inturn {
+			return nil
+		}
+	}
+	if h.alerts.missed == nil {
+		h.alerts.missed = make(map[common.Address]uint64)
+	}
+	h.alerts.missed[inturn]++
+	if h.alerts.missed[inturn] != h.alerts.threshold {
+		return nil
+	}
+	return &Alert{Kind: AlertMissedSlots, Number: header.Number.Uint64(), Hash: header.Hash(), Signer: &inturn,
+		Message: fmt.Sprintf("signer %v missed %d consecutive in-turn slots", inturn, h.alerts.threshold)}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// schedulingMockEngine is a mock PoA engine treating the coinbase of headers as
+// their signer and rotating the turns between a fixed set of signers.
+type schedulingMockEngine struct {
+	coinbaseMockEngine
+	signers []common.Address
+}
+
+func (m *schedulingMockEngine) InTurnSigner(chain consensus.ChainHeaderReader, parent *types.Header) (common.Address, error) {
+	return m.signers[(parent.Number.Uint64()+1)%uint64(len(m.signers))], nil
+}
+
+func TestAlerts(t *testing.T) {
+	var (
+		signerA = common.Address{0xaa}
+		signerB = common.Address{0xbb}
+		chain   = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+		poa     = &schedulingMockEngine{signers: []common.Address{signerA, signerB}}
+	)
+	h, err := New(&mockEngine{name: "pos"}, poa, 200, WithMissedSlotAlert(2))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	alerts := make(chan Alert, 16)
+	sub := h.SubscribeAlerts(alerts)
+	defer sub.Unsubscribe()
+
+	// Process a chain in which B seals every block from the transition on
+	var parent *types.Header
+	process := func(number uint64, signer common.Address, extra byte) *types.Header {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Coinbase: signer, Extra: []byte{extra}}
+		if parent != nil && number > 0 {
+			header.ParentHash = parent.Hash()
+		}
+		chain.headers[number] = header
+		h.Finalize(chain, header, nil, nil)
+		parent = header
+		return header
+	}
+	expect := func(kind AlertKind, number uint64) Alert {
+		t.Helper()
+		select {
+		case alert := <-alerts:
+			if alert.Kind != kind || alert.Number != number {
+				t.Fatalf("Alert mismatch: have %s at %d, want %s at %d", alert.Kind, alert.Number, kind, number)
+			}
+			return alert
+		case <-time.After(time.Second):
+			t.Fatalf("Missing %s alert", kind)
+		}
+		return Alert{}
+	}
+	for i := uint64(0); i < 200; i++ {
+		process(i, common.Address{}, 0)
+	}
+	expect(AlertTransitionArmed, 72)
+
+	transition := process(200, signerB, 0)
+	expect(AlertTransitionExecuted, 200)
+
+	// A misses its turns at 202 and 204, B sealing in its place
+	for i := uint64(201); i <= 204; i++ {
+		process(i, signerB, 0)
+	}
+	if alert := expect(AlertMissedSlots, 204); *alert.Signer != signerA {
+		t.Fatalf("Missed slots signer mismatch: have %v, want %v", alert.Signer, signerA)
+	}
+	// A competing transition block is a reorg across the boundary
+	parent = chain.headers[199]
+	if alert := process(200, signerA, 1); alert.Hash() == transition.Hash() {
+		t.Fatal("Competing transition block not distinct")
+	}
+	expect(AlertBoundaryReorg, 200)
+
+	select {
+	case alert := <-alerts:
+		t.Fatalf("Unexpected alert: %s at %d", alert.Kind, alert.Number)
+	default:
+	}
+}
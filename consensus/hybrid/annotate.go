@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxConsensusRange is the maximum number of blocks that can be annotated in a
+// single request.
+const maxConsensusRange = 10_000
+
+// BlockConsensus annotates a block with the consensus rules it was sealed under.
+type BlockConsensus struct {
+	Number     hexutil.Uint64  `json:"number"`
+	Hash       common.Hash     `json:"hash"`
+	Engine     string          `json:"engine"`           // "pos" or "poa"
+	Sealer     *common.Address `json:"sealer,omitempty"` // Signer of PoA blocks, nil for PoS ones
+	Checkpoint bool            `json:"checkpoint"`       // Whether the block is the transition checkpoint
+}
+
+// annotate returns the consensus annotation of the header. The sealer of PoA
+// blocks is recovered from the seal, which the PoA engine caches.
+func (h *Hybrid) annotate(header *types.Header) BlockConsensus {
+	number := header.Number.Uint64()
+	annotation := BlockConsensus{
+		Number: hexutil.Uint64(number),
+		Hash:   header.Hash(),
+		Engine: "pos",
+	}
+	if number < h.transitionBlock.Load() || h.straggler(header) {
+		return annotation
+	}
+	annotation.Engine = "poa"
+	annotation.Checkpoint = number == h.transitionBlock.Load()
+	if signer, err := h.poaEngine.Author(header); err == nil {
+		annotation.Sealer = &signer
+	}
+	return annotation
+}
+
+// ConsensusOf returns the engine, the sealer and whether it is the transition
+// checkpoint for every block in the given range, which defaults to the genesis
+// up to the current head. Explorers can annotate a whole page of blocks with a
+// single call, each header being looked up only once.
+func (api *API) ConsensusOf(from, to *rpc.BlockNumber) ([]BlockConsensus, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil || head.Number == nil {
+		return nil, errUnknownBlock
+	}
+	start, end, err := resolveRange(from, to, 0, 0, head.Number.Uint64(), maxConsensusRange)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.GetHeaderByNumber(end)
+	if header == nil {
+		return nil, fmt.Errorf("%w: #%d", errUnknownBlock, end)
+	}
+	// Walk the range backwards along the parent hashes, so it stays on a single
+	// chain even if the canonical one changes meanwhile.
+	annotations := make([]BlockConsensus, 0, end-start+1)
+	for {
+		annotations = append(annotations, api.hybrid.annotate(header))
+
+		number := header.Number.Uint64()
+		if number == start {
+			break
+		}
+		if header = api.chain.GetHeader(header.ParentHash, number-1); header == nil {
+			return nil, fmt.Errorf("%w: #%d", errUnknownBlock, number-1)
+		}
+	}
+	slices.Reverse(annotations)
+	return annotations, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestConsensusOf(t *testing.T) {
+	var (
+		signer = common.Address{0xaa}
+		chain  = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+		parent common.Hash
+	)
+	for i := uint64(0); i <= 20; i++ {
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(0)}
+		if i >= 10 {
+			header.Coinbase, header.Difficulty = signer, big.NewInt(2)
+		}
+		chain.headers[i] = header
+		parent = header.Hash()
+	}
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	api := &API{chain: chain, hybrid: h}
+
+	from, to := rpc.BlockNumber(8), rpc.BlockNumber(11)
+	blocks, err := api.ConsensusOf(&from, &to)
+	if err != nil {
+		t.Fatalf("Failed to annotate blocks: %v", err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("Annotation count mismatch: have %d, want 4", len(blocks))
+	}
+	for i, block := range blocks {
+		number := uint64(8 + i)
+		if uint64(block.Number) != number || block.Hash != chain.headers[number].Hash() {
+			t.Errorf("Block %d: identity mismatch: %+v", number, block)
+		}
+		if poa := number >= 10; poa != (block.Engine == "poa") || poa != (block.Sealer != nil) {
+			t.Errorf("Block %d: engine mismatch: %+v", number, block)
+		}
+		if block.Sealer != nil && *block.Sealer != signer {
+			t.Errorf("Block %d: sealer mismatch: have %v, want %v", number, *block.Sealer, signer)
+		}
+		if block.Checkpoint != (number == 10) {
+			t.Errorf("Block %d: checkpoint mismatch: %+v", number, block)
+		}
+	}
+	// Inverted ranges are rejected, missing bounds default to the whole chain
+	from, to = rpc.BlockNumber(15), rpc.BlockNumber(12)
+	if _, err := api.ConsensusOf(&from, &to); err == nil {
+		t.Fatal("Inverted range accepted")
+	}
+	if blocks, err := api.ConsensusOf(nil, nil); err != nil || len(blocks) != 21 {
+		t.Fatalf("Default range mismatch: %d blocks, %v", len(blocks), err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errUnknownBlock is returned when the requested block is not known.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errSignersUnsupported is returned if the PoA engine does not expose its
+	// authorized signers.
+	errSignersUnsupported = errors.New("PoA engine does not expose its signers")
+)
+
+// API is a user facing RPC API to inspect the state of the hybrid consensus
+// engine and its PoS to PoA transition.
+type API struct {
+	chain  consensus.ChainHeaderReader
+	hybrid *Hybrid
+}
+
+// Status describes the transition progress as seen from the current head.
+type Status struct {
+	TransitionBlock hexutil.Uint64   `json:"transitionBlock"`
+	Pending         bool             `json:"pending,omitempty"` // Whether the transition block awaits a runtime trigger
+	CurrentBlock    hexutil.Uint64   `json:"currentBlock"`
+	Mode            string           `json:"mode"`
+	BlocksRemaining hexutil.Uint64   `json:"blocksRemaining"`
+	InitialSigners  []common.Address `json:"initialSigners"`
+	Strict          bool             `json:"strict"`
+	Armed           bool             `json:"armed"`    // Whether the transition is imminent
+	Executed        bool             `json:"executed"` // Whether the transition block is part of the chain
+
+	ConfirmationDepth hexutil.Uint64 `json:"confirmationDepth"`
+	Final             bool           `json:"final"`
+}
+
+// Status returns the transition progress relative to the given head.
+func (h *Hybrid) Status(number uint64) *Status {
+	status := &Status{
+		TransitionBlock: hexutil.Uint64(h.transitionBlock.Load()),
+		Pending:         h.TransitionPending(),
+		CurrentBlock:    hexutil.Uint64(number),
+		Mode:            "pos",
+		InitialSigners:  h.InitialSigners(),
+		Strict:          h.strict,
+		Executed:        number >= h.transitionBlock.Load(),
+
+		ConfirmationDepth: hexutil.Uint64(h.confirmDepth),
+		Final:             h.TransitionFinal(number),
+	}
+	// The next block to be processed decides the active engine
+	if number+1 >= h.transitionBlock.Load() {
+		status.Mode = "poa"
+	} else if !status.Pending {
+		status.BlocksRemaining = hexutil.Uint64(h.transitionBlock.Load() - number - 1)
+	}
+	status.Armed = !status.Executed && number+signerKeyCheckWindow >= h.transitionBlock.Load()
+	return status
+}
+
+// Status returns the transition progress relative to the current head.
+func (api *API) Status() *Status {
+	var number uint64
+	if head := api.chain.CurrentHeader(); head != nil {
+		number = head.Number.Uint64()
+	}
+	return api.hybrid.Status(number)
+}
+
+// resolveRange resolves the optional bounds of a block range requested over RPC.
+// A missing lower bound defaults to start and one below floor is raised to it,
+// while the upper bound defaults to and is capped at end. Ranges spanning more
+// than limit blocks are refused, unless the limit is zero.
+func resolveRange(from, to *rpc.BlockNumber, floor, start, end, limit uint64) (uint64, uint64, error) {
+	if from != nil && *from >= 0 {
+		start = uint64(*from)
+	}
+	start = max(start, floor)
+	if to != nil && *to >= 0 && uint64(*to) < end {
+		end = uint64(*to)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid range: from %d is after to %d", start, end)
+	}
+	if limit > 0 && end-start+1 > limit {
+		return 0, 0, fmt.Errorf("range of %d blocks exceeds the limit of %d", end-start+1, limit)
+	}
+	return start, end, nil
+}
+
+// Signers returns the PoA signers authorized at the given block, defaulting to
+// the current head.
+func (api *API) Signers(number *rpc.BlockNumber) ([]common.Address, error) {
+	var header *types.Header
+	if number == nil || *number < 0 {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(*number))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	if header.Number.Uint64() < api.hybrid.transitionBlock.Load() {
+		return nil, fmt.Errorf("%w: block %d is before transition block %d", ErrTransitionNotReached, header.Number.Uint64(), api.hybrid.transitionBlock.Load())
+	}
+	engine := api.hybrid.poaEngine
+	if lazy, ok := engine.(*lazyEngine); ok {
+		engine = lazy.get()
+	}
+	lister, ok := engine.(SignerLister)
+	if !ok {
+		return nil, errSignersUnsupported
+	}
+	return lister.Signers(api.chain, header)
+}
+
+// ShadowReport returns the findings of the shadow PoA verification run against
+// PoS blocks ahead of the transition, or nil if it is disabled.
+func (api *API) ShadowReport() *ShadowReport {
+	return api.hybrid.ShadowReport()
+}
+
+// DualReport returns the divergences found by verifying headers around the
+// transition with both engines, or nil if it is disabled.
+func (api *API) DualReport() *DualReport {
+	return api.hybrid.DualReport()
+}
+
+// BuildCheckpointExtra returns the extraData of a checkpoint block listing the
+// given signers, built exactly like the one of the transition block. Without
+// signers the configured initial signers are used.
+func (api *API) BuildCheckpointExtra(signers []common.Address) hexutil.Bytes {
+	if len(signers) == 0 {
+		return bytes.Clone(api.hybrid.checkpoint)
+	}
+	return CheckpointExtra(signers)
+}
+
+// APIs returns the RPC APIs this consensus engine provides, followed by the
+// ones of the wrapped engines.
+func (h *Hybrid) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	apis := []rpc.API{{
+		Namespace: "hybrid",
+		Service:   &API{chain: chain, hybrid: h},
+	}}
+	if h.manual {
+		apis = append(apis, rpc.API{
+			Namespace:     "hybrid",
+			Service:       &AdminAPI{chain: chain, hybrid: h},
+			Authenticated: true,
+		})
+	}
+	return append(apis, h.engineAPIs(chain)...)
+}
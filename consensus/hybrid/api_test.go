@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestResolveRange(t *testing.T) {
+	bound := func(n int64) *rpc.BlockNumber {
+		number := rpc.BlockNumber(n)
+		return &number
+	}
+	tests := []struct {
+		from, to   *rpc.BlockNumber
+		floor      uint64
+		start, end uint64
+		limit      uint64
+		wantStart  uint64
+		wantEnd    uint64
+		wantErr    bool
+	}{
+		// Missing and symbolic bounds default to the whole range
+		{nil, nil, 10, 10, 100, 0, 10, 100, false},
+		{bound(int64(rpc.LatestBlockNumber)), bound(int64(rpc.PendingBlockNumber)), 10, 10, 100, 0, 10, 100, false},
+
+		// The default start may lie above the floor
+		{nil, nil, 0, 10, 100, 0, 10, 100, false},
+		{bound(5), nil, 0, 10, 100, 0, 5, 100, false},
+
+		// Bounds are clamped to the floor and the end
+		{bound(5), bound(200), 10, 10, 100, 0, 10, 100, false},
+		{bound(20), bound(30), 10, 10, 100, 0, 20, 30, false},
+
+		// Inverted and empty ranges are refused
+		{bound(30), bound(20), 10, 10, 100, 0, 0, 0, true},
+		{nil, bound(5), 10, 10, 100, 0, 0, 0, true},
+		{nil, nil, 10, 10, 5, 0, 0, 0, true},
+
+		// The limit applies to the resolved range only
+		{nil, nil, 0, 0, 99, 100, 0, 99, false},
+		{nil, nil, 0, 0, 100, 100, 0, 0, true},
+		{bound(50), nil, 0, 0, 1000, 100, 0, 0, true},
+		{bound(901), nil, 0, 0, 1000, 100, 901, 1000, false},
+	}
+	for i, tt := range tests {
+		start, end, err := resolveRange(tt.from, tt.to, tt.floor, tt.start, tt.end, tt.limit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, tt.wantErr)
+			continue
+		}
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("test %d: range mismatch: have [%d, %d], want [%d, %d]", i, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxVerifyRange is the maximum number of blocks that can be re-verified in a
+// single request.
+const maxVerifyRange = 10_000
+
+// Reasons a PoA block's sealer is reported as mismatched.
+const (
+	MismatchUnauthorized = "unauthorized" // Sealer not authorized at the parent block
+	MismatchInTurn       = "in-turn"      // In-turn difficulty sealed by an out-of-turn signer
+	MismatchOutOfTurn    = "out-of-turn"  // Out-of-turn difficulty sealed by the in-turn signer
+)
+
+// BlockVerdict is the outcome of re-verifying a single block.
+type BlockVerdict struct {
+	Number  hexutil.Uint64  `json:"number"`
+	Hash    common.Hash     `json:"hash"`
+	Engine  string          `json:"engine"`           // "pos" or "poa"
+	Sealer  *common.Address `json:"sealer,omitempty"` // Signer of PoA blocks, nil for PoS ones
+	Valid   bool            `json:"valid"`
+	Error   string          `json:"error,omitempty"`
+	Failure *ErrorData      `json:"failure,omitempty"` // Code of the transition failure, if classified
+	Elapsed float64         `json:"elapsed"`           // Seconds spent verifying the block
+}
+
+// SealerMismatch is a PoA block sealed by a signer other than the consensus
+// rules expect at its height.
+type SealerMismatch struct {
+	Number   hexutil.Uint64  `json:"number"`
+	Hash     common.Hash     `json:"hash"`
+	Sealer   common.Address  `json:"sealer"`
+	Expected *common.Address `json:"expected,omitempty"` // In-turn signer, if known
+	Reason   string          `json:"reason"`
+}
+
+// VerifyReport is the outcome of re-verifying a range of blocks.
+type VerifyReport struct {
+	From       hexutil.Uint64    `json:"from"`
+	To         hexutil.Uint64    `json:"to"`
+	Valid      hexutil.Uint64    `json:"valid"`   // Number of blocks passing verification
+	Invalid    hexutil.Uint64    `json:"invalid"` // Number of blocks failing verification
+	Blocks     []*BlockVerdict   `json:"blocks"`
+	Mismatches []*SealerMismatch `json:"mismatches"`
+	Elapsed    float64           `json:"elapsed"` // Seconds spent verifying the range
+}
+
+// VerifyRange re-verifies the headers of the blocks in the given range from the
+// local database, each by the engine of its era, which defaults to the
+// transition block up to the current head. The sealers of PoA blocks are also
+// checked against the signers authorized and in-turn at their parent, as far
+// as the PoA engine exposes them. Validators can thus be audited after the
+// transition without trusting the verdicts recorded at import.
+func (api *API) VerifyRange(from, to *rpc.BlockNumber) (*VerifyReport, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil || head.Number == nil {
+		return nil, errUnknownBlock
+	}
+	start := api.hybrid.transitionBlock.Load()
+	if start > head.Number.Uint64() {
+		start = 0
+	}
+	start, end, err := resolveRange(from, to, 0, start, head.Number.Uint64(), maxVerifyRange)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.GetHeaderByNumber(end)
+	if header == nil {
+		return nil, fmt.Errorf("%w: #%d", errUnknownBlock, end)
+	}
+	// Collect the range backwards along the parent hashes, so it stays on a
+	// single chain even if the canonical one changes meanwhile.
+	headers := make([]*types.Header, 0, end-start+1)
+	for {
+		headers = append(headers, header)
+
+		number := header.Number.Uint64()
+		if number == start {
+			break
+		}
+		if header = api.chain.GetHeader(header.ParentHash, number-1); header == nil {
+			return nil, fmt.Errorf("%w: #%d", errUnknownBlock, number-1)
+		}
+	}
+	slices.Reverse(headers)
+
+	report := &VerifyReport{
+		From:       hexutil.Uint64(start),
+		To:         hexutil.Uint64(end),
+		Blocks:     make([]*BlockVerdict, 0, len(headers)),
+		Mismatches: []*SealerMismatch{},
+	}
+	began := time.Now()
+	for _, header := range headers {
+		annotation := api.hybrid.annotate(header)
+		verdict := &BlockVerdict{
+			Number: annotation.Number,
+			Hash:   annotation.Hash,
+			Engine: annotation.Engine,
+			Sealer: annotation.Sealer,
+		}
+		verified := time.Now()
+		err := api.hybrid.VerifyHeader(api.chain, header)
+		verdict.Elapsed = time.Since(verified).Seconds()
+		if err != nil {
+			verdict.Error = err.Error()
+			verdict.Failure = failureOf(err)
+			report.Invalid++
+		} else {
+			verdict.Valid = true
+			report.Valid++
+		}
+		report.Blocks = append(report.Blocks, verdict)
+
+		if annotation.Sealer != nil {
+			if mismatch := api.checkSealer(header, *annotation.Sealer); mismatch != nil {
+				report.Mismatches = append(report.Mismatches, mismatch)
+			}
+		}
+	}
+	report.Elapsed = time.Since(began).Seconds()
+
+	log.Info("Re-verified block range", "from", start, "to", end, "valid", uint64(report.Valid),
+		"invalid", uint64(report.Invalid), "mismatches", len(report.Mismatches), "elapsed", common.PrettyDuration(time.Since(began)))
+	return report, nil
+}
+
+// checkSealer checks the sealer of a PoA block against the signers authorized
+// and in-turn at its parent, returning nil if it matches or cannot be checked.
+func (api *API) checkSealer(header *types.Header, sealer common.Address) *SealerMismatch {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	parent := api.chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return nil
+	}
+	mismatch := &SealerMismatch{
+		Number: hexutil.Uint64(number),
+		Hash:   header.Hash(),
+		Sealer: sealer,
+	}
+	if lister, ok := findCapability[SignerLister](api.hybrid.poaEngine); ok {
+		if signers, err := lister.Signers(api.chain, parent); err == nil && !slices.Contains(signers, sealer) {
+			mismatch.Reason = MismatchUnauthorized
+			return mismatch
+		}
+	}
+	scheduler, ok := findCapability[SlotScheduler](api.hybrid.poaEngine)
+	if !ok || header.Difficulty == nil {
+		return nil
+	}
+	inturn, err := scheduler.InTurnSigner(api.chain, parent)
+	if err != nil {
+		return nil
+	}
+	switch claimed := header.Difficulty.Cmp(diffInTurn) == 0; {
+	case claimed && sealer != inturn:
+		mismatch.Reason = MismatchInTurn
+	case !claimed && sealer == inturn:
+		mismatch.Reason = MismatchOutOfTurn
+	default:
+		return nil
+	}
+	mismatch.Expected = &inturn
+	return mismatch
+}
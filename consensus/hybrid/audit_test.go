@@ -0,0 +1,124 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// auditMockEngine is a scheduling mock PoA engine exposing its signers and
+// rejecting a single block.
+type auditMockEngine struct {
+	schedulingMockEngine
+	invalid uint64
+}
+
+func (m *auditMockEngine) Signers(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error) {
+	return m.signers, nil
+}
+
+func (m *auditMockEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if header.Number.Uint64() == m.invalid {
+		return errors.New("invalid seal")
+	}
+	return nil
+}
+
+func TestVerifyRange(t *testing.T) {
+	var (
+		signerA  = common.Address{0xaa}
+		signerB  = common.Address{0xbb}
+		outsider = common.Address{0xcc}
+		chain    = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+		poa      = &auditMockEngine{schedulingMockEngine: schedulingMockEngine{signers: []common.Address{signerA, signerB}}, invalid: 16}
+	)
+	h, err := New(&mockEngine{name: "pos"}, poa, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	for i := uint64(0); i < 20; i++ {
+		// Signers take turns by the parity of the block number
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(2), Coinbase: poa.signers[i%2]}
+		switch i {
+		case 12:
+			header.Coinbase = signerB // Out-of-turn signer claiming the turn
+		case 13:
+			header.Difficulty = big.NewInt(1) // In-turn signer not claiming the turn
+		case 14:
+			header.Coinbase = outsider
+		}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+		chain.headers[i] = header
+	}
+	api := &API{chain: chain, hybrid: h}
+
+	// The range defaults to the PoA era
+	report, err := api.VerifyRange(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to verify range: %v", err)
+	}
+	if report.From != 10 || report.To != 19 || len(report.Blocks) != 10 {
+		t.Fatalf("Range mismatch: have %d-%d with %d verdicts, want 10-19 with 10", report.From, report.To, len(report.Blocks))
+	}
+	if report.Valid != 9 || report.Invalid != 1 {
+		t.Errorf("Verdicts mismatch: have %d valid and %d invalid, want 9 and 1", report.Valid, report.Invalid)
+	}
+	if verdict := report.Blocks[6]; verdict.Number != 16 || verdict.Valid || verdict.Error == "" {
+		t.Errorf("Invalid block not reported: %+v", verdict)
+	}
+	if verdict := report.Blocks[0]; verdict.Engine != "poa" || verdict.Sealer == nil || *verdict.Sealer != signerA {
+		t.Errorf("Transition block verdict mismatch: %+v", verdict)
+	}
+	want := map[hexutil.Uint64]string{12: MismatchInTurn, 13: MismatchOutOfTurn, 14: MismatchUnauthorized}
+	if len(report.Mismatches) != len(want) {
+		t.Fatalf("Mismatch count mismatch: have %d, want %d", len(report.Mismatches), len(want))
+	}
+	for _, mismatch := range report.Mismatches {
+		if want[mismatch.Number] != mismatch.Reason {
+			t.Errorf("Block %d: mismatch reason %q, want %q", mismatch.Number, mismatch.Reason, want[mismatch.Number])
+		}
+	}
+	// PoS blocks are verified by the PoS engine, without sealer checks
+	from, to := rpc.BlockNumber(5), rpc.BlockNumber(9)
+	if report, err = api.VerifyRange(&from, &to); err != nil {
+		t.Fatalf("Failed to verify range: %v", err)
+	}
+	if report.Valid != 5 || len(report.Mismatches) != 0 || report.Blocks[0].Engine != "pos" || report.Blocks[0].Sealer != nil {
+		t.Errorf("PoS range report mismatch: %+v", report)
+	}
+	// Inverted and oversized ranges are refused
+	from, to = 9, 5
+	if _, err := api.VerifyRange(&from, &to); err == nil {
+		t.Error("Inverted range accepted")
+	}
+	from, to = 0, maxVerifyRange+1
+	chain.headers[maxVerifyRange+1] = &types.Header{Number: big.NewInt(maxVerifyRange + 1)}
+	if _, err := api.VerifyRange(&from, &to); err == nil {
+		t.Error("Oversized range accepted")
+	}
+}
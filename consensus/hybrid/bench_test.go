@@ -0,0 +1,310 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// benchEngine is a mock engine delivering a successful verification result for
+// every header in a batch, so the cost of the routing layer can be measured.
+type benchEngine struct {
+	mockEngine
+}
+
+func (e *benchEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	quit := make(chan struct{})
+	results := make(chan error, len(headers))
+	for range headers {
+		results <- nil
+	}
+	close(results)
+	return quit, results
+}
+
+// benchTransition is the transition block used by the benchmarks.
+const benchTransition = 100_000
+
+// makeBenchHeaders creates a batch of consecutive headers starting at first.
+func makeBenchHeaders(first uint64, n int) []*types.Header {
+	headers := make([]*types.Header, n)
+	for i := range headers {
+		headers[i] = &types.Header{Number: new(big.Int).SetUint64(first + uint64(i)), Difficulty: big.NewInt(0)}
+		if first+uint64(i) >= benchTransition {
+			headers[i].Difficulty = big.NewInt(2)
+		}
+	}
+	return headers
+}
+
+// benchBatches returns the header batches entirely before, entirely after and
+// spanning the transition for the given batch size.
+func benchBatches(n int) map[string][]*types.Header {
+	return map[string][]*types.Header{
+		"pre":      makeBenchHeaders(benchTransition-uint64(n), n),
+		"post":     makeBenchHeaders(benchTransition, n),
+		"spanning": makeBenchHeaders(benchTransition-uint64(n/2), n),
+	}
+}
+
+func newBenchHybrid(tb testing.TB) *Hybrid {
+	h, err := New(&benchEngine{mockEngine{name: "pos"}}, &benchEngine{mockEngine{name: "poa"}}, benchTransition)
+	if err != nil {
+		tb.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	return h
+}
+
+// verifyBatch runs a batch through VerifyHeaders and drains all results.
+func verifyBatch(h *Hybrid, chain consensus.ChainHeaderReader, headers []*types.Header) {
+	_, results := h.VerifyHeaders(chain, headers)
+	for range headers {
+		<-results
+	}
+}
+
+func BenchmarkVerifyHeaders(b *testing.B) {
+	h := newBenchHybrid(b)
+	chain := &mockChainReader{}
+
+	for _, size := range []int{16, 128, 1024} {
+		batches := benchBatches(size)
+		for _, kind := range []string{"pre", "post", "spanning"} {
+			headers := batches[kind]
+			b.Run(fmt.Sprintf("%s/%d", kind, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					verifyBatch(h, chain, headers)
+				}
+			})
+		}
+	}
+}
+
+// costlyEngine is a bench engine whose single-header verification costs about
+// as much as recovering a seal signature.
+type costlyEngine struct {
+	benchEngine
+}
+
+func (e *costlyEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	hash := header.Number.Bytes()
+	for i := 0; i < 64; i++ {
+		hash = crypto.Keccak256(hash)
+	}
+	return nil
+}
+
+// BenchmarkVerifyHeadersBoundary measures the header by header verification of
+// boundary batches needing dual verification, serially and on the worker pool.
+func BenchmarkVerifyHeadersBoundary(b *testing.B) {
+	h, err := New(&costlyEngine{benchEngine{mockEngine{name: "pos"}}}, &costlyEngine{benchEngine{mockEngine{name: "poa"}}}, benchTransition, WithDualVerifyWindow(1024))
+	if err != nil {
+		b.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	var (
+		chain   = &mockChainReader{}
+		headers = benchBatches(1024)["spanning"]
+	)
+	for name, workers := range map[string]int{"serial": 1, "pool": runtime.GOMAXPROCS(0)} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, results := h.verifyParallel(chain, headers, workers)
+				for range headers {
+					<-results
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDelegatedCalls measures the routing layer of the per-block calls
+// delegated to the wrapped engines.
+func BenchmarkDelegatedCalls(b *testing.B) {
+	h := newBenchHybrid(b)
+	chain := &mockChainReader{}
+	for _, number := range []uint64{benchTransition - 1, benchTransition + 1} {
+		var (
+			header = &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(2)}
+			block  = types.NewBlockWithHeader(header)
+			era    = "pos"
+		)
+		if number >= benchTransition {
+			era = "poa"
+		}
+		b.Run(era+"/Author", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.Author(header)
+			}
+		})
+		b.Run(era+"/VerifyUncles", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.VerifyUncles(nil, block)
+			}
+		})
+		b.Run(era+"/FinalizeAndAssemble", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.FinalizeAndAssemble(chain, header, nil, nil, nil)
+			}
+		})
+		b.Run(era+"/SealHash", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.SealHash(header)
+			}
+		})
+		b.Run(era+"/CalcDifficulty", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.CalcDifficulty(chain, 0, header)
+			}
+		})
+		b.Run(era+"/Seal", func(b *testing.B) {
+			results := make(chan *types.Block, 1)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				h.Seal(chain, block, results, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkPrepareCheckpoint(b *testing.B) {
+	h := newBenchHybrid(b)
+	chain := &mockChainReader{}
+	header := &types.Header{Number: big.NewInt(benchTransition)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		header.Extra = nil
+		if err := h.Prepare(chain, header); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTransitionExtra measures building the extra-data of the transition
+// block as the miner retries it: from the configured vanity, and re-preparing a
+// header that already lists the signers.
+func BenchmarkTransitionExtra(b *testing.B) {
+	var (
+		checkpoint = CheckpointExtra(defaultInitialSigners)
+		vanity     = make([]byte, extraVanity)
+		prepared   = transitionExtra(vanity, checkpoint)
+	)
+	b.Run("vanity", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			transitionExtra(vanity, checkpoint)
+		}
+	})
+	b.Run("prepared", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			transitionExtra(prepared, checkpoint)
+		}
+	})
+}
+
+func BenchmarkSealCheckpoint(b *testing.B) {
+	h := newBenchHybrid(b)
+	chain := &mockChainReader{}
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(benchTransition)})
+	results := make(chan *types.Block, 1)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := h.Seal(chain, block, results, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestVerifyHeadersAllocs is a regression gate on the allocations of the header
+// routing layer: era-pure batches must not allocate on top of the wrapped
+// engine, and boundary-spanning batches must not allocate per header.
+func TestVerifyHeadersAllocs(t *testing.T) {
+	h := newBenchHybrid(t)
+	chain := &mockChainReader{}
+	inner := &benchEngine{}
+
+	for _, kind := range []string{"pre", "post"} {
+		headers := benchBatches(128)[kind]
+		want := testing.AllocsPerRun(100, func() {
+			_, results := inner.VerifyHeaders(chain, headers)
+			for range headers {
+				<-results
+			}
+		})
+		if have := testing.AllocsPerRun(100, func() { verifyBatch(h, chain, headers) }); have > want {
+			t.Errorf("%s batch: routing layer allocates %v times, wrapped engine alone %v", kind, have, want)
+		}
+	}
+	var (
+		smallBatch = benchBatches(16)["spanning"]
+		largeBatch = benchBatches(1024)["spanning"]
+	)
+	small := testing.AllocsPerRun(100, func() { verifyBatch(h, chain, smallBatch) })
+	large := testing.AllocsPerRun(100, func() { verifyBatch(h, chain, largeBatch) })
+	if large > small+4 {
+		t.Errorf("spanning batch allocations grow with batch size: %v for 16 headers, %v for 1024", small, large)
+	}
+}
+
+// TestSelectEngineAllocs checks that engine selection, on the path of every
+// delegated call, does not allocate unless debug logging is enabled.
+func TestSelectEngineAllocs(t *testing.T) {
+	defer log.SetDefault(log.Root())
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(io.Discard, log.LevelInfo, false)))
+
+	h := newBenchHybrid(t)
+	for _, number := range []uint64{0, benchTransition - 1, benchTransition, benchTransition + 1, 2 * benchTransition} {
+		h.selectEngine(number) // Announce the switch outside of the measurement
+		if allocs := testing.AllocsPerRun(100, func() { h.selectEngine(number) }); allocs != 0 {
+			t.Errorf("block %d: engine selection allocates %v times", number, allocs)
+		}
+	}
+}
+
+// TestTransitionExtraAllocs checks that re-preparing an already prepared
+// transition block does not allocate, and building it allocates once.
+func TestTransitionExtraAllocs(t *testing.T) {
+	var (
+		checkpoint = CheckpointExtra(defaultInitialSigners)
+		vanity     = make([]byte, extraVanity)
+		prepared   = transitionExtra(vanity, checkpoint)
+	)
+	if have := testing.AllocsPerRun(100, func() { transitionExtra(prepared, checkpoint) }); have != 0 {
+		t.Errorf("re-preparing allocates %v times", have)
+	}
+	if have := testing.AllocsPerRun(100, func() { transitionExtra(vanity, checkpoint) }); have != 1 {
+		t.Errorf("building allocates %v times, want 1", have)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/schedule"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// innerEngine is implemented by engines wrapping another one, such as beacon.
+type innerEngine interface {
+	InnerEngine() consensus.Engine
+}
+
+// apiProvider is implemented by engines exposing RPC APIs of their own.
+type apiProvider interface {
+	APIs(chain consensus.ChainHeaderReader) []rpc.API
+}
+
+// threaded is implemented by engines whose sealing runs on a number of threads.
+type threaded interface {
+	SetThreads(threads int)
+}
+
+// Capability returns the engine responsible for the block with the given number
+// as a T, if it implements the optional interface T. Lazy engines are built and
+// wrappers such as beacon are looked through, so that the optional interfaces
+// of the wrapped engines stay reachable behind the hybrid engine.
+func Capability[T any](h *Hybrid, number uint64) (T, bool) {
+	return findCapability[T](schedule.EngineAt(h, number))
+}
+
+// findCapability returns the outermost layer of the given engine implementing
+// T, building lazy layers along the way.
+func findCapability[T any](engine consensus.Engine) (T, bool) {
+	for engine != nil {
+		if lazy, ok := engine.(*lazyEngine); ok {
+			engine = lazy.get()
+		}
+		if capability, ok := engine.(T); ok {
+			return capability, true
+		}
+		inner, ok := engine.(innerEngine)
+		if !ok {
+			break
+		}
+		engine = inner.InnerEngine()
+	}
+	var none T
+	return none, false
+}
+
+// SetThreads updates the sealing threads of the wrapped engines supporting it.
+// Lazy engines not built yet are skipped, they start with their defaults.
+func (h *Hybrid) SetThreads(threads int) {
+	for _, engine := range []consensus.Engine{h.posEngine, h.poaEngine} {
+		if !initialized(engine) {
+			continue
+		}
+		if th, ok := findCapability[threaded](engine); ok {
+			th.SetThreads(threads)
+		}
+	}
+}
+
+// engineAPIs returns the RPC APIs of the wrapped engines. The PoA engine takes
+// precedence if both engines provide a namespace. A lazy PoS engine is only
+// built for its APIs if the chain has not passed the transition yet, so that
+// nodes following the PoA chain never build it. Likewise, a lazy PoA engine is
+// only built for its APIs once the chain is within the warmup window of the
+// transition, where it would be built anyway; before that its APIs are left
+// out.
+func (h *Hybrid) engineAPIs(chain consensus.ChainHeaderReader) []rpc.API {
+	var (
+		engines    []consensus.Engine
+		head       = chain.CurrentHeader()
+		transition = h.transitionBlock.Load()
+	)
+	if initialized(h.poaEngine) || (head != nil && head.Number.Uint64()+poaWarmupWindow >= transition) {
+		engines = append(engines, h.poaEngine)
+	}
+	if initialized(h.posEngine) || head == nil || head.Number.Uint64() < transition {
+		engines = append(engines, h.posEngine)
+	}
+	var (
+		apis       []rpc.API
+		namespaces = map[string]bool{"hybrid": true}
+	)
+	for _, engine := range engines {
+		provider, ok := findCapability[apiProvider](engine)
+		if !ok {
+			continue
+		}
+		provided := make(map[string]bool)
+		for _, api := range provider.APIs(chain) {
+			if namespaces[api.Namespace] {
+				continue
+			}
+			provided[api.Namespace] = true
+			apis = append(apis, api)
+		}
+		for namespace := range provided {
+			namespaces[namespace] = true
+		}
+	}
+	return apis
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// capableEngine is a mock engine implementing optional engine interfaces.
+type capableEngine struct {
+	mockEngine
+	namespace string
+	threads   int
+}
+
+func (e *capableEngine) SetThreads(threads int) { e.threads = threads }
+
+func (e *capableEngine) APIs(chain consensus.ChainHeaderReader) []rpc.API {
+	return []rpc.API{{Namespace: e.namespace, Service: new(struct{})}, {Namespace: "shared", Service: e}}
+}
+
+func TestCapabilityPassThrough(t *testing.T) {
+	var (
+		pos   = &capableEngine{namespace: "pos"}
+		poa   = &capableEngine{namespace: "poa"}
+		built bool
+	)
+	h, err := New(beacon.New(pos), Lazy("poa", func() consensus.Engine { built = true; return poa }), 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// Optional interfaces are found through the beacon and lazy wrappers
+	if _, ok := Capability[*beacon.Beacon](h, 5); !ok {
+		t.Fatal("Beacon wrapper not found before the transition")
+	}
+	if engine, ok := Capability[*capableEngine](h, 5); !ok || engine != pos {
+		t.Fatal("Engine wrapped by beacon not found before the transition")
+	}
+	h.SetThreads(2)
+	if pos.threads != 2 || poa.threads != 0 || built {
+		t.Fatalf("Threads set on unbuilt lazy engine: pos %d, poa %d, built %v", pos.threads, poa.threads, built)
+	}
+	if engine, ok := Capability[*capableEngine](h, 10); !ok || engine != poa || !built {
+		t.Fatal("Lazy engine not found past the transition")
+	}
+	h.SetThreads(4)
+	if pos.threads != 4 || poa.threads != 4 {
+		t.Fatalf("Threads mismatch: pos %d, poa %d", pos.threads, poa.threads)
+	}
+	if _, ok := Capability[Rotator](h, 10); ok {
+		t.Fatal("Unimplemented interface reported")
+	}
+}
+
+func TestCapabilityAPIs(t *testing.T) {
+	namespaces := func(apis []rpc.API) []string {
+		var list []string
+		for _, api := range apis {
+			list = append(list, api.Namespace)
+		}
+		return list
+	}
+	var (
+		pos = &capableEngine{namespace: "pos"}
+		poa = &capableEngine{namespace: "poa"}
+	)
+	// Before the transition both engines expose their APIs, the PoA engine
+	// taking precedence for shared namespaces
+	h, err := New(beacon.New(pos), poa, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	apis := h.APIs(newTestHeaderChain(params.TestChainConfig, 5, 1))
+	if have, want := namespaces(apis), []string{"hybrid", "poa", "shared", "pos"}; !slices.Equal(have, want) {
+		t.Fatalf("Namespaces mismatch: have %v, want %v", have, want)
+	}
+	if apis[2].Service != poa {
+		t.Fatal("Shared namespace not served by the PoA engine")
+	}
+	// Past the transition a lazy PoS engine is not built for its APIs
+	var built bool
+	h, err = New(Lazy("pos", func() consensus.Engine { built = true; return pos }), poa, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	apis = h.APIs(newTestHeaderChain(params.TestChainConfig, 20, 1))
+	if have, want := namespaces(apis), []string{"hybrid", "poa", "shared"}; !slices.Equal(have, want) || built {
+		t.Fatalf("Namespaces mismatch: have %v, want %v, PoS built %v", have, want, built)
+	}
+	// Far ahead of the transition a lazy PoA engine is not built for its APIs
+	built = false
+	h, err = New(pos, Lazy("poa", func() consensus.Engine { built = true; return poa }), 2*poaWarmupWindow)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	apis = h.APIs(newTestHeaderChain(params.TestChainConfig, 5, 1))
+	if have, want := namespaces(apis), []string{"hybrid", "pos", "shared"}; !slices.Equal(have, want) || built {
+		t.Fatalf("Namespaces mismatch: have %v, want %v, PoA built %v", have, want, built)
+	}
+	// Within the warmup window it is
+	h, err = New(pos, Lazy("poa", func() consensus.Engine { built = true; return poa }), poaWarmupWindow)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	apis = h.APIs(newTestHeaderChain(params.TestChainConfig, 5, 1))
+	if have, want := namespaces(apis), []string{"hybrid", "poa", "shared", "pos"}; !slices.Equal(have, want) || !built {
+		t.Fatalf("Namespaces mismatch: have %v, want %v, PoA built %v", have, want, built)
+	}
+}
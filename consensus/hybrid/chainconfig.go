@@ -0,0 +1,163 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrMissingTTD is returned if the chain config lacks the terminal total
+	// difficulty, without which the PoS engine cannot tell PoS blocks apart.
+	ErrMissingTTD = errors.New("'terminalTotalDifficulty' is not set in genesis block")
+
+	// ErrMissingClique is returned if the chain config lacks the clique settings
+	// both engines are built from.
+	ErrMissingClique = errors.New("chain config has no clique settings")
+
+	// ErrNoTransition is returned if the chain config schedules no transition.
+	ErrNoTransition = errors.New("chain config has no PoS to PoA transition block")
+
+	// ErrUnsupportedEngine is returned for unknown engine types.
+	ErrUnsupportedEngine = errors.New("unsupported consensus engine type")
+
+	// ErrEngineCombination is returned for engine types that cannot back the
+	// two eras of the same chain.
+	ErrEngineCombination = errors.New("unsupported combination of consensus engine types")
+)
+
+// EngineType names a concrete consensus engine backing one era of the chain.
+type EngineType string
+
+const (
+	// EngineClique is clique, wrapped into the beacon engine for the PoS era.
+	EngineClique EngineType = "clique"
+
+	// EngineEthashFaker is the ethash faker accepting all seals, for tests.
+	EngineEthashFaker EngineType = "ethash-faker"
+)
+
+// EngineTypes selects the concrete engines backing the two eras of the chain.
+type EngineTypes struct {
+	PoS EngineType // Engine wrapped into the beacon engine before the transition
+	PoA EngineType // Engine used from the transition on
+}
+
+// DefaultEngineTypes backs both eras with clique.
+var DefaultEngineTypes = EngineTypes{PoS: EngineClique, PoA: EngineClique}
+
+// Validate checks that the engine types are available and can back the same
+// chain. The ethash faker accepts any seal, so it must not be paired with a
+// real engine.
+func (t EngineTypes) Validate() error {
+	for _, kind := range []EngineType{t.PoS, t.PoA} {
+		switch kind {
+		case EngineClique, EngineEthashFaker:
+		default:
+			return fmt.Errorf("%w: %q", ErrUnsupportedEngine, kind)
+		}
+	}
+	if (t.PoS == EngineEthashFaker) != (t.PoA == EngineEthashFaker) {
+		return fmt.Errorf("%w: PoS %q with PoA %q", ErrEngineCombination, t.PoS, t.PoA)
+	}
+	return nil
+}
+
+// build returns a lazy engine of the given type, beacon-wrapped for the PoS era.
+func (t EngineType) build(config *params.ChainConfig, db ethdb.Database, pos bool) consensus.Engine {
+	name := string(t)
+	if pos {
+		name = "beacon+" + name
+	}
+	return Lazy(name, func() consensus.Engine {
+		var engine consensus.Engine
+		switch t {
+		case EngineClique:
+			engine = clique.New(config.Clique, db)
+		case EngineEthashFaker:
+			engine = ethash.NewFaker()
+		}
+		if pos {
+			engine = beacon.New(engine)
+		}
+		return engine
+	})
+}
+
+// NewFromChainConfig creates a hybrid engine for the chain config, building the
+// beacon-wrapped clique PoS engine and the clique PoA engine itself. Both are
+// lazy: the PoS engine is never built on a node that only processes blocks past
+// the transition, while the PoA engine is built shortly before the transition.
+//
+// The transition settings of the chain config, the initial signers and the
+// confirmation depth, take effect unless overridden by the given options. The
+// engine state is persisted in the database and double signs are monitored.
+func NewFromChainConfig(config *params.ChainConfig, db ethdb.Database, opts ...Option) (*Hybrid, error) {
+	return NewFromEngineTypes(config, db, DefaultEngineTypes, opts...)
+}
+
+// NewFromEngineTypes is like NewFromChainConfig, backing the two eras with the
+// given engine types. Unset types default to clique.
+func NewFromEngineTypes(config *params.ChainConfig, db ethdb.Database, types EngineTypes, opts ...Option) (*Hybrid, error) {
+	if types.PoS == "" {
+		types.PoS = DefaultEngineTypes.PoS
+	}
+	if types.PoA == "" {
+		types.PoA = DefaultEngineTypes.PoA
+	}
+	if err := types.Validate(); err != nil {
+		return nil, err
+	}
+	switch {
+	case config.TerminalTotalDifficulty == nil:
+		return nil, ErrMissingTTD
+	case config.Clique == nil:
+		return nil, ErrMissingClique
+	case !config.HasPoSToPoATransition():
+		return nil, ErrNoTransition
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	// A transition triggered by the terminal PoS block is pending until its first
+	// descendant is reached, unless a previous run already resolved it
+	transition := configuredTransition(config, db)
+
+	posEngine := types.PoS.build(config, db, true)
+	poaEngine := types.PoA.build(config, db, false)
+	if config.TerminalPoSBlockHash != nil {
+		opts = append([]Option{WithTerminalHash(*config.TerminalPoSBlockHash)}, opts...)
+	}
+	opts = append([]Option{
+		WithManualTransition(config.PoSToPoAManualTransition),
+		WithInitialSigners(config.PoAInitialSigners),
+		WithConfirmationDepth(config.TransitionConfirmations()),
+		WithGraceWindow(config.TransitionGrace()),
+		WithDatabase(db),
+		WithDoubleSignMonitor(),
+	}, opts...)
+
+	return New(posEngine, poaEngine, transition, opts...)
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestNewFromChainConfig(t *testing.T) {
+	signers := []common.Address{{0x01}, {0x02}, {0x03}}
+	depth, grace := uint64(12), uint64(4)
+	newConfig := func() *params.ChainConfig {
+		config := *params.AllCliqueProtocolChanges
+		config.TerminalTotalDifficulty = common.Big0
+		config.PoSToPoATransitionBlock = big.NewInt(100)
+		config.PoAInitialSigners = signers
+		config.TransitionConfirmationDepth = &depth
+		config.TransitionGraceWindow = &grace
+		return &config
+	}
+	h, err := NewFromChainConfig(newConfig(), rawdb.NewMemoryDatabase())
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+
+	if h.transitionBlock.Load() != 100 {
+		t.Errorf("Transition block mismatch: have %d, want 100", h.transitionBlock.Load())
+	}
+	if !slices.Equal(h.InitialSigners(), signers) {
+		t.Errorf("Initial signers mismatch: have %v, want %v", h.InitialSigners(), signers)
+	}
+	if h.confirmDepth != depth {
+		t.Errorf("Confirmation depth mismatch: have %d, want %d", h.confirmDepth, depth)
+	}
+	if h.GraceWindow() != grace {
+		t.Errorf("Grace window mismatch: have %d, want %d", h.GraceWindow(), grace)
+	}
+	if h.db == nil || h.doubleSign == nil {
+		t.Error("Engine state not persisted or double signs not monitored")
+	}
+	// Options override the chain config
+	override := []common.Address{{0x04}, {0x05}, {0x06}}
+	h, err = NewFromChainConfig(newConfig(), rawdb.NewMemoryDatabase(), WithInitialSigners(override))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+	if !slices.Equal(h.InitialSigners(), override) {
+		t.Errorf("Overridden initial signers mismatch: have %v, want %v", h.InitialSigners(), override)
+	}
+	// Clique is founded on the effective signers once it is built, not before
+	if initialized(h.poaEngine) {
+		t.Fatal("PoA engine built on creation")
+	}
+	c, ok := findCapability[*clique.Clique](h.poaEngine)
+	if !ok {
+		t.Fatal("PoA engine is no clique engine")
+	}
+	parent := &types.Header{Number: big.NewInt(99)}
+	snap, err := c.Snapshot(&configChainReader{config: newConfig(), headers: map[uint64]*types.Header{99: parent}}, parent)
+	if err != nil {
+		t.Fatalf("Failed to retrieve takeover snapshot: %v", err)
+	}
+	if founders := slices.SortedFunc(maps.Keys(snap.Signers), common.Address.Cmp); !slices.Equal(founders, override) {
+		t.Errorf("Founding signers mismatch: have %v, want %v", founders, override)
+	}
+	// Incomplete or invalid configs are refused
+	tests := []struct {
+		mutate func(*params.ChainConfig)
+		err    error
+	}{
+		{func(c *params.ChainConfig) { c.TerminalTotalDifficulty = nil }, ErrMissingTTD},
+		{func(c *params.ChainConfig) { c.Clique = nil }, ErrMissingClique},
+		{func(c *params.ChainConfig) { c.PoSToPoATransitionBlock = nil }, ErrNoTransition},
+		{func(c *params.ChainConfig) { c.PoAInitialSigners = []common.Address{{0x01}, {0x01}} }, nil},
+	}
+	for i, tt := range tests {
+		config := newConfig()
+		tt.mutate(config)
+		_, err := NewFromChainConfig(config, rawdb.NewMemoryDatabase())
+		if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestEngineTypes(t *testing.T) {
+	tests := []struct {
+		types EngineTypes
+		err   error
+	}{
+		{DefaultEngineTypes, nil},
+		{EngineTypes{PoS: EngineEthashFaker, PoA: EngineEthashFaker}, nil},
+		{EngineTypes{PoS: EngineClique, PoA: EngineEthashFaker}, ErrEngineCombination},
+		{EngineTypes{PoS: EngineEthashFaker, PoA: EngineClique}, ErrEngineCombination},
+		{EngineTypes{PoS: EngineClique, PoA: "aura"}, ErrUnsupportedEngine},
+		{EngineTypes{PoS: "contract", PoA: EngineClique}, ErrUnsupportedEngine},
+	}
+	for i, tt := range tests {
+		if err := tt.types.Validate(); !errors.Is(err, tt.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Engines are built from the selected types once used
+	config := *params.AllCliqueProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.PoSToPoATransitionBlock = big.NewInt(100)
+
+	h, err := NewFromEngineTypes(&config, rawdb.NewMemoryDatabase(), EngineTypes{PoS: EngineEthashFaker, PoA: EngineEthashFaker})
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+	if have := fmt.Sprintf("%T", h.poaEngine.(*lazyEngine).get()); have != "*ethash.Ethash" {
+		t.Errorf("PoA engine type mismatch: have %s, want *ethash.Ethash", have)
+	}
+	if have := fmt.Sprintf("%T", h.posEngine.(*lazyEngine).get()); have != "*beacon.Beacon" {
+		t.Errorf("PoS engine type mismatch: have %s, want *beacon.Beacon", have)
+	}
+	// Unset types default to clique
+	h, err = NewFromEngineTypes(&config, rawdb.NewMemoryDatabase(), EngineTypes{})
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+	if have := fmt.Sprintf("%T", h.poaEngine.(*lazyEngine).get()); have != "*clique.Clique" {
+		t.Errorf("Default PoA engine type mismatch: have %s, want *clique.Clique", have)
+	}
+}
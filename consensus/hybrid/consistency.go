@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// consistencyBoundaryWindow is the number of blocks on either side of the
+	// transition making up the boundary region samples are weighted toward.
+	consistencyBoundaryWindow = 1024
+
+	// consistencyMinDepth is the number of blocks below the head that are never
+	// sampled, so reorgs cannot be mistaken for inconsistencies.
+	consistencyMinDepth = 128
+)
+
+var (
+	consistencyCheckedCounter = metrics.NewRegisteredCounter("hybrid/consistency/checked", nil)
+	consistencyFailedCounter  = metrics.NewRegisteredCounter("hybrid/consistency/failed", nil)
+)
+
+// consistencyChecker re-verifies random historical blocks in the background,
+// half of them from the boundary region around the transition, to detect
+// database corruption long before the blocks are needed again.
+type consistencyChecker struct {
+	interval time.Duration // Delay between two sampled blocks
+	rand     *rand.Rand    // Source of the sampled block numbers
+
+	start sync.Once
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newConsistencyChecker(interval time.Duration) *consistencyChecker {
+	return &consistencyChecker{
+		interval: interval,
+		rand:     rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		quit:     make(chan struct{}),
+	}
+}
+
+// stop terminates the checker, waiting for a running check to finish.
+func (c *consistencyChecker) stop() {
+	close(c.quit)
+	c.wg.Wait()
+}
+
+// sample picks the number of a block to check out of the blocks deep enough
+// below the given head, or reports false if there is none yet. The blocks of
+// the boundary region are picked half of the time.
+func (c *consistencyChecker) sample(head, transition uint64) (uint64, bool) {
+	if head <= consistencyMinDepth {
+		return 0, false
+	}
+	last := head - consistencyMinDepth
+
+	if transition != PendingTransition && c.rand.IntN(2) == 0 {
+		first := uint64(1)
+		if transition > consistencyBoundaryWindow {
+			first = transition - consistencyBoundaryWindow
+		}
+		if first <= last {
+			return first + c.rand.Uint64N(min(last, transition+consistencyBoundaryWindow)-first+1), true
+		}
+	}
+	return 1 + c.rand.Uint64N(last), true
+}
+
+// StartConsistencyCheck launches re-verifying random historical blocks of the
+// given chain in the background, if enabled with WithConsistencyCheck. The
+// checker is stopped along with the engine.
+func (h *Hybrid) StartConsistencyCheck(chain consensus.ChainHeaderReader) {
+	c := h.consistency
+	if c == nil {
+		return
+	}
+	c.start.Do(func() {
+		log.Info("Started hybrid consistency checker", "interval", c.interval)
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+
+			ticker := time.NewTicker(c.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					if number, ok := c.sample(chain.CurrentHeader().Number.Uint64(), h.transitionBlock.Load()); ok {
+						h.checkConsistency(chain, number)
+					}
+				case <-c.quit:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// checkConsistency re-verifies the canonical block of the given number by the
+// engine of its era, and checks it is still linked to by its child. Any
+// discrepancy raises an alert, as the block was valid when it was imported.
+func (h *Hybrid) checkConsistency(chain consensus.ChainHeaderReader, number uint64) error {
+	consistencyCheckedCounter.Inc(1)
+
+	err := func() error {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return fmt.Errorf("%w: canonical header #%d missing", errUnknownBlock, number)
+		}
+		if child := chain.GetHeaderByNumber(number + 1); child != nil && child.ParentHash != header.Hash() {
+			return fmt.Errorf("header hash %x differs from parent hash %x of child", header.Hash(), child.ParentHash)
+		}
+		if err := h.VerifyHeader(chain, header); err != nil {
+			return fmt.Errorf("header %x fails re-verification: %w", header.Hash(), err)
+		}
+		return nil
+	}()
+	if err != nil {
+		consistencyFailedCounter.Inc(1)
+		alert := Alert{Kind: AlertInconsistentBlock, Number: number, Message: err.Error()}
+		if header := chain.GetHeaderByNumber(number); header != nil {
+			alert.Hash = header.Hash()
+		}
+		h.raiseAlert(alert)
+		return err
+	}
+	log.Debug("Re-verified historical block", "number", number)
+	return nil
+}
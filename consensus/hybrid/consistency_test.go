@@ -0,0 +1,188 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestConsistencySample(t *testing.T) {
+	c := newConsistencyChecker(time.Second)
+	c.rand = rand.New(rand.NewPCG(1, 2))
+
+	if _, ok := c.sample(consistencyMinDepth, 10); ok {
+		t.Fatal("Sampled a block too close to the head")
+	}
+	const (
+		head       = 20_000
+		transition = 10_000
+		samples    = 10_000
+	)
+	boundary := func(number uint64) bool {
+		return number+consistencyBoundaryWindow >= transition && number <= transition+consistencyBoundaryWindow
+	}
+	for _, test := range []struct {
+		transition uint64
+		min, max   int // Bounds of the samples expected in the boundary region
+	}{
+		{transition, samples / 2, samples * 2 / 3},
+		{PendingTransition, 0, samples / 5},
+	} {
+		var near int
+		for i := 0; i < samples; i++ {
+			number, ok := c.sample(head, test.transition)
+			if !ok {
+				t.Fatalf("No block sampled at head %d", head)
+			}
+			if number == 0 || number > head-consistencyMinDepth {
+				t.Fatalf("Sampled block %d out of range", number)
+			}
+			if boundary(number) {
+				near++
+			}
+		}
+		if near < test.min || near > test.max {
+			t.Errorf("Transition %d: %d of %d samples in the boundary region, want %d-%d", test.transition, near, samples, test.min, test.max)
+		}
+	}
+	// A transition above the sampled range must not bias the samples
+	for i := 0; i < samples; i++ {
+		if number, _ := c.sample(500, 2_000); number == 0 || number > 500-consistencyMinDepth {
+			t.Fatalf("Sampled block %d out of range", number)
+		}
+	}
+}
+
+// newLinkedChain creates a chain of linked headers from genesis up to and
+// including the given number.
+func newLinkedChain(head uint64) *configChainReader {
+	chain := &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	for i := uint64(0); i <= head; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i)}
+		if i > 0 {
+			header.ParentHash = chain.headers[i-1].Hash()
+		}
+		chain.headers[i] = header
+	}
+	return chain
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	const transition = 100
+
+	errInvalid := errors.New("invalid header")
+
+	tests := []struct {
+		name    string
+		number  uint64
+		corrupt func(chain *configChainReader, pos, poa *trackingMockEngine)
+		fail    bool
+	}{
+		{"pos", 50, func(*configChainReader, *trackingMockEngine, *trackingMockEngine) {}, false},
+		{"poa", 150, func(*configChainReader, *trackingMockEngine, *trackingMockEngine) {}, false},
+		{"missing", 150, func(chain *configChainReader, _, _ *trackingMockEngine) {
+			delete(chain.headers, 150)
+		}, true},
+		{"unlinked", 50, func(chain *configChainReader, _, _ *trackingMockEngine) {
+			chain.headers[50] = &types.Header{Number: big.NewInt(50), Extra: []byte{1}}
+		}, true},
+		{"invalid pos", 50, func(_ *configChainReader, pos, _ *trackingMockEngine) {
+			pos.setError("VerifyHeader", errInvalid)
+		}, true},
+		{"invalid poa", 150, func(_ *configChainReader, _, poa *trackingMockEngine) {
+			poa.setError("VerifyHeader", errInvalid)
+		}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				chain = newLinkedChain(300)
+				pos   = newTrackingMockEngine("pos")
+				poa   = newTrackingMockEngine("poa")
+			)
+			h, err := New(pos, poa, transition)
+			if err != nil {
+				t.Fatalf("Failed to create hybrid engine: %v", err)
+			}
+			defer h.Close()
+
+			alerts := make(chan Alert, 1)
+			sub := h.SubscribeAlerts(alerts)
+			defer sub.Unsubscribe()
+
+			test.corrupt(chain, pos, poa)
+			err = h.checkConsistency(chain, test.number)
+			if failed := err != nil; failed != test.fail {
+				t.Fatalf("Check failure mismatch: have %v, want failure %v", err, test.fail)
+			}
+			select {
+			case alert := <-alerts:
+				if !test.fail {
+					t.Fatalf("Unexpected alert: %v", alert)
+				}
+				if alert.Kind != AlertInconsistentBlock || alert.Number != test.number {
+					t.Errorf("Alert mismatch: have %s at #%d, want %s at #%d", alert.Kind, alert.Number, AlertInconsistentBlock, test.number)
+				}
+			default:
+				if test.fail {
+					t.Fatal("No alert raised for inconsistent block")
+				}
+			}
+		})
+	}
+}
+
+func TestConsistencyCheckerLoop(t *testing.T) {
+	var (
+		chain = newLinkedChain(300)
+		pos   = newTrackingMockEngine("pos")
+		poa   = newTrackingMockEngine("poa")
+	)
+	pos.setError("VerifyHeader", errors.New("corrupted"))
+	poa.setError("VerifyHeader", errors.New("corrupted"))
+
+	h, err := New(pos, poa, 100, WithConsistencyCheck(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	alerts := make(chan Alert, 1)
+	sub := h.SubscribeAlerts(alerts)
+	defer sub.Unsubscribe()
+
+	h.StartConsistencyCheck(chain)
+	h.StartConsistencyCheck(chain) // Starting twice must not launch a second checker
+
+	select {
+	case alert := <-alerts:
+		if alert.Kind != AlertInconsistentBlock {
+			t.Errorf("Alert kind mismatch: have %s, want %s", alert.Kind, AlertInconsistentBlock)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No alert raised by the background checker")
+	}
+	sub.Unsubscribe()
+	if err := h.Close(); err != nil {
+		t.Fatalf("Failed to close engine: %v", err)
+	}
+}
@@ -32,18 +32,118 @@ Usage:
 	posEngine := beacon.New(clique.New(config.Clique, db))
 	poaEngine := clique.New(config.Clique, db)
 
-	// Create hybrid engine with transition at block 1000
-	// Initial signers are hardcoded in defaultInitialSigners
-	hybridEngine, err := hybrid.New(posEngine, poaEngine, 1000)
+	// Create hybrid engine with transition at block 1000. Without the
+	// WithInitialSigners option the placeholder defaultInitialSigners are
+	// used, which strict (production) mode refuses.
+	hybridEngine, err := hybrid.New(posEngine, poaEngine, 1000,
+		hybrid.WithInitialSigners(config.PoAInitialSigners),
+		hybrid.WithStrict(true))
 	if err != nil {
 		log.Fatal("Failed to create hybrid engine:", err)
 	}
 
 	// Use hybridEngine as any other consensus.Engine
 	// It will automatically use PoS for blocks < 1000 and PoA for blocks >= 1000
-	// The transition block (1000) will be prepared as a checkpoint block with hardcoded initial signers
+	// The transition block (1000) will be prepared as a checkpoint block with the initial signers
+
+Nodes configured through a chain config can leave building the engines to
+NewFromChainConfig, which validates the config and applies its transition settings:
+
+	hybridEngine, err := hybrid.NewFromChainConfig(config, db, hybrid.WithStrict(true))
 
 The hybrid engine is thread-safe and implements the full consensus.Engine interface,
 delegating all method calls to the appropriate underlying engine based on block number.
+
+Wrapping the underlying engines with Lazy defers their construction until first use. A
+lazy PoA engine is initialized once the chain gets close to the transition, while a lazy
+PoS engine is never built on nodes that only process blocks after the transition.
+
+The engine API keeps serving a consensus client up to the last PoS block. Once the chain
+head is past the transition, or a call concerns a block past it, every call fails with
+the ConsensusRetired error of code -38100, telling the client to stand down.
+
+Nodes run with --hybrid.transitionlog report a synthetic TransitionLog in the transition
+block over eth_getLogs and eth_getBlockReceipts, recording the signers taking over under
+TransitionLogTopic. The log is not part of the consensus data: it is absent from the
+receipts root and bloom, and only exists at the RPC layer.
+
+Once the chain head passed the transition, branches ending in a PoS block are kept as
+side chains and never reorg the PoA chain, and are rejected outright once the transition
+is final. PoS blocks past the transition are only accepted within the grace window of
+the transitionGraceWindow chain config setting, later ones fail verification with
+ErrLatePoSBlock.
+Once the transition is final, the clique snapshots persisted for the PoS era are pruned,
+which `geth hybrid prune-snapshots` also does offline. Until then, the blocks from the
+--hybrid.freezewindow blocks before the transition on are kept out of the ancient store,
+so reorgs across the boundary never touch frozen blocks. Rewinding the chain below the
+transition, e.g. with debug_setHead, resets this bookkeeping along with the executed
+transition, so the resynced transition block executes anew. As this isolates the node
+from its PoA peers, debug_setHead only rewinds a PoA head into the PoS era if forced.
+
+Instead of at a fixed block, the transition can be triggered at the first descendant of
+the terminal PoS block pinned by the terminalPoSBlockHash chain config setting. Until
+that block is reached the transition is pending, its child then becomes the transition
+block and its height is persisted for later runs. Transition blocks not descending from
+a pinned terminal block fail verification with ErrTerminalHashMismatch.
+
+Small networks coordinating the cutover out-of-band enable the posToPoaManualTransition
+chain config setting instead. Operators then flip the node to PoA at the current head
+through hybrid_flipToPoA, only served on authenticated endpoints, and the chosen height
+is recorded and applied as if configured from then on.
+
+Once the transition block is canonical, the initial signers attest it by signing a
+TransitionRecord of its height, hash and the initial signer set. The attestations are
+gossiped over the hbt/2 protocol, persisted, and served through hybrid_transitionRecord,
+so nodes joining later can discover and verify the actual transition point.
+A fresh node can be bootstrapped from such a record with WithTransitionProof: once it
+is verified to be signed by a majority of the initial signers, the engine switches to
+PoA at the attested block and rejects transition blocks of any other hash.
+
+Block explorers and indexers can fetch a machine-readable description of the rules of
+both eras, their engines, difficulty schemes, signer sources and finality rules, from
+hybrid_getConsensusSpec instead of hardcoding them.
+hybrid_consensusOf annotates a whole range of blocks with their engine, PoA sealer and
+whether they are the transition checkpoint in a single call. For compliance audits,
+hybrid_verifyRange re-verifies a range of stored blocks by the engine of their era and
+reports the verdict and timing of every block along with the PoA blocks sealed by
+unauthorized signers or claiming the wrong turn.
+Long-lived fallback validators can also run with --hybrid.consistencycheck, which keeps
+re-verifying a random historical block at the given interval, half of the time one from
+around the transition, and raises an inconsistentBlock alert for any that fails, as an
+early warning of database corruption.
+
+Callers that only want to know who could seal after a block use hybrid_getSnapshotAt,
+which reports the fee recipient of beacon blocks and the clique snapshot of blocks sealed
+under clique in either era, hiding the era split.
+Every epoch checkpoint past the transition is diffed against the signer list of the
+previous one, the transition block for the first epoch. The diffs are published through
+SubscribeEpochDiffs, the signer set size through the hybrid/epoch/signers gauge, and the
+diffs of any range of canonical checkpoints are served by hybrid_epochDiffs.
+
+Deployments embedding data in the transition block, such as the hash of the governance
+proposal ordering the cutover, contribute its vanity through WithTransitionExtra, while
+the initial signer list and the seal of the checkpoint stay laid out by the engine.
+
+Transition failures carry stable codes, reported as the code and data of JSON-RPC errors
+and with the verdicts of hybrid_verifyRange: an invalid transition checkpoint (-39001),
+an unauthorized sealer within the first epoch past the transition (-39002), a PoS block
+past the grace window (-39003) and configuration drift (-39004). CodeOf extracts them
+from errors returned by the engine.
+
+The hybrid engine implements schedule.Schedule with a PoS and a PoA phase, and routes
+every delegated call through it, so that later engine migrations can reuse the same
+boundary handling from the consensus/schedule package.
+
+Optional interfaces of the wrapped engines stay reachable through Capability, which
+looks through lazy and beacon wrappers, while RPC APIs and sealing threads of the wrapped
+engines are passed through by the hybrid engine itself.
+
+Panics of the wrapped engines in delegated calls that can fail are recovered and fail the
+call with an EnginePanic naming the engine, method and block, raising an enginePanic
+alert, so a bug in one engine fails the block at hand rather than the node. Calls without
+an error result, such as Finalize, and the goroutines the engines start are not covered.
+
+For resilience testing, building with the hybridfault tag enables InjectFault, which
+delays, fails or panics individual delegated calls at chosen block heights.
 */
 package hybrid
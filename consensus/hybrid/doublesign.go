@@ -0,0 +1,258 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	doubleSignQueue   = 1024 // Headers buffered for the monitor before dropping them
+	doubleSignHeights = 8192 // Recent (height, signer) pairs remembered by the monitor
+	maxEvidence       = 256  // Double-sign evidence records retained
+)
+
+// evidenceKey is the database key the double-sign evidence is persisted under.
+var evidenceKey = []byte("hybrid-doublesign-evidence")
+
+var (
+	doubleSignScannedCounter  = metrics.NewRegisteredCounter("hybrid/doublesign/scanned", nil)
+	doubleSignDroppedCounter  = metrics.NewRegisteredCounter("hybrid/doublesign/dropped", nil)
+	doubleSignDetectedCounter = metrics.NewRegisteredCounter("hybrid/doublesign/detected", nil)
+)
+
+// DoubleSignEvidence proves that a PoA signer sealed two different blocks at
+// the same height. Both headers carry the signer's seal, so the evidence can be
+// checked independently by anyone.
+type DoubleSignEvidence struct {
+	Signer common.Address `json:"signer"`
+	Number uint64         `json:"number"`
+	First  *types.Header  `json:"first"`  // Header seen first at the height
+	Second *types.Header  `json:"second"` // Conflicting header seen later
+}
+
+// signedHeight identifies a block height sealed by a particular signer.
+type signedHeight struct {
+	number uint64
+	signer common.Address
+}
+
+// doubleSignMonitor scans imported PoA headers in the background for signers
+// that sealed more than one block at the same height.
+type doubleSignMonitor struct {
+	engine consensus.Engine    // PoA engine recovering the signers of headers
+	db     ethdb.KeyValueStore // Database to persist evidence in (nil = in-memory only)
+	raise  func(Alert)         // Raises an alert for detected double signs
+
+	headers chan *types.Header
+	seen    *lru.BasicLRU[signedHeight, *types.Header]
+	known   map[[2]common.Hash]struct{}
+
+	evidence []*DoubleSignEvidence
+	feed     event.Feed
+	lock     sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newDoubleSignMonitor() *doubleSignMonitor {
+	seen := lru.NewBasicLRU[signedHeight, *types.Header](doubleSignHeights)
+	return &doubleSignMonitor{
+		headers: make(chan *types.Header, doubleSignQueue),
+		seen:    &seen,
+		known:   make(map[[2]common.Hash]struct{}),
+		quit:    make(chan struct{}),
+	}
+}
+
+// start restores the persisted evidence and launches the scanning loop.
+func (m *doubleSignMonitor) start(engine consensus.Engine, db ethdb.KeyValueStore, raise func(Alert)) {
+	m.engine, m.db, m.raise = engine, db, raise
+	m.restore()
+
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// stop terminates the scanning loop and waits for it to exit.
+func (m *doubleSignMonitor) stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// observe queues headers for scanning without ever blocking verification. If
+// the monitor falls behind, headers are dropped.
+func (m *doubleSignMonitor) observe(headers ...*types.Header) {
+	if m == nil {
+		return
+	}
+	for _, header := range headers {
+		select {
+		case m.headers <- header:
+		default:
+			doubleSignDroppedCounter.Inc(1)
+		}
+	}
+}
+
+func (m *doubleSignMonitor) loop() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case header := <-m.headers:
+			m.scan(header)
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// scan records the signer of the header at its height, raising an alert if the
+// signer already sealed a different block there.
+func (m *doubleSignMonitor) scan(header *types.Header) {
+	signer, err := m.engine.Author(header)
+	if err != nil {
+		return // Unsigned or malformed, verification rejects it anyway
+	}
+	doubleSignScannedCounter.Inc(1)
+
+	key := signedHeight{number: header.Number.Uint64(), signer: signer}
+	first, ok := m.seen.Get(key)
+	if !ok {
+		m.seen.Add(key, header)
+		return
+	}
+	if first.Hash() == header.Hash() {
+		return
+	}
+	pair := [2]common.Hash{first.Hash(), header.Hash()}
+	if _, ok := m.known[pair]; ok {
+		return
+	}
+	m.known[pair] = struct{}{}
+	m.report(&DoubleSignEvidence{
+		Signer: signer,
+		Number: key.number,
+		First:  first,
+		Second: header,
+	})
+}
+
+// report records the evidence, persists it and notifies subscribers.
+func (m *doubleSignMonitor) report(evidence *DoubleSignEvidence) {
+	doubleSignDetectedCounter.Inc(1)
+	log.Error("Detected double-signed PoA blocks", "signer", evidence.Signer, "number", evidence.Number,
+		"first", evidence.First.Hash(), "second", evidence.Second.Hash())
+
+	m.lock.Lock()
+	m.evidence = append(m.evidence, evidence)
+	if len(m.evidence) > maxEvidence {
+		m.evidence = m.evidence[len(m.evidence)-maxEvidence:]
+	}
+	if err := m.store(); err != nil {
+		log.Error("Failed to persist double-sign evidence", "err", err)
+	}
+	m.lock.Unlock()
+
+	m.feed.Send(*evidence)
+	m.raise(Alert{Kind: AlertDoubleSign, Number: evidence.Number, Hash: evidence.Second.Hash(), Signer: &evidence.Signer,
+		Message: fmt.Sprintf("signer %v sealed blocks %x and %x", evidence.Signer, evidence.First.Hash(), evidence.Second.Hash())})
+}
+
+// restore loads the evidence persisted by a previous run.
+func (m *doubleSignMonitor) restore() {
+	if m.db == nil {
+		return
+	}
+	blob, err := m.db.Get(evidenceKey)
+	if err != nil {
+		return // Nothing persisted yet
+	}
+	var evidence []*DoubleSignEvidence
+	if err := json.Unmarshal(blob, &evidence); err != nil {
+		log.Error("Failed to decode persisted double-sign evidence", "err", err)
+		return
+	}
+	for _, e := range evidence {
+		m.known[[2]common.Hash{e.First.Hash(), e.Second.Hash()}] = struct{}{}
+	}
+	m.evidence = evidence
+	if len(evidence) > 0 {
+		log.Warn("Restored persisted double-sign evidence", "count", len(evidence))
+	}
+}
+
+// store persists the retained evidence. The caller must hold the lock.
+func (m *doubleSignMonitor) store() error {
+	if m.db == nil {
+		return nil
+	}
+	blob, err := json.Marshal(m.evidence)
+	if err != nil {
+		return err
+	}
+	return m.db.Put(evidenceKey, blob)
+}
+
+// list returns the retained evidence, optionally filtered by signer.
+func (m *doubleSignMonitor) list(signer *common.Address) []*DoubleSignEvidence {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	evidence := make([]*DoubleSignEvidence, 0, len(m.evidence))
+	for _, e := range m.evidence {
+		if signer == nil || e.Signer == *signer {
+			evidence = append(evidence, e)
+		}
+	}
+	return evidence
+}
+
+// SubscribeDoubleSign subscribes to double-sign evidence found by the monitor.
+// Without the monitor enabled, the subscription never delivers anything.
+func (h *Hybrid) SubscribeDoubleSign(ch chan<- DoubleSignEvidence) event.Subscription {
+	if h.doubleSign == nil {
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			<-quit
+			return nil
+		})
+	}
+	return h.doubleSign.feed.Subscribe(ch)
+}
+
+// DoubleSignEvidence returns the evidence of double-signed PoA blocks collected
+// by the monitor, optionally restricted to a single signer. It returns nil if
+// the monitor is disabled.
+func (api *API) DoubleSignEvidence(signer *common.Address) []*DoubleSignEvidence {
+	if api.hybrid.doubleSign == nil {
+		return nil
+	}
+	return api.hybrid.doubleSign.list(signer)
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// coinbaseMockEngine is a mock PoA engine treating the coinbase of headers as
+// their signer.
+type coinbaseMockEngine struct {
+	mockEngine
+}
+
+func (m *coinbaseMockEngine) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+func TestDoubleSignMonitor(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		chain   = &mockChainReader{}
+		signerA = common.Address{0xaa}
+		signerB = common.Address{0xbb}
+	)
+	newHybrid := func() *Hybrid {
+		h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10, WithDatabase(db), WithDoubleSignMonitor())
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		return h
+	}
+	h := newHybrid()
+	api := &API{chain: chain, hybrid: h}
+
+	events := make(chan DoubleSignEvidence, 1)
+	sub := h.SubscribeDoubleSign(events)
+	defer sub.Unsubscribe()
+
+	header := func(number int64, signer common.Address, extra byte) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(2), Coinbase: signer, Extra: []byte{extra}}
+	}
+	first := header(12, signerA, 1)
+	headers := []*types.Header{
+		header(9, signerA, 1), header(9, signerA, 2), // PoS era, not scanned
+		header(11, signerA, 1), header(11, signerB, 2), // Different signers at one height
+		first, first, // Same block seen twice
+	}
+	_, results := h.VerifyHeaders(chain, headers)
+	for range headers {
+		<-results
+	}
+	second := header(12, signerA, 2)
+	if err := h.VerifyHeader(chain, second); err != nil {
+		t.Fatalf("Failed to verify header: %v", err)
+	}
+	select {
+	case evidence := <-events:
+		if evidence.Signer != signerA || evidence.Number != 12 {
+			t.Errorf("Evidence signer/number mismatch: have %v/%d, want %v/12", evidence.Signer, evidence.Number, signerA)
+		}
+		if evidence.First.Hash() != first.Hash() || evidence.Second.Hash() != second.Hash() {
+			t.Errorf("Evidence headers mismatch")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Double sign not reported")
+	}
+	// Seeing the conflicting block again must not duplicate the evidence. The
+	// monitor scans in order, so a later double sign flushes the queue.
+	h.VerifyHeader(chain, second)
+	h.VerifyHeader(chain, header(13, signerB, 1))
+	h.VerifyHeader(chain, header(13, signerB, 2))
+	select {
+	case evidence := <-events:
+		if evidence.Signer != signerB || evidence.Number != 13 {
+			t.Errorf("Evidence signer/number mismatch: have %v/%d, want %v/13", evidence.Signer, evidence.Number, signerB)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Double sign not reported")
+	}
+	h.Close()
+
+	if evidence := api.DoubleSignEvidence(nil); len(evidence) != 2 {
+		t.Fatalf("Evidence count mismatch: have %d, want 2", len(evidence))
+	}
+	if evidence := api.DoubleSignEvidence(&signerA); len(evidence) != 1 {
+		t.Fatalf("Evidence of signer count mismatch: have %d, want 1", len(evidence))
+	}
+	// Evidence survives a restart and is not reported again
+	h = newHybrid()
+	defer h.Close()
+
+	api = &API{chain: chain, hybrid: h}
+	if evidence := api.DoubleSignEvidence(&signerA); len(evidence) != 1 || evidence[0].Second.Hash() != second.Hash() {
+		t.Fatalf("Restored evidence mismatch: %v", evidence)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// effectiveConfigKey is the database key the effective engine configuration
+// is persisted under.
+var effectiveConfigKey = []byte("hybrid-effective-config")
+
+// ErrConfigDrift is returned in strict mode if consensus-critical settings
+// differ from the ones persisted by a previous run.
+var ErrConfigDrift = errors.New("hybrid configuration drifted from previous run")
+
+// effectiveConfig are the consensus-critical settings the engine was started
+// with, after all overrides have been resolved. Nodes of the same network must
+// agree on them, so unnoticed changes between restarts fork the node off.
+type effectiveConfig struct {
+	TransitionBlock uint64           `json:"transitionBlock"`
+	TerminalHash    common.Hash      `json:"terminalHash"`
+	InitialSigners  []common.Address `json:"initialSigners"`
+}
+
+// effectiveConfig returns the consensus-critical settings of the engine.
+func (h *Hybrid) effectiveConfig() *effectiveConfig {
+	return &effectiveConfig{
+		TransitionBlock: h.transitionBlock.Load(),
+		TerminalHash:    h.terminalHash,
+		InitialSigners:  slices.Clone(h.initialSigners),
+	}
+}
+
+// drift returns a description of every setting that differs between the two
+// configurations. The signer order is significant, as it determines the
+// extra-data of the transition block. A transition pending on the terminal
+// block is not considered drifted from the one it is later resolved to.
+func (c *effectiveConfig) drift(current *effectiveConfig) []string {
+	var drifted []string
+	if c.TransitionBlock != current.TransitionBlock && c.TransitionBlock != PendingTransition && current.TransitionBlock != PendingTransition {
+		drifted = append(drifted, fmt.Sprintf("transition block %d -> %d", c.TransitionBlock, current.TransitionBlock))
+	}
+	if c.TerminalHash != current.TerminalHash {
+		drifted = append(drifted, fmt.Sprintf("terminal block %x -> %x", c.TerminalHash, current.TerminalHash))
+	}
+	if !slices.Equal(c.InitialSigners, current.InitialSigners) {
+		drifted = append(drifted, fmt.Sprintf("initial signers %v -> %v", c.InitialSigners, current.InitialSigners))
+	}
+	return drifted
+}
+
+// checkDrift compares the effective configuration with the one persisted by a
+// previous run and records the current one. Drift is refused in strict mode,
+// otherwise it is logged prominently and the new configuration is accepted.
+func (h *Hybrid) checkDrift() error {
+	if h.db == nil {
+		return nil
+	}
+	current := h.effectiveConfig()
+	if blob, err := h.db.Get(effectiveConfigKey); err == nil {
+		var stored effectiveConfig
+		if err := json.Unmarshal(blob, &stored); err != nil {
+			log.Error("Failed to decode persisted hybrid configuration", "err", err)
+		} else if drifted := stored.drift(current); len(drifted) > 0 {
+			if h.strict {
+				return &TransitionError{Code: CodeConfigDrift, Err: fmt.Errorf("%w: %s", ErrConfigDrift, strings.Join(drifted, ", "))}
+			}
+			log.Error("##################################################################")
+			log.Error("Consensus-critical hybrid configuration changed since last run!")
+			for _, change := range drifted {
+				log.Error("Drifted hybrid setting", "change", change)
+			}
+			log.Error("Make sure all validators run the same configuration.")
+			log.Error("##################################################################")
+		} else {
+			return nil
+		}
+	}
+	h.storeEffectiveConfig(current)
+	return nil
+}
+
+// storeEffectiveConfig persists the effective configuration for the drift
+// check of the next run.
+func (h *Hybrid) storeEffectiveConfig(config *effectiveConfig) {
+	blob, err := json.Marshal(config)
+	if err == nil {
+		err = h.db.Put(effectiveConfigKey, blob)
+	}
+	if err != nil {
+		log.Warn("Failed to persist hybrid configuration", "err", err)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestConfigDrift(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		signers = []common.Address{{0xa1}, {0xa2}, {0xa3}}
+		edited  = []common.Address{{0xa1}, {0xa2}, {0xa4}}
+	)
+	create := func(transition uint64, signers []common.Address, strict bool) error {
+		_, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, transition,
+			WithStrict(strict), WithInitialSigners(signers), WithDatabase(db))
+		return err
+	}
+	// The first run records the configuration, identical restarts are accepted
+	if err := create(100, signers, true); err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if err := create(100, signers, true); err != nil {
+		t.Fatalf("Failed to restart engine with same configuration: %v", err)
+	}
+	// Drifted signers or transition block are refused in strict mode
+	err := create(100, edited, true)
+	if !errors.Is(err, ErrConfigDrift) {
+		t.Errorf("Expected ErrConfigDrift for edited signers, got %v", err)
+	}
+	if code, _ := CodeOf(err); code != CodeConfigDrift {
+		t.Errorf("Error code mismatch: have %d, want %d", code, CodeConfigDrift)
+	}
+	if err := create(101, signers, true); !errors.Is(err, ErrConfigDrift) {
+		t.Errorf("Expected ErrConfigDrift for moved transition, got %v", err)
+	}
+	// A refused configuration must not replace the recorded one
+	if err := create(100, signers, true); err != nil {
+		t.Fatalf("Recorded configuration was replaced: %v", err)
+	}
+	// Outside of strict mode drift is accepted and recorded
+	if err := create(100, edited, false); err != nil {
+		t.Fatalf("Expected drift to be accepted in non-strict mode, got %v", err)
+	}
+	if err := create(100, edited, true); err != nil {
+		t.Errorf("Accepted configuration was not recorded: %v", err)
+	}
+	if err := create(100, signers, true); !errors.Is(err, ErrConfigDrift) {
+		t.Errorf("Expected ErrConfigDrift for reverted signers, got %v", err)
+	}
+}
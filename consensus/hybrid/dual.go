@@ -0,0 +1,152 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxDivergences is the number of most recent divergences retained for the
+// dual verification report.
+const maxDivergences = 32
+
+var (
+	dualCheckedCounter    = metrics.NewRegisteredCounter("hybrid/dual/checked", nil)
+	dualDivergenceCounter = metrics.NewRegisteredCounter("hybrid/dual/divergence", nil)
+)
+
+// Divergence describes a header on which the routed engine and the other engine
+// disagreed about acceptance.
+type Divergence struct {
+	Number        uint64      `json:"number"`
+	Hash          common.Hash `json:"hash"`
+	Engine        string      `json:"engine"`               // Engine the header was routed to ("pos" or "poa")
+	Accepted      bool        `json:"accepted"`             // Verdict of the routed engine
+	OtherAccepted bool        `json:"otherAccepted"`        // Verdict of the other engine
+	Error         string      `json:"error,omitempty"`      // Error of the routed engine, if rejected
+	OtherError    string      `json:"otherError,omitempty"` // Error of the other engine, if rejected
+}
+
+// DualReport summarises the dual-engine verification around the transition.
+type DualReport struct {
+	Window      uint64       `json:"window"`      // Number of blocks on either side of the transition verified by both engines
+	Checked     uint64       `json:"checked"`     // Number of headers verified by both engines
+	Divergent   uint64       `json:"divergent"`   // Number of headers the engines disagreed on
+	Divergences []Divergence `json:"divergences"` // Most recent divergences, oldest first
+}
+
+// dualVerifier verifies headers close to the transition with both engines and
+// records any disagreement between them.
+type dualVerifier struct {
+	window uint64
+
+	report DualReport
+	lock   sync.Mutex
+}
+
+func newDualVerifier(window uint64) *dualVerifier {
+	return &dualVerifier{
+		window: window,
+		report: DualReport{Window: window, Divergences: []Divergence{}},
+	}
+}
+
+// covers returns whether any number in [first, last] falls into the window.
+func (d *dualVerifier) covers(first, last, transition uint64) bool {
+	if d == nil {
+		return false
+	}
+	return last+d.window >= transition && first <= transition+d.window
+}
+
+// snapshot returns a copy of the current report.
+func (d *dualVerifier) snapshot() *DualReport {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	report := d.report
+	report.Divergences = slices.Clone(d.report.Divergences)
+	return &report
+}
+
+// dualVerify runs the header through the engine it was not routed to and
+// compares the verdict with the routed engine's result. It never changes the
+// outcome of the verification.
+func (h *Hybrid) dualVerify(chain consensus.ChainHeaderReader, header *types.Header, usedPoA bool, routedErr error) {
+	number := header.Number.Uint64()
+	if !h.dual.covers(number, number, h.transitionBlock.Load()) {
+		return
+	}
+	engine, other := "pos", h.poaEngine
+	if usedPoA {
+		engine, other = "poa", h.posEngine
+	}
+	otherErr := other.VerifyHeader(chain, header)
+
+	h.dual.lock.Lock()
+	defer h.dual.lock.Unlock()
+
+	dualCheckedCounter.Inc(1)
+	h.dual.report.Checked++
+	if (routedErr == nil) == (otherErr == nil) {
+		return
+	}
+	divergence := Divergence{
+		Number:        number,
+		Hash:          header.Hash(),
+		Engine:        engine,
+		Accepted:      routedErr == nil,
+		OtherAccepted: otherErr == nil,
+	}
+	if routedErr != nil {
+		divergence.Error = routedErr.Error()
+	}
+	if otherErr != nil {
+		divergence.OtherError = otherErr.Error()
+	}
+	dualDivergenceCounter.Inc(1)
+	h.dual.report.Divergent++
+	h.dual.report.Divergences = append(h.dual.report.Divergences, divergence)
+	if len(h.dual.report.Divergences) > maxDivergences {
+		h.dual.report.Divergences = h.dual.report.Divergences[1:]
+	}
+	log.Warn("Consensus engines disagree on header near the transition",
+		"blockNumber", number,
+		"blockHash", divergence.Hash,
+		"transitionBlock", h.transitionBlock.Load(),
+		"routedEngine", engine,
+		"accepted", divergence.Accepted,
+		"otherAccepted", divergence.OtherAccepted,
+		"error", routedErr,
+		"otherError", otherErr)
+}
+
+// DualReport returns the findings of the dual-engine verification, or nil if it
+// is disabled.
+func (h *Hybrid) DualReport() *DualReport {
+	if h.dual == nil {
+		return nil
+	}
+	return h.dual.snapshot()
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDualVerification(t *testing.T) {
+	posEngine := newTrackingMockEngine("pos")
+	poaEngine := newTrackingMockEngine("poa")
+	poaEngine.setError("VerifyHeader", errors.New("unauthorized signer"))
+
+	h, err := New(posEngine, poaEngine, 100, WithDualVerifyWindow(2))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := &mockChainReader{}
+	header := func(n int64) *types.Header { return &types.Header{Number: big.NewInt(n)} }
+
+	// Outside the window only the routed engine runs
+	h.VerifyHeader(chain, header(97))
+	if calls := poaEngine.getCallCount("VerifyHeader"); calls != 0 {
+		t.Fatalf("Expected no PoA verification outside the window, got %d", calls)
+	}
+	// Inside the window the routed verdict stands, divergences get recorded
+	if err := h.VerifyHeader(chain, header(98)); err != nil {
+		t.Fatalf("Dual verification changed the PoS verdict: %v", err)
+	}
+	if err := h.VerifyHeader(chain, header(102)); err == nil {
+		t.Fatal("Dual verification changed the PoA verdict")
+	}
+	h.VerifyHeader(chain, header(103))
+
+	report := h.DualReport()
+	if report.Checked != 2 || report.Divergent != 2 {
+		t.Fatalf("Unexpected dual report counters: %+v", report)
+	}
+	first, second := report.Divergences[0], report.Divergences[1]
+	if first.Number != 98 || first.Engine != "pos" || !first.Accepted || first.OtherAccepted {
+		t.Errorf("Unexpected pre-transition divergence: %+v", first)
+	}
+	if second.Number != 102 || second.Engine != "poa" || second.Accepted || !second.OtherAccepted {
+		t.Errorf("Unexpected post-transition divergence: %+v", second)
+	}
+	// Agreeing engines record no divergence
+	poaEngine.setError("VerifyHeader", nil)
+	h.VerifyHeader(chain, header(100))
+	if report := h.DualReport(); report.Checked != 3 || report.Divergent != 2 {
+		t.Errorf("Unexpected dual report counters after agreement: %+v", report)
+	}
+	// Batches touching the window are verified per header by both engines
+	poaBatches := poaEngine.getCallCount("VerifyHeaders")
+	_, results := h.VerifyHeaders(chain, []*types.Header{header(101), header(102), header(103)})
+	for range 3 {
+		<-results
+	}
+	if calls := poaEngine.getCallCount("VerifyHeaders"); calls != poaBatches {
+		t.Error("Batch inside the dual window took the single-engine fast path")
+	}
+	if report := h.DualReport(); report.Checked != 5 {
+		t.Errorf("Expected batch headers inside the window to be dual verified, got %d", report.Checked)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxEpochDiffs is the maximum number of epoch checkpoints that can be diffed
+// in a single request.
+const maxEpochDiffs = 1024
+
+var epochSignersGauge = metrics.NewRegisteredGauge("hybrid/epoch/signers", nil)
+
+// EpochDiff is the change of the signer list of a PoA epoch checkpoint versus
+// the previous checkpoint, the transition block for the first epoch. Old lists
+// the signers of the previous checkpoint, New the ones of this checkpoint.
+type EpochDiff struct {
+	SignerSetChange
+	Previous uint64 `json:"previous"` // Number of the previous checkpoint
+}
+
+// previousCheckpoint returns the number of the checkpoint preceding the epoch
+// checkpoint of the given number.
+func (h *Hybrid) previousCheckpoint(chain consensus.ChainHeaderReader, number uint64) uint64 {
+	epoch := chain.Config().Clique.Epoch
+	return max(number-epoch, h.transitionBlock.Load())
+}
+
+// newEpochDiff diffs the signer lists of an epoch checkpoint and the previous
+// checkpoint.
+func newEpochDiff(header, previous *types.Header) (*EpochDiff, error) {
+	signers, err := checkpointSigners(header.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint #%d: %w", header.Number, err)
+	}
+	old, err := checkpointSigners(previous.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint #%d: %w", previous.Number, err)
+	}
+	return &EpochDiff{
+		SignerSetChange: newSignerSetChange(header, old, signers),
+		Previous:        previous.Number.Uint64(),
+	}, nil
+}
+
+// epochCheckpoint returns whether the block with the given number is an epoch
+// checkpoint of the PoA era past the transition block.
+func (h *Hybrid) epochCheckpoint(chain consensus.ChainHeaderReader, number uint64) bool {
+	config := chain.Config().Clique
+	if config == nil || config.Epoch == 0 {
+		return false
+	}
+	return number > h.transitionBlock.Load() && number%config.Epoch == 0
+}
+
+// watchEpoch announces the signer set diff of a processed PoA epoch checkpoint
+// and tracks the size of the signer set. The previous checkpoint is looked up
+// through the ancestors of the block, which need not be canonical yet.
+func (h *Hybrid) watchEpoch(chain consensus.ChainHeaderReader, header *types.Header) {
+	number := header.Number.Uint64()
+	if number == h.transitionBlock.Load() {
+		if signers, err := checkpointSigners(header.Extra); err == nil {
+			epochSignersGauge.Update(int64(len(signers)))
+		}
+		return
+	}
+	if !h.epochCheckpoint(chain, number) {
+		return
+	}
+	previous := header
+	for target := h.previousCheckpoint(chain, number); previous != nil && previous.Number.Uint64() > target; {
+		previous = chain.GetHeader(previous.ParentHash, previous.Number.Uint64()-1)
+	}
+	if previous == nil {
+		log.Debug("Previous PoA checkpoint unavailable", "number", number, "hash", header.Hash())
+		return
+	}
+	diff, err := newEpochDiff(header, previous)
+	if err != nil {
+		log.Warn("Failed to diff PoA epoch checkpoint", "number", number, "hash", header.Hash(), "err", err)
+		return
+	}
+	epochSignersGauge.Update(int64(len(diff.New)))
+	if len(diff.Added) > 0 || len(diff.Removed) > 0 {
+		log.Info("PoA signer set changed over epoch", "number", number, "previous", diff.Previous,
+			"signers", len(diff.New), "added", diff.Added, "removed", diff.Removed)
+	}
+	h.epochDiffs.Send(*diff)
+}
+
+// SubscribeEpochDiffs subscribes to the signer set diffs of processed PoA epoch
+// checkpoints, sent for every checkpoint whether the set changed or not.
+func (h *Hybrid) SubscribeEpochDiffs(ch chan<- EpochDiff) event.Subscription {
+	return h.epochDiffs.Subscribe(ch)
+}
+
+// EpochDiffs returns the signer set diffs of the canonical PoA epoch checkpoints
+// in the given range, which defaults to the transition block up to the current
+// head, so governance changes can be followed without decoding headers.
+func (api *API) EpochDiffs(from, to *rpc.BlockNumber) ([]*EpochDiff, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil || head.Number.Uint64() < api.hybrid.transitionBlock.Load() {
+		return nil, fmt.Errorf("%w: no PoA blocks yet", ErrTransitionNotReached)
+	}
+	clique := api.chain.Config().Clique
+	if clique == nil || clique.Epoch == 0 {
+		return nil, fmt.Errorf("%w: no clique epoch configured", ErrUnsupportedEngine)
+	}
+	floor := api.hybrid.transitionBlock.Load() + 1
+	start, end, err := resolveRange(from, to, floor, floor, head.Number.Uint64(), 0)
+	if err != nil {
+		return nil, err
+	}
+	first := (start + clique.Epoch - 1) / clique.Epoch * clique.Epoch
+	if first <= end && (end-first)/clique.Epoch >= maxEpochDiffs {
+		return nil, fmt.Errorf("range too large: more than %d checkpoints", maxEpochDiffs)
+	}
+	diffs := []*EpochDiff{}
+	for number := first; number <= end; number += clique.Epoch {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("%w: #%d", errUnknownBlock, number)
+		}
+		prev := api.hybrid.previousCheckpoint(api.chain, number)
+		previous := api.chain.GetHeaderByNumber(prev)
+		if previous == nil {
+			return nil, fmt.Errorf("%w: #%d", errUnknownBlock, prev)
+		}
+		diff, err := newEpochDiff(header, previous)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestEpochDiffs(t *testing.T) {
+	const (
+		transition = 95
+		epoch      = 10
+	)
+	var (
+		a, b, c, d = common.Address{0x0a}, common.Address{0x0b}, common.Address{0x0c}, common.Address{0x0d}
+		config     = *params.TestChainConfig
+		chain      = &configChainReader{config: &config, headers: make(map[uint64]*types.Header)}
+	)
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: epoch}
+
+	// Signer lists of the transition block and the following checkpoints
+	checkpoints := map[uint64][]common.Address{
+		transition: {a, b, c},
+		100:        {a, b, c},
+		110:        {a, b, c, d},
+		120:        {a, c, d},
+	}
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, transition)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+
+	diffs := make(chan EpochDiff, 8)
+	sub := h.SubscribeEpochDiffs(diffs)
+	defer sub.Unsubscribe()
+
+	for i := uint64(transition - 5); i <= 125; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Extra: CheckpointExtra(checkpoints[i])}
+		if parent := chain.headers[i-1]; parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		chain.headers[i] = header
+		h.Finalize(chain, header, nil, nil)
+
+		if i == transition {
+			if size := epochSignersGauge.Snapshot().Value(); size != 3 {
+				t.Errorf("Initial signer set size mismatch: have %d, want 3", size)
+			}
+		}
+	}
+	diff := func(number, previous uint64, added, removed []common.Address) *EpochDiff {
+		return &EpochDiff{
+			SignerSetChange: SignerSetChange{
+				Number:  number,
+				Hash:    chain.headers[number].Hash(),
+				Old:     checkpoints[previous],
+				New:     checkpoints[number],
+				Added:   added,
+				Removed: removed,
+			},
+			Previous: previous,
+		}
+	}
+	want := []*EpochDiff{
+		diff(100, transition, []common.Address{}, []common.Address{}),
+		diff(110, 100, []common.Address{d}, []common.Address{}),
+		diff(120, 110, []common.Address{}, []common.Address{b}),
+	}
+	for i, expected := range want {
+		select {
+		case have := <-diffs:
+			if !reflect.DeepEqual(&have, expected) {
+				t.Errorf("Diff %d mismatch: have %+v, want %+v", i, have, expected)
+			}
+		default:
+			t.Fatalf("Diff %d not published", i)
+		}
+	}
+	select {
+	case extra := <-diffs:
+		t.Fatalf("Unexpected diff published: %+v", extra)
+	default:
+	}
+	if size := epochSignersGauge.Snapshot().Value(); size != 3 {
+		t.Errorf("Signer set size mismatch: have %d, want 3", size)
+	}
+	// The history endpoint reports the same diffs from the canonical chain
+	api := &API{chain: chain, hybrid: h}
+	history, err := api.EpochDiffs(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to retrieve epoch diffs: %v", err)
+	}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("History mismatch: have %+v, want %+v", history, want)
+	}
+	from, to := rpc.BlockNumber(101), rpc.BlockNumber(115)
+	if history, err = api.EpochDiffs(&from, &to); err != nil {
+		t.Fatalf("Failed to retrieve epoch diffs: %v", err)
+	}
+	if !reflect.DeepEqual(history, want[1:2]) {
+		t.Errorf("Ranged history mismatch: have %+v, want %+v", history, want[1:2])
+	}
+	from = rpc.BlockNumber(121)
+	if history, err = api.EpochDiffs(&from, nil); err != nil || len(history) != 0 {
+		t.Errorf("Range without checkpoints: have %v, %v, want none", history, err)
+	}
+	// Chains past the transition without clique epochs have no history
+	config.Clique = nil
+	if _, err := api.EpochDiffs(nil, nil); !errors.Is(err, ErrUnsupportedEngine) {
+		t.Errorf("Error mismatch without epochs: have %v, want %v", err, ErrUnsupportedEngine)
+	}
+}
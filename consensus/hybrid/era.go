@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrEraViolation is returned for headers styled after the consensus era they
+// are not part of, e.g. PoS headers after the transition.
+var ErrEraViolation = errors.New("header violates era rules")
+
+// EraError describes the era rule a header violates.
+type EraError struct {
+	Number uint64 // Number of the offending header
+	Rule   string // Short name of the violated rule, usable as a metric label
+}
+
+// Error implements error.
+func (e *EraError) Error() string {
+	return fmt.Sprintf("%v: block %d breaks rule %q", ErrEraViolation, e.Number, e.Rule)
+}
+
+// Unwrap returns ErrEraViolation.
+func (e *EraError) Unwrap() error { return ErrEraViolation }
+
+// Reason returns the name of the violated rule.
+func (e *EraError) Reason() string { return e.Rule }
+
+// CheckEra checks the header against the era rules that need no chain context,
+// cheap enough to run on headers fresh off the network. Headers after the
+// transition must be PoA styled: a non-zero difficulty and no mix digest, which
+// PoS headers use for the beacon randomness. PoS headers within the grace
+// window are left to the full verification.
+func (h *Hybrid) CheckEra(header *types.Header) error {
+	number := header.Number.Uint64()
+	if number < h.transitionBlock.Load() || (h.straggler(header) && number-h.transitionBlock.Load() < h.graceWindow) {
+		return nil
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+		return &EraError{Number: number, Rule: "pos-difficulty"}
+	}
+	if header.MixDigest != (common.Hash{}) {
+		return &EraError{Number: number, Rule: "pos-mixdigest"}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCheckEra(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	tests := []struct {
+		header *types.Header
+		rule   string
+	}{
+		// PoS headers are left to the PoS engine before the transition
+		{&types.Header{Number: big.NewInt(99), Difficulty: common.Big0, MixDigest: common.Hash{0x01}}, ""},
+		{&types.Header{Number: big.NewInt(100), Difficulty: common.Big2}, ""},
+		{&types.Header{Number: big.NewInt(101), Difficulty: common.Big1}, ""},
+		{&types.Header{Number: big.NewInt(100), Difficulty: common.Big0}, "pos-difficulty"},
+		{&types.Header{Number: big.NewInt(101)}, "pos-difficulty"},
+		{&types.Header{Number: big.NewInt(101), Difficulty: common.Big2, MixDigest: common.Hash{0x01}}, "pos-mixdigest"},
+	}
+	for i, tt := range tests {
+		err := h.CheckEra(tt.header)
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("test %d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		var eraErr *EraError
+		if !errors.As(err, &eraErr) || !errors.Is(err, ErrEraViolation) {
+			t.Errorf("test %d: error mismatch: have %v, want era violation", i, err)
+			continue
+		}
+		if eraErr.Reason() != tt.rule {
+			t.Errorf("test %d: rule mismatch: have %s, want %s", i, eraErr.Reason(), tt.rule)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// EraStats summarizes a range of the canonical chain per consensus era, so
+// offline copies of the chain, e.g. exported after a failover, can be compared
+// against the chain they were taken from.
+type EraStats struct {
+	From            uint64                    // First block of the range
+	To              uint64                    // Last block of the range
+	PoSBlocks       uint64                    // Blocks of the range in the PoS era
+	PoABlocks       uint64                    // Blocks of the range in the PoA era
+	TransitionBlock uint64                    // Transition block of the chain (PendingTransition = unresolved)
+	Boundary        common.Hash               // Hash of the transition block (zero = outside the range)
+	Sealers         map[common.Address]uint64 // PoA blocks of the range per sealer
+}
+
+// EraStats walks the canonical chain from the given block to the given one,
+// counting the blocks of each era and the PoA blocks of every sealer.
+func (h *Hybrid) EraStats(chain consensus.ChainHeaderReader, from, to uint64) (*EraStats, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: from %d is after to %d", from, to)
+	}
+	stats := &EraStats{
+		From:            from,
+		To:              to,
+		TransitionBlock: h.transitionBlock.Load(),
+		Sealers:         make(map[common.Address]uint64),
+	}
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := from; number <= to; number++ {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("%w: #%d", errUnknownBlock, number)
+		}
+		annotation := h.annotate(header)
+		if annotation.Engine == "pos" {
+			stats.PoSBlocks++
+		} else {
+			stats.PoABlocks++
+		}
+		if annotation.Checkpoint {
+			stats.Boundary = annotation.Hash
+		}
+		if annotation.Sealer != nil {
+			stats.Sealers[*annotation.Sealer]++
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Collecting era statistics", "number", number, "remaining", to-number, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return stats, nil
+}
+
+// String renders the statistics for operators, listing the sealers by the
+// number of blocks sealed.
+func (s *EraStats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Consensus eras of blocks %d-%d:\n", s.From, s.To)
+	fmt.Fprintf(&b, "  PoS blocks:       %d\n", s.PoSBlocks)
+	fmt.Fprintf(&b, "  PoA blocks:       %d\n", s.PoABlocks)
+	switch {
+	case s.TransitionBlock == PendingTransition:
+		fmt.Fprintf(&b, "  Transition block: pending\n")
+	case s.Boundary == (common.Hash{}):
+		fmt.Fprintf(&b, "  Transition block: %d (outside the range)\n", s.TransitionBlock)
+	default:
+		fmt.Fprintf(&b, "  Transition block: %d, hash %v\n", s.TransitionBlock, s.Boundary)
+	}
+	sealers := make([]common.Address, 0, len(s.Sealers))
+	for sealer := range s.Sealers {
+		sealers = append(sealers, sealer)
+	}
+	slices.SortFunc(sealers, func(a, b common.Address) int {
+		if c := cmp.Compare(s.Sealers[b], s.Sealers[a]); c != 0 {
+			return c
+		}
+		return bytes.Compare(a[:], b[:])
+	})
+	fmt.Fprintf(&b, "  Sealers seen:     %d\n", len(sealers))
+	for _, sealer := range sealers {
+		fmt.Fprintf(&b, "    %v: %d blocks\n", sealer, s.Sealers[sealer])
+	}
+	return b.String()
+}
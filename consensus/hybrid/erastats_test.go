@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestEraStats(t *testing.T) {
+	var (
+		chain   = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+		signers = []common.Address{{0x1}, {0x2}}
+	)
+	for i := uint64(0); i < 20; i++ {
+		chain.headers[i] = &types.Header{Number: new(big.Int).SetUint64(i), Coinbase: signers[i%3%2]}
+	}
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	stats, err := h.EraStats(chain, 0, 19)
+	if err != nil {
+		t.Fatalf("Failed to collect era statistics: %v", err)
+	}
+	if stats.PoSBlocks != 10 || stats.PoABlocks != 10 {
+		t.Errorf("Era blocks mismatch: have %d/%d, want 10/10", stats.PoSBlocks, stats.PoABlocks)
+	}
+	if stats.Boundary != chain.headers[10].Hash() {
+		t.Errorf("Boundary mismatch: have %x, want %x", stats.Boundary, chain.headers[10].Hash())
+	}
+	// Blocks 10-19 are sealed by the second signer if their number modulo 3 is 1
+	if stats.Sealers[signers[0]] != 6 || stats.Sealers[signers[1]] != 4 || len(stats.Sealers) != 2 {
+		t.Errorf("Sealers mismatch: %v", stats.Sealers)
+	}
+	if out := stats.String(); !strings.Contains(out, "Transition block: 10, hash") || !strings.Contains(out, "Sealers seen:     2") {
+		t.Errorf("Unexpected rendering:\n%s", out)
+	}
+	// Ranges not containing the transition have no boundary
+	if stats, _ = h.EraStats(chain, 0, 9); stats.Boundary != (common.Hash{}) || stats.PoABlocks != 0 || len(stats.Sealers) != 0 {
+		t.Errorf("PoS range statistics mismatch: %+v", stats)
+	}
+	if _, err := h.EraStats(chain, 0, 20); !errors.Is(err, errUnknownBlock) {
+		t.Errorf("Error mismatch: have %v, want %v", err, errUnknownBlock)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultCliqueEpoch is the epoch length clique falls back to if none is
+// configured.
+const defaultCliqueEpoch = 30000
+
+// ErrorCode is the stable code of a class of transition failures. It is the
+// code of the JSON-RPC errors reporting such failures, and never changes once
+// assigned, so orchestration can react on it instead of the error message.
+type ErrorCode int
+
+// Classes of transition failures.
+const (
+	CodeInvalidCheckpoint  ErrorCode = -39001 // Transition checkpoint contradicting the configured transition
+	CodeUnauthorizedSealer ErrorCode = -39002 // PoA block of the first epoch sealed by an unauthorized signer
+	CodeLatePoSBlock       ErrorCode = -39003 // PoS block past the grace window
+	CodeConfigDrift        ErrorCode = -39004 // Consensus-critical settings changed since the previous run
+)
+
+// String returns the reason reported along with the code.
+func (c ErrorCode) String() string {
+	switch c {
+	case CodeInvalidCheckpoint:
+		return "invalidCheckpoint"
+	case CodeUnauthorizedSealer:
+		return "unauthorizedSealer"
+	case CodeLatePoSBlock:
+		return "latePoSBlock"
+	case CodeConfigDrift:
+		return "configDrift"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(c))
+	}
+}
+
+// ErrorData is the data of JSON-RPC errors reporting transition failures.
+type ErrorData struct {
+	Code   ErrorCode `json:"code"`
+	Reason string    `json:"reason"`
+}
+
+// TransitionError is a transition failure tagged with the code of its class.
+// It implements rpc.Error and rpc.DataError, and unwraps to the failure, so
+// errors.Is keeps matching the sentinel errors.
+type TransitionError struct {
+	Code ErrorCode
+	Err  error
+}
+
+// Error implements error, returning the message of the failure.
+func (e *TransitionError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the failure.
+func (e *TransitionError) Unwrap() error { return e.Err }
+
+// ErrorCode implements rpc.Error, returning the code of the failure class.
+func (e *TransitionError) ErrorCode() int { return int(e.Code) }
+
+// ErrorData implements rpc.DataError, returning the code and the reason.
+func (e *TransitionError) ErrorData() interface{} {
+	return &ErrorData{Code: e.Code, Reason: e.Code.String()}
+}
+
+// CodeOf returns the code of the transition failure the error reports, if any.
+func CodeOf(err error) (ErrorCode, bool) {
+	var coded *TransitionError
+	if errors.As(err, &coded) {
+		return coded.Code, true
+	}
+	return 0, false
+}
+
+// failureOf returns the error data of the transition failure the error reports,
+// or nil if it reports none.
+func failureOf(err error) *ErrorData {
+	code, ok := CodeOf(err)
+	if !ok {
+		return nil
+	}
+	return &ErrorData{Code: code, Reason: code.String()}
+}
+
+// classifyError tags a verification failure of the given header reported by
+// the clique engine with the code of its class. Only failures around the
+// transition are classified: an invalid signer list on the transition block
+// and an unauthorized sealer within the first epoch past it, the one sealed by
+// the initial signers.
+func (h *Hybrid) classifyError(chain consensus.ChainHeaderReader, header *types.Header, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := CodeOf(err); ok {
+		return err
+	}
+	number := header.Number.Uint64()
+	switch {
+	case errors.Is(err, clique.ErrInvalidCheckpointSigners) && number == h.transitionBlock.Load():
+		return &TransitionError{Code: CodeInvalidCheckpoint, Err: err}
+	case (errors.Is(err, clique.ErrUnauthorizedSigner) || errors.Is(err, clique.ErrRecentlySigned)) && h.firstEpoch(chain, number):
+		return &TransitionError{Code: CodeUnauthorizedSealer, Err: err}
+	}
+	return err
+}
+
+// firstEpoch reports whether the block with the given number is past the
+// transition but not past the first epoch checkpoint following it.
+func (h *Hybrid) firstEpoch(chain consensus.ChainHeaderReader, number uint64) bool {
+	transition := h.transitionBlock.Load()
+	if number < transition {
+		return false
+	}
+	epoch := uint64(defaultCliqueEpoch)
+	if config := chain.Config().Clique; config != nil && config.Epoch != 0 {
+		epoch = config.Epoch
+	}
+	return number <= (transition/epoch+1)*epoch
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestClassifyError(t *testing.T) {
+	var (
+		config = *params.TestChainConfig
+		chain  = &configChainReader{config: &config, headers: make(map[uint64]*types.Header)}
+		other  = errors.New("other failure")
+	)
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30}
+
+	tests := []struct {
+		number uint64
+		err    error
+		code   ErrorCode // 0 if unclassified
+	}{
+		{100, clique.ErrInvalidCheckpointSigners, CodeInvalidCheckpoint},
+		{120, clique.ErrInvalidCheckpointSigners, 0},
+		{100, clique.ErrUnauthorizedSigner, CodeUnauthorizedSealer},
+		{115, clique.ErrRecentlySigned, CodeUnauthorizedSealer},
+		{120, clique.ErrUnauthorizedSigner, CodeUnauthorizedSealer},
+		{121, clique.ErrUnauthorizedSigner, 0},
+		{110, other, 0},
+		{110, nil, 0},
+	}
+	for i, test := range tests {
+		poaEngine := newTrackingMockEngine("poa")
+		poaEngine.setError("VerifyHeader", test.err)
+
+		h, err := New(newTrackingMockEngine("pos"), poaEngine, 100)
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		header := &types.Header{Number: new(big.Int).SetUint64(test.number), Difficulty: big.NewInt(2)}
+		err = h.VerifyHeader(chain, header)
+		h.Close()
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("Test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+		code, ok := CodeOf(err)
+		if ok != (test.code != 0) || code != test.code {
+			t.Errorf("Test %d: code mismatch: have %d (%v), want %d", i, code, ok, test.code)
+		}
+	}
+}
+
+// failingService is an RPC service failing with a transition failure.
+type failingService struct{}
+
+func (failingService) Fail() error {
+	return &TransitionError{Code: CodeLatePoSBlock, Err: fmt.Errorf("%w: block 13", ErrLatePoSBlock)}
+}
+
+func TestTransitionErrorRPC(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", failingService{}); err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_fail")
+	if err == nil {
+		t.Fatal("Call succeeded")
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != int(CodeLatePoSBlock) {
+		t.Fatalf("Error code mismatch: have %v, want %d", err, CodeLatePoSBlock)
+	}
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("Error without data: %v", err)
+	}
+	want := map[string]interface{}{"code": float64(CodeLatePoSBlock), "reason": "latePoSBlock"}
+	if data := dataErr.ErrorData(); !reflect.DeepEqual(data, want) {
+		t.Errorf("Error data mismatch: have %v, want %v", data, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrVanityTooLong is returned if an extraData builder contributes more than the
+// vanity of the transition block can hold.
+var ErrVanityTooLong = errors.New("transition block vanity too long")
+
+// ExtraBuilder contributes to the extraData of the transition block, e.g. by
+// embedding the hash of a governance proposal. It is passed the header being
+// prepared, which it must not modify, and the vanity set so far, and returns the
+// vanity to seal the block with, zero padded to 32 bytes if shorter. The signer
+// list and the seal following the vanity are laid out by the engine alone.
+//
+// The builder is called every time the transition block is prepared, so it must
+// return the same vanity for the same header to keep rebuilt work stable.
+type ExtraBuilder func(header *types.Header, vanity []byte) ([]byte, error)
+
+// buildTransitionExtra returns the extraData of the transition block, with the
+// vanity of the current extraData as amended by the configured builder.
+func (h *Hybrid) buildTransitionExtra(header *types.Header) ([]byte, error) {
+	extra := transitionExtra(header.Extra, h.checkpoint)
+	if h.extraBuilder == nil {
+		return extra, nil
+	}
+	vanity, err := h.extraBuilder(header, bytes.Clone(extra[:extraVanity]))
+	if err != nil {
+		return nil, fmt.Errorf("transition extra-data builder failed: %w", err)
+	}
+	if len(vanity) > extraVanity {
+		return nil, fmt.Errorf("%w: %d bytes, max %d", ErrVanityTooLong, len(vanity), extraVanity)
+	}
+	padded := make([]byte, extraVanity)
+	copy(padded, vanity)
+	if bytes.Equal(padded, extra[:extraVanity]) {
+		return extra, nil
+	}
+	// The extraData may still be the one of the header, shared with the miner
+	// configuration, build the amended one in a fresh buffer
+	want := bytes.Clone(h.checkpoint)
+	copy(want, padded)
+	return want, nil
+}
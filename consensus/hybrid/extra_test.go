@@ -0,0 +1,134 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that an extraData builder sets the vanity of the transition block while
+// the signer list and seal stay laid out by the engine.
+func TestTransitionExtraBuilder(t *testing.T) {
+	var (
+		proposal   = common.HexToHash("0x5eed")
+		minerExtra = bytes.Repeat([]byte{0x42}, 8)
+		errBuilder = errors.New("proposal unavailable")
+	)
+	tests := []struct {
+		name    string
+		builder ExtraBuilder
+		vanity  []byte // Expected vanity of the transition block
+		err     error
+	}{
+		{
+			name: "proposal",
+			builder: func(header *types.Header, vanity []byte) ([]byte, error) {
+				return proposal[:], nil
+			},
+			vanity: proposal[:],
+		},
+		{
+			name: "amended",
+			builder: func(header *types.Header, vanity []byte) ([]byte, error) {
+				copy(vanity[len(minerExtra):], "gov")
+				return vanity, nil
+			},
+			vanity: append(append(bytes.Clone(minerExtra), "gov"...), make([]byte, extraVanity-len(minerExtra)-3)...),
+		},
+		{
+			name: "padded",
+			builder: func(header *types.Header, vanity []byte) ([]byte, error) {
+				return []byte{0x01}, nil
+			},
+			vanity: append([]byte{0x01}, make([]byte, extraVanity-1)...),
+		},
+		{
+			name: "too long",
+			builder: func(header *types.Header, vanity []byte) ([]byte, error) {
+				return make([]byte, extraVanity+1), nil
+			},
+			err: ErrVanityTooLong,
+		},
+		{
+			name: "failing",
+			builder: func(header *types.Header, vanity []byte) ([]byte, error) {
+				return nil, errBuilder
+			},
+			err: errBuilder,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithTransitionExtra(test.builder))
+			if err != nil {
+				t.Fatalf("Failed to create hybrid engine: %v", err)
+			}
+			shared := bytes.Clone(minerExtra)
+			header := &types.Header{Number: big.NewInt(100), Extra: shared}
+
+			err = h.Prepare(&mockChainReader{}, header)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Error mismatch: have %v, want %v", err, test.err)
+			}
+			if !bytes.Equal(shared, minerExtra) {
+				t.Errorf("Miner extra-data modified: have %x, want %x", shared, minerExtra)
+			}
+			if test.err != nil {
+				return
+			}
+			want := CheckpointExtra(defaultInitialSigners)
+			copy(want, test.vanity)
+			if !bytes.Equal(header.Extra, want) {
+				t.Fatalf("Extra-data mismatch: have %x, want %x", header.Extra, want)
+			}
+			// Re-preparing the block leaves the built extra-data untouched
+			first := &header.Extra[0]
+			if err := h.Prepare(&mockChainReader{}, header); err != nil {
+				t.Fatalf("Failed to prepare transition block again: %v", err)
+			}
+			if !bytes.Equal(header.Extra, want) || &header.Extra[0] != first {
+				t.Errorf("Re-preparing rebuilt the extra-data: have %x, want %x", header.Extra, want)
+			}
+		})
+	}
+}
+
+// Tests that the builder is only consulted for the transition block.
+func TestTransitionExtraBuilderScope(t *testing.T) {
+	var calls int
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithTransitionExtra(func(header *types.Header, vanity []byte) ([]byte, error) {
+		calls++
+		return vanity, nil
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	for _, number := range []int64{99, 100, 101} {
+		if err := h.Prepare(&mockChainReader{}, &types.Header{Number: big.NewInt(number)}); err != nil {
+			t.Fatalf("Failed to prepare block %d: %v", number, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Builder calls mismatch: have %d, want 1", calls)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build hybridfault
+
+package hybrid
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Fault describes a misbehaviour injected into a delegated consensus call at a
+// specific block height, used by chaos tests to simulate a failing engine.
+type Fault struct {
+	Method string        // Delegated method to disturb, e.g. "VerifyHeader"
+	Number uint64        // Block number at which the fault triggers
+	Delay  time.Duration // Time to stall before proceeding (or failing)
+	Err    error         // Error returned instead of delegating, if set
+	Panic  bool          // Whether to panic instead of delegating
+}
+
+type faultKey struct {
+	method string
+	number uint64
+}
+
+var (
+	faults     = make(map[faultKey]Fault)
+	faultsLock sync.RWMutex
+)
+
+// InjectFault registers a fault, replacing any previous fault registered for
+// the same method and height.
+func InjectFault(fault Fault) {
+	faultsLock.Lock()
+	defer faultsLock.Unlock()
+
+	faults[faultKey{fault.Method, fault.Number}] = fault
+	log.Warn("Injected hybrid consensus fault", "method", fault.Method, "number", fault.Number,
+		"delay", fault.Delay, "err", fault.Err, "panic", fault.Panic)
+}
+
+// ClearFaults removes all registered faults.
+func ClearFaults() {
+	faultsLock.Lock()
+	defer faultsLock.Unlock()
+
+	clear(faults)
+}
+
+// injectFault triggers the fault registered for the delegated call, if any.
+func injectFault(method string, number uint64) error {
+	faultsLock.RLock()
+	fault, ok := faults[faultKey{method, number}]
+	faultsLock.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+	if fault.Panic {
+		panic(fmt.Sprintf("injected hybrid consensus fault: %s at block %d", method, number))
+	}
+	return fault.Err
+}
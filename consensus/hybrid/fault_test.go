@@ -0,0 +1,73 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build hybridfault
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestFaultInjection(t *testing.T) {
+	defer ClearFaults()
+
+	posEngine := newTrackingMockEngine("pos")
+	poaEngine := newTrackingMockEngine("poa")
+	h, err := New(posEngine, poaEngine, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := &mockChainReader{}
+	header := func(n int64) *types.Header { return &types.Header{Number: big.NewInt(n)} }
+
+	// Errors replace the delegated call at the configured height only
+	errInjected := errors.New("injected")
+	InjectFault(Fault{Method: "VerifyHeader", Number: 100, Err: errInjected})
+	if err := h.VerifyHeader(chain, header(100)); !errors.Is(err, errInjected) {
+		t.Fatalf("Expected injected error, got %v", err)
+	}
+	if calls := poaEngine.getCallCount("VerifyHeader"); calls != 0 {
+		t.Fatalf("Faulted call still reached the PoA engine %d times", calls)
+	}
+	if err := h.VerifyHeader(chain, header(101)); err != nil {
+		t.Fatalf("Fault leaked to another height: %v", err)
+	}
+	// Delays stall the call before delegating
+	InjectFault(Fault{Method: "Prepare", Number: 99, Delay: 50 * time.Millisecond})
+	start := time.Now()
+	if err := h.Prepare(chain, header(99)); err != nil {
+		t.Fatalf("Delayed call failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected delayed call, returned after %v", elapsed)
+	}
+	// Panics are isolated, failing the call
+	InjectFault(Fault{Method: "Author", Number: 100, Panic: true})
+	if _, err := h.Author(header(100)); !errors.Is(err, ErrEnginePanic) {
+		t.Errorf("Expected isolated panic, got %v", err)
+	}
+	// Cleared faults no longer trigger
+	ClearFaults()
+	if err := h.VerifyHeader(chain, header(100)); err != nil {
+		t.Fatalf("Cleared fault still triggered: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxTransitionHeaders is the number of distinct sealed headers at the transition
+// height remembered to detect competing transition blocks.
+const maxTransitionHeaders = 16
+
+// forkEvidenceKey is the database key the fork evidence is persisted under.
+var forkEvidenceKey = []byte("hybrid-fork-evidence")
+
+var forkDetectedCounter = metrics.NewRegisteredCounter("hybrid/fork/detected", nil)
+
+// ForkEvidence proves that two different sealed blocks exist at the transition
+// height, i.e. that the network disagrees on how the PoS chain was handed over
+// to the PoA signers.
+type ForkEvidence struct {
+	Number        uint64           `json:"number"`
+	First         *types.Header    `json:"first"`         // Transition block seen first
+	Second        *types.Header    `json:"second"`        // Competing transition block seen later
+	FirstSigners  []common.Address `json:"firstSigners"`  // Signers listed by the first block
+	SecondSigners []common.Address `json:"secondSigners"` // Signers listed by the competing block
+	SignersDiffer bool             `json:"signersDiffer"` // Whether the competing blocks hand over to different signers
+}
+
+// forkWatcher remembers the sealed headers seen at the transition height and the
+// evidence of competing ones.
+type forkWatcher struct {
+	seen     []*types.Header // Distinct headers at the transition height, in order of arrival
+	evidence []*ForkEvidence
+	lock     sync.Mutex
+}
+
+// restore loads the evidence persisted by a previous run, remembering the
+// competing headers in it.
+func (w *forkWatcher) restore(db ethdb.KeyValueStore) {
+	if db == nil {
+		return
+	}
+	blob, err := db.Get(forkEvidenceKey)
+	if err != nil {
+		return // Nothing persisted yet
+	}
+	var evidence []*ForkEvidence
+	if err := json.Unmarshal(blob, &evidence); err != nil {
+		log.Error("Failed to decode persisted fork evidence", "err", err)
+		return
+	}
+	for _, e := range evidence {
+		w.remember(e.First)
+		w.remember(e.Second)
+	}
+	w.evidence = evidence
+	if len(evidence) > 0 {
+		log.Error("Restored evidence of competing transition blocks", "count", len(evidence))
+	}
+}
+
+// remember adds the header to the seen ones, reporting whether it was new. The
+// lock must be held.
+func (w *forkWatcher) remember(header *types.Header) bool {
+	hash := header.Hash()
+	for _, seen := range w.seen {
+		if seen.Hash() == hash {
+			return false
+		}
+	}
+	if len(w.seen) >= maxTransitionHeaders {
+		return false
+	}
+	w.seen = append(w.seen, header)
+	return true
+}
+
+// watchTransition checks a header at the transition height against the ones
+// seen before, recording evidence and raising an alert for competing transition
+// blocks. Headers at other heights and unsealed ones are ignored.
+func (h *Hybrid) watchTransition(chain consensus.ChainHeaderReader, header *types.Header) {
+	if header.Number.Uint64() != h.transitionBlock.Load() {
+		return
+	}
+	w := &h.forks
+	w.lock.Lock()
+
+	// Headers are verified repeatedly during import, skip rehashing them
+	if slices.Contains(w.seen, header) {
+		w.lock.Unlock()
+		return
+	}
+	if _, err := h.poaEngine.Author(header); err != nil {
+		w.lock.Unlock()
+		return // Unsealed or malformed, verification rejects it anyway
+	}
+
+	// After a restart, the transition block processed earlier was seen first
+	if len(w.seen) == 0 {
+		if executed := h.runtime.get().Executed; executed != (common.Hash{}) && executed != header.Hash() {
+			if first := chain.GetHeaderByHash(executed); first != nil && first.Hash() == executed {
+				w.remember(first)
+			}
+		}
+	}
+	if len(w.seen) == 0 {
+		w.remember(header)
+		w.lock.Unlock()
+		return
+	}
+	first := w.seen[0]
+	if !w.remember(header) {
+		w.lock.Unlock()
+		return
+	}
+	firstSigners, _ := checkpointSigners(first.Extra)
+	secondSigners, _ := checkpointSigners(header.Extra)
+	evidence := &ForkEvidence{
+		Number:        h.transitionBlock.Load(),
+		First:         first,
+		Second:        header,
+		FirstSigners:  firstSigners,
+		SecondSigners: secondSigners,
+		SignersDiffer: !slices.Equal(firstSigners, secondSigners),
+	}
+	w.evidence = append(w.evidence, evidence)
+	if err := h.storeForkEvidence(); err != nil {
+		log.Error("Failed to persist fork evidence", "err", err)
+	}
+	w.lock.Unlock()
+	forkDetectedCounter.Inc(1)
+
+	message := fmt.Sprintf("competing transition blocks %x and %x", first.Hash(), header.Hash())
+	if evidence.SignersDiffer {
+		message += fmt.Sprintf(", handing over to signers %v and %v", firstSigners, secondSigners)
+	}
+	h.raiseAlert(Alert{Kind: AlertCompetingTransition, Number: h.transitionBlock.Load(), Hash: header.Hash(), Message: message})
+}
+
+// storeForkEvidence persists the evidence. The lock must be held.
+func (h *Hybrid) storeForkEvidence() error {
+	if h.db == nil {
+		return nil
+	}
+	blob, err := json.Marshal(h.forks.evidence)
+	if err != nil {
+		return err
+	}
+	return h.db.Put(forkEvidenceKey, blob)
+}
+
+// ForkEvidence returns the evidence of competing transition blocks observed by
+// the engine.
+func (h *Hybrid) ForkEvidence() []*ForkEvidence {
+	h.forks.lock.Lock()
+	defer h.forks.lock.Unlock()
+
+	return slices.Clone(h.forks.evidence)
+}
+
+// ForkEvidence returns the evidence of competing blocks at the transition height
+// observed by the node, including blocks handing over to different signer sets.
+func (api *API) ForkEvidence() []*ForkEvidence {
+	return api.hybrid.ForkEvidence()
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that competing blocks at the transition height are detected, alerted on
+// and remembered across restarts.
+func TestCompetingTransitionBlocks(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		chain = &mockChainReader{}
+	)
+	newHybrid := func() *Hybrid {
+		h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 10, WithDatabase(db))
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		return h
+	}
+	h := newHybrid()
+	alerts := make(chan Alert, 4)
+	sub := h.SubscribeAlerts(alerts)
+	defer sub.Unsubscribe()
+
+	var (
+		first    = &types.Header{Number: big.NewInt(10), Difficulty: common.Big2, Extra: CheckpointExtra(defaultInitialSigners)}
+		same     = &types.Header{Number: big.NewInt(10), Difficulty: common.Big2, Extra: CheckpointExtra(defaultInitialSigners), Time: 1}
+		hijacked = &types.Header{Number: big.NewInt(10), Difficulty: common.Big2, Extra: CheckpointExtra([]common.Address{{0xee}})}
+	)
+	for _, header := range []*types.Header{first, first, {Number: big.NewInt(11)}} {
+		if err := h.VerifyHeader(chain, header); err != nil {
+			t.Fatalf("Failed to verify header: %v", err)
+		}
+	}
+	if evidence := h.ForkEvidence(); len(evidence) != 0 {
+		t.Fatalf("Evidence without competing blocks: %v", evidence)
+	}
+	// Competing blocks are reported, whether or not they list the same signers
+	h.VerifyHeader(chain, same)
+	_, results := h.VerifyHeaders(chain, []*types.Header{hijacked, {Number: big.NewInt(11)}})
+	for range 2 {
+		<-results
+	}
+	evidence := h.ForkEvidence()
+	if len(evidence) != 2 {
+		t.Fatalf("Evidence count mismatch: have %d, want 2", len(evidence))
+	}
+	if evidence[0].First.Hash() != first.Hash() || evidence[0].Second.Hash() != same.Hash() || evidence[0].SignersDiffer {
+		t.Errorf("Evidence of competing blocks mismatch: %+v", evidence[0])
+	}
+	if evidence[1].Second.Hash() != hijacked.Hash() || !evidence[1].SignersDiffer || evidence[1].SecondSigners[0] != (common.Address{0xee}) {
+		t.Errorf("Evidence of competing signer sets mismatch: %+v", evidence[1])
+	}
+	for i := 0; i < 2; i++ {
+		if alert := <-alerts; alert.Kind != AlertCompetingTransition {
+			t.Errorf("Alert %d kind mismatch: have %s, want %s", i, alert.Kind, AlertCompetingTransition)
+		}
+	}
+	// The evidence survives a restart, without being reported again
+	h = newHybrid()
+	api := &API{chain: chain, hybrid: h}
+	h.VerifyHeader(chain, hijacked)
+	if evidence := api.ForkEvidence(); len(evidence) != 2 {
+		t.Fatalf("Restored evidence count mismatch: have %d, want 2", len(evidence))
+	}
+}
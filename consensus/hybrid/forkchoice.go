@@ -0,0 +1,63 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ErrStalePoSBranch is returned for PoS branches competing with a PoA chain
+// whose transition block is already final.
+var ErrStalePoSBranch = errors.New("PoS branch competing with final PoA chain")
+
+var (
+	forkChoiceDeprioritizedCounter = metrics.NewRegisteredCounter("hybrid/forkchoice/deprioritized", nil)
+	forkChoiceRejectedCounter      = metrics.NewRegisteredCounter("hybrid/forkchoice/rejected", nil)
+)
+
+// ReorgNeeded implements consensus.ForkChooser. Once the canonical chain passed
+// the transition, a branch ending in a PoS styled block never replaces it, so a
+// briefly resurrected beacon chain cannot reorg the PoA network. Such branches
+// are kept as side chains while the transition block may still be reorged, and
+// rejected outright once it is final.
+func (h *Hybrid) ReorgNeeded(current, extern *types.Header) (bool, error) {
+	head := current.Number.Uint64()
+	if head < h.transitionBlock.Load() || !h.posStyled(extern) {
+		return true, nil
+	}
+	number := extern.Number.Uint64()
+	if h.TransitionFinal(head) {
+		forkChoiceRejectedCounter.Inc(1)
+		return false, fmt.Errorf("%w: block %d [%x] against PoA head %d, transition at block %d is %d blocks deep",
+			ErrStalePoSBranch, number, extern.Hash().Bytes()[:4], head, h.transitionBlock.Load(), head-h.transitionBlock.Load())
+	}
+	forkChoiceDeprioritizedCounter.Inc(1)
+	log.Warn("Keeping PoS branch as side chain of the PoA chain", "number", number, "hash", extern.Hash(),
+		"head", head, "transitionBlock", h.transitionBlock.Load())
+	return false, nil
+}
+
+// posStyled reports whether the header is a PoS era block, or styled after one
+// past the transition by its zero difficulty.
+func (h *Hybrid) posStyled(header *types.Header) bool {
+	return header.Number.Uint64() < h.transitionBlock.Load() || (header.Difficulty != nil && header.Difficulty.Sign() == 0)
+}
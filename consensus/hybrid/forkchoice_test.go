@@ -0,0 +1,55 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestReorgNeeded(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 10, WithConfirmationDepth(5))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	header := func(number, difficulty int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(difficulty)}
+	}
+	tests := []struct {
+		current, extern *types.Header
+		reorg           bool
+		err             error
+	}{
+		{header(8, 0), header(9, 0), true, nil},                  // PoS reorgs before the transition
+		{header(9, 0), header(10, 2), true, nil},                 // Transition block
+		{header(12, 2), header(11, 1), true, nil},                // PoA reorgs
+		{header(12, 2), header(9, 0), false, nil},                // PoS branch deprioritized
+		{header(12, 2), header(13, 0), false, nil},               // PoS styled branch past the transition
+		{header(15, 2), header(9, 0), false, ErrStalePoSBranch},  // PoS branch rejected once final
+		{header(15, 2), header(16, 0), false, ErrStalePoSBranch}, // PoS styled branch rejected once final
+		{header(15, 2), header(14, 1), true, nil},                // PoA reorgs once final
+	}
+	for i, tt := range tests {
+		reorg, err := h.ReorgNeeded(tt.current, tt.extern)
+		if reorg != tt.reorg || !errors.Is(err, tt.err) {
+			t.Errorf("test %d: have %v, %v, want %v, %v", i, reorg, err, tt.reorg, tt.err)
+		}
+	}
+}
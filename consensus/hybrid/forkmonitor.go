@@ -0,0 +1,176 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxForkBlocks is the number of blocks around the transition the fork monitor
+// tracks at most, bounding its memory if peers flood it with branches.
+const maxForkBlocks = 4096
+
+var forkObservedCounter = metrics.NewRegisteredCounter("hybrid/forkmonitor/observed", nil)
+
+// Branch describes a chain of blocks around the transition, from its tip back
+// to where it leaves the monitored window.
+type Branch struct {
+	Tip        common.Hash      `json:"tip"`
+	Number     uint64           `json:"number"`     // Number of the tip
+	Length     uint64           `json:"length"`     // Number of blocks of the branch within the window
+	ForkNumber uint64           `json:"forkNumber"` // Number of the last block shared with another branch (0 = none)
+	Sealers    []common.Address `json:"sealers"`    // Sealers of the blocks not shared with other branches
+	Canonical  bool             `json:"canonical"`  // Whether the tip is part of the canonical chain
+}
+
+// ForkReport lists the branches observed around the transition.
+type ForkReport struct {
+	Window   uint64   `json:"window"`   // Number of blocks on either side of the transition monitored
+	Observed uint64   `json:"observed"` // Number of distinct blocks observed within the window
+	Branches []Branch `json:"branches"` // Observed branches, longest first
+}
+
+// forkBlock is a block observed by the fork monitor.
+type forkBlock struct {
+	number uint64
+	parent common.Hash
+	sealer common.Address
+}
+
+// forkMonitor tracks all blocks seen within a window around the transition, from
+// both sync and locally produced blocks, to reveal competing branches.
+type forkMonitor struct {
+	window uint64
+	blocks map[common.Hash]*forkBlock
+	full   bool // Whether the block limit was hit, logged once
+	lock   sync.Mutex
+}
+
+func newForkMonitor(window uint64) *forkMonitor {
+	return &forkMonitor{window: window, blocks: make(map[common.Hash]*forkBlock)}
+}
+
+// covers returns whether any number in [first, last] falls into the window.
+func (m *forkMonitor) covers(first, last, transition uint64) bool {
+	return m != nil && last+m.window >= transition && first <= transition+m.window
+}
+
+// monitorForks records the headers within the fork monitor window. The headers
+// must be ordered by number.
+func (h *Hybrid) monitorForks(headers ...*types.Header) {
+	m := h.forkMonitor
+	if len(headers) == 0 || !m.covers(headers[0].Number.Uint64(), headers[len(headers)-1].Number.Uint64(), h.transitionBlock.Load()) {
+		return
+	}
+	for _, header := range headers {
+		number := header.Number.Uint64()
+		if !m.covers(number, number, h.transitionBlock.Load()) {
+			continue
+		}
+		hash := header.Hash()
+
+		m.lock.Lock()
+		_, known := m.blocks[hash]
+		m.lock.Unlock()
+		if known {
+			continue
+		}
+		engine := h.posEngine
+		if number >= h.transitionBlock.Load() {
+			engine = h.poaEngine
+		}
+		sealer, _ := engine.Author(header)
+
+		m.lock.Lock()
+		if len(m.blocks) < maxForkBlocks {
+			m.blocks[hash] = &forkBlock{number: number, parent: header.ParentHash, sealer: sealer}
+			forkObservedCounter.Inc(1)
+		} else if !m.full {
+			m.full = true
+			log.Warn("Fork monitor full, ignoring further blocks around the transition", "limit", maxForkBlocks)
+		}
+		m.lock.Unlock()
+	}
+}
+
+// report assembles the branches observed so far. Tips are the blocks no other
+// observed block builds on.
+func (m *forkMonitor) report(chain consensus.ChainHeaderReader) *ForkReport {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	children := make(map[common.Hash]int, len(m.blocks))
+	for _, block := range m.blocks {
+		children[block.parent]++
+	}
+	report := &ForkReport{Window: m.window, Observed: uint64(len(m.blocks)), Branches: []Branch{}}
+	for hash, block := range m.blocks {
+		if children[hash] > 0 {
+			continue
+		}
+		branch := Branch{Tip: hash, Number: block.number, Sealers: []common.Address{}}
+		exclusive := true
+		for cur := block; cur != nil; cur = m.blocks[cur.parent] {
+			branch.Length++
+			if !exclusive {
+				continue
+			}
+			if !slices.Contains(branch.Sealers, cur.sealer) {
+				branch.Sealers = append(branch.Sealers, cur.sealer)
+			}
+			if children[cur.parent] > 1 {
+				exclusive, branch.ForkNumber = false, cur.number-1
+			}
+		}
+		if chain != nil {
+			if canonical := chain.GetHeaderByNumber(block.number); canonical != nil {
+				branch.Canonical = canonical.Hash() == hash
+			}
+		}
+		report.Branches = append(report.Branches, branch)
+	}
+	sort.Slice(report.Branches, func(i, j int) bool {
+		if report.Branches[i].Length != report.Branches[j].Length {
+			return report.Branches[i].Length > report.Branches[j].Length
+		}
+		return report.Branches[i].Tip.Cmp(report.Branches[j].Tip) < 0
+	})
+	return report
+}
+
+// ForkReport returns the branches observed around the transition, or nil if
+// the fork monitor is disabled.
+func (h *Hybrid) ForkReport(chain consensus.ChainHeaderReader) *ForkReport {
+	if h.forkMonitor == nil {
+		return nil
+	}
+	return h.forkMonitor.report(chain)
+}
+
+// Forks returns the branches observed within the fork monitor window around the
+// transition, with their tips, lengths and sealers, or nil if it is disabled.
+func (api *API) Forks() *ForkReport {
+	return api.hybrid.ForkReport(api.chain)
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestForkMonitor(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10, WithForkMonitorWindow(3))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := &mockChainReader{}
+
+	// Build a shared PoS segment, forked into two PoA branches sealed by
+	// different signers
+	extend := func(parent *types.Header, n int, sealer common.Address) []*types.Header {
+		var headers []*types.Header
+		for i := 0; i < n; i++ {
+			header := &types.Header{Number: new(big.Int).Add(parent.Number, common.Big1), ParentHash: parent.Hash(), Coinbase: sealer}
+			headers = append(headers, header)
+			parent = header
+		}
+		return headers
+	}
+	var (
+		signerA = common.Address{0xaa}
+		signerB = common.Address{0xbb}
+		shared  = extend(&types.Header{Number: big.NewInt(4)}, 5, common.Address{}) // 5..9
+		branchA = extend(shared[4], 2, signerA)                                     // 10..11
+		branchB = extend(shared[4], 5, signerB)                                     // 10..14
+	)
+	_, results := h.VerifyHeaders(chain, shared)
+	for range shared {
+		<-results
+	}
+	for _, header := range branchA {
+		h.VerifyHeader(chain, header)
+	}
+	_, results = h.VerifyHeaders(chain, branchB)
+	for range branchB {
+		<-results
+	}
+	report := (&API{chain: chain, hybrid: h}).Forks()
+	if report == nil {
+		t.Fatal("Missing fork report")
+	}
+	// Blocks 5, 6 and 14 are outside the window
+	if report.Observed != 9 {
+		t.Errorf("Observed block count mismatch: have %d, want 9", report.Observed)
+	}
+	if len(report.Branches) != 2 {
+		t.Fatalf("Branch count mismatch: have %d, want 2", len(report.Branches))
+	}
+	want := []Branch{
+		{Tip: branchB[3].Hash(), Number: 13, Length: 7, ForkNumber: 9, Sealers: []common.Address{signerB}},
+		{Tip: branchA[1].Hash(), Number: 11, Length: 5, ForkNumber: 9, Sealers: []common.Address{signerA}},
+	}
+	for i, branch := range report.Branches {
+		if branch.Tip != want[i].Tip || branch.Number != want[i].Number || branch.Length != want[i].Length ||
+			branch.ForkNumber != want[i].ForkNumber || len(branch.Sealers) != 1 || branch.Sealers[0] != want[i].Sealers[0] {
+			t.Errorf("Branch %d mismatch: have %+v, want %+v", i, branch, want[i])
+		}
+	}
+	// Without the monitor enabled there is no report
+	h, _ = New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10)
+	if report := h.ForkReport(chain); report != nil {
+		t.Errorf("Unexpected report from disabled monitor: %+v", report)
+	}
+}
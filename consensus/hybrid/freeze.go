@@ -0,0 +1,59 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultFreezeWindow is the default number of blocks before the transition
+// which are kept out of the ancient store until the transition is final.
+const DefaultFreezeWindow = 1024
+
+// holdFreezer keeps the blocks from the freeze window before the transition on
+// out of the ancient store, so reorgs across the boundary never have to touch
+// frozen blocks. The hold is lifted by releaseFreezer once the transition is
+// final. A disabled window or a transition which never becomes final lifts a
+// hold left by a previous run instead.
+func (h *Hybrid) holdFreezer() {
+	if h.db == nil || h.readOnly || h.TransitionPending() {
+		return
+	}
+	if h.freezeWindow == 0 || h.confirmDepth == 0 {
+		if rawdb.ReadFreezeHold(h.db) != nil {
+			rawdb.DeleteFreezeHold(h.db)
+		}
+		return
+	}
+	transition := h.transitionBlock.Load()
+	hold := transition - min(transition, h.freezeWindow)
+	rawdb.WriteFreezeHold(h.db, hold)
+	log.Debug("Holding back transition blocks from the freezer", "transitionBlock", transition, "from", hold)
+}
+
+// releaseFreezer lifts the freeze hold, once per run. The transition must be
+// final.
+func (h *Hybrid) releaseFreezer() {
+	if h.db == nil || h.readOnly || !h.freezerReleased.CompareAndSwap(false, true) {
+		return
+	}
+	if hold := rawdb.ReadFreezeHold(h.db); hold != nil {
+		rawdb.DeleteFreezeHold(h.db)
+		log.Info("Released transition blocks to the freezer", "transitionBlock", h.transitionBlock.Load(), "from", *hold)
+	}
+}
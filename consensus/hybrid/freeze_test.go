@@ -0,0 +1,73 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestFreezeHold(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 2000,
+		WithDatabase(db), WithFreezeWindow(100), WithConfirmationDepth(10))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if hold := rawdb.ReadFreezeHold(db); hold == nil || *hold != 1900 {
+		t.Fatalf("Freeze hold mismatch: have %v, want %d", hold, 1900)
+	}
+	// The hold is kept until the transition is final
+	h.retirePoS(2009)
+	if rawdb.ReadFreezeHold(db) == nil {
+		t.Fatal("Freeze hold lifted before the transition is final")
+	}
+	h.retirePoS(2010)
+	if hold := rawdb.ReadFreezeHold(db); hold != nil {
+		t.Fatalf("Freeze hold not lifted: %d", *hold)
+	}
+	// Runtime resolved transitions are held from their resolution on
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, PendingTransition,
+		WithDatabase(rawdb.NewMemoryDatabase()), WithFreezeWindow(100))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if rawdb.ReadFreezeHold(h.db) != nil {
+		t.Fatal("Freeze hold set for a pending transition")
+	}
+	h.resolveTransition(PendingTransition, 50, "test")
+	if hold := rawdb.ReadFreezeHold(h.db); hold == nil || *hold != 0 {
+		t.Fatalf("Freeze hold mismatch: have %v, want %d", hold, 0)
+	}
+	// Disabling the window lifts the hold of a previous run
+	rawdb.WriteFreezeHold(db, 1900)
+	if _, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 2000, WithDatabase(db)); err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if hold := rawdb.ReadFreezeHold(db); hold != nil {
+		t.Fatalf("Stale freeze hold not lifted: %d", *hold)
+	}
+	// Tools opening the database read-only leave the hold of the node alone
+	rawdb.WriteFreezeHold(db, 1900)
+	if _, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 2000, WithDatabase(db), WithReadOnly()); err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if hold := rawdb.ReadFreezeHold(db); hold == nil || *hold != 1900 {
+		t.Fatalf("Freeze hold of read-only database changed: %v", hold)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrGasCeilingExceeded is returned if a PoA block has a gas limit above the
+// ceiling scheduled for it in the chain config.
+var ErrGasCeilingExceeded = errors.New("gas limit above PoA gas ceiling")
+
+// verifyGasCeiling checks the gas limit of a PoA header against the ceiling
+// scheduled for it. If the ceiling was lowered below the gas limit of the
+// parent, the header needs to approach it at the maximum rate a gas limit may
+// decrease per block. The parent is looked up from the chain if not given.
+func verifyGasCeiling(chain consensus.ChainHeaderReader, header, parent *types.Header) error {
+	ceiling, ok := chain.Config().PoAGasCeiling(header.Number)
+	if !ok {
+		return nil
+	}
+	if parent == nil {
+		parent = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	limit := ceiling
+	if parent != nil && parent.GasLimit > ceiling {
+		if lowest := parent.GasLimit - parent.GasLimit/params.GasLimitBoundDivisor + 1; lowest > limit {
+			limit = lowest
+		}
+	}
+	if header.GasLimit > limit {
+		return fmt.Errorf("%w: have %d, max %d", ErrGasCeilingExceeded, header.GasLimit, limit)
+	}
+	return nil
+}
+
+// verifyGasCeilings extends the results of a batch verification of PoA headers
+// with the gas ceiling checks, using the preceding header of the batch as the
+// parent where available.
+func verifyGasCeilings(chain consensus.ChainHeaderReader, headers []*types.Header, abort chan<- struct{}, results <-chan error) (chan<- struct{}, <-chan error) {
+	var (
+		quit = make(chan struct{})
+		out  = make(chan error, len(headers))
+	)
+	go func() {
+		defer close(out)
+
+		for i, header := range headers {
+			var err error
+			select {
+			case res, ok := <-results:
+				if !ok {
+					return
+				}
+				err = res
+			case <-quit:
+				close(abort)
+				return
+			}
+			if err == nil {
+				var parent *types.Header
+				if i > 0 {
+					parent = headers[i-1]
+				}
+				err = verifyGasCeiling(chain, header, parent)
+			}
+			out <- err
+		}
+	}()
+	return quit, out
+}
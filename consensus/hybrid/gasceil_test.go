@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestGasCeilingVerification(t *testing.T) {
+	config := *params.TestChainConfig
+	config.PoSToPoATransitionBlock = big.NewInt(10)
+	config.PoAGasCeilings = []params.GasCeiling{
+		{Block: big.NewInt(10), Ceiling: 1_000_000},
+		{Block: big.NewInt(20), Ceiling: 2_000_000},
+	}
+	chain := newTestHeaderChain(&config, 30, 1)
+
+	h, err := New(&benchEngine{}, &benchEngine{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	child := func(parentGas, gas uint64, number uint64) *types.Header {
+		parent := chain.headers[number-1]
+		parent.GasLimit = parentGas
+		return &types.Header{ParentHash: parent.Hash(), Number: new(big.Int).SetUint64(number), GasLimit: gas}
+	}
+	tests := []struct {
+		name           string
+		number         uint64
+		parentGas, gas uint64
+		fail           bool
+	}{
+		{"pos era unbounded", 5, 10_000_000, 10_000_000, false},
+		{"at ceiling", 12, 900_000, 1_000_000, false},
+		{"above ceiling", 12, 900_000, 1_000_001, true},
+		{"approaching lowered ceiling", 12, 5_000_000, 5_000_000 - 5_000_000/1024 + 1, false},
+		{"too slowly approaching lowered ceiling", 12, 5_000_000, 5_000_000 - 5_000_000/1024 + 2, true},
+		{"raised ceiling", 25, 2_000_000, 2_000_000, false},
+		{"above raised ceiling", 25, 2_000_000, 2_000_001, true},
+	}
+	for _, tt := range tests {
+		header := child(tt.parentGas, tt.gas, tt.number)
+		err := h.VerifyHeader(chain, header)
+		if tt.fail != errors.Is(err, ErrGasCeilingExceeded) {
+			t.Errorf("%s: unexpected verification result: %v", tt.name, err)
+		}
+	}
+	// Batches check against parents within the batch, which are not yet known
+	// to the chain
+	first := child(1_000_000, 1_000_000, 15)
+	second := &types.Header{ParentHash: first.Hash(), Number: big.NewInt(16), GasLimit: 1_000_001}
+	_, results := h.VerifyHeaders(chain, []*types.Header{first, second})
+	if err := <-results; err != nil {
+		t.Errorf("Batch header 15 failed verification: %v", err)
+	}
+	if err := <-results; !errors.Is(err, ErrGasCeilingExceeded) {
+		t.Errorf("Batch header 16 verification error mismatch: have %v, want %v", err, ErrGasCeilingExceeded)
+	}
+}
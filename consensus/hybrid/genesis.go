@@ -0,0 +1,151 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// GenesisIssue describes a problem found in the genesis specification of a
+// hybrid network.
+type GenesisIssue struct {
+	Field   string // JSON path of the offending field
+	Message string // Human readable description of the problem
+	Warning bool   // Whether the network can launch despite the problem
+}
+
+// String implements the stringer interface.
+func (i GenesisIssue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", level, i.Field, i.Message)
+}
+
+// genesisSpec is the subset of a genesis specification relevant to hybrid
+// networks, keeping the raw encoding where formatting matters.
+type genesisSpec struct {
+	Config    json.RawMessage `json:"config"`
+	ExtraData *string         `json:"extraData"`
+}
+
+// transitionSpec holds the raw transition fields of the chain config.
+type transitionSpec struct {
+	PoAInitialSigners []string `json:"poaInitialSigners"`
+}
+
+// CheckGenesis validates the JSON encoded genesis specification of a hybrid
+// network. It returns every issue found, or an error if the specification
+// cannot be decoded at all. If strict is set, settings the engine only refuses
+// in strict mode are reported as errors instead of warnings.
+func CheckGenesis(data []byte, strict bool) ([]GenesisIssue, error) {
+	var spec genesisSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid genesis file: %v", err)
+	}
+	if len(spec.Config) == 0 {
+		return []GenesisIssue{{Field: "config", Message: "missing chain config"}}, nil
+	}
+	var (
+		issues []GenesisIssue
+		fail   = func(field, format string, args ...any) {
+			issues = append(issues, GenesisIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+		warn = func(field, format string, args ...any) {
+			issues = append(issues, GenesisIssue{Field: field, Message: fmt.Sprintf(format, args...), Warning: true})
+		}
+	)
+	// Decode the chain config strictly, since geth silently ignores misspelled
+	// fields which then fall back to their defaults.
+	var config params.ChainConfig
+	dec := json.NewDecoder(bytes.NewReader(spec.Config))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		if err := json.Unmarshal(spec.Config, &config); err != nil {
+			return nil, fmt.Errorf("invalid chain config: %v", err)
+		}
+		warn("config", "%v", err)
+	}
+	var raw transitionSpec
+	if err := json.Unmarshal(spec.Config, &raw); err != nil {
+		return nil, fmt.Errorf("invalid chain config: %v", err)
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		fail("config", "%v", err)
+	}
+	if config.TerminalTotalDifficulty == nil {
+		fail("config.terminalTotalDifficulty", "missing, the network cannot start in the PoS era")
+	}
+	if config.PoSToPoATransitionBlock == nil {
+		fail("config.posToPoaTransitionBlock", "missing, not a hybrid network")
+	}
+	if config.Clique == nil {
+		fail("config.clique", "missing, required to seal blocks after the transition")
+	} else {
+		if config.Clique.Period == 0 {
+			warn("config.clique.period", "zero period seals blocks on demand only")
+		}
+		if config.Clique.Epoch == 0 {
+			fail("config.clique.epoch", "must be positive")
+		} else if config.PoSToPoATransitionBlock != nil && config.PoSToPoATransitionBlock.Uint64()%config.Clique.Epoch != 0 {
+			warn("config.posToPoaTransitionBlock", "block %v is not aligned to the clique epoch %d", config.PoSToPoATransitionBlock, config.Clique.Epoch)
+		}
+	}
+	// Validate the textual form of the signers, the decoded config accepts any
+	// casing and thus cannot tell typos in checksummed addresses apart.
+	if len(raw.PoAInitialSigners) == 0 {
+		if strict {
+			fail("config.poaInitialSigners", "no initial signers configured, strict mode refuses the placeholder defaults")
+		} else {
+			warn("config.poaInitialSigners", "no initial signers configured, the placeholder defaults apply")
+		}
+	}
+	for i, signer := range raw.PoAInitialSigners {
+		field := fmt.Sprintf("config.poaInitialSigners[%d]", i)
+		switch {
+		case !common.IsHexAddress(signer) || !strings.HasPrefix(signer, "0x"):
+			fail(field, "invalid address %q", signer)
+		case signer == common.HexToAddress(signer).Hex():
+		case signer == strings.ToLower(signer) || signer == "0x"+strings.ToUpper(signer[2:]):
+			warn(field, "address %s is not checksummed, want %s", signer, common.HexToAddress(signer).Hex())
+		default:
+			fail(field, "address %s has an invalid checksum, want %s", signer, common.HexToAddress(signer).Hex())
+		}
+	}
+	// An empty extraData is allowed since the transition block establishes the
+	// signers, but anything present must follow the clique layout.
+	if spec.ExtraData != nil && *spec.ExtraData != "" && *spec.ExtraData != "0x" {
+		extra, err := hexutil.Decode(*spec.ExtraData)
+		switch {
+		case err != nil:
+			fail("extraData", "%v", err)
+		case len(extra) < extraVanity+extraSeal:
+			fail("extraData", "%d bytes, clique needs at least %d bytes of vanity and seal", len(extra), extraVanity+extraSeal)
+		case (len(extra)-extraVanity-extraSeal)%common.AddressLength != 0:
+			fail("extraData", "signer section of %d bytes is not a multiple of the address length", len(extra)-extraVanity-extraSeal)
+		}
+	}
+	return issues, nil
+}
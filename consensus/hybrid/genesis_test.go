@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const validHybridGenesis = `{
+	"config": {
+		"chainId": 1337,
+		"homesteadBlock": 0, "eip150Block": 0, "eip155Block": 0, "eip158Block": 0,
+		"byzantiumBlock": 0, "constantinopleBlock": 0, "petersburgBlock": 0,
+		"istanbulBlock": 0, "berlinBlock": 0, "londonBlock": 0,
+		"terminalTotalDifficulty": 0,
+		"clique": {"period": 5, "epoch": 30000},
+		"posToPoaTransitionBlock": 60000,
+		"poaInitialSigners": ["0x71562b71999873DB5b286dF957af199Ec94617F7"]
+	},
+	"extraData": "0x",
+	"gasLimit": "0x1c9c380",
+	"difficulty": "0x0",
+	"alloc": {}
+}`
+
+func TestCheckGenesis(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(string) string
+		strict bool
+		want   []string // Substrings of the expected issues, in order
+		fatal  int      // Number of expected errors
+	}{
+		{name: "valid", edit: func(s string) string { return s }},
+		{
+			name:  "missing ttd",
+			edit:  func(s string) string { return strings.Replace(s, `"terminalTotalDifficulty": 0,`, "", 1) },
+			want:  []string{"config.terminalTotalDifficulty"},
+			fatal: 1,
+		},
+		{
+			name: "misaligned transition",
+			edit: func(s string) string { return strings.Replace(s, "60000", "60001", 1) },
+			want: []string{"warning: config.posToPoaTransitionBlock: block 60001 is not aligned"},
+		},
+		{
+			name: "lowercase signer",
+			edit: func(s string) string {
+				return strings.Replace(s, "0x71562b71999873DB5b286dF957af199Ec94617F7", "0x71562b71999873db5b286df957af199ec94617f7", 1)
+			},
+			want: []string{"warning: config.poaInitialSigners[0]: address 0x71562b71999873db5b286df957af199ec94617f7 is not checksummed"},
+		},
+		{
+			name: "bad checksum",
+			edit: func(s string) string {
+				return strings.Replace(s, "0x71562b71999873DB5b286dF957af199Ec94617F7", "0x71562B71999873DB5b286dF957af199Ec94617F7", 1)
+			},
+			want:  []string{"invalid checksum"},
+			fatal: 1,
+		},
+		{
+			name:  "short extradata",
+			edit:  func(s string) string { return strings.Replace(s, `"extraData": "0x"`, `"extraData": "0x0000"`, 1) },
+			want:  []string{"extraData: 2 bytes"},
+			fatal: 1,
+		},
+		{
+			name: "missing signers",
+			edit: func(s string) string {
+				return strings.Replace(s, `"poaInitialSigners"`, `"unusedSigners"`, 1)
+			},
+			want: []string{`warning: config: json: unknown field "unusedSigners"`, "warning: config.poaInitialSigners: no initial signers configured"},
+		},
+		{
+			name: "missing signers strict",
+			edit: func(s string) string {
+				return strings.Replace(s, `"poaInitialSigners"`, `"unusedSigners"`, 1)
+			},
+			strict: true,
+			want:   []string{`warning: config: json: unknown field "unusedSigners"`, "config.poaInitialSigners: no initial signers configured, strict mode"},
+			fatal:  1,
+		},
+		{
+			name: "misspelled field",
+			edit: func(s string) string {
+				return strings.Replace(s, `"clique"`, `"clique": {"period": 5, "epoch": 30000}, "cliqe"`, 1)
+			},
+			want: []string{`warning: config: json: unknown field "cliqe"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := CheckGenesis([]byte(tt.edit(validHybridGenesis)), tt.strict)
+			if err != nil {
+				t.Fatalf("failed to check genesis: %v", err)
+			}
+			if len(issues) != len(tt.want) {
+				t.Fatalf("issue count mismatch: have %v, want %d", issues, len(tt.want))
+			}
+			var fatal int
+			for i, issue := range issues {
+				if !strings.Contains(issue.String(), tt.want[i]) {
+					t.Errorf("issue %d mismatch: have %q, want %q", i, issue, tt.want[i])
+				}
+				if !issue.Warning {
+					fatal++
+				}
+			}
+			if fatal != tt.fatal {
+				t.Errorf("error count mismatch: have %d, want %d", fatal, tt.fatal)
+			}
+		})
+	}
+	if _, err := CheckGenesis([]byte("{"), false); err == nil {
+		t.Error("expected malformed genesis to be rejected")
+	}
+}
+
+func TestCheckpointExtra(t *testing.T) {
+	signers := []common.Address{{0x01}, {0x02}}
+	extra := CheckpointExtra(signers)
+	if want := extraVanity + 2*common.AddressLength + extraSeal; len(extra) != want {
+		t.Fatalf("extraData length mismatch: have %d, want %d", len(extra), want)
+	}
+	for i, signer := range signers {
+		if have := common.BytesToAddress(extra[extraVanity+i*common.AddressLength : extraVanity+(i+1)*common.AddressLength]); have != signer {
+			t.Errorf("signer %d mismatch: have %v, want %v", i, have, signer)
+		}
+	}
+	if !bytes.Equal(extra[:extraVanity], make([]byte, extraVanity)) || !bytes.Equal(extra[len(extra)-extraSeal:], make([]byte, extraSeal)) {
+		t.Error("vanity and seal are not zeroed")
+	}
+}
+
+func TestBuildCheckpointExtra(t *testing.T) {
+	const transition = 10
+
+	signers := []common.Address{{0x01}, {0x02}, {0x03}}
+	h, err := New(newTrackingMockEngine("pos"), newTrackingMockEngine("poa"), transition, WithInitialSigners(signers))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// The RPC must produce the same bytes the transition block is prepared with
+	header := &types.Header{Number: big.NewInt(transition)}
+	if err := h.Prepare(&mockChainReader{}, header); err != nil {
+		t.Fatalf("Failed to prepare transition block: %v", err)
+	}
+	api := &API{chain: &mockChainReader{}, hybrid: h}
+	if have := api.BuildCheckpointExtra(nil); !bytes.Equal(have, header.Extra) {
+		t.Errorf("default extraData mismatch: have %x, want %x", have, header.Extra)
+	}
+	if have, want := api.BuildCheckpointExtra(signers[:1]), CheckpointExtra(signers[:1]); !bytes.Equal(have, want) {
+		t.Errorf("custom extraData mismatch: have %x, want %x", have, want)
+	}
+}
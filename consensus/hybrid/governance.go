@@ -0,0 +1,192 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// proposalsKey is the database key the signer proposals are persisted under.
+var proposalsKey = []byte("hybrid-proposals")
+
+var (
+	// ErrTransitionNotReached is returned by PoA-only operations while the
+	// chain is still in the PoS era.
+	ErrTransitionNotReached = errors.New("transition not reached")
+
+	// ErrGovernanceUnsupported is returned if the PoA engine does not support
+	// signer proposals.
+	ErrGovernanceUnsupported = errors.New("PoA engine does not support signer proposals")
+
+	// ErrRotationUnsupported is returned if the PoA engine does not support
+	// signer key rotations, or they are not activated.
+	ErrRotationUnsupported = errors.New("PoA engine does not support signer key rotation")
+)
+
+// Proposer is implemented by PoA engines that vote on signer set changes when
+// sealing blocks, such as clique.
+type Proposer interface {
+	// Propose injects a new authorization proposal.
+	Propose(address common.Address, auth bool)
+
+	// Discard drops a currently running proposal.
+	Discard(address common.Address)
+
+	// Proposals returns the currently running proposals.
+	Proposals() map[common.Address]bool
+}
+
+// Rotator is implemented by PoA engines that allow the local signer to rotate
+// its signing key through an announcement in the blocks it seals.
+type Rotator interface {
+	// Rotate announces a rotation of the local signing key to the given address.
+	Rotate(signer common.Address)
+}
+
+// proposer returns the PoA engine as a Proposer, constructing it if it is lazy.
+// Proposals persisted by a previous run are restored on first access.
+func (h *Hybrid) proposer() (Proposer, error) {
+	engine := h.poaEngine
+	if lazy, ok := engine.(*lazyEngine); ok {
+		engine = lazy.get()
+	}
+	proposer, ok := engine.(Proposer)
+	if !ok {
+		return nil, ErrGovernanceUnsupported
+	}
+	h.restored.Do(func() { h.restoreProposals(proposer) })
+	return proposer, nil
+}
+
+// restoreProposals injects the persisted signer proposals into the PoA engine.
+func (h *Hybrid) restoreProposals(proposer Proposer) {
+	if h.db == nil {
+		return
+	}
+	blob, err := h.db.Get(proposalsKey)
+	if err != nil {
+		return // Nothing persisted yet
+	}
+	var proposals map[common.Address]bool
+	if err := json.Unmarshal(blob, &proposals); err != nil {
+		log.Error("Failed to decode persisted signer proposals", "err", err)
+		return
+	}
+	for address, auth := range proposals {
+		proposer.Propose(address, auth)
+	}
+	if len(proposals) > 0 {
+		log.Info("Restored persisted signer proposals", "count", len(proposals))
+	}
+}
+
+// storeProposals persists the current signer proposals of the PoA engine.
+func (h *Hybrid) storeProposals(proposer Proposer) error {
+	if h.db == nil {
+		return nil
+	}
+	blob, err := json.Marshal(proposer.Proposals())
+	if err != nil {
+		return err
+	}
+	return h.db.Put(proposalsKey, blob)
+}
+
+// governable returns an error unless the PoA engine seals the block following
+// the current head of the chain.
+func (h *Hybrid) governable(chain consensus.ChainHeaderReader) error {
+	var number uint64
+	if head := chain.CurrentHeader(); head != nil {
+		number = head.Number.Uint64()
+	}
+	if number+1 < h.transitionBlock.Load() {
+		return fmt.Errorf("%w: signer proposals are accepted once the head reaches block %d, head is at %d (%d blocks remaining)",
+			ErrTransitionNotReached, h.transitionBlock.Load()-1, number, h.transitionBlock.Load()-1-number)
+	}
+	return nil
+}
+
+// Propose injects a new authorization proposal into the PoA engine, which the
+// local signer will vote on when sealing blocks.
+func (api *API) Propose(address common.Address, auth bool) error {
+	if err := api.hybrid.governable(api.chain); err != nil {
+		return err
+	}
+	proposer, err := api.hybrid.proposer()
+	if err != nil {
+		return err
+	}
+	proposer.Propose(address, auth)
+	log.Info("Signer proposal added", "address", address, "authorize", auth)
+	return api.hybrid.storeProposals(proposer)
+}
+
+// Discard drops a currently running proposal of the PoA engine.
+func (api *API) Discard(address common.Address) error {
+	if err := api.hybrid.governable(api.chain); err != nil {
+		return err
+	}
+	proposer, err := api.hybrid.proposer()
+	if err != nil {
+		return err
+	}
+	proposer.Discard(address)
+	log.Info("Signer proposal discarded", "address", address)
+	return api.hybrid.storeProposals(proposer)
+}
+
+// Proposals returns the signer proposals the local signer currently votes on,
+// including the ones restored from the database after a restart.
+func (api *API) Proposals() (map[common.Address]bool, error) {
+	proposer, err := api.hybrid.proposer()
+	if err != nil {
+		return nil, err
+	}
+	return proposer.Proposals(), nil
+}
+
+// RotateSigner makes the local signer announce a rotation of its signing key to
+// the given address in the blocks it seals. The new key takes over at the next
+// epoch checkpoint after the announcement is included, and must be authorized
+// on the node from then on. Announcements are only made once the rotation
+// activation is reached. A zero address cancels a pending rotation.
+func (api *API) RotateSigner(signer common.Address) error {
+	if err := api.hybrid.governable(api.chain); err != nil {
+		return err
+	}
+	if api.chain.Config().PoAActivationBlock(params.PoASignerRotation) == nil {
+		return fmt.Errorf("%w: %q is not scheduled in the chain config", ErrRotationUnsupported, params.PoASignerRotation)
+	}
+	engine := api.hybrid.poaEngine
+	if lazy, ok := engine.(*lazyEngine); ok {
+		engine = lazy.get()
+	}
+	rotator, ok := engine.(Rotator)
+	if !ok {
+		return ErrRotationUnsupported
+	}
+	rotator.Rotate(signer)
+	log.Info("Signer key rotation requested", "signer", signer)
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"maps"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSignerProposals(t *testing.T) {
+	const transition = 100
+
+	poa := clique.New(&params.CliqueConfig{Period: 1, Epoch: 30000}, rawdb.NewMemoryDatabase())
+	h, err := New(newTrackingMockEngine("pos"), Lazy("poa", func() consensus.Engine { return poa }), transition)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	var (
+		address = common.Address{0x01}
+		chain   = newTestHeaderChain(params.TestChainConfig, transition-2, 1)
+		api     = &API{chain: chain, hybrid: h}
+	)
+	// Governance is refused while the next block is still a PoS block
+	if err := api.Propose(address, true); !errors.Is(err, ErrTransitionNotReached) {
+		t.Fatalf("Propose before the transition: have %v, want %v", err, ErrTransitionNotReached)
+	}
+	if err := api.Discard(address); !errors.Is(err, ErrTransitionNotReached) {
+		t.Fatalf("Discard before the transition: have %v, want %v", err, ErrTransitionNotReached)
+	}
+	// Once the next block is sealed by the PoA engine, proposals go through
+	chain = newTestHeaderChain(params.TestChainConfig, transition-1, 1)
+	api.chain = chain
+	if err := api.Propose(address, true); err != nil {
+		t.Fatalf("Failed to propose: %v", err)
+	}
+	if proposals := poa.Proposals(); len(proposals) != 1 || !proposals[address] {
+		t.Fatalf("Proposal not forwarded: %v", proposals)
+	}
+	if err := api.Discard(address); err != nil {
+		t.Fatalf("Failed to discard: %v", err)
+	}
+	if proposals := poa.Proposals(); len(proposals) != 0 {
+		t.Fatalf("Proposal not discarded: %v", proposals)
+	}
+	// Engines without proposals are reported as such
+	h.poaEngine = newTrackingMockEngine("poa")
+	if err := api.Propose(address, true); !errors.Is(err, ErrGovernanceUnsupported) {
+		t.Fatalf("Propose without support: have %v, want %v", err, ErrGovernanceUnsupported)
+	}
+}
+
+func TestSignerProposalPersistence(t *testing.T) {
+	const transition = 100
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		chain   = newTestHeaderChain(params.TestChainConfig, transition, 1)
+		config  = &params.CliqueConfig{Period: 1, Epoch: 30000}
+		added   = common.Address{0x01}
+		removed = common.Address{0x02}
+	)
+	newAPI := func() (*API, *clique.Clique) {
+		poa := clique.New(config, db)
+		h, err := New(newTrackingMockEngine("pos"), Lazy("poa", func() consensus.Engine { return poa }), transition, WithDatabase(db))
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		return &API{chain: chain, hybrid: h}, poa
+	}
+	api, _ := newAPI()
+	for _, address := range []common.Address{added, removed, {0x03}} {
+		if err := api.Propose(address, address != removed); err != nil {
+			t.Fatalf("Failed to propose: %v", err)
+		}
+	}
+	if err := api.Discard(common.Address{0x03}); err != nil {
+		t.Fatalf("Failed to discard: %v", err)
+	}
+	// A restarted engine picks the proposals up again
+	api, poa := newAPI()
+	if len(poa.Proposals()) != 0 {
+		t.Fatalf("Proposals restored eagerly: %v", poa.Proposals())
+	}
+	proposals, err := api.Proposals()
+	if err != nil {
+		t.Fatalf("Failed to list proposals: %v", err)
+	}
+	want := map[common.Address]bool{added: true, removed: false}
+	if !maps.Equal(proposals, want) {
+		t.Fatalf("Proposals mismatch: have %v, want %v", proposals, want)
+	}
+	if !maps.Equal(poa.Proposals(), want) {
+		t.Fatalf("Proposals not restored into the engine: have %v, want %v", poa.Proposals(), want)
+	}
+}
+
+func TestSignerRotationRequest(t *testing.T) {
+	const transition = 100
+
+	poa := clique.New(&params.CliqueConfig{Period: 1, Epoch: 30000}, rawdb.NewMemoryDatabase())
+	h, err := New(newTrackingMockEngine("pos"), poa, transition)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	config := *params.TestChainConfig
+	config.PoSToPoATransitionBlock = big.NewInt(transition)
+
+	api := &API{chain: newTestHeaderChain(&config, transition, 1), hybrid: h}
+	if err := api.RotateSigner(common.Address{0x01}); !errors.Is(err, ErrRotationUnsupported) {
+		t.Fatalf("Rotation without activation: have %v, want %v", err, ErrRotationUnsupported)
+	}
+	config.PoAActivations = map[string]uint64{params.PoASignerRotation: 10}
+	if err := api.RotateSigner(common.Address{0x01}); err != nil {
+		t.Fatalf("Failed to request rotation: %v", err)
+	}
+	api.chain = newTestHeaderChain(&config, transition-2, 1)
+	if err := api.RotateSigner(common.Address{0x01}); !errors.Is(err, ErrTransitionNotReached) {
+		t.Fatalf("Rotation before the transition: have %v, want %v", err, ErrTransitionNotReached)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ErrLatePoSBlock is returned for PoS styled headers past the transition that
+// are outside of the grace window.
+var ErrLatePoSBlock = errors.New("PoS block past the grace window")
+
+var (
+	graceToleratedCounter = metrics.NewRegisteredCounter("hybrid/grace/tolerated", nil)
+	graceRejectedCounter  = metrics.NewRegisteredCounter("hybrid/grace/rejected", nil)
+)
+
+// GraceWindow returns the number of blocks starting at the transition in which
+// straggling PoS blocks are tolerated.
+func (h *Hybrid) GraceWindow() uint64 {
+	return h.graceWindow
+}
+
+// straggler reports whether the header is a PoS styled one past the transition.
+func (h *Hybrid) straggler(header *types.Header) bool {
+	return header.Number.Uint64() >= h.transitionBlock.Load() && h.posStyled(header)
+}
+
+// stragglers reports whether any of the headers is a PoS styled one past the
+// transition.
+func (h *Hybrid) stragglers(headers []*types.Header) bool {
+	for _, header := range headers {
+		if h.straggler(header) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyStraggler verifies a PoS styled header past the transition. Within the
+// grace window it is checked by the PoS engine, so blocks the beacon chain kept
+// producing during a messy failover are accepted as side chain blocks, which
+// the fork choice never prefers over the PoA chain. Past the window it is
+// rejected outright.
+func (h *Hybrid) verifyStraggler(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	if number-h.transitionBlock.Load() >= h.graceWindow {
+		graceRejectedCounter.Inc(1)
+		return &TransitionError{Code: CodeLatePoSBlock, Err: fmt.Errorf("%w: block %d is %d blocks past the transition at block %d, grace window is %d blocks",
+			ErrLatePoSBlock, number, number-h.transitionBlock.Load(), h.transitionBlock.Load(), h.graceWindow)}
+	}
+	graceToleratedCounter.Inc(1)
+	log.Warn("Tolerating PoS block within the grace window", "number", number, "hash", header.Hash(),
+		"transitionBlock", h.transitionBlock.Load(), "graceWindow", h.graceWindow)
+
+	labelDelegate(false, methodVerifyHeader)
+	defer unlabelDelegate()
+	return h.posEngine.VerifyHeader(chain, header)
+}
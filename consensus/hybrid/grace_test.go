@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestGraceWindow(t *testing.T) {
+	var (
+		posEngine = newTrackingMockEngine("pos")
+		poaEngine = newTrackingMockEngine("poa")
+		chain     = &mockChainReader{}
+	)
+	h, err := New(posEngine, poaEngine, 10, WithGraceWindow(3))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	pos := func(number int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(0)}
+	}
+	// PoS blocks within the window are verified by the PoS engine
+	for _, number := range []int64{10, 12} {
+		if err := h.VerifyHeader(chain, pos(number)); err != nil {
+			t.Fatalf("Straggler %d rejected: %v", number, err)
+		}
+		if err := h.CheckEra(pos(number)); err != nil {
+			t.Fatalf("Straggler %d violates era rules: %v", number, err)
+		}
+	}
+	if calls := posEngine.getCallCount("VerifyHeader"); calls != 2 {
+		t.Fatalf("PoS verifications mismatch: have %d, want 2", calls)
+	}
+	// Later ones are rejected without consulting any engine
+	err = h.VerifyHeader(chain, pos(13))
+	if !errors.Is(err, ErrLatePoSBlock) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrLatePoSBlock)
+	}
+	if code, _ := CodeOf(err); code != CodeLatePoSBlock {
+		t.Fatalf("Error code mismatch: have %d, want %d", code, CodeLatePoSBlock)
+	}
+	if err := h.CheckEra(pos(13)); !errors.Is(err, ErrEraViolation) {
+		t.Fatalf("Era error mismatch: have %v, want %v", err, ErrEraViolation)
+	}
+	if posEngine.getCallCount("VerifyHeader") != 2 || poaEngine.getCallCount("VerifyHeader") != 0 {
+		t.Fatal("Late PoS block handed to an engine")
+	}
+	// Batches are checked header by header, PoA blocks still go to the PoA engine
+	headers := []*types.Header{pos(11), {Number: big.NewInt(12), Difficulty: big.NewInt(2)}, pos(14)}
+	_, results := h.VerifyHeaders(chain, headers)
+	for i, want := range []error{nil, nil, ErrLatePoSBlock} {
+		if err := <-results; !errors.Is(err, want) {
+			t.Errorf("Header %d: error mismatch: have %v, want %v", i, err, want)
+		}
+	}
+	if calls := poaEngine.getCallCount("VerifyHeader"); calls != 1 {
+		t.Fatalf("PoA verifications mismatch: have %d, want 1", calls)
+	}
+	// Without a window no straggler is tolerated
+	h, err = New(posEngine, poaEngine, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if err := h.VerifyHeader(chain, pos(10)); !errors.Is(err, ErrLatePoSBlock) {
+		t.Fatalf("Error mismatch without window: have %v, want %v", err, ErrLatePoSBlock)
+	}
+}
+
+// TestGraceWindowInsertChain tests that stragglers imported through the chain
+// are executed as the PoS blocks they are: the fees go to their coinbase and
+// their withdrawals are credited.
+func TestGraceWindowInsertChain(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase = common.Address{0xc0}
+		receiver = common.Address{0xee}
+		config   = *params.MergedTestChainConfig
+	)
+	config.CancunTime, config.PragueTime, config.OsakaTime = nil, nil, nil
+	config.PoSToPoATransitionBlock = big.NewInt(2)
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: 30000}
+
+	gspec := &core.Genesis{
+		Config:  &config,
+		Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	// Blocks 2 and 3 are built by the PoS chain past the transition
+	signer := types.LatestSigner(&config)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, beacon.New(ethash.NewFaker()), 3, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(coinbase)
+		price := new(big.Int).Add(gen.BaseFee(), big.NewInt(params.GWei))
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(addr), receiver, big.NewInt(1), params.TxGas, price, nil), signer, key)
+		if err != nil {
+			t.Fatalf("Failed to sign transaction: %v", err)
+		}
+		gen.AddTx(tx)
+		gen.AddWithdrawal(&types.Withdrawal{Validator: uint64(i), Address: receiver, Amount: 1})
+	})
+	db := rawdb.NewMemoryDatabase()
+	engine, err := New(beacon.New(ethash.NewFaker()), clique.New(config.Clique, db), 2, WithGraceWindow(4))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain, err := core.NewBlockChain(db, gspec, engine, core.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Block %d: failed to insert: %v", blocks[n].NumberU64(), err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != blocks[2].Hash() {
+		t.Fatalf("Head mismatch: have %x, want %x", head, blocks[2].Hash())
+	}
+	for _, block := range blocks[1:] {
+		if author, err := engine.Author(block.Header()); err != nil || author != coinbase {
+			t.Errorf("Straggler %d author mismatch: have %x (%v), want %x", block.NumberU64(), author, err, coinbase)
+		}
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("Failed to open head state: %v", err)
+	}
+	// Three tips of 1 gwei per gas, three withdrawals of 1 gwei and three transfers of 1 wei
+	tips := new(big.Int).Mul(new(big.Int).SetUint64(3*params.TxGas), big.NewInt(params.GWei))
+	if have := statedb.GetBalance(coinbase).ToBig(); have.Cmp(tips) != 0 {
+		t.Errorf("Coinbase balance mismatch: have %v, want %v", have, tips)
+	}
+	want := big.NewInt(3*params.GWei + 3)
+	if have := statedb.GetBalance(receiver).ToBig(); have.Cmp(want) != 0 {
+		t.Errorf("Withdrawal receiver balance mismatch: have %v, want %v", have, want)
+	}
+}
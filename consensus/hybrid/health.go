@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+)
+
+// stallPeriods is the number of block periods without a new PoA block after
+// which the chain is considered stalled.
+const stallPeriods = 3
+
+var (
+	// ErrSignerNotAuthorized is returned by the readiness check if none of the
+	// local signers is able to seal in the PoA era.
+	ErrSignerNotAuthorized = errors.New("no local signer is authorized to seal")
+
+	// ErrChainStalled is returned by the liveness check if no new PoA block was
+	// added within several block periods.
+	ErrChainStalled = errors.New("chain stalled")
+)
+
+// Ready reports whether the node is set up for the era of the block following
+// the current head. Nodes without local signers only need to follow the chain,
+// which the engine is always able to. Signers additionally need their keys
+// ahead of the transition, and to be authorized once in the PoA era.
+func (h *Hybrid) Ready(chain consensus.ChainHeaderReader) error {
+	if len(h.localSigners) == 0 {
+		return nil
+	}
+	head := chain.CurrentHeader()
+	number := head.Number.Uint64()
+	if number+1 < h.transitionBlock.Load() {
+		if number+signerKeyCheckWindow >= h.transitionBlock.Load() {
+			return h.checkSignerKey()
+		}
+		return nil
+	}
+	// The transition block authorizes the initial signers, afterwards the PoA
+	// engine tracks the authorized set
+	authorized := h.initialSigners
+	if number >= h.transitionBlock.Load() {
+		engine := h.poaEngine
+		if lazy, ok := engine.(*lazyEngine); ok {
+			engine = lazy.get()
+		}
+		if lister, ok := engine.(SignerLister); ok {
+			signers, err := lister.Signers(chain, head)
+			if err != nil {
+				return err
+			}
+			authorized = signers
+		}
+	}
+	if slices.ContainsFunc(h.localSigners, func(signer common.Address) bool {
+		return (h.hasKey == nil || h.hasKey(signer)) && slices.Contains(authorized, signer)
+	}) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrSignerNotAuthorized, h.localSigners)
+}
+
+// Live reports whether the chain keeps progressing after the transition, i.e.
+// the head block is no older than a few clique periods.
+func (h *Hybrid) Live(chain consensus.ChainHeaderReader, now time.Time) error {
+	head := chain.CurrentHeader()
+	if head.Number.Uint64() < h.transitionBlock.Load() {
+		return nil
+	}
+	clique := chain.Config().Clique
+	if clique == nil || clique.Period == 0 {
+		return nil // Blocks are only sealed on demand
+	}
+	if age := now.Unix() - int64(head.Time); age > int64(stallPeriods*clique.Period) {
+		return fmt.Errorf("%w: no new block for %v since #%d", ErrChainStalled, time.Duration(age)*time.Second, head.Number)
+	}
+	return nil
+}
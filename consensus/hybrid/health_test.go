@@ -0,0 +1,82 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestReadiness(t *testing.T) {
+	var (
+		initial = []common.Address{{0x01}, {0x02}, {0x03}}
+		keys    = map[common.Address]bool{{0x01}: true}
+		hasKey  = func(signer common.Address) bool { return keys[signer] }
+		config  = &params.ChainConfig{ChainID: params.TestChainConfig.ChainID, Clique: &params.CliqueConfig{Period: 5, Epoch: 30}}
+	)
+	tests := []struct {
+		head   uint64
+		local  []common.Address
+		expect error
+	}{
+		{head: 10, expect: nil},                                                  // Plain followers are always ready
+		{head: 10, local: []common.Address{{0x01}}, expect: nil},                 // Signer with key
+		{head: 10, local: []common.Address{{0x02}}, expect: ErrMissingSignerKey}, // Signer lacking its key near the transition
+		{head: 99, local: []common.Address{{0x01}}, expect: nil},                 // Initial signer sealing the transition block
+		{head: 99, local: []common.Address{{0x04}}, expect: ErrSignerNotAuthorized},
+		{head: 120, local: []common.Address{{0x04}, {0x01}}, expect: nil},            // Any authorized local signer suffices
+		{head: 120, local: []common.Address{{0x02}}, expect: ErrSignerNotAuthorized}, // Authorized, but the key is missing
+	}
+	for i, tt := range tests {
+		// Missing keys are refused at construction too, so inject the signers
+		h, err := New(&mockEngine{name: "pos"}, &listingMockEngine{initial: initial}, 100, WithInitialSigners(initial))
+		if err != nil {
+			t.Fatalf("test %d: failed to create hybrid engine: %v", i, err)
+		}
+		h.localSigners, h.hasKey = tt.local, hasKey
+
+		chain := newTestHeaderChain(config, tt.head, 5)
+		if err := h.Ready(chain); !errors.Is(err, tt.expect) {
+			t.Errorf("test %d: readiness mismatch: have %v, want %v", i, err, tt.expect)
+		}
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	config := &params.ChainConfig{ChainID: params.TestChainConfig.ChainID, Clique: &params.CliqueConfig{Period: 5, Epoch: 30}}
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// Headers are 5 seconds apart, the head of 120 blocks is at 600
+	chain := newTestHeaderChain(config, 120, 5)
+	if err := h.Live(chain, time.Unix(615, 0)); err != nil {
+		t.Errorf("Chain within three periods reported stalled: %v", err)
+	}
+	if err := h.Live(chain, time.Unix(616, 0)); !errors.Is(err, ErrChainStalled) {
+		t.Errorf("Stalled chain: have %v, want %v", err, ErrChainStalled)
+	}
+	// Stalls of the PoS era are up to the consensus client to detect
+	chain = newTestHeaderChain(config, 90, 5)
+	if err := h.Live(chain, time.Unix(10_000, 0)); err != nil {
+		t.Errorf("PoS chain reported stalled: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// HeartbeatInterval is how often local signers announce their liveness.
+	HeartbeatInterval = 30 * time.Second
+
+	// heartbeatTimeout is how long a signer is considered online after its
+	// latest heartbeat.
+	heartbeatTimeout = 3 * HeartbeatInterval
+
+	// heartbeatFutureSlack is how far in the future a heartbeat may be dated
+	// before it is ignored.
+	heartbeatFutureSlack = 15 * time.Second
+)
+
+// heartbeatDomain separates heartbeat signatures from any other data signed
+// with the keys of signers.
+var heartbeatDomain = []byte("hybrid-heartbeat/1")
+
+// ErrInvalidHeartbeat is returned if the signature of a heartbeat does not match
+// the signer it claims to originate from.
+var ErrInvalidHeartbeat = errors.New("invalid heartbeat signature")
+
+var (
+	heartbeatReceivedCounter = metrics.NewRegisteredCounter("hybrid/heartbeat/received", nil)
+	heartbeatInvalidCounter  = metrics.NewRegisteredCounter("hybrid/heartbeat/invalid", nil)
+	heartbeatOnlineGauge     = metrics.NewRegisteredGauge("hybrid/heartbeat/online", nil)
+)
+
+// SignFn signs the keccak256 hash of data with the key of the given account,
+// returning a 65 byte [R || S || V] signature.
+type SignFn func(signer common.Address, data []byte) ([]byte, error)
+
+// Heartbeat is a signed liveness announcement of a PoA signer, carrying the
+// head of the chain as seen by its node.
+type Heartbeat struct {
+	Signer    common.Address
+	Number    uint64      // Number of the head block of the signer's node
+	Hash      common.Hash // Hash of the head block of the signer's node
+	Time      uint64      // Unix time the heartbeat was created at
+	Signature []byte      // Signature over the heartbeat payload
+}
+
+// payload returns the data covered by the heartbeat signature.
+func (hb *Heartbeat) payload() []byte {
+	blob, _ := rlp.EncodeToBytes([]interface{}{hb.Signer, hb.Number, hb.Hash, hb.Time})
+	return append(slices.Clone(heartbeatDomain), blob...)
+}
+
+// ID returns a unique identifier of the heartbeat, covering its signature.
+func (hb *Heartbeat) ID() common.Hash {
+	return crypto.Keccak256Hash(hb.payload(), hb.Signature)
+}
+
+// Verify checks that the heartbeat was signed by the signer it names.
+func (hb *Heartbeat) Verify() error {
+	if len(hb.Signature) != crypto.SignatureLength {
+		return ErrInvalidHeartbeat
+	}
+	pubkey, err := crypto.Ecrecover(crypto.Keccak256(hb.payload()), hb.Signature)
+	if err != nil {
+		return ErrInvalidHeartbeat
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	if signer != hb.Signer {
+		return ErrInvalidHeartbeat
+	}
+	return nil
+}
+
+// NewHeartbeat creates a heartbeat of the signer announcing the given head,
+// signed with the supplied signing function.
+func NewHeartbeat(signer common.Address, head *types.Header, sign SignFn) (*Heartbeat, error) {
+	hb := &Heartbeat{
+		Signer: signer,
+		Number: head.Number.Uint64(),
+		Hash:   head.Hash(),
+		Time:   uint64(time.Now().Unix()),
+	}
+	sig, err := sign(signer, hb.payload())
+	if err != nil {
+		return nil, err
+	}
+	hb.Signature = sig
+	return hb, nil
+}
+
+// SignerHealth is the liveness of a single designated signer.
+type SignerHealth struct {
+	Signer   common.Address `json:"signer"`
+	Online   bool           `json:"online"`
+	LastSeen uint64         `json:"lastSeen,omitempty"` // Unix time of the latest heartbeat
+	Number   uint64         `json:"number,omitempty"`   // Head number announced in the latest heartbeat
+	Hash     common.Hash    `json:"hash,omitempty"`     // Head hash announced in the latest heartbeat
+}
+
+// NetworkHealth is the liveness of the designated PoA signers as learned from
+// their heartbeats.
+type NetworkHealth struct {
+	Online  int              `json:"online"`
+	Absent  []common.Address `json:"absent"`
+	Signers []SignerHealth   `json:"signers"`
+}
+
+// heartbeatTracker keeps the latest heartbeat of every designated signer.
+type heartbeatTracker struct {
+	latest map[common.Address]*Heartbeat
+	since  time.Time // Time tracking started, before which absence is expected
+	lock   sync.Mutex
+}
+
+// AddHeartbeat records a heartbeat received from the network. It returns an
+// error if the heartbeat is forged, and whether it is new and should be relayed
+// to other peers. Heartbeats of signers outside the initial signer set, older
+// than the known one or dated in the future are ignored.
+func (h *Hybrid) AddHeartbeat(hb *Heartbeat) (bool, error) {
+	if err := hb.Verify(); err != nil {
+		heartbeatInvalidCounter.Inc(1)
+		return false, err
+	}
+	if !slices.Contains(h.initialSigners, hb.Signer) {
+		return false, nil
+	}
+	if time.Unix(int64(hb.Time), 0).After(time.Now().Add(heartbeatFutureSlack)) {
+		return false, nil
+	}
+	h.heartbeats.lock.Lock()
+	defer h.heartbeats.lock.Unlock()
+
+	if known, ok := h.heartbeats.latest[hb.Signer]; ok && known.Time >= hb.Time {
+		return false, nil
+	}
+	if h.heartbeats.latest == nil {
+		h.heartbeats.latest = make(map[common.Address]*Heartbeat)
+	}
+	h.heartbeats.latest[hb.Signer] = hb
+	heartbeatReceivedCounter.Inc(1)
+	return true, nil
+}
+
+// NetworkHealth reports which of the initial signers announced themselves
+// recently, and the head their nodes are at.
+func (h *Hybrid) NetworkHealth() *NetworkHealth {
+	h.heartbeats.lock.Lock()
+	defer h.heartbeats.lock.Unlock()
+
+	var (
+		now    = time.Now()
+		health = &NetworkHealth{Absent: []common.Address{}, Signers: make([]SignerHealth, 0, len(h.initialSigners))}
+	)
+	for _, signer := range h.initialSigners {
+		status := SignerHealth{Signer: signer}
+		if hb, ok := h.heartbeats.latest[signer]; ok {
+			status.LastSeen, status.Number, status.Hash = hb.Time, hb.Number, hb.Hash
+			status.Online = now.Sub(time.Unix(int64(hb.Time), 0)) <= heartbeatTimeout
+		}
+		if status.Online {
+			health.Online++
+		} else {
+			health.Absent = append(health.Absent, signer)
+		}
+		health.Signers = append(health.Signers, status)
+	}
+	heartbeatOnlineGauge.Update(int64(health.Online))
+	return health
+}
+
+// CheckSignerPresence warns if some initial signers have not announced their
+// liveness while the chain at the given head has yet to reach the transition,
+// giving operators time to bring them online before they are needed.
+func (h *Hybrid) CheckSignerPresence(number uint64) {
+	if number+1 >= h.transitionBlock.Load() || time.Since(h.heartbeats.since) < heartbeatTimeout {
+		return
+	}
+	health := h.NetworkHealth()
+	if len(health.Absent) == 0 {
+		return
+	}
+	log.Warn("Designated PoA signers are absent ahead of the transition",
+		"absent", health.Absent,
+		"online", health.Online,
+		"blockNumber", number,
+		"transitionBlock", h.transitionBlock.Load(),
+		"blocksRemaining", h.transitionBlock.Load()-number-1)
+}
+
+// NetworkHealth returns the liveness of the initial PoA signers, as announced
+// through their heartbeats.
+func (api *API) NetworkHealth() *NetworkHealth {
+	return api.hybrid.NetworkHealth()
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestHeartbeats(t *testing.T) {
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		keyC, _ = crypto.GenerateKey()
+		signerA = crypto.PubkeyToAddress(keyA.PublicKey)
+		signerB = crypto.PubkeyToAddress(keyB.PublicKey)
+		signerC = crypto.PubkeyToAddress(keyC.PublicKey)
+		keys    = map[common.Address]*ecdsa.PrivateKey{signerA: keyA, signerB: keyB, signerC: keyC}
+	)
+	sign := func(signer common.Address, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), keys[signer])
+	}
+
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100,
+		WithInitialSigners([]common.Address{signerA, signerB, {0x01}}))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	head := &types.Header{Number: big.NewInt(42)}
+
+	hbA, err := NewHeartbeat(signerA, head, sign)
+	if err != nil {
+		t.Fatalf("Failed to create heartbeat: %v", err)
+	}
+	if relay, err := h.AddHeartbeat(hbA); err != nil || !relay {
+		t.Fatalf("Valid heartbeat: have %v/%v, want true/nil", relay, err)
+	}
+	// Repeated heartbeats are not relayed again
+	if relay, err := h.AddHeartbeat(hbA); err != nil || relay {
+		t.Fatalf("Repeated heartbeat: have %v/%v, want false/nil", relay, err)
+	}
+	// Heartbeats claiming another signer are rejected
+	forged := *hbA
+	forged.Signer = signerB
+	if _, err := h.AddHeartbeat(&forged); !errors.Is(err, ErrInvalidHeartbeat) {
+		t.Fatalf("Forged heartbeat: have %v, want %v", err, ErrInvalidHeartbeat)
+	}
+	// Heartbeats of signers outside the initial set are ignored
+	hbC, _ := NewHeartbeat(signerC, head, sign)
+	if relay, err := h.AddHeartbeat(hbC); err != nil || relay {
+		t.Fatalf("Foreign heartbeat: have %v/%v, want false/nil", relay, err)
+	}
+	health := h.NetworkHealth()
+	if health.Online != 1 || len(health.Absent) != 2 || health.Absent[0] != signerB {
+		t.Fatalf("Health mismatch: online %d, absent %v", health.Online, health.Absent)
+	}
+	if status := health.Signers[0]; !status.Online || status.Number != 42 || status.Hash != head.Hash() {
+		t.Fatalf("Signer health mismatch: %+v", status)
+	}
+}
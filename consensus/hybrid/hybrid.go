@@ -19,24 +19,55 @@
 package hybrid
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/schedule"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // Various error messages to mark invalid configurations.
 var (
 	ErrInvalidTransitionBlock = errors.New("invalid PoS to PoA transition block")
 	ErrMissingEngine          = errors.New("missing consensus engine")
+	ErrPlaceholderSigners     = errors.New("initial signers contain placeholder addresses")
+	ErrTooFewSigners          = errors.New("too few initial signers")
+	ErrNoSigners              = errors.New("empty initial signer set")
+	ErrZeroSigner             = errors.New("zero address in initial signer set")
+	ErrDuplicateSigner        = errors.New("duplicate address in initial signer set")
+	ErrEvenSigners            = errors.New("even number of initial signers")
+)
+
+// DefaultMinSigners is the minimum number of initial PoA signers required by
+// strict mode unless configured otherwise.
+const DefaultMinSigners = 3
+
+// Clique extraData layout of the transition checkpoint block.
+const (
+	extraVanity = 32                     // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = crypto.SignatureLength // Fixed number of extra-data suffix bytes reserved for signer seal
+)
+
+// Phases of the engine schedule of the hybrid engine.
+const (
+	phasePoS = iota // Governed by the PoS engine, up to the transition
+	phasePoA        // Governed by the PoA engine, from the transition on
 )
 
 // Hardcoded initial signers for PoA after transition
@@ -55,20 +86,55 @@ var defaultInitialSigners = []common.Address{
 type Hybrid struct {
 	posEngine        consensus.Engine // Engine used for PoS consensus (before transition)
 	poaEngine        consensus.Engine // Engine used for PoA consensus (after transition)
-	transitionBlock  uint64           // Block number at which to switch from PoS to PoA
+	transitionBlock  atomic.Uint64    // Block number at which to switch from PoS to PoA (pendingTransition = unresolved)
+	terminalHash     common.Hash      // Hash of the last PoS block the transition descends from (zero = not pinned)
+	manual           bool             // Whether operators may flip to PoA at the current head
 	initialSigners   []common.Address // Initial signers for PoA after transition
-	mu               sync.RWMutex     // Protects concurrent access to engine selection
-	transitionLogged bool             // Tracks if transition has been logged to avoid spam
+	checkpoint       []byte           // Extra-data of the transition block with an empty vanity, never modified
+	extraBuilder     ExtraBuilder     // Contributes the vanity of the transition block (nil = keep the miner's)
+	strict           bool             // Refuse placeholder or too few initial signers
+	minSigners       int              // Minimum number of initial signers enforced in strict mode
+	localSigners     []common.Address // Accounts this node seals PoA blocks with, if any
+	hasKey           KeyChecker       // Reports whether the key of the local signer is available
+	keyChecked       atomic.Bool      // Whether the local signer key was verified near the transition
+	snapshotsPruned  atomic.Bool      // Whether the PoS era clique snapshots were pruned in this run
+	freezerReleased  atomic.Bool      // Whether the freeze hold was lifted in this run
+	shadow           *shadowVerifier  // Shadow PoA verification ahead of the transition (nil = disabled)
+	dual             *dualVerifier    // Dual-engine verification around the transition (nil = disabled)
+	forkMonitor      *forkMonitor     // Branches observed around the transition (nil = disabled)
+	reorgs           *reorgReporter   // Reports of reorgs around the transition (nil = disabled)
+	countdown        uint64           // Blocks ahead of the transition the countdown is logged for (0 = disabled)
+	confirmDepth     uint64           // Depth after which the transition block is final (0 = never)
+	graceWindow      uint64           // Blocks from the transition on in which PoS blocks are tolerated
+	freezeWindow     uint64           // Blocks before the transition kept out of the freezer until final (0 = disabled)
+	mu               sync.Mutex       // Protects the rate limiting of engine selection logs
 	lastLoggedEngine string           // Tracks last logged engine type to avoid spam
 	lastLogTime      time.Time        // Tracks last log time for rate limiting
+
+	db          ethdb.KeyValueStore // Database to persist engine state in (nil = in-memory only)
+	readOnly    bool                // Whether the database is opened read-only by a tool
+	restored    sync.Once           // Restores persisted signer proposals into the PoA engine
+	doubleSign  *doubleSignMonitor  // Detection of double-signed imported PoA blocks (nil = disabled)
+	heartbeats  heartbeatTracker    // Latest heartbeats of the initial signers
+	alerts      alertWatcher        // Critical events derived from processed blocks
+	webhook     *Webhook            // Endpoint alerts are delivered to (nil = disabled)
+	consistency *consistencyChecker // Re-verification of random historical blocks (nil = disabled)
+	signerSets  event.Feed          // Changes of the authorized PoA signer set
+	epochDiffs  event.Feed          // Signer set diffs of PoA epoch checkpoints
+	runtime     runtimeState        // Progress through the transition, persisted across restarts
+	forks       forkWatcher         // Competing blocks seen at the transition height
+	sealers     sealerIndex         // Canonical PoA blocks by the signer sealing them
+	records     recordBook          // Transition records co-signed by the initial signers
+	proof       *TransitionRecord   // Co-signed record the transition block is bootstrapped from (nil = none)
 }
 
 // New creates a new hybrid consensus engine that transitions from PoS to PoA at the specified block number.
 // posEngine is the consensus engine used before the transition (typically beacon-wrapped clique).
 // poaEngine is the consensus engine used after the transition (typically pure clique).
 // transitionBlock is the block number at which the transition occurs.
-// The initial PoA validators are hardcoded in defaultInitialSigners.
-func New(posEngine, poaEngine consensus.Engine, transitionBlock uint64) (*Hybrid, error) {
+// The initial PoA validators default to defaultInitialSigners unless overridden
+// through the supplied options.
+func New(posEngine, poaEngine consensus.Engine, transitionBlock uint64, opts ...Option) (*Hybrid, error) {
 	if posEngine == nil {
 		return nil, ErrMissingEngine
 	}
@@ -76,12 +142,86 @@ func New(posEngine, poaEngine consensus.Engine, transitionBlock uint64) (*Hybrid
 		return nil, ErrMissingEngine
 	}
 	// transitionBlock == 0 is valid (transition at genesis)
+	h := &Hybrid{
+		posEngine:      posEngine,
+		poaEngine:      poaEngine,
+		initialSigners: defaultInitialSigners,
+		minSigners:     DefaultMinSigners,
+		confirmDepth:   params.DefaultTransitionConfirmationDepth,
+		heartbeats:     heartbeatTracker{since: time.Now()},
+		alerts:         alertWatcher{threshold: DefaultMissedSlotAlert},
+	}
+	h.transitionBlock.Store(transitionBlock)
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.checkpoint = CheckpointExtra(h.initialSigners)
+	h.seedTakeOver()
+
+	if err := h.checkSigners(); err != nil {
+		log.Error("Refusing to create hybrid consensus engine",
+			"transitionBlock", transitionBlock,
+			"strict", h.strict,
+			"signers", h.initialSigners,
+			"error", err)
+		return nil, err
+	}
+	if err := h.checkSignerKey(); err != nil {
+		log.Error("Refusing to create hybrid consensus engine",
+			"transitionBlock", transitionBlock,
+			"signers", h.localSigners,
+			"error", err)
+		return nil, err
+	}
+	if h.db != nil && !h.readOnly {
+		// Databases predating the versioned schema may stem from legacy releases
+		// with hardcoded signers, which have to be made explicit beforehand
+		if ReadSchemaVersion(h.db) == 0 {
+			if err := h.migrateLegacyConfig(); err != nil {
+				log.Error("Refusing to create hybrid consensus engine",
+					"transitionBlock", transitionBlock,
+					"error", err)
+				return nil, err
+			}
+		}
+		if err := migrateSchema(h.db); err != nil {
+			log.Error("Refusing to create hybrid consensus engine",
+				"transitionBlock", transitionBlock,
+				"error", err)
+			return nil, err
+		}
+	}
+	if err := h.checkDrift(); err != nil {
+		log.Error("Refusing to create hybrid consensus engine",
+			"transitionBlock", transitionBlock,
+			"error", err)
+		return nil, err
+	}
+	h.runtime.load(h.db)
+	h.forks.restore(h.db)
+	h.records.restore(h.db)
+	if err := h.applyTransitionProof(); err != nil {
+		log.Error("Refusing to create hybrid consensus engine",
+			"transitionBlock", transitionBlock,
+			"error", err)
+		return nil, err
+	}
+	h.sealers.init(h.db)
+	h.holdFreezer()
+
+	if h.doubleSign != nil {
+		h.doubleSign.start(poaEngine, h.db, h.raiseAlert)
+	}
+	if h.webhook != nil {
+		h.webhook.start(h.SubscribeAlerts)
+	}
 
 	// Log startup configuration including transition parameters (Requirement 4.4)
 	log.Info("Created hybrid consensus engine",
 		"transitionBlock", transitionBlock,
-		"initialSigners", len(defaultInitialSigners),
-		"signers", defaultInitialSigners,
+		"initialSigners", len(h.initialSigners),
+		"signers", h.initialSigners,
+		"strict", h.strict,
 		"posEngine", fmt.Sprintf("%T", posEngine),
 		"poaEngine", fmt.Sprintf("%T", poaEngine))
 
@@ -90,117 +230,243 @@ func New(posEngine, poaEngine consensus.Engine, transitionBlock uint64) (*Hybrid
 		"transitionAtBlock", transitionBlock,
 		"posEngineType", fmt.Sprintf("%T", posEngine),
 		"poaEngineType", fmt.Sprintf("%T", poaEngine),
-		"initialPoAValidators", len(defaultInitialSigners))
+		"initialPoAValidators", len(h.initialSigners))
+
+	return h, nil
+}
+
+// checkSigners validates the initial signer set. Empty sets, zero and duplicate
+// addresses are always rejected, while the production-mode requirements are
+// only logged outside of strict mode.
+func (h *Hybrid) checkSigners() error {
+	if len(h.initialSigners) == 0 {
+		return ErrNoSigners
+	}
+	seen := make(map[common.Address]struct{}, len(h.initialSigners))
+	for _, signer := range h.initialSigners {
+		if signer == (common.Address{}) {
+			return ErrZeroSigner
+		}
+		if _, ok := seen[signer]; ok {
+			return fmt.Errorf("%w: %v", ErrDuplicateSigner, signer)
+		}
+		seen[signer] = struct{}{}
+	}
+	var placeholders []common.Address
+	for _, signer := range h.initialSigners {
+		if isPlaceholderSigner(signer) {
+			placeholders = append(placeholders, signer)
+		}
+	}
+	if len(placeholders) > 0 {
+		if h.strict {
+			return fmt.Errorf("%w: %v", ErrPlaceholderSigners, placeholders)
+		}
+		log.Warn("Hybrid consensus is using placeholder PoA signers, do not run this in production",
+			"placeholders", placeholders)
+	}
+	if len(h.initialSigners) < h.minSigners {
+		if h.strict {
+			return fmt.Errorf("%w: have %d, want at least %d", ErrTooFewSigners, len(h.initialSigners), h.minSigners)
+		}
+		log.Warn("Hybrid consensus has fewer initial PoA signers than recommended",
+			"have", len(h.initialSigners), "want", h.minSigners)
+	}
+	// Clique needs a strict majority to vote signers in or out, an even signer
+	// set can split evenly and deadlock governance.
+	if len(h.initialSigners)%2 == 0 {
+		if h.strict {
+			return fmt.Errorf("%w: %d", ErrEvenSigners, len(h.initialSigners))
+		}
+		log.Warn("Hybrid consensus has an even number of initial PoA signers, voting may deadlock",
+			"signers", len(h.initialSigners))
+	}
+	return nil
+}
 
-	return &Hybrid{
-		posEngine:       posEngine,
-		poaEngine:       poaEngine,
-		transitionBlock: transitionBlock,
-		initialSigners:  defaultInitialSigners,
-	}, nil
+// isPlaceholderSigner reports whether the address is one of the built-in
+// placeholder signers that must be replaced before deployment.
+func isPlaceholderSigner(addr common.Address) bool {
+	for _, placeholder := range defaultInitialSigners {
+		if addr == placeholder {
+			return true
+		}
+	}
+	return false
 }
 
 // shouldUsePoA determines whether to use PoA consensus based on the block number.
 // Returns true if the block number is >= transitionBlock, false otherwise.
 func (h *Hybrid) shouldUsePoA(blockNumber uint64) bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	usePoA := blockNumber >= h.transitionBlock
+	usePoA := schedule.PhaseAt(h, blockNumber) == phasePoA
 
 	// Log transition boundary checks for monitoring (Requirement 4.2)
-	if blockNumber == h.transitionBlock-1 || blockNumber == h.transitionBlock || blockNumber == h.transitionBlock+1 {
+	if blockNumber+1 >= h.transitionBlock.Load() && blockNumber <= h.transitionBlock.Load()+1 && log.Root().Enabled(context.Background(), log.LevelDebug) {
+		decision := "PoS"
+		if usePoA {
+			decision = "PoA"
+		}
 		log.Debug("Consensus engine decision at transition boundary",
 			"blockNumber", blockNumber,
-			"transitionBlock", h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
 			"usePoA", usePoA,
-			"decision", func() string {
-				if usePoA {
-					return "PoA"
-				}
-				return "PoS"
-			}())
+			"decision", decision)
 	}
-
 	return usePoA
 }
 
+// UsesPoA implements consensus.Transitioner, reporting whether the block with
+// the given number is verified and built by the PoA engine.
+func (h *Hybrid) UsesPoA(number uint64) bool {
+	return schedule.PhaseAt(h, number) == phasePoA
+}
+
+// TransitionBlock implements consensus.Transitioner, returning the transition
+// block, or false while a runtime triggered transition is pending.
+func (h *Hybrid) TransitionBlock() (uint64, bool) {
+	if h.TransitionPending() {
+		return 0, false
+	}
+	return h.transitionBlock.Load(), true
+}
+
+// Phases implements schedule.Schedule: the chain is governed by the PoS engine
+// up to the transition and by the PoA engine from it on.
+func (h *Hybrid) Phases() int {
+	return 2
+}
+
+// PhaseEngine implements schedule.Schedule, returning the engine of the given
+// phase as currently configured.
+func (h *Hybrid) PhaseEngine(phase int) consensus.Engine {
+	if phase == phasePoA {
+		return h.poaEngine
+	}
+	return h.posEngine
+}
+
+// Activated implements schedule.Schedule, reporting whether the given phase is
+// active at the block with the given number. The PoA phase activates at the
+// transition block, which may still be pending.
+func (h *Hybrid) Activated(phase int, number uint64) bool {
+	return phase == phasePoS || number >= h.transitionBlock.Load()
+}
+
+// EngineName returns a human readable name of the engine responsible for the
+// block with the given number, such as "beacon+clique" before the transition.
+func (h *Hybrid) EngineName(number uint64) string {
+	engine := schedule.EngineAt(h, number)
+	if lazy, ok := engine.(*lazyEngine); ok {
+		return lazy.name
+	}
+	return fmt.Sprintf("%T", engine)
+}
+
 // selectEngine returns the appropriate consensus engine based on the block number.
 // Logs engine selection and transitions as required by requirements 4.1 and 4.2.
+// It sits on the path of every delegated call, so outside of the transition
+// block and debug logging it does nothing but compare the block number.
 func (h *Hybrid) selectEngine(blockNumber uint64) consensus.Engine {
 	usePoA := h.shouldUsePoA(blockNumber)
+	if blockNumber == h.transitionBlock.Load() {
+		h.announceSwitch(blockNumber)
+	}
+	if log.Root().Enabled(context.Background(), log.LevelDebug) {
+		h.logEngineSelection(blockNumber, usePoA)
+	}
+	if usePoA {
+		return h.PhaseEngine(phasePoA)
+	}
+	return h.PhaseEngine(phasePoS)
+}
 
-	// Log consensus engine transitions (Requirement 4.1)
-	if blockNumber == h.transitionBlock && !h.transitionLogged {
-		h.transitionLogged = true
-		log.Info("Consensus engine transition occurred",
-			"blockNumber", blockNumber,
-			"transitionBlock", h.transitionBlock,
-			"from", "PoS",
-			"to", "PoA",
-			"newEngine", fmt.Sprintf("%T", h.poaEngine),
-			"timestamp", time.Now().Unix())
-
-		// Also log at warn level to ensure visibility in production logs
-		log.Warn("CONSENSUS TRANSITION: Switched from PoS to PoA consensus",
-			"atBlock", blockNumber,
-			"configuredTransitionBlock", h.transitionBlock)
+// announceSwitch logs the switch to the PoA engine once (Requirement 4.1).
+func (h *Hybrid) announceSwitch(blockNumber uint64) {
+	if h.runtime.get().Switched {
+		return
 	}
+	var switched bool
+	h.runtime.update(func(state *RuntimeState) bool {
+		switched, state.Switched = !state.Switched, true
+		return switched
+	})
+	if !switched {
+		return
+	}
+	log.Info("Consensus engine transition occurred",
+		"blockNumber", blockNumber,
+		"transitionBlock", h.transitionBlock.Load(),
+		"from", "PoS",
+		"to", "PoA",
+		"newEngine", fmt.Sprintf("%T", h.poaEngine),
+		"timestamp", time.Now().Unix())
+
+	// Also log at warn level to ensure visibility in production logs
+	log.Warn("CONSENSUS TRANSITION: Switched from PoS to PoA consensus",
+		"atBlock", blockNumber,
+		"configuredTransitionBlock", h.transitionBlock.Load())
+}
 
-	// Log which engine is being used (Requirement 4.2) with rate limiting
-	currentEngine := "PoS"
+// logEngineSelection logs which engine is being used (Requirement 4.2), rate
+// limited to every 10 seconds or whenever the engine changes.
+func (h *Hybrid) logEngineSelection(blockNumber uint64, usePoA bool) {
+	var (
+		current = "PoS"
+		engine  = h.posEngine
+	)
 	if usePoA {
-		currentEngine = "PoA"
+		current, engine = "PoA", h.poaEngine
 	}
-
-	// Rate limit logging to avoid spam - log every 10 seconds or when engine changes
 	now := time.Now()
-	if h.lastLoggedEngine != currentEngine || now.Sub(h.lastLogTime) > 10*time.Second {
-		h.lastLoggedEngine = currentEngine
-		h.lastLogTime = now
 
-		log.Debug("Using consensus engine",
-			"blockNumber", blockNumber,
-			"engine", currentEngine,
-			"engineType", func() string {
-				if usePoA {
-					return fmt.Sprintf("%T", h.poaEngine)
-				}
-				return fmt.Sprintf("%T", h.posEngine)
-			}(),
-			"transitionBlock", h.transitionBlock,
-			"blocksUntilTransition", func() int64 {
-				if blockNumber < h.transitionBlock {
-					return int64(h.transitionBlock - blockNumber)
-				}
-				return int64(blockNumber - h.transitionBlock) // blocks since transition
-			}())
+	h.mu.Lock()
+	if h.lastLoggedEngine == current && now.Sub(h.lastLogTime) <= 10*time.Second {
+		h.mu.Unlock()
+		return
 	}
+	h.lastLoggedEngine, h.lastLogTime = current, now
+	h.mu.Unlock()
 
-	if usePoA {
-		return h.poaEngine
+	// Blocks until the transition, or since it once passed
+	distance := int64(blockNumber - h.transitionBlock.Load())
+	if blockNumber < h.transitionBlock.Load() {
+		distance = int64(h.transitionBlock.Load() - blockNumber)
 	}
-	return h.posEngine
+	log.Debug("Using consensus engine",
+		"blockNumber", blockNumber,
+		"engine", current,
+		"engineType", fmt.Sprintf("%T", engine),
+		"transitionBlock", h.transitionBlock.Load(),
+		"blocksUntilTransition", distance)
 }
 
-// selectEngineFromHeader returns the appropriate consensus engine based on the header's block number.
-func (h *Hybrid) selectEngineFromHeader(header *types.Header) consensus.Engine {
-	return h.selectEngine(header.Number.Uint64())
+// selectEngineFromHeader returns the consensus engine responsible for the header
+// and whether it is the PoA engine. PoS stragglers past the transition go to the
+// PoS engine in every delegated call, not only in header verification, so that
+// their author, fees and withdrawals are the ones of the PoS chain they belong
+// to.
+func (h *Hybrid) selectEngineFromHeader(header *types.Header) (consensus.Engine, bool) {
+	if h.straggler(header) {
+		return h.posEngine, false
+	}
+	number := header.Number.Uint64()
+	return h.selectEngine(number), number >= h.transitionBlock.Load()
 }
 
 // Author implements consensus.Engine, returning the verified author of the block.
-func (h *Hybrid) Author(header *types.Header) (common.Address, error) {
+func (h *Hybrid) Author(header *types.Header) (author common.Address, err error) {
 	blockNumber := header.Number.Uint64()
-
-	// Use the correct engine based on block number, not current state
-	var engine consensus.Engine
-	if blockNumber < h.transitionBlock {
-		engine = h.posEngine
-	} else {
-		engine = h.poaEngine
+	defer h.isolatePanic(methodAuthor, blockNumber, &err)
+	if err := injectFault("Author", blockNumber); err != nil {
+		return common.Address{}, err
 	}
 
-	author, err := engine.Author(header)
+	// Use the correct engine based on the header, not current state
+	engine, usePoA := h.selectEngineFromHeader(header)
+
+	labelDelegate(usePoA, methodAuthor)
+	author, err = engine.Author(header)
+	unlabelDelegate()
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
@@ -208,7 +474,7 @@ func (h *Hybrid) Author(header *types.Header) (common.Address, error) {
 			"blockNumber", blockNumber,
 			"blockHash", header.Hash().Hex(),
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
 			"error", err)
 	}
 
@@ -217,29 +483,60 @@ func (h *Hybrid) Author(header *types.Header) (common.Address, error) {
 
 // VerifyHeader checks whether a header conforms to the consensus rules of the
 // appropriate engine based on block number.
-func (h *Hybrid) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+func (h *Hybrid) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) (err error) {
 	blockNumber := header.Number.Uint64()
+	defer h.isolatePanic(methodVerifyHeader, blockNumber, &err)
+	h.recheckSignerKey(blockNumber)
+	h.warmupPoA(blockNumber)
+	if err := injectFault("VerifyHeader", blockNumber); err != nil {
+		return err
+	}
+	if err := h.checkTerminal(chain, header); err != nil {
+		return err
+	}
+	if err := h.checkProof(header); err != nil {
+		return err
+	}
 
 	// Special handling for transition boundary: if we're verifying a PoS block
 	// but the current consensus is PoA (e.g., during chain reorg), we need to
 	// use the PoS engine for verification
-	if blockNumber < h.transitionBlock {
+	if blockNumber < h.transitionBlock.Load() {
 		// This is a PoS block, always use PoS engine regardless of current state
+		h.shadowVerify(chain, header)
+		h.monitorForks(header)
+		labelDelegate(false, methodVerifyHeader)
 		err := h.posEngine.VerifyHeader(chain, header)
+		unlabelDelegate()
+		h.dualVerify(chain, header, false, err)
 		if err != nil {
 			log.Error("PoS header verification failed",
 				"blockNumber", blockNumber,
 				"blockHash", header.Hash().Hex(),
 				"engine", fmt.Sprintf("%T", h.posEngine),
-				"transitionBlock", h.transitionBlock,
+				"transitionBlock", h.transitionBlock.Load(),
 				"error", err)
 		}
 		return err
 	}
 
+	// PoS blocks straggling past the transition are subject to the grace window
+	if h.straggler(header) {
+		return h.verifyStraggler(chain, header)
+	}
 	// For blocks at or after transition, use PoA engine
+	h.retirePoS(blockNumber)
+	h.doubleSign.observe(header)
+	h.watchTransition(chain, header)
+	h.monitorForks(header)
 	engine := h.poaEngine
-	err := engine.VerifyHeader(chain, header)
+	labelDelegate(true, methodVerifyHeader)
+	err = h.classifyError(chain, header, engine.VerifyHeader(chain, header))
+	unlabelDelegate()
+	h.dualVerify(chain, header, true, err)
+	if err == nil {
+		err = verifyGasCeiling(chain, header, nil)
+	}
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
@@ -247,8 +544,8 @@ func (h *Hybrid) VerifyHeader(chain consensus.ChainHeaderReader, header *types.H
 			"blockNumber", blockNumber,
 			"blockHash", header.Hash().Hex(),
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
-			"isAfterTransition", blockNumber >= h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
+			"isAfterTransition", blockNumber >= h.transitionBlock.Load(),
 			"error", err)
 	}
 
@@ -257,7 +554,7 @@ func (h *Hybrid) VerifyHeader(chain consensus.ChainHeaderReader, header *types.H
 
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
 // concurrently using the appropriate engine for each header.
-func (h *Hybrid) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+func (h *Hybrid) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (abort chan<- struct{}, results <-chan error) {
 	if len(headers) == 0 {
 		// Return channels that immediately close for empty input
 		quit := make(chan struct{})
@@ -271,56 +568,85 @@ func (h *Hybrid) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*typ
 	firstBlock := headers[0].Number.Uint64()
 	lastBlock := headers[len(headers)-1].Number.Uint64()
 
-	// If all headers are before transition, use PoS engine
-	if lastBlock < h.transitionBlock {
+	// Panics of the engines while setting up the verification fail the batch,
+	// the ones of their verifier goroutines are beyond reach
+	defer func() {
+		if value := recover(); value != nil {
+			abort, results = failHeaders(len(headers), h.enginePanic(methodVerifyHeaders, firstBlock, value))
+		}
+	}()
+	h.recheckSignerKey(lastBlock)
+	h.warmupPoA(lastBlock)
+	if err := injectFault("VerifyHeaders", firstBlock); err != nil {
+		return failHeaders(len(headers), err)
+	}
+
+	// If all headers are before transition, use PoS engine. Batches touching the
+	// dual verification window, containing PoS stragglers past the transition or
+	// blocks checked against the terminal PoS block take the per-header path below.
+	perHeader := h.checkTerminals(chain, headers) || h.checkProofs(headers) || h.dual.covers(firstBlock, lastBlock, h.transitionBlock.Load()) || h.stragglers(headers)
+	if lastBlock < h.transitionBlock.Load() && !perHeader {
+		for _, header := range headers {
+			h.shadowVerify(chain, header)
+		}
+		h.monitorForks(headers...)
+		// The verifier goroutines of the engine inherit the labels
+		labelDelegate(false, methodVerifyHeaders)
+		defer unlabelDelegate()
 		return h.posEngine.VerifyHeaders(chain, headers)
 	}
 
 	// If all headers are at or after transition, use PoA engine
-	if firstBlock >= h.transitionBlock {
-		return h.poaEngine.VerifyHeaders(chain, headers)
+	if firstBlock >= h.transitionBlock.Load() && !perHeader {
+		h.retirePoS(lastBlock)
+		h.doubleSign.observe(headers...)
+		h.watchTransition(chain, headers[0])
+		h.monitorForks(headers...)
+		labelDelegate(true, methodVerifyHeaders)
+		abort, results := h.poaEngine.VerifyHeaders(chain, headers)
+		unlabelDelegate()
+		if len(chain.Config().PoAGasCeilings) == 0 {
+			return abort, results
+		}
+		return verifyGasCeilings(chain, headers, abort, results)
 	}
 
-	// Headers span the transition boundary - we need to split them
-	// and verify each group with the appropriate engine
-	quit := make(chan struct{})
-	results := make(chan error, len(headers))
-
-	go func() {
-		defer close(results)
-
-		for _, header := range headers {
-			select {
-			case <-quit:
-				return
-			default:
-				err := h.VerifyHeader(chain, header)
-				select {
-				case results <- err:
-				case <-quit:
-					return
-				}
-			}
-		}
-	}()
+	// Headers span the transition boundary - split them and verify each part
+	// with the appropriate engine
+	if !perHeader {
+		return h.verifySplit(chain, headers)
+	}
+	// Headers need dual verification or contain stragglers, done header by header
+	return h.verifyParallel(chain, headers, runtime.GOMAXPROCS(0))
+}
 
+// failHeaders returns verification channels failing each of the given number of
+// headers with the given error.
+func failHeaders(n int, err error) (chan<- struct{}, <-chan error) {
+	quit := make(chan struct{})
+	results := make(chan error, n)
+	for range n {
+		results <- err
+	}
+	close(results)
 	return quit, results
 }
 
 // VerifyUncles verifies that the given block's uncles conform to the consensus
 // rules of the appropriate engine.
-func (h *Hybrid) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
-	blockNumber := block.Number().Uint64()
-
-	// Use the correct engine based on block number, not current state
-	var engine consensus.Engine
-	if blockNumber < h.transitionBlock {
-		engine = h.posEngine
-	} else {
-		engine = h.poaEngine
+func (h *Hybrid) VerifyUncles(chain consensus.ChainReader, block *types.Block) (err error) {
+	blockNumber := block.NumberU64()
+	defer h.isolatePanic(methodVerifyUncles, blockNumber, &err)
+	if err := injectFault("VerifyUncles", blockNumber); err != nil {
+		return err
 	}
 
-	err := engine.VerifyUncles(chain, block)
+	// Use the correct engine based on the header, not current state
+	engine, usePoA := h.selectEngineFromHeader(block.Header())
+
+	labelDelegate(usePoA, methodVerifyUncles)
+	err = engine.VerifyUncles(chain, block)
+	unlabelDelegate()
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
@@ -328,8 +654,8 @@ func (h *Hybrid) VerifyUncles(chain consensus.ChainReader, block *types.Block) e
 			"blockNumber", blockNumber,
 			"blockHash", block.Hash().Hex(),
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
-			"isAfterTransition", blockNumber >= h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
+			"isAfterTransition", blockNumber >= h.transitionBlock.Load(),
 			"uncleCount", len(block.Uncles()),
 			"error", err)
 	}
@@ -339,14 +665,29 @@ func (h *Hybrid) VerifyUncles(chain consensus.ChainReader, block *types.Block) e
 
 // Prepare initializes the consensus fields of a block header according to the
 // rules of the appropriate engine.
-func (h *Hybrid) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+func (h *Hybrid) Prepare(chain consensus.ChainHeaderReader, header *types.Header) (err error) {
 	blockNumber := header.Number.Uint64()
-
+	defer h.isolatePanic(methodPrepare, blockNumber, &err)
+	h.recheckSignerKey(blockNumber)
+	h.warmupPoA(blockNumber)
+	h.retirePoS(blockNumber)
+	if err := injectFault("Prepare", blockNumber); err != nil {
+		return err
+	}
+	if err := h.checkTerminal(chain, header); err != nil {
+		return err
+	}
+	if err := h.checkProof(header); err != nil {
+		return err
+	}
+	if err := h.sealPolicy().CheckPrepare(header); err != nil {
+		return err
+	}
 	// Check if this is the transition block - if so, we need to set up initial signers
-	if blockNumber == h.transitionBlock {
+	if blockNumber == h.transitionBlock.Load() {
 		log.Info("Preparing PoS to PoA transition block",
 			"blockNumber", blockNumber,
-			"transitionBlock", h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
 			"initialSigners", len(h.initialSigners),
 			"signers", h.initialSigners)
 
@@ -358,18 +699,24 @@ func (h *Hybrid) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 		return h.prepareTransitionBlock(chain, header)
 	}
 
-	engine := h.selectEngineFromHeader(header)
-	err := engine.Prepare(chain, header)
+	// Proposals persisted by a previous run are voted on from the first PoA block
+	if blockNumber >= h.transitionBlock.Load() {
+		h.proposer()
+	}
+	engine, usePoA := h.selectEngineFromHeader(header)
+	labelDelegate(usePoA, methodPrepare)
+	err = engine.Prepare(chain, header)
+	unlabelDelegate()
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
 		log.Error("Block preparation failed",
 			"blockNumber", blockNumber,
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
-			"isAfterTransition", blockNumber >= h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
+			"isAfterTransition", blockNumber >= h.transitionBlock.Load(),
 			"blocksFromTransition", func() int64 {
-				return int64(blockNumber) - int64(h.transitionBlock)
+				return int64(blockNumber) - int64(h.transitionBlock.Load())
 			}(),
 			"error", err)
 	}
@@ -378,24 +725,45 @@ func (h *Hybrid) Prepare(chain consensus.ChainHeaderReader, header *types.Header
 }
 
 // Finalize runs any post-transaction state modifications using the appropriate engine.
+// It has no error result to report a panic of the engine with, so panics are not
+// isolated, see isolatePanic.
 func (h *Hybrid) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state vm.StateDB, body *types.Body) {
-	engine := h.selectEngineFromHeader(header)
+	blockNumber := header.Number.Uint64()
+	injectFault("Finalize", blockNumber) // Finalize can't fail, only delays and panics apply
+	engine, usePoA := h.selectEngineFromHeader(header)
+	labelDelegate(usePoA, methodFinalize)
 	engine.Finalize(chain, header, state, body)
+	unlabelDelegate()
+
+	// Stragglers are not part of the chain the transition watchers follow
+	if !usePoA && blockNumber >= h.transitionBlock.Load() {
+		return
+	}
+	h.watchBlock(chain, header)
+	h.watchSignerSet(chain, header)
+	h.watchEpoch(chain, header)
 }
 
 // FinalizeAndAssemble runs any post-transaction state modifications and assembles
 // the final block using the appropriate engine.
-func (h *Hybrid) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, body *types.Body, receipts []*types.Receipt) (*types.Block, error) {
-	engine := h.selectEngineFromHeader(header)
-	block, err := engine.FinalizeAndAssemble(chain, header, state, body, receipts)
+func (h *Hybrid) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, body *types.Body, receipts []*types.Receipt) (block *types.Block, err error) {
+	blockNumber := header.Number.Uint64()
+	defer h.isolatePanic(methodFinalizeAndAssemble, blockNumber, &err)
+	if err := injectFault("FinalizeAndAssemble", blockNumber); err != nil {
+		return nil, err
+	}
+	engine, usePoA := h.selectEngineFromHeader(header)
+	labelDelegate(usePoA, methodFinalizeAndAssemble)
+	block, err = engine.FinalizeAndAssemble(chain, header, state, body, receipts)
+	unlabelDelegate()
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
 		log.Error("Block finalization and assembly failed",
-			"blockNumber", header.Number.Uint64(),
+			"blockNumber", blockNumber,
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
-			"isAfterTransition", header.Number.Uint64() >= h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
+			"isAfterTransition", blockNumber >= h.transitionBlock.Load(),
 			"txCount", len(body.Transactions),
 			"receiptCount", len(receipts),
 			"error", err)
@@ -406,26 +774,39 @@ func (h *Hybrid) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *
 
 // Seal generates a new sealing request for the given input block using the
 // appropriate engine.
-func (h *Hybrid) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
-	engine := h.selectEngineFromHeader(block.Header())
+func (h *Hybrid) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) (err error) {
+	blockNumber := block.NumberU64()
+	defer h.isolatePanic(methodSeal, blockNumber, &err)
+	if err := injectFault("Seal", blockNumber); err != nil {
+		return err
+	}
+	if err := h.sealPolicy().CheckSeal(chain, block.Header()); err != nil {
+		log.Error("Refusing to seal block styled after the wrong era", "blockNumber", blockNumber,
+			"transitionBlock", h.transitionBlock.Load(), "difficulty", block.Difficulty(), "error", err)
+		return err
+	}
+	engine, usePoA := h.selectEngineFromHeader(block.Header())
 
 	log.Debug("Sealing block",
-		"blockNumber", block.Number().Uint64(),
+		"blockNumber", blockNumber,
 		"blockHash", block.Hash().Hex(),
 		"engine", fmt.Sprintf("%T", engine),
-		"transitionBlock", h.transitionBlock,
-		"isAfterTransition", block.Number().Uint64() >= h.transitionBlock)
+		"transitionBlock", h.transitionBlock.Load(),
+		"isAfterTransition", usePoA)
 
-	err := engine.Seal(chain, block, results, stop)
+	// The sealing goroutines of the engine inherit the labels
+	labelDelegate(usePoA, methodSeal)
+	err = engine.Seal(chain, block, results, stop)
+	unlabelDelegate()
 
 	// Log detailed error information for transition-related failures (Requirement 4.3)
 	if err != nil {
 		log.Error("Block sealing failed",
-			"blockNumber", block.Number().Uint64(),
+			"blockNumber", blockNumber,
 			"blockHash", block.Hash().Hex(),
 			"engine", fmt.Sprintf("%T", engine),
-			"transitionBlock", h.transitionBlock,
-			"isAfterTransition", block.Number().Uint64() >= h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
+			"isAfterTransition", usePoA,
 			"error", err)
 	}
 
@@ -433,25 +814,35 @@ func (h *Hybrid) Seal(chain consensus.ChainHeaderReader, block *types.Block, res
 }
 
 // SealHash returns the hash of a block prior to it being sealed using the
-// appropriate engine.
+// appropriate engine. Panics of the engine are not isolated, see isolatePanic.
 func (h *Hybrid) SealHash(header *types.Header) common.Hash {
-	engine := h.selectEngineFromHeader(header)
+	engine, usePoA := h.selectEngineFromHeader(header)
+
+	labelDelegate(usePoA, methodSealHash)
+	defer unlabelDelegate()
 	return engine.SealHash(header)
 }
 
 // CalcDifficulty is the difficulty adjustment algorithm using the appropriate engine.
+// Panics of the engine are not isolated, see isolatePanic.
 func (h *Hybrid) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
 	// For difficulty calculation, we need to determine which engine to use.
-	// We use the parent block number + 1 to determine the engine for the new block.
+	// We use the parent block number + 1 to determine the engine for the new block,
+	// unless the parent is a PoS straggler, which only the PoS chain builds on.
 	nextBlockNumber := parent.Number.Uint64() + 1
-	engine := h.selectEngine(nextBlockNumber)
+	engine, usePoA := h.selectEngine(nextBlockNumber), nextBlockNumber >= h.transitionBlock.Load()
+	if h.straggler(parent) {
+		engine, usePoA = h.posEngine, false
+	}
+	labelDelegate(usePoA, methodCalcDifficulty)
+	defer unlabelDelegate()
 	return engine.CalcDifficulty(chain, time, parent)
 }
 
 // Close terminates any background threads maintained by both consensus engines.
 func (h *Hybrid) Close() error {
 	log.Info("Closing hybrid consensus engine",
-		"transitionBlock", h.transitionBlock,
+		"transitionBlock", h.transitionBlock.Load(),
 		"posEngine", fmt.Sprintf("%T", h.posEngine),
 		"poaEngine", fmt.Sprintf("%T", h.poaEngine))
 
@@ -474,6 +865,15 @@ func (h *Hybrid) Close() error {
 		}
 	}
 
+	if h.doubleSign != nil {
+		h.doubleSign.stop()
+	}
+	if h.webhook != nil {
+		h.webhook.stop()
+	}
+	if h.consistency != nil {
+		h.consistency.stop()
+	}
 	// Return the first error encountered, if any
 	if err1 != nil {
 		return err1
@@ -481,6 +881,49 @@ func (h *Hybrid) Close() error {
 	return err2
 }
 
+// CheckpointExtra returns the extraData of a clique checkpoint block listing the
+// given signers, with an empty vanity and a zero seal:
+// [32 bytes vanity] + [N * 20 bytes addresses] + [65 bytes seal]
+func CheckpointExtra(signers []common.Address) []byte {
+	extra := make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal)
+	for i, signer := range signers {
+		copy(extra[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	return extra
+}
+
+// transitionExtra returns the extraData of the transition block, keeping the
+// vanity of the current extraData. The checkpoint is the prebuilt extraData of
+// the block, which is only read. ExtraData that already lists the signers of the
+// checkpoint is returned as is, without allocating.
+func transitionExtra(extra []byte, checkpoint []byte) []byte {
+	if len(extra) == len(checkpoint) && bytes.Equal(extra[extraVanity:len(extra)-extraSeal], checkpoint[extraVanity:len(checkpoint)-extraSeal]) {
+		return extra
+	}
+	// The current extraData may be shared with the miner configuration, build
+	// the new one in a fresh buffer
+	want := bytes.Clone(checkpoint)
+	copy(want[:extraVanity], extra)
+	return want
+}
+
+// checkpointSigners returns the signers listed in the extraData of a clique
+// checkpoint block.
+func checkpointSigners(extra []byte) ([]common.Address, error) {
+	if len(extra) < extraVanity+extraSeal {
+		return nil, fmt.Errorf("extra-data too short: %d bytes", len(extra))
+	}
+	section := extra[extraVanity : len(extra)-extraSeal]
+	if len(section)%common.AddressLength != 0 {
+		return nil, fmt.Errorf("invalid signer section length: %d bytes", len(section))
+	}
+	signers := make([]common.Address, len(section)/common.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], section[i*common.AddressLength:])
+	}
+	return signers, nil
+}
+
 // prepareTransitionBlock prepares the transition block by setting up initial signers in extraData.
 // This block becomes a checkpoint block for the PoA consensus.
 func (h *Hybrid) prepareTransitionBlock(chain consensus.ChainHeaderReader, header *types.Header) error {
@@ -488,26 +931,26 @@ func (h *Hybrid) prepareTransitionBlock(chain consensus.ChainHeaderReader, heade
 
 	log.Info("Starting transition block preparation",
 		"blockNumber", blockNumber,
-		"transitionBlock", h.transitionBlock,
+		"transitionBlock", h.transitionBlock.Load(),
 		"initialSignerCount", len(h.initialSigners))
 
-	// Constants from clique package
-	const (
-		extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for signer vanity
-		extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for signer seal (crypto.SignatureLength)
-	)
-
-	// Create extraData with initial signers
-	// Format: [32 bytes vanity] + [N * 20 bytes addresses] + [65 bytes seal]
-	extraData := make([]byte, extraVanity+len(h.initialSigners)*common.AddressLength+extraSeal)
-
-	// Copy signers into extraData
-	for i, signer := range h.initialSigners {
-		copy(extraData[extraVanity+i*common.AddressLength:], signer[:])
-		log.Debug("Added initial signer to transition block",
-			"index", i,
-			"signer", signer.Hex(),
-			"blockNumber", blockNumber)
+	// Create extraData with initial signers, keeping the vanity set by the miner
+	// unless a builder contributes its own. Re-preparing the same header leaves
+	// it unchanged.
+	extraData, err := h.buildTransitionExtra(header)
+	if err != nil {
+		log.Error("Failed to build transition block extra-data",
+			"blockNumber", blockNumber,
+			"error", err)
+		return err
+	}
+	if log.Root().Enabled(context.Background(), log.LevelDebug) {
+		for i, signer := range h.initialSigners {
+			log.Debug("Added initial signer to transition block",
+				"index", i,
+				"signer", signer.Hex(),
+				"blockNumber", blockNumber)
+		}
 	}
 
 	header.Extra = extraData
@@ -519,12 +962,14 @@ func (h *Hybrid) prepareTransitionBlock(chain consensus.ChainHeaderReader, heade
 		"extraDataLength", len(extraData))
 
 	// Use PoA engine to prepare the rest of the header
-	err := h.poaEngine.Prepare(chain, header)
+	labelDelegate(true, methodPrepare)
+	err = h.poaEngine.Prepare(chain, header)
+	unlabelDelegate()
 	if err != nil {
 		// Log detailed error information for transition-related failures (Requirement 4.3)
 		log.Error("Failed to prepare transition block with PoA engine",
 			"blockNumber", blockNumber,
-			"transitionBlock", h.transitionBlock,
+			"transitionBlock", h.transitionBlock.Load(),
 			"signerCount", len(h.initialSigners),
 			"error", err)
 		return err
@@ -17,6 +17,7 @@
 package hybrid
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/schedule"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -211,8 +213,8 @@ func TestNew(t *testing.T) {
 	if hybrid == nil {
 		t.Fatal("Expected hybrid engine, got nil")
 	}
-	if hybrid.transitionBlock != transitionBlock {
-		t.Errorf("Expected transition block %d, got %d", transitionBlock, hybrid.transitionBlock)
+	if hybrid.transitionBlock.Load() != transitionBlock {
+		t.Errorf("Expected transition block %d, got %d", transitionBlock, hybrid.transitionBlock.Load())
 	}
 	if len(hybrid.initialSigners) == 0 {
 		t.Error("Expected hardcoded initial signers, got empty list")
@@ -236,6 +238,82 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestStrictMode(t *testing.T) {
+	posEngine := &mockEngine{name: "pos"}
+	poaEngine := &mockEngine{name: "poa"}
+	signers := []common.Address{
+		common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		common.HexToAddress("0x00000000000000000000000000000000000000a2"),
+		common.HexToAddress("0x00000000000000000000000000000000000000a3"),
+	}
+
+	// Placeholder signers are tolerated outside of strict mode
+	if _, err := New(posEngine, poaEngine, 100); err != nil {
+		t.Fatalf("Expected no error in non-strict mode, got %v", err)
+	}
+	// Placeholder signers must be rejected in strict mode
+	if _, err := New(posEngine, poaEngine, 100, WithStrict(true)); !errors.Is(err, ErrPlaceholderSigners) {
+		t.Errorf("Expected ErrPlaceholderSigners, got %v", err)
+	}
+	mixed := append([]common.Address{defaultInitialSigners[0]}, signers[1:]...)
+	if _, err := New(posEngine, poaEngine, 100, WithStrict(true), WithInitialSigners(mixed)); !errors.Is(err, ErrPlaceholderSigners) {
+		t.Errorf("Expected ErrPlaceholderSigners for partially replaced set, got %v", err)
+	}
+	// Real signers meeting the minimum are accepted
+	h, err := New(posEngine, poaEngine, 100, WithStrict(true), WithInitialSigners(signers))
+	if err != nil {
+		t.Fatalf("Expected no error with real signers, got %v", err)
+	}
+	if len(h.initialSigners) != len(signers) || h.initialSigners[0] != signers[0] {
+		t.Errorf("Expected configured signers %v, got %v", signers, h.initialSigners)
+	}
+	// Too few signers are rejected in strict mode only
+	if _, err := New(posEngine, poaEngine, 100, WithStrict(true), WithInitialSigners(signers[:2])); !errors.Is(err, ErrTooFewSigners) {
+		t.Errorf("Expected ErrTooFewSigners, got %v", err)
+	}
+	if _, err := New(posEngine, poaEngine, 100, WithStrict(true), WithMinSigners(1), WithInitialSigners(signers[:1])); err != nil {
+		t.Errorf("Expected no error with lowered minimum, got %v", err)
+	}
+	if _, err := New(posEngine, poaEngine, 100, WithInitialSigners(signers[:1])); err != nil {
+		t.Errorf("Expected no error for too few signers in non-strict mode, got %v", err)
+	}
+}
+
+func TestSignerSetValidation(t *testing.T) {
+	posEngine := &mockEngine{name: "pos"}
+	poaEngine := &mockEngine{name: "poa"}
+	var (
+		a = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+		b = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+		c = common.HexToAddress("0x00000000000000000000000000000000000000a3")
+		d = common.HexToAddress("0x00000000000000000000000000000000000000a4")
+	)
+	tests := []struct {
+		name    string
+		signers []common.Address
+		strict  bool
+		err     error
+	}{
+		{"zero address", []common.Address{a, {}, c}, false, ErrZeroSigner},
+		{"duplicate address", []common.Address{a, b, a}, false, ErrDuplicateSigner},
+		{"even count non-strict", []common.Address{a, b, c, d}, false, nil},
+		{"even count strict", []common.Address{a, b, c, d}, true, ErrEvenSigners},
+		{"odd count strict", []common.Address{a, b, c}, true, nil},
+	}
+	for _, tt := range tests {
+		_, err := New(posEngine, poaEngine, 100, WithStrict(tt.strict), WithInitialSigners(tt.signers))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+	// An empty set can't be configured through the options, but must still be
+	// caught if the engine is assembled by hand
+	h := &Hybrid{posEngine: posEngine, poaEngine: poaEngine}
+	if err := h.checkSigners(); !errors.Is(err, ErrNoSigners) {
+		t.Errorf("Expected ErrNoSigners, got %v", err)
+	}
+}
+
 func TestShouldUsePoA(t *testing.T) {
 	posEngine := &mockEngine{name: "pos"}
 	poaEngine := &mockEngine{name: "poa"}
@@ -296,6 +374,50 @@ func TestSelectEngine(t *testing.T) {
 		t.Error("Expected PoA engine for block after transition")
 	}
 }
+
+func TestSchedule(t *testing.T) {
+	posEngine := &mockEngine{name: "pos"}
+	poaEngine := &mockEngine{name: "poa"}
+
+	hybrid, err := New(posEngine, poaEngine, PendingTransition)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer hybrid.Close()
+
+	// A pending transition keeps the whole chain in the PoS phase
+	if engine := schedule.EngineAt(hybrid, PendingTransition-1); engine != posEngine {
+		t.Errorf("Expected PoS engine with pending transition, got %v", engine)
+	}
+	hybrid.transitionBlock.Store(100)
+	for _, test := range []struct {
+		number     uint64
+		engine     consensus.Engine
+		activation bool
+	}{
+		{0, posEngine, false},
+		{99, posEngine, false},
+		{100, poaEngine, true},
+		{101, poaEngine, false},
+	} {
+		if engine := schedule.EngineAt(hybrid, test.number); engine != test.engine {
+			t.Errorf("Block %d: engine mismatch: have %v, want %v", test.number, engine, test.engine)
+		}
+		if activation := schedule.IsActivation(hybrid, test.number); activation != test.activation {
+			t.Errorf("Block %d: activation mismatch: have %v, want %v", test.number, activation, test.activation)
+		}
+		if usesPoA := hybrid.UsesPoA(test.number); usesPoA != (test.engine == poaEngine) {
+			t.Errorf("Block %d: UsesPoA mismatch: have %v", test.number, usesPoA)
+		}
+	}
+	// Engines replaced after creation are routed to right away
+	replacement := &mockEngine{name: "replacement"}
+	hybrid.poaEngine = replacement
+	if engine := hybrid.selectEngine(150); engine != replacement {
+		t.Errorf("Expected replaced PoA engine, got %v", engine)
+	}
+}
+
 func TestPrepareTransitionBlock(t *testing.T) {
 	posEngine := &mockEngine{name: "pos"}
 	poaEngine := &mockEngine{name: "poa"}
@@ -342,6 +464,50 @@ func TestPrepareTransitionBlock(t *testing.T) {
 	}
 }
 
+// Tests that preparing the transition block repeatedly, as the miner does when
+// it rebuilds a work item, keeps the vanity and leaves the header unchanged.
+func TestPrepareTransitionIdempotent(t *testing.T) {
+	hybrid, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	vanity := bytes.Repeat([]byte{0x42}, extraVanity)
+	header := &types.Header{
+		Number: big.NewInt(100),
+		Extra:  append([]byte{}, vanity...),
+	}
+	chain := &mockChainReader{}
+	if err := hybrid.Prepare(chain, header); err != nil {
+		t.Fatalf("Failed to prepare transition block: %v", err)
+	}
+	if !bytes.Equal(header.Extra[:extraVanity], vanity) {
+		t.Errorf("Vanity clobbered: have %x, want %x", header.Extra[:extraVanity], vanity)
+	}
+	want := common.CopyBytes(header.Extra)
+	first := &header.Extra[0]
+
+	for i := 0; i < 3; i++ {
+		if err := hybrid.Prepare(chain, header); err != nil {
+			t.Fatalf("Prepare %d failed: %v", i, err)
+		}
+		if !bytes.Equal(header.Extra, want) {
+			t.Fatalf("Prepare %d changed extra-data: have %x, want %x", i, header.Extra, want)
+		}
+		if &header.Extra[0] != first {
+			t.Errorf("Prepare %d rebuilt an already correct extra-data", i)
+		}
+	}
+	// A header listing the wrong signers is rebuilt, still keeping the vanity
+	header.Extra = CheckpointExtra([]common.Address{{0xff}})
+	copy(header.Extra, vanity)
+	if err := hybrid.Prepare(chain, header); err != nil {
+		t.Fatalf("Failed to prepare transition block: %v", err)
+	}
+	if !bytes.Equal(header.Extra, want) {
+		t.Errorf("Signers not rebuilt: have %x, want %x", header.Extra, want)
+	}
+}
+
 // mockChainReader is a simple mock implementation for testing
 type mockChainReader struct{}
 
@@ -643,8 +809,8 @@ func TestConsensusInterfaceDelegation(t *testing.T) {
 	}
 
 	// Create test data
-	header := &types.Header{Number: big.NewInt(50)}       // Before transition
-	headerAfter := &types.Header{Number: big.NewInt(150)} // After transition
+	header := &types.Header{Number: big.NewInt(50)}                                  // Before transition
+	headerAfter := &types.Header{Number: big.NewInt(150), Difficulty: big.NewInt(2)} // After transition, PoA styled
 	block := types.NewBlock(header, &types.Body{}, nil, nil)
 	blockAfter := types.NewBlock(headerAfter, &types.Body{}, nil, nil)
 	chain := &mockChainReader{}
@@ -921,8 +1087,8 @@ func TestErrorPropagation(t *testing.T) {
 	}
 
 	// Test data
-	header := &types.Header{Number: big.NewInt(50)}       // Before transition
-	headerAfter := &types.Header{Number: big.NewInt(150)} // After transition
+	header := &types.Header{Number: big.NewInt(50)}                                  // Before transition
+	headerAfter := &types.Header{Number: big.NewInt(150), Difficulty: big.NewInt(2)} // After transition, PoA styled
 	block := types.NewBlock(header, &types.Body{}, nil, nil)
 	blockAfter := types.NewBlock(headerAfter, &types.Body{}, nil, nil)
 	chain := &mockChainReader{}
@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// ErrEnginePanic is returned if a wrapped engine panicked in a delegated call.
+var ErrEnginePanic = errors.New("consensus engine panicked")
+
+var enginePanicCounter = metrics.NewRegisteredCounter("hybrid/engine/panics", nil)
+
+// EnginePanic is a panic of a wrapped engine in a delegated call, converted
+// into an error failing the call.
+type EnginePanic struct {
+	Engine string      // Engine responsible for the block, as named by EngineName
+	Method string      // Delegated method, e.g. "VerifyHeader"
+	Number uint64      // Number of the block the call was for
+	Value  interface{} // Value the engine panicked with
+}
+
+// Error implements error.
+func (e *EnginePanic) Error() string {
+	return fmt.Sprintf("%v: %s in %s at block %d: %v", ErrEnginePanic, e.Engine, e.Method, e.Number, e.Value)
+}
+
+// Unwrap returns ErrEnginePanic.
+func (e *EnginePanic) Unwrap() error { return ErrEnginePanic }
+
+// isolatePanic recovers from a panic in a delegated call for the block with the
+// given number, failing the call with an EnginePanic instead. It has to be
+// deferred directly by the delegating method for recover to take effect.
+//
+// Only the calls able to report a failure are isolated. Finalize, SealHash and
+// CalcDifficulty have no error result, so a recovered panic could only be
+// replaced by a made up result: a partially finalized state, a zero seal hash
+// or a missing difficulty. Each of these would silently corrupt the block
+// instead of failing it, so panics in those calls are left to propagate.
+func (h *Hybrid) isolatePanic(method delegateMethod, number uint64, err *error) {
+	if value := recover(); value != nil {
+		*err = h.enginePanic(method, number, value)
+	}
+}
+
+// enginePanic converts the value a wrapped engine panicked with into an error,
+// reporting it, so that a bug in one engine fails the block at hand rather
+// than bringing down the node.
+func (h *Hybrid) enginePanic(method delegateMethod, number uint64, value interface{}) error {
+	unlabelDelegate() // The panic skipped the unlabelling of the call
+
+	err := &EnginePanic{Engine: h.EngineName(number), Method: methodNames[method], Number: number, Value: value}
+	enginePanicCounter.Inc(1)
+	log.Error("Recovered from consensus engine panic", "engine", err.Engine, "method", err.Method,
+		"number", number, "panic", value, "stack", string(debug.Stack()))
+	h.raiseAlert(Alert{Kind: AlertEnginePanic, Number: number, Message: err.Error()})
+	return err
+}
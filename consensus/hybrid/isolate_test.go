@@ -0,0 +1,128 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// panickingMockEngine is a mock engine panicking in every delegated call that
+// can fail.
+type panickingMockEngine struct {
+	mockEngine
+}
+
+func (m *panickingMockEngine) Author(header *types.Header) (common.Address, error) {
+	panic("author")
+}
+
+func (m *panickingMockEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	panic("verify header")
+}
+
+func (m *panickingMockEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	panic("verify headers")
+}
+
+func (m *panickingMockEngine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	panic("verify uncles")
+}
+
+func (m *panickingMockEngine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	panic("prepare")
+}
+
+func (m *panickingMockEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, body *types.Body, receipts []*types.Receipt) (*types.Block, error) {
+	panic("finalize and assemble")
+}
+
+func (m *panickingMockEngine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	panic("seal")
+}
+
+func TestPanicIsolation(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &panickingMockEngine{}, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+
+	alerts := make(chan Alert, 16)
+	sub := h.SubscribeAlerts(alerts)
+	defer sub.Unsubscribe()
+
+	var (
+		chain  = &mockChainReader{}
+		header = &types.Header{Number: big.NewInt(150), Difficulty: big.NewInt(2)}
+		block  = types.NewBlockWithHeader(header)
+	)
+	calls := map[string]func() error{
+		"Author": func() error {
+			_, err := h.Author(header)
+			return err
+		},
+		"VerifyHeader": func() error { return h.VerifyHeader(chain, header) },
+		"VerifyHeaders": func() error {
+			_, results := h.VerifyHeaders(chain, []*types.Header{header, header})
+			var err error
+			for result := range results {
+				if result == nil {
+					return errors.New("header passed verification")
+				}
+				err = result
+			}
+			return err
+		},
+		"VerifyUncles": func() error { return h.VerifyUncles(nil, block) },
+		"Prepare":      func() error { return h.Prepare(chain, header) },
+		"FinalizeAndAssemble": func() error {
+			_, err := h.FinalizeAndAssemble(chain, header, nil, &types.Body{}, nil)
+			return err
+		},
+		"Seal": func() error { return h.Seal(chain, block, make(chan *types.Block, 1), nil) },
+	}
+	for method, call := range calls {
+		err := call()
+		if !errors.Is(err, ErrEnginePanic) {
+			t.Errorf("%s: error mismatch: have %v, want %v", method, err, ErrEnginePanic)
+			continue
+		}
+		var panicked *EnginePanic
+		if !errors.As(err, &panicked) || panicked.Method != method || panicked.Number != 150 || panicked.Engine != h.EngineName(150) {
+			t.Errorf("%s: panic not annotated: %+v", method, panicked)
+		}
+		select {
+		case alert := <-alerts:
+			if alert.Kind != AlertEnginePanic || alert.Number != 150 {
+				t.Errorf("%s: alert mismatch: %+v", method, alert)
+			}
+		default:
+			t.Errorf("%s: no alert raised", method)
+		}
+	}
+	// Calls to the healthy engine are unaffected
+	if err := h.VerifyHeader(chain, &types.Header{Number: big.NewInt(50)}); err != nil {
+		t.Errorf("PoS verification failed: %v", err)
+	}
+}
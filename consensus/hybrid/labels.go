@@ -0,0 +1,74 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// delegateMethod enumerates the consensus methods delegated to the wrapped
+// engines, for labelling them in CPU profiles.
+type delegateMethod int
+
+const (
+	methodAuthor delegateMethod = iota
+	methodVerifyHeader
+	methodVerifyHeaders
+	methodVerifyUncles
+	methodPrepare
+	methodFinalize
+	methodFinalizeAndAssemble
+	methodSeal
+	methodSealHash
+	methodCalcDifficulty
+	methodCount
+)
+
+var methodNames = [methodCount]string{
+	"Author", "VerifyHeader", "VerifyHeaders", "VerifyUncles", "Prepare",
+	"Finalize", "FinalizeAndAssemble", "Seal", "SealHash", "CalcDifficulty",
+}
+
+// delegateLabels holds the profiler label sets of every delegated call by era
+// (0 = pos, 1 = poa) and method. They are built once, so that labelling calls
+// on the hot path does not allocate.
+var delegateLabels = func() (labels [2][methodCount]context.Context) {
+	for era, name := range []string{"pos", "poa"} {
+		for method := range methodCount {
+			labels[era][method] = pprof.WithLabels(context.Background(), pprof.Labels("engine", name, "method", methodNames[method]))
+		}
+	}
+	return labels
+}()
+
+// labelDelegate labels the calling goroutine, and the goroutines it starts, with
+// the engine and method of a delegated call, so CPU profiles attribute the time
+// spent to the wrapped engines rather than the routing layer. The labels must be
+// removed with unlabelDelegate once the call returns.
+func labelDelegate(poa bool, method delegateMethod) {
+	era := 0
+	if poa {
+		era = 1
+	}
+	pprof.SetGoroutineLabels(delegateLabels[era][method])
+}
+
+// unlabelDelegate removes the labels set by labelDelegate.
+func unlabelDelegate() {
+	pprof.SetGoroutineLabels(context.Background())
+}
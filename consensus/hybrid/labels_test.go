@@ -0,0 +1,74 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"math/big"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// goroutineLabels returns the goroutine profile, which lists the labels of
+// every goroutine.
+func goroutineLabels(t *testing.T) string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatalf("Failed to write goroutine profile: %v", err)
+	}
+	return buf.String()
+}
+
+// labelRecordingEngine is a mock engine recording the goroutine profile during
+// Author calls.
+type labelRecordingEngine struct {
+	mockEngine
+	t       *testing.T
+	profile string
+}
+
+func (e *labelRecordingEngine) Author(header *types.Header) (common.Address, error) {
+	e.profile = goroutineLabels(e.t)
+	return common.Address{}, nil
+}
+
+func TestDelegateLabels(t *testing.T) {
+	var (
+		pos = &labelRecordingEngine{mockEngine: mockEngine{name: "pos"}, t: t}
+		poa = &labelRecordingEngine{mockEngine: mockEngine{name: "poa"}, t: t}
+	)
+	h, err := New(pos, poa, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	h.Author(&types.Header{Number: big.NewInt(9)})
+	h.Author(&types.Header{Number: big.NewInt(10)})
+
+	for _, engine := range []*labelRecordingEngine{pos, poa} {
+		want := `labels: {"engine":"` + engine.name + `", "method":"Author"}`
+		if !strings.Contains(engine.profile, want) {
+			t.Errorf("%s call not labelled, want %s", engine.name, want)
+		}
+	}
+	if profile := goroutineLabels(t); strings.Contains(profile, `"method":"Author"`) {
+		t.Error("Labels not removed after delegated call")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// poaWarmupWindow is the number of blocks before the transition from which on a
+// lazily constructed PoA engine is initialized, so its snapshot caches are in
+// place before the first PoA block arrives.
+const poaWarmupWindow = 1024
+
+// EngineFactory constructs a consensus engine.
+type EngineFactory func() consensus.Engine
+
+// lazyEngine is a consensus engine that defers constructing the wrapped engine
+// until it is first needed.
+type lazyEngine struct {
+	name     string                 // Human readable name of the engine for logging
+	build    EngineFactory          // Constructor of the wrapped engine
+	built    func(consensus.Engine) // Hook run on every construction, if set
+	engine   consensus.Engine
+	released bool // Whether the engine was constructed before and released since
+	lock     sync.RWMutex
+}
+
+// Lazy returns a consensus engine which constructs the actual engine through
+// the given factory the first time any of its methods is called. Passing lazy
+// engines to New avoids allocating the caches of an engine that the node may
+// never need, e.g. the PoS engine of a node that only follows the chain past
+// the transition.
+func Lazy(name string, build EngineFactory) consensus.Engine {
+	return &lazyEngine{name: name, build: build}
+}
+
+// get returns the wrapped engine, constructing it if needed.
+func (l *lazyEngine) get() consensus.Engine {
+	l.lock.RLock()
+	engine := l.engine
+	l.lock.RUnlock()
+	if engine != nil {
+		return engine
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.engine == nil {
+		if l.released {
+			log.Info("Re-opening retired hybrid consensus sub-engine", "engine", l.name)
+		} else {
+			log.Info("Initializing hybrid consensus sub-engine", "engine", l.name)
+		}
+		l.engine = l.build()
+		if l.built != nil {
+			l.built(l.engine)
+		}
+	}
+	return l.engine
+}
+
+// release closes the wrapped engine and drops it, so that it is constructed
+// anew on its next use. It reports whether there was an engine to release.
+func (l *lazyEngine) release() (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.engine == nil {
+		return false, nil
+	}
+	err := l.engine.Close()
+	l.engine, l.released = nil, true
+	return true, err
+}
+
+// initialized reports whether the wrapped engine has been constructed.
+func (l *lazyEngine) initialized() bool {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	return l.engine != nil
+}
+
+// warmup constructs the engine if the given engine is lazy and has not been
+// initialized yet.
+func warmup(engine consensus.Engine) {
+	if l, ok := engine.(*lazyEngine); ok {
+		l.get()
+	}
+}
+
+// initialized reports whether the given engine is constructed. Engines that
+// are not lazy are always considered initialized.
+func initialized(engine consensus.Engine) bool {
+	if l, ok := engine.(*lazyEngine); ok {
+		return l.initialized()
+	}
+	return true
+}
+
+// Author implements consensus.Engine.
+func (l *lazyEngine) Author(header *types.Header) (common.Address, error) {
+	return l.get().Author(header)
+}
+
+// VerifyHeader implements consensus.Engine.
+func (l *lazyEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return l.get().VerifyHeader(chain, header)
+}
+
+// VerifyHeaders implements consensus.Engine.
+func (l *lazyEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	return l.get().VerifyHeaders(chain, headers)
+}
+
+// VerifyUncles implements consensus.Engine.
+func (l *lazyEngine) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return l.get().VerifyUncles(chain, block)
+}
+
+// Prepare implements consensus.Engine.
+func (l *lazyEngine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	return l.get().Prepare(chain, header)
+}
+
+// Finalize implements consensus.Engine.
+func (l *lazyEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state vm.StateDB, body *types.Body) {
+	l.get().Finalize(chain, header, state, body)
+}
+
+// FinalizeAndAssemble implements consensus.Engine.
+func (l *lazyEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, body *types.Body, receipts []*types.Receipt) (*types.Block, error) {
+	return l.get().FinalizeAndAssemble(chain, header, state, body, receipts)
+}
+
+// Seal implements consensus.Engine.
+func (l *lazyEngine) Seal(chain consensus.ChainHeaderReader, block *types.Block, results chan<- *types.Block, stop <-chan struct{}) error {
+	return l.get().Seal(chain, block, results, stop)
+}
+
+// SealHash implements consensus.Engine.
+func (l *lazyEngine) SealHash(header *types.Header) common.Hash {
+	return l.get().SealHash(header)
+}
+
+// CalcDifficulty implements consensus.Engine.
+func (l *lazyEngine) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
+	return l.get().CalcDifficulty(chain, time, parent)
+}
+
+// Close implements consensus.Engine, closing the wrapped engine if it was ever
+// constructed.
+func (l *lazyEngine) Close() error {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if l.engine == nil {
+		return nil
+	}
+	return l.engine.Close()
+}
+
+// warmupPoA initializes a lazily constructed PoA engine once the chain gets
+// within poaWarmupWindow blocks of the transition.
+func (h *Hybrid) warmupPoA(number uint64) {
+	if number+poaWarmupWindow >= h.transitionBlock.Load() {
+		warmup(h.poaEngine)
+	}
+}
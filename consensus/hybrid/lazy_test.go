@@ -0,0 +1,85 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLazyEngines(t *testing.T) {
+	const transition = 10_000
+
+	var posBuilt, poaBuilt int
+	posEngine := Lazy("pos", func() consensus.Engine {
+		posBuilt++
+		return newTrackingMockEngine("pos")
+	})
+	poaEngine := Lazy("poa", func() consensus.Engine {
+		poaBuilt++
+		return newTrackingMockEngine("poa")
+	})
+	h, err := New(posEngine, poaEngine, transition)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if posBuilt != 0 || poaBuilt != 0 {
+		t.Fatalf("Engines constructed eagerly: pos %d, poa %d", posBuilt, poaBuilt)
+	}
+	chain := &mockChainReader{}
+
+	// Verifying PoS blocks far from the transition only builds the PoS engine
+	if err := h.VerifyHeader(chain, &types.Header{Number: big.NewInt(1)}); err != nil {
+		t.Fatalf("Failed to verify header: %v", err)
+	}
+	if posBuilt != 1 || poaBuilt != 0 {
+		t.Fatalf("Unexpected constructions far from the transition: pos %d, poa %d", posBuilt, poaBuilt)
+	}
+	// Entering the warmup window builds the PoA engine ahead of the transition
+	if err := h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition - poaWarmupWindow)}); err != nil {
+		t.Fatalf("Failed to verify header: %v", err)
+	}
+	if !initialized(poaEngine) {
+		t.Fatal("PoA engine not initialized within the warmup window")
+	}
+	// Engines are constructed only once
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition)})
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition - 1)})
+	if posBuilt != 1 || poaBuilt != 1 {
+		t.Fatalf("Engines constructed repeatedly: pos %d, poa %d", posBuilt, poaBuilt)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Failed to close hybrid engine: %v", err)
+	}
+}
+
+func TestLazyEngineCloseUnused(t *testing.T) {
+	built := false
+	engine := Lazy("unused", func() consensus.Engine {
+		built = true
+		return newTrackingMockEngine("unused")
+	})
+	if err := engine.Close(); err != nil {
+		t.Fatalf("Failed to close unused engine: %v", err)
+	}
+	if built {
+		t.Fatal("Closing an unused lazy engine constructed it")
+	}
+}
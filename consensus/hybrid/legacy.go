@@ -0,0 +1,93 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrLegacyTransitionMismatch is returned in strict mode if the transition
+// block imported by a legacy node lists neither the hardcoded nor the
+// configured initial signers.
+var ErrLegacyTransitionMismatch = errors.New("imported transition block does not match legacy signers")
+
+// migrateLegacyConfig makes the configuration of nodes that went through the
+// transition with a release predating the persisted state explicit. Those
+// releases sealed the transition block with the hardcoded default signers,
+// which this records as the effective configuration after validating the
+// imported transition block against it, so that changing the configured
+// signers afterwards is caught as drift. Nodes that have not imported the
+// transition block yet are not bound by the defaults and are left alone.
+func (h *Hybrid) migrateLegacyConfig() error {
+	if has, _ := h.db.Has(effectiveConfigKey); has {
+		return nil
+	}
+	reader, ok := h.db.(ethdb.Reader)
+	if !ok {
+		return nil
+	}
+	hash := rawdb.ReadCanonicalHash(reader, h.transitionBlock.Load())
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	header := rawdb.ReadHeader(reader, hash, h.transitionBlock.Load())
+	if header == nil {
+		return nil
+	}
+	legacy := &effectiveConfig{
+		TransitionBlock: h.transitionBlock.Load(),
+		InitialSigners:  slices.Clone(defaultInitialSigners),
+	}
+	signers, err := checkpointSigners(header.Extra)
+	switch {
+	case err != nil || len(signers) == 0:
+		// Transition blocks off an epoch boundary carry no signer list, the
+		// legacy behaviour can only be assumed
+		log.Warn("Cannot validate legacy transition block signers", "number", h.transitionBlock.Load(), "hash", hash, "err", err)
+
+	case slices.Equal(signers, legacy.InitialSigners):
+		log.Info("Validated legacy transition block against hardcoded signers", "number", h.transitionBlock.Load(), "hash", hash)
+
+	case slices.Equal(signers, h.initialSigners):
+		return nil // Sealed with the configured signers, nothing legacy to record
+
+	default:
+		if h.strict {
+			return &TransitionError{Code: CodeInvalidCheckpoint, Err: fmt.Errorf("%w: block %d lists %v", ErrLegacyTransitionMismatch, h.transitionBlock.Load(), signers)}
+		}
+		log.Error("Imported transition block does not match legacy signers, adopting its signers",
+			"number", h.transitionBlock.Load(), "hash", hash, "signers", signers)
+		legacy.InitialSigners = signers
+	}
+	blob, err := json.Marshal(legacy)
+	if err != nil {
+		return err
+	}
+	if err := h.db.Put(effectiveConfigKey, blob); err != nil {
+		return err
+	}
+	log.Info("Recorded explicit configuration of legacy hybrid node", "transitionBlock", legacy.TransitionBlock, "signers", legacy.InitialSigners)
+	return nil
+}
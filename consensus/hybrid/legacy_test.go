@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// newLegacyDatabase returns a database of a legacy node that imported the
+// transition block listing the given signers.
+func newLegacyDatabase(transition uint64, signers []common.Address) ethdb.Database {
+	db := rawdb.NewMemoryDatabase()
+	if signers != nil {
+		header := &types.Header{Number: new(big.Int).SetUint64(transition), Difficulty: big.NewInt(2), Extra: CheckpointExtra(signers)}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), transition)
+	}
+	return db
+}
+
+func TestLegacyConfigMigration(t *testing.T) {
+	var (
+		signers = []common.Address{{0xa1}, {0xa2}, {0xa3}}
+		others  = []common.Address{{0xb1}, {0xb2}, {0xb3}}
+	)
+	create := func(db ethdb.Database, signers []common.Address, strict bool) error {
+		opts := []Option{WithStrict(strict), WithDatabase(db)}
+		if signers != nil {
+			opts = append(opts, WithInitialSigners(signers))
+		}
+		_, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, opts...)
+		return err
+	}
+	// Legacy nodes past the transition keep running with the defaults, but
+	// can no longer switch to other signers
+	db := newLegacyDatabase(100, defaultInitialSigners)
+	if err := create(db, nil, false); err != nil {
+		t.Fatalf("Failed to migrate legacy node: %v", err)
+	}
+	db = newLegacyDatabase(100, defaultInitialSigners)
+	if err := create(db, signers, true); !errors.Is(err, ErrConfigDrift) {
+		t.Errorf("Expected ErrConfigDrift for legacy node with new signers, got %v", err)
+	}
+	// Legacy nodes before the transition are free to configure their signers
+	db = newLegacyDatabase(100, nil)
+	if err := create(db, signers, true); err != nil {
+		t.Errorf("Failed to migrate legacy node before transition: %v", err)
+	}
+	// Transition blocks sealed with the configured signers are accepted
+	db = newLegacyDatabase(100, signers)
+	if err := create(db, signers, true); err != nil {
+		t.Errorf("Failed to migrate node with configured signers: %v", err)
+	}
+	// Transition blocks matching neither are refused in strict mode only
+	db = newLegacyDatabase(100, others)
+	if err := create(db, signers, true); !errors.Is(err, ErrLegacyTransitionMismatch) {
+		t.Errorf("Expected ErrLegacyTransitionMismatch, got %v", err)
+	}
+	if version := ReadSchemaVersion(db); version != 0 {
+		t.Errorf("Failed migration bumped schema version to %d", version)
+	}
+	if err := create(db, others, false); err != nil {
+		t.Errorf("Failed to adopt signers of transition block: %v", err)
+	}
+	// Migration only happens once, before the schema version is recorded
+	if err := create(db, others, true); err != nil {
+		t.Errorf("Failed to restart migrated node: %v", err)
+	}
+}
+
+func TestCheckpointSigners(t *testing.T) {
+	signers := []common.Address{{0xa1}, {0xa2}, {0xa3}}
+	have, err := checkpointSigners(CheckpointExtra(signers))
+	if err != nil {
+		t.Fatalf("Failed to parse checkpoint extra: %v", err)
+	}
+	if len(have) != len(signers) || have[0] != signers[0] || have[2] != signers[2] {
+		t.Errorf("Signer mismatch: have %v, want %v", have, signers)
+	}
+	if _, err := checkpointSigners(make([]byte, extraVanity)); err == nil {
+		t.Error("Expected error for truncated extra-data")
+	}
+	if _, err := checkpointSigners(make([]byte, extraVanity+extraSeal+1)); err == nil {
+		t.Error("Expected error for misaligned signer section")
+	}
+}
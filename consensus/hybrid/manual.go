@@ -0,0 +1,74 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// ErrManualTransitionDisabled is returned for manual flips on engines whose
+	// chain config does not enable the manual transition.
+	ErrManualTransitionDisabled = errors.New("manual PoS to PoA transition not enabled")
+
+	// ErrTransitionReached is returned for manual flips once the chain is at or
+	// past the block before the transition.
+	ErrTransitionReached = errors.New("transition already reached")
+)
+
+// FlipToPoA moves the transition to the block following the given head, which
+// becomes the first block built and verified by the PoA engine. The height is
+// recorded in the database, so the engine behaves as if it had been configured
+// on later runs. It returns the new transition block.
+func (h *Hybrid) FlipToPoA(head uint64) (uint64, error) {
+	if !h.manual {
+		return 0, ErrManualTransitionDisabled
+	}
+	for {
+		transition := h.transitionBlock.Load()
+		if head+1 >= transition {
+			return 0, fmt.Errorf("%w: head %d, transition at block %d", ErrTransitionReached, head, transition)
+		}
+		if h.resolveTransition(transition, head+1, "manual") {
+			log.Warn("Flipped to PoA consensus manually", "head", head, "transitionBlock", head+1)
+			return head + 1, nil
+		}
+	}
+}
+
+// AdminAPI is the RPC API changing the course of the transition. It is only
+// served on authenticated endpoints.
+type AdminAPI struct {
+	chain  consensus.ChainHeaderReader
+	hybrid *Hybrid
+}
+
+// FlipToPoA flips the node to PoA at the current head, returning the first
+// PoA block. All nodes of the network have to flip at the same head.
+func (api *AdminAPI) FlipToPoA() (hexutil.Uint64, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil {
+		return 0, errUnknownBlock
+	}
+	number, err := api.hybrid.FlipToPoA(head.Number.Uint64())
+	return hexutil.Uint64(number), err
+}
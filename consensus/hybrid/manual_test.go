@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestFlipToPoA(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, PendingTransition, WithManualTransition(true), WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if status := h.Status(41); !status.Pending || status.Mode != "pos" || status.BlocksRemaining != 0 {
+		t.Fatalf("Pending status mismatch: %+v", status)
+	}
+	// The block after the head becomes the first PoA block
+	number, err := h.FlipToPoA(41)
+	if err != nil {
+		t.Fatalf("Failed to flip to PoA: %v", err)
+	}
+	if number != 42 || !h.UsesPoA(42) || h.UsesPoA(41) || h.TransitionPending() {
+		t.Fatalf("Transition mismatch: have %d, want 42", number)
+	}
+	if recorded, ok := rawdb.ReadResolvedTransition(db); !ok || recorded != 42 {
+		t.Fatalf("Recorded transition mismatch: have %d (%v), want 42", recorded, ok)
+	}
+	// The transition cannot be moved once reached
+	if _, err := h.FlipToPoA(41); !errors.Is(err, ErrTransitionReached) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrTransitionReached)
+	}
+	// Later runs start from the recorded height, ahead of a configured one
+	config := &params.ChainConfig{PoSToPoATransitionBlock: big.NewInt(100), PoSToPoAManualTransition: true}
+	if have := configuredTransition(config, db); have != 42 {
+		t.Fatalf("Restored transition mismatch: have %d, want 42", have)
+	}
+	if _, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 42, WithManualTransition(true), WithDatabase(db)); err != nil {
+		t.Fatalf("Failed to restart hybrid engine: %v", err)
+	}
+	// Engines without the manual transition refuse to flip
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if _, err := h.FlipToPoA(41); !errors.Is(err, ErrManualTransitionDisabled) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrManualTransitionDisabled)
+	}
+}
+
+func TestFlipToPoAAPI(t *testing.T) {
+	chain := newTestHeaderChain(params.TestChainConfig, 5, 1)
+	for _, manual := range []bool{false, true} {
+		h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithManualTransition(manual))
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		var admin *AdminAPI
+		for _, api := range h.APIs(chain) {
+			if service, ok := api.Service.(*AdminAPI); ok {
+				if !api.Authenticated {
+					t.Fatal("Admin API served without authentication")
+				}
+				admin = service
+			}
+		}
+		if (admin != nil) != manual {
+			t.Fatalf("Admin API availability mismatch: have %v, want %v", admin != nil, manual)
+		}
+		if admin == nil {
+			continue
+		}
+		number, err := admin.FlipToPoA()
+		if err != nil {
+			t.Fatalf("Failed to flip to PoA: %v", err)
+		}
+		if number != 6 || !h.UsesPoA(6) {
+			t.Fatalf("Transition mismatch: have %d, want 6", number)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// metricsPrefix is the common prefix of the names of all hybrid engine metrics.
+const metricsPrefix = "hybrid/"
+
+// UnregisterMetrics removes all hybrid engine metrics from the default registry,
+// excluding them from being reported. The engine keeps updating them regardless.
+func UnregisterMetrics() {
+	var names []string
+	metrics.DefaultRegistry.Each(func(name string, _ interface{}) {
+		if strings.HasPrefix(name, metricsPrefix) {
+			names = append(names, name)
+		}
+	})
+	for _, name := range names {
+		metrics.DefaultRegistry.Unregister(name)
+	}
+}
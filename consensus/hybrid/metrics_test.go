@@ -0,0 +1,54 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestUnregisterMetrics(t *testing.T) {
+	registered := make(map[string]interface{})
+	metrics.DefaultRegistry.Each(func(name string, metric interface{}) {
+		if strings.HasPrefix(name, metricsPrefix) {
+			registered[name] = metric
+		}
+	})
+	if len(registered) == 0 {
+		t.Fatal("Hybrid metrics not registered")
+	}
+	defer func() {
+		for name, metric := range registered {
+			metrics.DefaultRegistry.Register(name, metric)
+		}
+	}()
+	metrics.NewRegisteredCounter("hybridish/unrelated", nil)
+	defer metrics.DefaultRegistry.Unregister("hybridish/unrelated")
+
+	UnregisterMetrics()
+
+	metrics.DefaultRegistry.Each(func(name string, _ interface{}) {
+		if strings.HasPrefix(name, metricsPrefix) {
+			t.Errorf("Metric %s still registered", name)
+		}
+	})
+	if metrics.DefaultRegistry.Get("hybridish/unrelated") == nil {
+		t.Error("Unrelated metric unregistered")
+	}
+}
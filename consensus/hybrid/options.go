@@ -0,0 +1,249 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Option configures optional behaviour of the hybrid consensus engine.
+type Option func(*Hybrid)
+
+// WithInitialSigners overrides the built-in initial PoA signer set. An empty
+// list keeps the defaults.
+func WithInitialSigners(signers []common.Address) Option {
+	return func(h *Hybrid) {
+		if len(signers) > 0 {
+			h.initialSigners = slices.Clone(signers)
+		}
+	}
+}
+
+// WithStrict toggles production mode, in which the engine refuses to start if
+// the initial signer set still contains placeholder addresses or has fewer
+// signers than the configured minimum, or if consensus-critical settings
+// changed since the previous run.
+func WithStrict(strict bool) Option {
+	return func(h *Hybrid) {
+		h.strict = strict
+	}
+}
+
+// WithMinSigners sets the minimum number of initial signers required in strict
+// mode. Non-positive values keep the default.
+func WithMinSigners(n int) Option {
+	return func(h *Hybrid) {
+		if n > 0 {
+			h.minSigners = n
+		}
+	}
+}
+
+// WithLocalSigner declares the account this node seals PoA blocks with. If the
+// account is one of the initial signers, hasKey is used to verify that its key
+// is available, both at construction and again shortly before the transition.
+func WithLocalSigner(signer common.Address, hasKey KeyChecker) Option {
+	return WithLocalSigners([]common.Address{signer}, hasKey)
+}
+
+// WithLocalSigners declares several accounts this node seals PoA blocks with.
+// Keys of initial signers are verified like with WithLocalSigner.
+func WithLocalSigners(signers []common.Address, hasKey KeyChecker) Option {
+	return func(h *Hybrid) {
+		h.localSigners = slices.DeleteFunc(slices.Clone(signers), func(signer common.Address) bool {
+			return signer == (common.Address{})
+		})
+		h.hasKey = hasKey
+	}
+}
+
+// WithShadowWindow enables shadow PoA verification of PoS blocks within the
+// given number of blocks before the transition. Shadow verification reports
+// problems the PoA engine would have with the chain, but never rejects blocks.
+func WithShadowWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		if window > 0 {
+			h.shadow = newShadowVerifier(window)
+		}
+	}
+}
+
+// WithDualVerifyWindow enables verifying headers within the given number of
+// blocks on either side of the transition with both engines, reporting any
+// disagreement on their acceptance. The routed engine's verdict always wins.
+func WithDualVerifyWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		if window > 0 {
+			h.dual = newDualVerifier(window)
+		}
+	}
+}
+
+// WithForkMonitorWindow enables tracking all branches observed within the given
+// number of blocks on either side of the transition, revealing a network
+// partitioning over the transition as it forms.
+func WithForkMonitorWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		if window > 0 {
+			h.forkMonitor = newForkMonitor(window)
+		}
+	}
+}
+
+// WithReorgReportWindow enables reporting reorgs that drop or add blocks within
+// the given number of blocks on either side of the transition.
+func WithReorgReportWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		if window > 0 {
+			h.reorgs = newReorgReporter(window)
+		}
+	}
+}
+
+// WithCountdownWindow enables logging the number of blocks left until the
+// transition for every block processed within the given number of blocks ahead
+// of it.
+func WithCountdownWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		h.countdown = window
+	}
+}
+
+// WithConfirmationDepth sets the number of blocks the transition block needs
+// to be buried under before it is considered final, after which a lazily
+// constructed PoS engine is closed. Zero never finalizes the transition.
+func WithConfirmationDepth(depth uint64) Option {
+	return func(h *Hybrid) {
+		h.confirmDepth = depth
+	}
+}
+
+// WithFreezeWindow sets the number of blocks before the transition which are
+// kept out of the ancient store, along with all later ones, until the
+// transition is final. Zero disables the hold.
+func WithFreezeWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		h.freezeWindow = window
+	}
+}
+
+// WithReadOnly declares the database read-only, as opened by tools inspecting
+// the chain of a node. The engine then neither migrates the persisted state nor
+// holds blocks back from the freezer.
+func WithReadOnly() Option {
+	return func(h *Hybrid) {
+		h.readOnly = true
+	}
+}
+
+// WithGraceWindow sets the number of blocks starting at the transition in which
+// PoS styled blocks are still accepted, verified by the PoS engine. PoS blocks
+// past the window are rejected with ErrLatePoSBlock. Zero tolerates none.
+func WithGraceWindow(window uint64) Option {
+	return func(h *Hybrid) {
+		h.graceWindow = window
+	}
+}
+
+// WithTerminalHash pins the last PoS block, whose child has to be the
+// transition block. Engines created with PendingTransition switch to PoA at the
+// first descendant of the block once it is reached, others reject transition
+// blocks not descending from it with ErrTerminalHashMismatch.
+func WithTerminalHash(hash common.Hash) Option {
+	return func(h *Hybrid) {
+		h.terminalHash = hash
+	}
+}
+
+// WithManualTransition allows operators to flip the engine to PoA at the
+// current head through FlipToPoA, recording the chosen height in the database.
+func WithManualTransition(enabled bool) Option {
+	return func(h *Hybrid) {
+		h.manual = enabled
+	}
+}
+
+// WithTransitionProof bootstraps the transition block from a record co-signed
+// by the initial signers, switching engines created with PendingTransition to
+// PoA at the attested block. The record must be signed by a majority of the
+// initial signers and the transition block must match the attested hash.
+func WithTransitionProof(proof *TransitionRecord) Option {
+	return func(h *Hybrid) {
+		h.proof = proof
+	}
+}
+
+// WithTransitionExtra sets a builder contributing the vanity of the transition
+// block's extraData. The engine still lays out the initial signers and the seal
+// of the checkpoint, and fails preparing the block if the builder does.
+func WithTransitionExtra(builder ExtraBuilder) Option {
+	return func(h *Hybrid) {
+		h.extraBuilder = builder
+	}
+}
+
+// WithDatabase sets the database the engine persists its own state in, such as
+// the signer proposals of the local PoA signer. Without a database that state
+// is lost on restart.
+func WithDatabase(db ethdb.KeyValueStore) Option {
+	return func(h *Hybrid) {
+		h.db = db
+	}
+}
+
+// WithDoubleSignMonitor enables scanning imported PoA headers in the background
+// for signers sealing two different blocks at the same height. Evidence is
+// logged, persisted in the engine database and served over RPC.
+func WithDoubleSignMonitor() Option {
+	return func(h *Hybrid) {
+		h.doubleSign = newDoubleSignMonitor()
+	}
+}
+
+// WithMissedSlotAlert sets the number of consecutive in-turn slots a signer may
+// miss before an alert is raised. Zero keeps the default.
+func WithMissedSlotAlert(n uint64) Option {
+	return func(h *Hybrid) {
+		if n > 0 {
+			h.alerts.threshold = n
+		}
+	}
+}
+
+// WithAlertWebhook delivers the alerts raised by the engine to the given webhook.
+// The engine starts the webhook and stops it along with itself.
+func WithAlertWebhook(webhook *Webhook) Option {
+	return func(h *Hybrid) {
+		h.webhook = webhook
+	}
+}
+
+// WithConsistencyCheck enables re-verifying a random historical block at the
+// given interval, weighted toward the blocks around the transition, raising an
+// alert for any that fails. Zero disables the checker. It is launched through
+// StartConsistencyCheck once the chain is available.
+func WithConsistencyCheck(interval time.Duration) Option {
+	return func(h *Hybrid) {
+		if interval > 0 {
+			h.consistency = newConsistencyChecker(interval)
+		}
+	}
+}
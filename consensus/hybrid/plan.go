@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"cmp"
+	"errors"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PlanVersion is the version of the transition plan document format.
+const PlanVersion = 1
+
+// errNoTransition is returned if a chain config schedules no PoS to PoA transition.
+var errNoTransition = errors.New("chain config has no PoS to PoA transition")
+
+// PlanGasCeiling is a PoA gas limit ceiling resolved to an absolute block.
+type PlanGasCeiling struct {
+	Block   uint64 `json:"block"`
+	Ceiling uint64 `json:"ceiling"`
+}
+
+// Plan is the effective configuration of a PoS to PoA transition, with every
+// value resolved the way the engine applies it. Operators distribute it and
+// compare it across the fleet to catch diverging configurations early.
+type Plan struct {
+	Version     int         `json:"version"`
+	ChainID     *big.Int    `json:"chainId"`
+	GenesisHash common.Hash `json:"genesisHash"`
+
+	TerminalTotalDifficulty *big.Int     `json:"terminalTotalDifficulty,omitempty"`
+	TransitionBlock         uint64       `json:"transitionBlock"`
+	TransitionHash          *common.Hash `json:"transitionHash,omitempty"` // Pinned once the transition block is canonical
+	TerminalHash            *common.Hash `json:"terminalHash,omitempty"`   // Last PoS block the transition block descends from
+	ConfirmationDepth       uint64       `json:"confirmationDepth"`
+	GraceWindow             uint64       `json:"graceWindow"`
+
+	Signers            []common.Address `json:"signers"`
+	PlaceholderSigners bool             `json:"placeholderSigners,omitempty"` // Whether the built-in placeholder signers are used
+	CheckpointExtra    hexutil.Bytes    `json:"checkpointExtra"`
+
+	Period      uint64            `json:"period"`
+	Epoch       uint64            `json:"epoch"`
+	GasCeilings []PlanGasCeiling  `json:"gasCeilings"`
+	Activations map[string]uint64 `json:"activations"`
+}
+
+// NewPlan resolves the transition plan of the chain with the given config and
+// genesis hash.
+func NewPlan(config *params.ChainConfig, genesis common.Hash) (*Plan, error) {
+	if config.PoSToPoATransitionBlock == nil {
+		return nil, errNoTransition
+	}
+	if config.Clique == nil {
+		return nil, errors.New("chain config has no clique section")
+	}
+	transition := config.PoSToPoATransitionBlock
+	plan := &Plan{
+		Version:                 PlanVersion,
+		ChainID:                 config.ChainID,
+		GenesisHash:             genesis,
+		TerminalTotalDifficulty: config.TerminalTotalDifficulty,
+		TransitionBlock:         transition.Uint64(),
+		TerminalHash:            config.TerminalPoSBlockHash,
+		ConfirmationDepth:       config.TransitionConfirmations(),
+		GraceWindow:             config.TransitionGrace(),
+		Signers:                 slices.Clone(config.PoAInitialSigners),
+		Period:                  config.Clique.Period,
+		Epoch:                   config.Clique.Epoch,
+		GasCeilings:             []PlanGasCeiling{},
+		Activations:             make(map[string]uint64),
+	}
+	if len(plan.Signers) == 0 {
+		plan.Signers, plan.PlaceholderSigners = slices.Clone(defaultInitialSigners), true
+	}
+	plan.CheckpointExtra = CheckpointExtra(plan.Signers)
+
+	for _, ceiling := range config.PoAGasCeilings {
+		if block := ceiling.At(transition); block != nil {
+			plan.GasCeilings = append(plan.GasCeilings, PlanGasCeiling{Block: block.Uint64(), Ceiling: ceiling.Ceiling})
+		}
+	}
+	slices.SortFunc(plan.GasCeilings, func(a, b PlanGasCeiling) int { return cmp.Compare(a.Block, b.Block) })
+
+	for name := range config.PoAActivations {
+		plan.Activations[name] = config.PoAActivationBlock(name).Uint64()
+	}
+	return plan, nil
+}
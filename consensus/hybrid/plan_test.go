@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestNewPlan(t *testing.T) {
+	var (
+		offset  = uint64(10)
+		signers = []common.Address{{0x01}, {0x02}, {0x03}}
+	)
+	config := &params.ChainConfig{
+		ChainID:                 big.NewInt(1337),
+		TerminalTotalDifficulty: big.NewInt(0),
+		PoSToPoATransitionBlock: big.NewInt(100),
+		PoAInitialSigners:       signers,
+		Clique:                  &params.CliqueConfig{Period: 5, Epoch: 50},
+		PoAGasCeilings: []params.GasCeiling{
+			{Offset: &offset, Ceiling: 20_000_000},
+			{Block: big.NewInt(105), Ceiling: 30_000_000},
+		},
+		PoAActivations: map[string]uint64{params.PoASignerRotation: 50},
+	}
+	plan, err := NewPlan(config, common.Hash{0xaa})
+	if err != nil {
+		t.Fatalf("Failed to build plan: %v", err)
+	}
+	if plan.TransitionBlock != 100 || plan.Period != 5 || plan.Epoch != 50 || plan.PlaceholderSigners {
+		t.Errorf("Plan mismatch: %+v", plan)
+	}
+	if !bytes.Equal(plan.CheckpointExtra, CheckpointExtra(signers)) {
+		t.Errorf("Checkpoint extra mismatch: have %x", plan.CheckpointExtra)
+	}
+	if len(plan.GasCeilings) != 2 || plan.GasCeilings[0] != (PlanGasCeiling{Block: 105, Ceiling: 30_000_000}) || plan.GasCeilings[1].Block != 110 {
+		t.Errorf("Gas ceilings mismatch: %v", plan.GasCeilings)
+	}
+	if block := plan.Activations[params.PoASignerRotation]; block != 150 {
+		t.Errorf("Activation mismatch: have %d, want 150", block)
+	}
+	// Without configured signers the placeholders are flagged
+	config.PoAInitialSigners = nil
+	if plan, err = NewPlan(config, common.Hash{}); err != nil || !plan.PlaceholderSigners || len(plan.Signers) == 0 {
+		t.Errorf("Placeholder plan mismatch: %+v, %v", plan, err)
+	}
+	config.PoSToPoATransitionBlock = nil
+	if _, err := NewPlan(config, common.Hash{}); err != errNoTransition {
+		t.Errorf("Plan without transition: have %v, want %v", err, errNoTransition)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrInvalidTransitionProof is returned if a transition proof is not signed
+	// by a majority of the configured initial signers, or attests a transition
+	// other than the one the engine is configured with.
+	ErrInvalidTransitionProof = errors.New("invalid transition proof")
+
+	// ErrTransitionProofMismatch is returned for transition blocks whose hash
+	// differs from the one attested by the transition proof.
+	ErrTransitionProofMismatch = errors.New("transition block does not match the transition proof")
+)
+
+// ReadTransitionProof reads a transition record, as served by the
+// hybrid_transitionRecord RPC method, from a JSON file. The signatures are not
+// verified, which is left to the engine knowing the initial signers.
+func ReadTransitionProof(path string) (*TransitionRecord, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transition proof: %w", err)
+	}
+	var record TransitionRecord
+	if err := json.Unmarshal(blob, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode transition proof %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// applyTransitionProof verifies the transition proof against the initial signer
+// set and routes the chain to the PoA engine from the attested block on. A node
+// bootstrapping onto a network whose transition was triggered at runtime thus
+// learns the transition block without having to witness the trigger. It does
+// nothing if no proof is configured.
+func (h *Hybrid) applyTransitionProof() error {
+	proof := h.proof
+	if proof == nil {
+		return nil
+	}
+	if !slices.Equal(proof.Signers, h.initialSigners) {
+		return fmt.Errorf("%w: attested signers %v, want %v", ErrInvalidTransitionProof, proof.Signers, h.initialSigners)
+	}
+	if err := proof.Verify(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransitionProof, err)
+	}
+	if quorum := len(h.initialSigners)/2 + 1; len(proof.Signatures) < quorum {
+		return fmt.Errorf("%w: signed by %d of %d initial signers, want at least %d",
+			ErrInvalidTransitionProof, len(proof.Signatures), len(h.initialSigners), quorum)
+	}
+	switch transition := h.transitionBlock.Load(); transition {
+	case PendingTransition:
+		h.resolveTransition(PendingTransition, proof.Number, "transition proof")
+	case proof.Number:
+	default:
+		return fmt.Errorf("%w: attests block %d, transition is at block %d", ErrInvalidTransitionProof, proof.Number, transition)
+	}
+	// Serve the proof to peers joining later, like an attestation gossiped to us
+	for _, a := range proof.Attestations() {
+		if _, err := h.AddTransitionAttestation(a); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransitionProof, err)
+		}
+	}
+	return nil
+}
+
+// checkProof verifies that the transition block is the one attested by the
+// transition proof. It does nothing if no proof is configured.
+func (h *Hybrid) checkProof(header *types.Header) error {
+	if h.proof == nil || header.Number.Uint64() != h.proof.Number {
+		return nil
+	}
+	if hash := header.Hash(); hash != h.proof.Hash {
+		return &TransitionError{Code: CodeInvalidCheckpoint, Err: fmt.Errorf("%w: block %d has hash %x, want %x", ErrTransitionProofMismatch, h.proof.Number, hash.Bytes()[:4], h.proof.Hash.Bytes()[:4])}
+	}
+	return nil
+}
+
+// checkProofs reports whether any of the headers is the transition block
+// attested by the transition proof, requiring per header verification.
+func (h *Hybrid) checkProofs(headers []*types.Header) bool {
+	if h.proof == nil {
+		return false
+	}
+	for _, header := range headers {
+		if header.Number.Uint64() == h.proof.Number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTransitionProof(t *testing.T) {
+	var (
+		keys    = make(map[common.Address]*ecdsa.PrivateKey)
+		signers []common.Address
+	)
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		signer := crypto.PubkeyToAddress(key.PublicKey)
+		keys[signer] = key
+		signers = append(signers, signer)
+	}
+	sign := func(signer common.Address, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), keys[signer])
+	}
+	transition := &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(2)}
+	proof := func(number uint64, signed int) *TransitionRecord {
+		record := &TransitionRecord{Number: number, Hash: transition.Hash(), Signers: signers, Signatures: make(map[common.Address]hexutil.Bytes)}
+		for _, signer := range signers[:signed] {
+			a, err := NewTransitionAttestation(signer, number, record.Hash, signers, sign)
+			if err != nil {
+				t.Fatalf("Failed to create attestation: %v", err)
+			}
+			record.Signatures[signer] = a.Signature
+		}
+		return record
+	}
+	// Write the proof to disk the way operators hand it to fresh nodes
+	path := filepath.Join(t.TempDir(), "proof.json")
+	blob, _ := json.Marshal(proof(100, 2))
+	if err := os.WriteFile(path, blob, 0600); err != nil {
+		t.Fatalf("Failed to write proof: %v", err)
+	}
+	loaded, err := ReadTransitionProof(path)
+	if err != nil {
+		t.Fatalf("Failed to read proof: %v", err)
+	}
+	db := rawdb.NewMemoryDatabase()
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, PendingTransition,
+		WithInitialSigners(signers), WithTransitionProof(loaded), WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if h.TransitionPending() || !h.UsesPoA(100) || h.UsesPoA(99) {
+		t.Fatal("Transition not configured from the proof")
+	}
+	if record := h.TransitionRecord(); record == nil || len(record.Signatures) != 2 {
+		t.Fatalf("Proof not served as transition record: %+v", record)
+	}
+	// Only the attested transition block is accepted
+	if err := h.VerifyHeader(&mockChainReader{}, transition); err != nil {
+		t.Fatalf("Attested transition block rejected: %v", err)
+	}
+	forged := &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(1)}
+	if err := h.VerifyHeader(&mockChainReader{}, forged); !errors.Is(err, ErrTransitionProofMismatch) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrTransitionProofMismatch)
+	}
+	// Proofs without quorum, of other signers or contradicting the config are refused
+	for name, test := range map[string]struct {
+		transition uint64
+		proof      *TransitionRecord
+	}{
+		"minority":  {PendingTransition, proof(100, 1)},
+		"conflict":  {50, proof(100, 3)},
+		"signers":   {PendingTransition, &TransitionRecord{Number: 100, Signers: signers[:2]}},
+		"signature": {PendingTransition, &TransitionRecord{Number: 100, Signers: signers, Signatures: map[common.Address]hexutil.Bytes{signers[0]: make([]byte, 65)}}},
+	} {
+		_, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, test.transition,
+			WithInitialSigners(signers), WithTransitionProof(test.proof))
+		if !errors.Is(err, ErrInvalidTransitionProof) {
+			t.Errorf("%s: error mismatch: have %v, want %v", name, err, ErrInvalidTransitionProof)
+		}
+	}
+}
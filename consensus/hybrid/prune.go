@@ -0,0 +1,58 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PrunePoSSnapshots deletes the clique snapshots persisted for the PoS era of a
+// chain transitioning at the given block, such as the ones stored while shadow
+// verifying PoS blocks. The PoA engine founds its snapshots on the initial
+// signers at the block before the transition, so it never reads older ones.
+// That snapshot and all later ones are preserved. It returns the number of
+// snapshots deleted.
+func PrunePoSSnapshots(db ethdb.KeyValueStore, transition uint64) (int, error) {
+	if transition < 2 {
+		return 0, nil // No snapshots before the founding one
+	}
+	return clique.PruneSnapshots(db, transition-1)
+}
+
+// pruneSnapshots prunes the PoS era snapshots in the background, once per run.
+// The transition must be final.
+func (h *Hybrid) pruneSnapshots() {
+	if h.db == nil || h.TransitionPending() || !h.snapshotsPruned.CompareAndSwap(false, true) {
+		return
+	}
+	transition := h.transitionBlock.Load()
+	go func() {
+		start := time.Now()
+		pruned, err := PrunePoSSnapshots(h.db, transition)
+		if err != nil {
+			log.Warn("Failed to prune PoS era clique snapshots", "transitionBlock", transition, "pruned", pruned, "err", err)
+			return
+		}
+		if pruned > 0 {
+			log.Info("Pruned PoS era clique snapshots", "transitionBlock", transition, "pruned", pruned, "elapsed", time.Since(start))
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestPrunePoSSnapshots(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	snapshot := func(number uint64) []byte {
+		return append(rawdb.CliqueSnapshotPrefix, common.BigToHash(new(big.Int).SetUint64(number+1)).Bytes()...)
+	}
+	for _, number := range []uint64{0, 50, 99, 150} {
+		db.Put(snapshot(number), fmt.Appendf(nil, `{"number":%d}`, number))
+	}
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithDatabase(db), WithConfirmationDepth(10))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// Nothing is pruned until the transition is final
+	header := &types.Header{Number: big.NewInt(105), Difficulty: big.NewInt(2)}
+	if err := h.VerifyHeader(&mockChainReader{}, header); err != nil {
+		t.Fatalf("Failed to verify header: %v", err)
+	}
+	if ok, _ := db.Has(snapshot(0)); !ok {
+		t.Fatal("Snapshot pruned before the transition is final")
+	}
+	header = &types.Header{Number: big.NewInt(110), Difficulty: big.NewInt(2)}
+	if err := h.VerifyHeader(&mockChainReader{}, header); err != nil {
+		t.Fatalf("Failed to verify header: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ok, _ := db.Has(snapshot(50)); ok && time.Now().Before(deadline); ok, _ = db.Has(snapshot(50)) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The founding snapshot before the transition and later ones are kept
+	for _, number := range []uint64{0, 50, 99, 150} {
+		if ok, _ := db.Has(snapshot(number)); ok != (number >= 99) {
+			t.Errorf("Snapshot %d: kept %v, want %v", number, ok, number >= 99)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// maxTransitionRecords is the number of distinct transition records kept, so
+// that misbehaving signers cannot grow the record book without bounds.
+const maxTransitionRecords = 16
+
+// transitionRecordDomain separates transition record signatures from any other
+// data signed with the keys of signers.
+var transitionRecordDomain = []byte("hybrid-transition-record/1")
+
+// transitionRecordsKey is the database key the known transition records are
+// persisted under.
+var transitionRecordsKey = []byte("hybrid-transition-records")
+
+// ErrInvalidAttestation is returned if the signature of a transition attestation
+// does not match the signer it claims to originate from, or if that signer is
+// not one of the signers named in the attested record.
+var ErrInvalidAttestation = errors.New("invalid transition attestation")
+
+var (
+	attestationReceivedCounter = metrics.NewRegisteredCounter("hybrid/record/received", nil)
+	attestationInvalidCounter  = metrics.NewRegisteredCounter("hybrid/record/invalid", nil)
+)
+
+// TransitionRecord describes the executed transition, the height and hash of
+// the transition block and the initial signer set checkpointed in it, together
+// with the signatures of the initial signers attesting it. Nodes joining the
+// network later use it to discover and verify the actual transition point,
+// which under the terminal block or manual triggers is not part of any config.
+type TransitionRecord struct {
+	Number     uint64                           `json:"number"`
+	Hash       common.Hash                      `json:"hash"`
+	Signers    []common.Address                 `json:"signers"`
+	Signatures map[common.Address]hexutil.Bytes `json:"signatures"`
+}
+
+// payload returns the data covered by the signatures of the record.
+func (r *TransitionRecord) payload() []byte {
+	blob, _ := rlp.EncodeToBytes([]interface{}{r.Number, r.Hash, r.Signers})
+	return append(slices.Clone(transitionRecordDomain), blob...)
+}
+
+// ID identifies the attested content of the record, regardless of signatures.
+func (r *TransitionRecord) ID() common.Hash {
+	return crypto.Keccak256Hash(r.payload())
+}
+
+// verifySignature checks that the signature over the record was made by the
+// given signer, who must be one of the signers named in the record.
+func (r *TransitionRecord) verifySignature(signer common.Address, sig []byte) error {
+	if !slices.Contains(r.Signers, signer) {
+		return fmt.Errorf("%w: %v is not an initial signer", ErrInvalidAttestation, signer)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("%w: signature of %v has %d bytes", ErrInvalidAttestation, signer, len(sig))
+	}
+	pubkey, err := crypto.Ecrecover(crypto.Keccak256(r.payload()), sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	var recovered common.Address
+	copy(recovered[:], crypto.Keccak256(pubkey[1:])[12:])
+	if recovered != signer {
+		return fmt.Errorf("%w: signature of %v made by %v", ErrInvalidAttestation, signer, recovered)
+	}
+	return nil
+}
+
+// Verify checks every signature of the record.
+func (r *TransitionRecord) Verify() error {
+	for signer, sig := range r.Signatures {
+		if err := r.verifySignature(signer, sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attestations splits the record into the attestations of its signers, ordered
+// by signer.
+func (r *TransitionRecord) Attestations() []*TransitionAttestation {
+	attestations := make([]*TransitionAttestation, 0, len(r.Signatures))
+	for signer, sig := range r.Signatures {
+		attestations = append(attestations, &TransitionAttestation{
+			Number:    r.Number,
+			Hash:      r.Hash,
+			Signers:   slices.Clone(r.Signers),
+			Signer:    signer,
+			Signature: bytes.Clone(sig),
+		})
+	}
+	slices.SortFunc(attestations, func(a, b *TransitionAttestation) int {
+		return a.Signer.Cmp(b.Signer)
+	})
+	return attestations
+}
+
+// copy returns a deep copy of the record.
+func (r *TransitionRecord) copy() *TransitionRecord {
+	cpy := &TransitionRecord{
+		Number:     r.Number,
+		Hash:       r.Hash,
+		Signers:    slices.Clone(r.Signers),
+		Signatures: make(map[common.Address]hexutil.Bytes, len(r.Signatures)),
+	}
+	for signer, sig := range r.Signatures {
+		cpy.Signatures[signer] = bytes.Clone(sig)
+	}
+	return cpy
+}
+
+// TransitionAttestation is the signature of a single initial signer over a
+// transition record, the unit in which records are gossiped.
+type TransitionAttestation struct {
+	Number    uint64
+	Hash      common.Hash
+	Signers   []common.Address
+	Signer    common.Address
+	Signature []byte
+}
+
+// record returns the unsigned record the attestation is about.
+func (a *TransitionAttestation) record() *TransitionRecord {
+	return &TransitionRecord{Number: a.Number, Hash: a.Hash, Signers: a.Signers}
+}
+
+// Verify checks that the attestation was signed by the signer it names, who
+// must be one of the attested initial signers.
+func (a *TransitionAttestation) Verify() error {
+	return a.record().verifySignature(a.Signer, a.Signature)
+}
+
+// NewTransitionAttestation creates the attestation of the signer for the
+// transition at the given block, signed with the supplied signing function.
+func NewTransitionAttestation(signer common.Address, number uint64, hash common.Hash, signers []common.Address, sign SignFn) (*TransitionAttestation, error) {
+	a := &TransitionAttestation{
+		Number:  number,
+		Hash:    hash,
+		Signers: slices.Clone(signers),
+		Signer:  signer,
+	}
+	sig, err := sign(signer, a.record().payload())
+	if err != nil {
+		return nil, err
+	}
+	a.Signature = sig
+	return a, nil
+}
+
+// recordBook collects the attestations of the initial signers into transition
+// records and persists them.
+type recordBook struct {
+	db      ethdb.KeyValueStore               // Database to persist the records in (nil = in-memory only)
+	records map[common.Hash]*TransitionRecord // Known records by their ID
+	lock    sync.Mutex
+}
+
+// restore loads the records persisted by a previous run.
+func (b *recordBook) restore(db ethdb.KeyValueStore) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.db, b.records = db, make(map[common.Hash]*TransitionRecord)
+	if db == nil {
+		return
+	}
+	blob, err := db.Get(transitionRecordsKey)
+	if err != nil {
+		return // Nothing persisted yet
+	}
+	var records []*TransitionRecord
+	if err := json.Unmarshal(blob, &records); err != nil {
+		log.Error("Failed to decode persisted transition records", "err", err)
+		return
+	}
+	for _, record := range records {
+		b.records[record.ID()] = record
+	}
+}
+
+// persist stores all known records. The lock must be held.
+func (b *recordBook) persist() {
+	if b.db == nil {
+		return
+	}
+	records := make([]*TransitionRecord, 0, len(b.records))
+	for _, record := range b.records {
+		records = append(records, record)
+	}
+	blob, err := json.Marshal(records)
+	if err == nil {
+		err = b.db.Put(transitionRecordsKey, blob)
+	}
+	if err != nil {
+		log.Error("Failed to persist transition records", "err", err)
+	}
+}
+
+// AddTransitionAttestation adds an attestation received from the network or
+// made by a local signer to the record it attests. It returns an error if the
+// attestation is forged, and whether it is new and should be relayed to other
+// peers. Attestations of other signer sets than the initial one are ignored.
+func (h *Hybrid) AddTransitionAttestation(a *TransitionAttestation) (bool, error) {
+	if err := a.Verify(); err != nil {
+		attestationInvalidCounter.Inc(1)
+		return false, err
+	}
+	if !slices.Equal(a.Signers, h.initialSigners) {
+		return false, nil
+	}
+	h.records.lock.Lock()
+	defer h.records.lock.Unlock()
+
+	if h.records.records == nil {
+		h.records.records = make(map[common.Hash]*TransitionRecord)
+	}
+	id := a.record().ID()
+	record, ok := h.records.records[id]
+	if !ok {
+		if len(h.records.records) >= maxTransitionRecords {
+			return false, nil
+		}
+		if executed := h.runtime.get().Executed; executed != (common.Hash{}) && a.Hash != executed {
+			log.Warn("Transition attested at a block other than the executed one",
+				"signer", a.Signer, "number", a.Number, "hash", a.Hash, "executed", executed)
+		}
+		record = a.record()
+		record.Signers = slices.Clone(record.Signers)
+		record.Signatures = make(map[common.Address]hexutil.Bytes)
+		h.records.records[id] = record
+	}
+	if _, ok := record.Signatures[a.Signer]; ok {
+		return false, nil
+	}
+	record.Signatures[a.Signer] = bytes.Clone(a.Signature)
+	h.records.persist()
+
+	attestationReceivedCounter.Inc(1)
+	log.Info("Added transition attestation", "signer", a.Signer, "number", a.Number, "hash", a.Hash,
+		"attestations", len(record.Signatures), "signers", len(record.Signers))
+	return true, nil
+}
+
+// HasAttested reports whether the signer's attestation of the transition at
+// the given block is known.
+func (h *Hybrid) HasAttested(signer common.Address, number uint64, hash common.Hash) bool {
+	id := (&TransitionRecord{Number: number, Hash: hash, Signers: h.initialSigners}).ID()
+
+	h.records.lock.Lock()
+	defer h.records.lock.Unlock()
+
+	record, ok := h.records.records[id]
+	if !ok {
+		return false
+	}
+	_, ok = record.Signatures[signer]
+	return ok
+}
+
+// TransitionRecord returns the record of the transition executed by this node,
+// or if the node has not processed the transition yet, the one attested by the
+// most initial signers. It returns nil if no attestations are known.
+func (h *Hybrid) TransitionRecord() *TransitionRecord {
+	executed := h.runtime.get().Executed
+
+	h.records.lock.Lock()
+	defer h.records.lock.Unlock()
+
+	var best *TransitionRecord
+	for _, record := range h.records.records {
+		if executed != (common.Hash{}) && record.Hash == executed {
+			return record.copy()
+		}
+		if best == nil || len(record.Signatures) > len(best.Signatures) {
+			best = record
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.copy()
+}
+
+// TransitionAttestations returns all known attestations, for handing them to
+// newly connected peers.
+func (h *Hybrid) TransitionAttestations() []*TransitionAttestation {
+	h.records.lock.Lock()
+	defer h.records.lock.Unlock()
+
+	var attestations []*TransitionAttestation
+	for _, record := range h.records.records {
+		attestations = append(attestations, record.Attestations()...)
+	}
+	return attestations
+}
+
+// TransitionRecord returns the record of the executed transition co-signed by
+// the initial signers, or nil if none is known.
+func (api *API) TransitionRecord() *TransitionRecord {
+	return api.hybrid.TransitionRecord()
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTransitionRecord(t *testing.T) {
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		keyC, _ = crypto.GenerateKey()
+		signerA = crypto.PubkeyToAddress(keyA.PublicKey)
+		signerB = crypto.PubkeyToAddress(keyB.PublicKey)
+		signerC = crypto.PubkeyToAddress(keyC.PublicKey)
+		keys    = map[common.Address]*ecdsa.PrivateKey{signerA: keyA, signerB: keyB, signerC: keyC}
+		signers = []common.Address{signerA, signerB, signerC}
+		hash    = common.Hash{0xaa}
+		db      = rawdb.NewMemoryDatabase()
+	)
+	sign := func(signer common.Address, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), keys[signer])
+	}
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithInitialSigners(signers), WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if record := h.TransitionRecord(); record != nil {
+		t.Fatalf("Record without attestations: %+v", record)
+	}
+	for _, signer := range []common.Address{signerA, signerB} {
+		a, err := NewTransitionAttestation(signer, 100, hash, signers, sign)
+		if err != nil {
+			t.Fatalf("Failed to create attestation: %v", err)
+		}
+		if relay, err := h.AddTransitionAttestation(a); err != nil || !relay {
+			t.Fatalf("Valid attestation: have %v/%v, want true/nil", relay, err)
+		}
+		// Repeated attestations are not relayed again
+		if relay, err := h.AddTransitionAttestation(a); err != nil || relay {
+			t.Fatalf("Repeated attestation: have %v/%v, want false/nil", relay, err)
+		}
+	}
+	if !h.HasAttested(signerA, 100, hash) || h.HasAttested(signerC, 100, hash) {
+		t.Fatal("Attestation tracking mismatch")
+	}
+	// Forged attestations are rejected, attestations of other signer sets ignored
+	forged, _ := NewTransitionAttestation(signerC, 100, hash, signers, sign)
+	forged.Signer = signerB
+	if _, err := h.AddTransitionAttestation(forged); !errors.Is(err, ErrInvalidAttestation) {
+		t.Fatalf("Forged attestation: have %v, want %v", err, ErrInvalidAttestation)
+	}
+	other, _ := NewTransitionAttestation(signerC, 100, hash, []common.Address{signerC}, sign)
+	if relay, err := h.AddTransitionAttestation(other); err != nil || relay {
+		t.Fatalf("Foreign attestation: have %v/%v, want false/nil", relay, err)
+	}
+	record := h.TransitionRecord()
+	if record == nil || record.Number != 100 || record.Hash != hash || len(record.Signatures) != 2 {
+		t.Fatalf("Record mismatch: %+v", record)
+	}
+	if err := record.Verify(); err != nil {
+		t.Fatalf("Record failed to verify: %v", err)
+	}
+	if attestations := h.TransitionAttestations(); len(attestations) != 2 {
+		t.Fatalf("Attestation count mismatch: have %d, want 2", len(attestations))
+	}
+	// Records survive restarts
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithInitialSigners(signers), WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to restart hybrid engine: %v", err)
+	}
+	if restored := h.TransitionRecord(); restored == nil || len(restored.Signatures) != 2 {
+		t.Fatalf("Restored record mismatch: %+v", restored)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxReorgReports is the number of most recent reorg reports retained.
+const maxReorgReports = 32
+
+var (
+	reorgReportCounter   = metrics.NewRegisteredCounter("hybrid/reorg/reports", nil)
+	reorgCrossingCounter = metrics.NewRegisteredCounter("hybrid/reorg/crossing", nil)
+	reorgDroppedCounter  = metrics.NewRegisteredCounter("hybrid/reorg/dropped", nil)
+	reorgAddedCounter    = metrics.NewRegisteredCounter("hybrid/reorg/added", nil)
+)
+
+// ReorgBlock is a block dropped from or added to the canonical chain by a reorg.
+type ReorgBlock struct {
+	Number uint64         `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	Sealer common.Address `json:"sealer"`
+	Era    string         `json:"era"` // Consensus era of the block ("pos" or "poa")
+}
+
+// ReorgReport describes a reorg touching blocks close to the transition.
+type ReorgReport struct {
+	Time           uint64       `json:"time"` // Unix time the reorg happened at
+	AncestorNumber uint64       `json:"ancestorNumber"`
+	AncestorHash   common.Hash  `json:"ancestorHash"`
+	Depth          uint64       `json:"depth"`      // Number of blocks dropped from the canonical chain
+	Dropped        []ReorgBlock `json:"dropped"`    // Dropped blocks within the window, from the old head down
+	Added          []ReorgBlock `json:"added"`      // Added blocks within the window, from the new head down
+	DroppedEra     string       `json:"droppedEra"` // Era of the dropped blocks ("pos", "poa" or "mixed")
+	AddedEra       string       `json:"addedEra"`   // Era of the added blocks ("pos", "poa" or "mixed")
+}
+
+// reorgReporter reports the reorgs touching a window around the transition.
+type reorgReporter struct {
+	window uint64
+	feed   event.Feed
+
+	reports []*ReorgReport
+	lock    sync.Mutex
+}
+
+func newReorgReporter(window uint64) *reorgReporter {
+	return &reorgReporter{window: window}
+}
+
+// ObserveReorg implements consensus.ReorgObserver, reporting reorgs that drop or
+// add blocks within the reorg report window around the transition.
+func (h *Hybrid) ObserveReorg(ancestor *types.Header, dropped, added []*types.Header) {
+	r := h.reorgs
+	if r == nil || !h.reorgTouchesWindow(dropped) && !h.reorgTouchesWindow(added) {
+		return
+	}
+	report := &ReorgReport{
+		Time:           uint64(time.Now().Unix()),
+		AncestorNumber: ancestor.Number.Uint64(),
+		AncestorHash:   ancestor.Hash(),
+		Depth:          uint64(len(dropped)),
+		Dropped:        h.reorgBlocks(dropped),
+		Added:          h.reorgBlocks(added),
+	}
+	report.DroppedEra = reorgEra(report.Dropped)
+	report.AddedEra = reorgEra(report.Added)
+
+	reorgReportCounter.Inc(1)
+	reorgDroppedCounter.Inc(int64(len(dropped)))
+	reorgAddedCounter.Inc(int64(len(added)))
+	if report.DroppedEra != report.AddedEra {
+		reorgCrossingCounter.Inc(1)
+	}
+	log.Warn("Chain reorg near the PoS to PoA transition",
+		"transitionBlock", h.transitionBlock.Load(),
+		"ancestor", report.AncestorNumber, "ancestorHash", report.AncestorHash,
+		"depth", report.Depth, "added", len(report.Added),
+		"droppedEra", report.DroppedEra, "addedEra", report.AddedEra,
+		"droppedSealers", reorgSealers(report.Dropped), "addedSealers", reorgSealers(report.Added))
+
+	r.lock.Lock()
+	r.reports = append(r.reports, report)
+	if len(r.reports) > maxReorgReports {
+		r.reports = r.reports[1:]
+	}
+	r.lock.Unlock()
+
+	r.feed.Send(*report)
+}
+
+// reorgTouchesWindow returns whether any of the headers, ordered from the head
+// down, falls into the reorg report window.
+func (h *Hybrid) reorgTouchesWindow(headers []*types.Header) bool {
+	if len(headers) == 0 {
+		return false
+	}
+	first, last := headers[len(headers)-1].Number.Uint64(), headers[0].Number.Uint64()
+	return last+h.reorgs.window >= h.transitionBlock.Load() && first <= h.transitionBlock.Load()+h.reorgs.window
+}
+
+// reorgBlocks describes the headers of one side of a reorg within the window,
+// bounding the work done for deep reorgs.
+func (h *Hybrid) reorgBlocks(headers []*types.Header) []ReorgBlock {
+	blocks := []ReorgBlock{}
+	for _, header := range headers {
+		number := header.Number.Uint64()
+		if number+h.reorgs.window < h.transitionBlock.Load() || number > h.transitionBlock.Load()+h.reorgs.window {
+			continue
+		}
+		engine, era := h.posEngine, "pos"
+		if number >= h.transitionBlock.Load() {
+			engine, era = h.poaEngine, "poa"
+		}
+		sealer, _ := engine.Author(header)
+		blocks = append(blocks, ReorgBlock{Number: number, Hash: header.Hash(), Sealer: sealer, Era: era})
+	}
+	return blocks
+}
+
+// reorgEra returns the era of the blocks, "mixed" if they span the transition.
+func reorgEra(blocks []ReorgBlock) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+	era := blocks[0].Era
+	for _, block := range blocks[1:] {
+		if block.Era != era {
+			return "mixed"
+		}
+	}
+	return era
+}
+
+// reorgSealers returns the distinct sealers of the blocks.
+func reorgSealers(blocks []ReorgBlock) []common.Address {
+	var sealers []common.Address
+	for _, block := range blocks {
+		if !slices.Contains(sealers, block.Sealer) {
+			sealers = append(sealers, block.Sealer)
+		}
+	}
+	return sealers
+}
+
+// SubscribeReorgReports subscribes to the reports of reorgs near the transition.
+// Without the reporter enabled, the subscription never delivers anything.
+func (h *Hybrid) SubscribeReorgReports(ch chan<- ReorgReport) event.Subscription {
+	if h.reorgs == nil {
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			<-quit
+			return nil
+		})
+	}
+	return h.reorgs.feed.Subscribe(ch)
+}
+
+// ReorgReports returns the most recent reports of reorgs near the transition,
+// oldest first, or nil if the reporter is disabled.
+func (h *Hybrid) ReorgReports() []*ReorgReport {
+	if h.reorgs == nil {
+		return nil
+	}
+	h.reorgs.lock.Lock()
+	defer h.reorgs.lock.Unlock()
+
+	return slices.Clone(h.reorgs.reports)
+}
+
+// ReorgReports returns the most recent reports of reorgs that touched blocks
+// close to the transition, oldest first.
+func (api *API) ReorgReports() []*ReorgReport {
+	return api.hybrid.ReorgReports()
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestReorgReports(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 100, WithReorgReportWindow(10))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	reports := make(chan ReorgReport, 2)
+	sub := h.SubscribeReorgReports(reports)
+	defer sub.Unsubscribe()
+
+	// branch returns the headers from number down to from+1, sealed by signer
+	branch := func(from, number uint64, signer common.Address) []*types.Header {
+		var headers []*types.Header
+		for n := number; n > from; n-- {
+			headers = append(headers, &types.Header{Number: new(big.Int).SetUint64(n), Coinbase: signer, Extra: signer[:1]})
+		}
+		return headers
+	}
+	ancestor := func(number uint64) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(number)}
+	}
+	// Reorgs far from the transition are not reported
+	h.ObserveReorg(ancestor(50), branch(50, 52, common.Address{0xaa}), branch(50, 53, common.Address{0xbb}))
+	h.ObserveReorg(ancestor(200), branch(200, 202, common.Address{0xaa}), branch(200, 203, common.Address{0xbb}))
+	if len(h.ReorgReports()) != 0 {
+		t.Fatalf("Reorgs outside the window reported: %v", h.ReorgReports())
+	}
+	// A reorg replacing PoA blocks with a branch crossing the transition again
+	signerA, signerB := common.Address{0xaa}, common.Address{0xbb}
+	h.ObserveReorg(ancestor(98), branch(98, 103, signerA), branch(98, 99, signerB))
+
+	report := <-reports
+	if report.AncestorNumber != 98 || report.Depth != 5 || len(report.Dropped) != 5 || len(report.Added) != 1 {
+		t.Fatalf("Report mismatch: %+v", report)
+	}
+	if report.DroppedEra != "mixed" || report.AddedEra != "pos" {
+		t.Errorf("Era mismatch: have dropped %s added %s, want mixed and pos", report.DroppedEra, report.AddedEra)
+	}
+	if block := report.Dropped[0]; block.Number != 103 || block.Era != "poa" || block.Sealer != signerA {
+		t.Errorf("Dropped head mismatch: %+v", block)
+	}
+	// Deep reorgs only describe the blocks within the window
+	h.ObserveReorg(ancestor(80), branch(80, 120, signerA), branch(80, 121, signerB))
+	report = <-reports
+	if report.Depth != 40 || len(report.Dropped) != 21 || len(report.Added) != 21 || report.AddedEra != "mixed" {
+		t.Errorf("Deep reorg report mismatch: depth %d, dropped %d, added %d, era %s", report.Depth, len(report.Dropped), len(report.Added), report.AddedEra)
+	}
+	if reports := (&API{hybrid: h}).ReorgReports(); len(reports) != 2 {
+		t.Errorf("Retained report count mismatch: have %d, want 2", len(reports))
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// PendingTransition is the transition block of an engine waiting for a runtime
+// trigger, such as the terminal PoS block or a manual flip. It lies beyond any
+// block the chain will reach, while leaving room for adding windows to it
+// without overflowing.
+const PendingTransition = math.MaxInt64
+
+// TransitionPending reports whether the engine is still waiting for a runtime
+// trigger to learn the transition block.
+func (h *Hybrid) TransitionPending() bool {
+	return h.transitionBlock.Load() == PendingTransition
+}
+
+// resolveTransition moves the transition from the old block to the given one,
+// handing the chain over to the PoA engine from there on. The height is
+// persisted, so the engine behaves as if it had been configured on later runs.
+// It reports whether the transition was moved, which fails if it was changed
+// concurrently.
+func (h *Hybrid) resolveTransition(old, number uint64, trigger string) bool {
+	if !h.transitionBlock.CompareAndSwap(old, number) {
+		return false
+	}
+	if c, ok := findCapability[*clique.Clique](h.poaEngine); ok {
+		c.TakeOver(number, h.initialSigners)
+	}
+	if h.db != nil {
+		rawdb.WriteResolvedTransition(h.db, number)
+		h.storeEffectiveConfig(h.effectiveConfig())
+		h.holdFreezer()
+	}
+	log.Warn("Resolved PoS to PoA transition at runtime", "transitionBlock", number, "trigger", trigger)
+	return true
+}
+
+// seedTakeOver makes a clique PoA engine start from the initial signers at the
+// transition block rather than from the genesis. Lazy engines are seeded each
+// time they are constructed, with the transition block at that time.
+func (h *Hybrid) seedTakeOver() {
+	seed := func(engine consensus.Engine) {
+		if c, ok := findCapability[*clique.Clique](engine); ok {
+			c.TakeOver(h.transitionBlock.Load(), h.initialSigners)
+		}
+	}
+	if lazy, ok := h.poaEngine.(*lazyEngine); ok {
+		lazy.built = seed
+		return
+	}
+	seed(h.poaEngine)
+}
+
+// configuredTransition returns the transition block the engine for the chain
+// config starts with: the one resolved at runtime by a previous run, the
+// configured one, or the child of the terminal block if the database already
+// contains it. Otherwise the transition is pending until triggered.
+func configuredTransition(config *params.ChainConfig, db ethdb.KeyValueReader) uint64 {
+	if db != nil {
+		if number, ok := rawdb.ReadResolvedTransition(db); ok {
+			return number
+		}
+	}
+	if config.PoSToPoATransitionBlock != nil {
+		return config.PoSToPoATransitionBlock.Uint64()
+	}
+	if db != nil && config.TerminalPoSBlockHash != nil {
+		if number, ok := rawdb.ReadHeaderNumber(db, *config.TerminalPoSBlockHash); ok {
+			return number + 1
+		}
+	}
+	return PendingTransition
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var posRetirementsCounter = metrics.NewRegisteredCounter("hybrid/pos/retirements", nil)
+
+// ConfirmationDepth returns the number of blocks the transition block needs to
+// be buried under before it is considered final.
+func (h *Hybrid) ConfirmationDepth() uint64 {
+	return h.confirmDepth
+}
+
+// TransitionFinal reports whether the transition block is final as seen from a
+// chain head at the given height. Operations crossing the transition boundary,
+// such as retiring the PoS engine, limiting reorgs or pruning PoS history, must
+// only be performed once the transition is final.
+func (h *Hybrid) TransitionFinal(head uint64) bool {
+	return h.confirmDepth > 0 && head >= h.transitionBlock.Load()+h.confirmDepth
+}
+
+// retirePoS closes a lazily constructed PoS engine once the transition block
+// is final, freeing its caches and
+// background resources. Verifying historical PoS blocks afterwards re-opens
+// the engine transparently, after which it is retired again by the next
+// sufficiently deep block. The clique snapshots of the PoS era are pruned and
+// the boundary blocks are released to the freezer too.
+func (h *Hybrid) retirePoS(number uint64) {
+	if !h.TransitionFinal(number) {
+		return
+	}
+	h.pruneSnapshots()
+	h.releaseFreezer()
+	engine, ok := h.posEngine.(*lazyEngine)
+	if !ok || !engine.initialized() {
+		return
+	}
+	released, err := engine.release()
+	if !released {
+		return
+	}
+	posRetirementsCounter.Inc(1)
+	if err != nil {
+		log.Warn("Failed to close retired PoS engine", "engine", engine.name, "error", err)
+		return
+	}
+	log.Info("Retired PoS consensus engine",
+		"engine", engine.name,
+		"blockNumber", number,
+		"transitionBlock", h.transitionBlock.Load(),
+		"depth", h.confirmDepth)
+}
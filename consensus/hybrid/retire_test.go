@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestPoSRetirement(t *testing.T) {
+	const (
+		transition = 1000
+		depth      = 64
+	)
+	var built []*trackingMockEngine
+	posEngine := Lazy("pos", func() consensus.Engine {
+		engine := newTrackingMockEngine("pos")
+		built = append(built, engine)
+		return engine
+	})
+	h, err := New(posEngine, newTrackingMockEngine("poa"), transition, WithConfirmationDepth(depth))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := &mockChainReader{}
+
+	// Process a PoS block, then a PoA block not yet deep enough
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition - 1)})
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition + depth - 1)})
+	if !initialized(posEngine) {
+		t.Fatal("PoS engine retired before reaching the confirmation depth")
+	}
+	// Reaching the depth closes the engine
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(transition + depth)})
+	if initialized(posEngine) {
+		t.Fatal("PoS engine not retired at the confirmation depth")
+	}
+	if calls := built[0].getCallCount("Close"); calls != 1 {
+		t.Fatalf("Retired PoS engine closed %d times, want 1", calls)
+	}
+	// Historical re-verification re-opens a fresh engine
+	if err := h.VerifyHeader(chain, &types.Header{Number: big.NewInt(1)}); err != nil {
+		t.Fatalf("Failed to re-verify historical header: %v", err)
+	}
+	if len(built) != 2 || built[1].getCallCount("VerifyHeader") != 1 {
+		t.Fatalf("Historical verification did not re-open the PoS engine, built %d", len(built))
+	}
+	// ... which is retired again by the next deep block, including batches
+	_, results := h.VerifyHeaders(chain, []*types.Header{{Number: big.NewInt(transition + depth + 1)}})
+	<-results
+	if initialized(posEngine) {
+		t.Fatal("Re-opened PoS engine not retired again")
+	}
+}
+
+func TestPoSRetirementDisabled(t *testing.T) {
+	posEngine := Lazy("pos", func() consensus.Engine { return newTrackingMockEngine("pos") })
+	h, err := New(posEngine, newTrackingMockEngine("poa"), 10, WithConfirmationDepth(0))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := &mockChainReader{}
+
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(1)})
+	h.VerifyHeader(chain, &types.Header{Number: big.NewInt(1_000_000)})
+	if !initialized(posEngine) {
+		t.Fatal("PoS engine retired without a confirmation depth")
+	}
+}
+
+func TestTransitionFinal(t *testing.T) {
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 1000, WithConfirmationDepth(10))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	for _, tt := range []struct {
+		head  uint64
+		final bool
+	}{
+		{0, false}, {999, false}, {1000, false}, {1009, false}, {1010, true}, {5000, true},
+	} {
+		if final := h.TransitionFinal(tt.head); final != tt.final {
+			t.Errorf("head %d: final %v, want %v", tt.head, final, tt.final)
+		}
+	}
+}
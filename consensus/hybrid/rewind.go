@@ -0,0 +1,86 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var rewindCounter = metrics.NewRegisteredCounter("hybrid/rewind/boundary", nil)
+
+// Rewound implements consensus.Rewinder, resetting the state the engine derived
+// from the blocks dropped by rewinding the chain to the given head:
+//
+//   - the clique snapshots persisted for the dropped blocks are deleted.
+//   - a transition no longer final holds the boundary blocks back from the
+//     freezer again, and prunes the PoS era snapshots and retires the PoS
+//     engine again once it becomes final anew.
+//   - below the transition block, the transition counts as not executed, so
+//     processing a transition block again announces the switch rather than
+//     raising boundary reorg alerts.
+//   - below the arming window, the imminent transition is announced and the
+//     local signer key verified again.
+//
+// The transition block itself is kept, even if it was resolved at runtime, as
+// are the records co-signed by the initial signers: the rewound chain is
+// expected to transition at the same block.
+func (h *Hybrid) Rewound(head *types.Header) {
+	if h.TransitionPending() {
+		return
+	}
+	var (
+		number     = head.Number.Uint64()
+		transition = h.transitionBlock.Load()
+	)
+	if h.db != nil {
+		if dropped, err := clique.DropSnapshots(h.db, number); err != nil {
+			log.Warn("Failed to drop clique snapshots of rewound blocks", "head", number, "dropped", dropped, "err", err)
+		} else if dropped > 0 {
+			log.Debug("Dropped clique snapshots of rewound blocks", "head", number, "dropped", dropped)
+		}
+	}
+	if !h.TransitionFinal(number) {
+		h.snapshotsPruned.Store(false)
+		h.freezerReleased.Store(false)
+		h.holdFreezer()
+	}
+	if number >= transition {
+		return
+	}
+	rearm := number+signerKeyCheckWindow < transition
+	h.runtime.update(func(state *RuntimeState) bool {
+		changed := state.Executed != (common.Hash{}) || state.Switched || (rearm && state.Armed)
+		state.Executed, state.Switched = common.Hash{}, false
+		if rearm {
+			state.Armed = false
+		}
+		return changed
+	})
+	if rearm {
+		h.keyChecked.Store(false)
+	}
+	h.alerts.lock.Lock()
+	h.alerts.missed = nil
+	h.alerts.lock.Unlock()
+
+	rewindCounter.Inc(1)
+	log.Warn("Rewound chain below the PoS to PoA transition", "head", number, "transitionBlock", transition, "rearmed", rearm)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestRewindAcrossTransition(t *testing.T) {
+	const (
+		transition = 200
+		depth      = 10
+	)
+	tests := []struct {
+		name    string
+		head    uint64
+		alerts  []AlertKind // Alerts raised re-processing the blocks up to the transition
+		armed   bool        // Whether the transition stays armed
+		reset   bool        // Whether the executed transition is reset
+		snaps   []uint64    // Clique snapshots kept
+		retired bool        // Whether the transition stays final
+	}{
+		{"final", transition + depth, nil, true, false, []uint64{199, 205, 210}, true},
+		{"exact", transition, nil, true, false, []uint64{199}, false},
+		{"below", transition - 1, []AlertKind{AlertTransitionExecuted}, true, true, []uint64{199}, false},
+		{"far", transition - 150, []AlertKind{AlertTransitionArmed, AlertTransitionExecuted}, false, true, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				db    = rawdb.NewMemoryDatabase()
+				chain = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+			)
+			snapshot := func(number uint64) []byte {
+				return append(rawdb.CliqueSnapshotPrefix, common.BigToHash(new(big.Int).SetUint64(number+1)).Bytes()...)
+			}
+			for _, number := range []uint64{199, 205, 210, 215} {
+				db.Put(snapshot(number), fmt.Appendf(nil, `{"number":%d}`, number))
+			}
+			h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, transition, WithDatabase(db), WithConfirmationDepth(depth), WithFreezeWindow(64))
+			if err != nil {
+				t.Fatalf("Failed to create hybrid engine: %v", err)
+			}
+			defer h.Close()
+
+			alerts := make(chan Alert, 16)
+			sub := h.SubscribeAlerts(alerts)
+			defer sub.Unsubscribe()
+
+			process := func(number uint64, extra byte) {
+				header := &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{extra}}
+				if parent := chain.headers[number-1]; parent != nil {
+					header.ParentHash = parent.Hash()
+				}
+				chain.headers[number] = header
+				h.Finalize(chain, header, nil, nil)
+			}
+			drain := func() []AlertKind {
+				var kinds []AlertKind
+				for {
+					select {
+					case alert := <-alerts:
+						kinds = append(kinds, alert.Kind)
+					case <-time.After(50 * time.Millisecond):
+						return kinds
+					}
+				}
+			}
+			// Execute the transition and make it final
+			for i := uint64(1); i <= transition+depth+5; i++ {
+				process(i, 0)
+			}
+			if err := h.VerifyHeader(chain, chain.headers[transition+depth]); err != nil {
+				t.Fatalf("Failed to verify header: %v", err)
+			}
+			if !h.snapshotsPruned.Load() || !h.freezerReleased.Load() || rawdb.ReadFreezeHold(db) != nil {
+				t.Fatal("Final transition did not release the boundary")
+			}
+			drain()
+			executed := chain.headers[transition].Hash()
+
+			// Rewind the chain and check the engine state is reset accordingly
+			h.Rewound(chain.headers[test.head])
+			for number := range chain.headers {
+				if number > test.head {
+					delete(chain.headers, number)
+				}
+			}
+			state := h.RuntimeState()
+			if state.Armed != test.armed {
+				t.Errorf("Armed mismatch: have %v, want %v", state.Armed, test.armed)
+			}
+			if reset := state.Executed == (common.Hash{}); reset != test.reset {
+				t.Errorf("Executed transition reset mismatch: have %v, want %v", reset, test.reset)
+			}
+			if !test.reset && state.Executed != executed {
+				t.Errorf("Executed transition mismatch: have %x, want %x", state.Executed, executed)
+			}
+			if state.Switched == test.reset {
+				t.Errorf("Switched mismatch: have %v, want %v", state.Switched, !test.reset)
+			}
+			if h.keyChecked.Load() {
+				t.Error("Signer key check not reset")
+			}
+			for _, number := range []uint64{199, 205, 210, 215} {
+				if ok, _ := db.Has(snapshot(number)); ok != slices.Contains(test.snaps, number) {
+					t.Errorf("Snapshot %d: kept %v, want %v", number, ok, !ok)
+				}
+			}
+			if retired := h.snapshotsPruned.Load() && h.freezerReleased.Load(); retired != test.retired {
+				t.Errorf("Retirement mismatch: have %v, want %v", retired, test.retired)
+			}
+			if held := rawdb.ReadFreezeHold(db) != nil; held == test.retired {
+				t.Errorf("Freeze hold mismatch: have %v, want %v", held, !test.retired)
+			}
+			if h.UsesPoA(transition-1) || !h.UsesPoA(transition) {
+				t.Error("Transition block not kept across the rewind")
+			}
+			// Re-sync on a competing branch, which must not be reported as a
+			// boundary reorg but execute the transition anew
+			for i := test.head + 1; i <= transition; i++ {
+				process(i, 1)
+			}
+			if kinds := drain(); !slices.Equal(kinds, test.alerts) {
+				t.Fatalf("Alerts mismatch after rewind: have %v, want %v", kinds, test.alerts)
+			}
+			if test.reset && h.RuntimeState().Executed != chain.headers[transition].Hash() {
+				t.Error("Re-processed transition block not recorded as executed")
+			}
+			// The transition becomes final again, releasing the boundary anew
+			for i := uint64(transition + 1); i <= transition+depth; i++ {
+				process(i, 1)
+			}
+			if err := h.VerifyHeader(chain, chain.headers[transition+depth]); err != nil {
+				t.Fatalf("Failed to verify header: %v", err)
+			}
+			if !h.snapshotsPruned.Load() || !h.freezerReleased.Load() || rawdb.ReadFreezeHold(db) != nil {
+				t.Fatal("Final transition did not release the boundary after the rewind")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// runtimeStateKey is the database key the runtime state is persisted under.
+var runtimeStateKey = []byte("hybrid-runtime-state")
+
+// RuntimeState is the progress of the engine through the transition, kept
+// across restarts so that one-off actions are neither repeated nor skipped
+// when the node restarts, even in the middle of the transition.
+type RuntimeState struct {
+	Armed    bool        `json:"armed"`    // Whether the imminent transition was announced
+	Switched bool        `json:"switched"` // Whether the switch to the PoA engine was announced
+	Executed common.Hash `json:"executed"` // Hash of the first processed transition block
+}
+
+// runtimeState holds the runtime state and persists every change of it.
+type runtimeState struct {
+	db    ethdb.KeyValueStore // Database to persist the state in (nil = in-memory only)
+	state RuntimeState
+	lock  sync.Mutex
+}
+
+// load restores the state persisted by a previous run.
+func (r *runtimeState) load(db ethdb.KeyValueStore) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.db = db
+	if db == nil {
+		return
+	}
+	blob, err := db.Get(runtimeStateKey)
+	if err != nil {
+		return // Nothing persisted yet
+	}
+	if err := json.Unmarshal(blob, &r.state); err != nil {
+		log.Error("Failed to decode persisted hybrid runtime state", "err", err)
+		return
+	}
+	log.Info("Restored hybrid runtime state", "armed", r.state.Armed, "switched", r.state.Switched, "executed", r.state.Executed)
+}
+
+// get returns a copy of the current state.
+func (r *runtimeState) get() RuntimeState {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.state
+}
+
+// update applies fn to the state atomically, persisting the result if fn
+// reports a change. Persistence failures are logged, the change takes effect
+// in memory regardless.
+func (r *runtimeState) update(fn func(state *RuntimeState) bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	next := r.state
+	if !fn(&next) {
+		return
+	}
+	r.state = next
+	if r.db == nil {
+		return
+	}
+	blob, err := json.Marshal(next)
+	if err == nil {
+		err = r.db.Put(runtimeStateKey, blob)
+	}
+	if err != nil {
+		log.Error("Failed to persist hybrid runtime state", "err", err)
+	}
+}
+
+// RuntimeState returns the progress of the engine through the transition.
+func (h *Hybrid) RuntimeState() RuntimeState {
+	return h.runtime.get()
+}
+
+// ExecutedTransition returns the transition block number if the persisted
+// runtime state records the transition block as processed.
+func (h *Hybrid) ExecutedTransition() (uint64, bool) {
+	if h.runtime.get().Executed == (common.Hash{}) {
+		return 0, false
+	}
+	return h.transitionBlock.Load(), true
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestRuntimeStateRestart(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		chain = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	)
+	start := func() (*Hybrid, chan Alert) {
+		h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 200, WithDatabase(db))
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		alerts := make(chan Alert, 16)
+		h.SubscribeAlerts(alerts)
+		return h, alerts
+	}
+	process := func(h *Hybrid, number uint64, extra byte) *types.Header {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte{extra}}
+		if parent := chain.headers[number-1]; parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		chain.headers[number] = header
+		h.Finalize(chain, header, nil, nil)
+		return header
+	}
+	drain := func(alerts chan Alert) []AlertKind {
+		var kinds []AlertKind
+		for {
+			select {
+			case alert := <-alerts:
+				kinds = append(kinds, alert.Kind)
+			case <-time.After(50 * time.Millisecond):
+				return kinds
+			}
+		}
+	}
+	// Arm the transition, then restart before reaching it
+	h, alerts := start()
+	for i := uint64(1); i < 100; i++ {
+		process(h, i, 0)
+	}
+	if kinds := drain(alerts); len(kinds) != 1 || kinds[0] != AlertTransitionArmed {
+		t.Fatalf("Alerts mismatch before restart: %v", kinds)
+	}
+	h.Close()
+
+	// The restarted engine must not announce the transition again, but execute it
+	h, alerts = start()
+	if state := h.RuntimeState(); !state.Armed || state.Executed != (common.Hash{}) {
+		t.Fatalf("Runtime state not restored: %+v", state)
+	}
+	if number, ok := h.ExecutedTransition(); ok {
+		t.Fatalf("Transition reported executed at %d before reaching it", number)
+	}
+	for i := uint64(100); i <= 200; i++ {
+		process(h, i, 0)
+	}
+	transition := chain.headers[200]
+	if kinds := drain(alerts); len(kinds) != 1 || kinds[0] != AlertTransitionExecuted {
+		t.Fatalf("Alerts mismatch after restart: %v", kinds)
+	}
+	h.Close()
+
+	// A competing transition block after another restart is a boundary reorg
+	h, alerts = start()
+	if state := h.RuntimeState(); state.Executed != transition.Hash() {
+		t.Fatalf("Executed transition not restored: have %x, want %x", state.Executed, transition.Hash())
+	}
+	if number, ok := h.ExecutedTransition(); !ok || number != 200 {
+		t.Fatalf("Executed transition mismatch: have %d (%v), want 200", number, ok)
+	}
+	process(h, 200, 1)
+	if kinds := drain(alerts); len(kinds) != 1 || kinds[0] != AlertBoundaryReorg {
+		t.Fatalf("Alerts mismatch for competing transition block: %v", kinds)
+	}
+	h.Close()
+}
+
+func TestRuntimeStateSwitch(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 10, WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	h.selectEngine(9)
+	if h.RuntimeState().Switched {
+		t.Fatal("Switch recorded before transition")
+	}
+	h.selectEngine(10)
+	if !h.RuntimeState().Switched {
+		t.Fatal("Switch not recorded at transition")
+	}
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 10, WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to recreate hybrid engine: %v", err)
+	}
+	if !h.RuntimeState().Switched {
+		t.Fatal("Switch not restored")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// SchemaVersion is the version of the on-disk format of the state the engine
+// persists, such as signer proposals, double-sign evidence and the effective
+// configuration. It must be bumped along with a new migration whenever that
+// format changes.
+const SchemaVersion = 1
+
+// schemaVersionKey is the database key the schema version is persisted under.
+var schemaVersionKey = []byte("hybrid-schema-version")
+
+// ErrSchemaTooNew is returned if the persisted engine state was written by a
+// newer release using an unknown format.
+var ErrSchemaTooNew = errors.New("hybrid database schema too new")
+
+// migrations upgrade the persisted engine state: migrations[i] converts the
+// state from schema version i to i+1. Databases without a version record are
+// at version 0, the format predating versioning.
+var migrations = []func(db ethdb.KeyValueStore) error{
+	// Version 1 only introduces the version record, the format is unchanged.
+	func(db ethdb.KeyValueStore) error { return nil },
+}
+
+// ReadSchemaVersion returns the schema version of the persisted engine state,
+// or 0 if none was recorded.
+func ReadSchemaVersion(db ethdb.KeyValueReader) uint64 {
+	blob, err := db.Get(schemaVersionKey)
+	if err != nil || len(blob) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(blob)
+}
+
+// writeSchemaVersion records the schema version of the persisted engine state.
+func writeSchemaVersion(db ethdb.KeyValueWriter, version uint64) error {
+	return db.Put(schemaVersionKey, binary.BigEndian.AppendUint64(nil, version))
+}
+
+// migrateSchema upgrades the persisted engine state to SchemaVersion.
+func migrateSchema(db ethdb.KeyValueStore) error {
+	return applyMigrations(db, migrations[:SchemaVersion])
+}
+
+// applyMigrations upgrades the persisted engine state to the version reached
+// by the given migrations, one version at a time so an interrupted upgrade
+// resumes where it stopped. State written by a newer release is refused rather
+// than misinterpreted.
+func applyMigrations(db ethdb.KeyValueStore, steps []func(db ethdb.KeyValueStore) error) error {
+	var (
+		version = ReadSchemaVersion(db)
+		target  = uint64(len(steps))
+	)
+	if version > target {
+		return fmt.Errorf("%w: have %d, support up to %d", ErrSchemaTooNew, version, target)
+	}
+	for ; version < target; version++ {
+		if err := steps[version](db); err != nil {
+			return fmt.Errorf("hybrid schema migration %d -> %d failed: %w", version, version+1, err)
+		}
+		if err := writeSchemaVersion(db, version+1); err != nil {
+			return err
+		}
+		log.Info("Migrated hybrid database schema", "from", version, "to", version+1)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+func TestSchemaMigration(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	// Legacy state without a version record is kept and upgraded
+	if err := db.Put(proposalsKey, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithDatabase(db)); err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if version := ReadSchemaVersion(db); version != SchemaVersion {
+		t.Errorf("Schema version mismatch: have %d, want %d", version, SchemaVersion)
+	}
+	if blob, _ := db.Get(proposalsKey); string(blob) != `{}` {
+		t.Errorf("Legacy state lost during migration: %q", blob)
+	}
+	// State written by a newer release is refused
+	if err := writeSchemaVersion(db, SchemaVersion+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithDatabase(db)); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("Expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestSchemaMigrationSteps(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		applied []int
+		failing = errors.New("failing migration")
+		fail    = true
+	)
+	steps := []func(ethdb.KeyValueStore) error{
+		func(ethdb.KeyValueStore) error { applied = append(applied, 0); return nil },
+		func(ethdb.KeyValueStore) error {
+			if fail {
+				return failing
+			}
+			applied = append(applied, 1)
+			return nil
+		},
+	}
+	// A failing step keeps the completed ones recorded
+	if err := applyMigrations(db, steps); !errors.Is(err, failing) {
+		t.Fatalf("Expected failing migration, got %v", err)
+	}
+	if version := ReadSchemaVersion(db); version != 1 {
+		t.Fatalf("Completed migration not recorded: have %d, want 1", version)
+	}
+	// A retry resumes after the last completed step
+	fail = false
+	if err := applyMigrations(db, steps); err != nil {
+		t.Fatalf("Migration failed: %v", err)
+	}
+	if len(applied) != 2 || applied[0] != 0 || applied[1] != 1 {
+		t.Errorf("Applied migrations mismatch: have %v, want [0 1]", applied)
+	}
+	if version := ReadSchemaVersion(db); version != 2 {
+		t.Errorf("Schema version mismatch: have %d, want 2", version)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// sealerHeadKey is the database key the number of the last indexed block is
+	// persisted under.
+	sealerHeadKey = []byte("hybrid-sealer-head")
+
+	// sealerBlockPrefix prefixes the index entries, keyed by signer and block
+	// number, holding the hash of the block sealed.
+	sealerBlockPrefix = []byte("hybrid-sealer-b")
+
+	// sealerNumberPrefix prefixes the hashes of the indexed blocks, keyed by
+	// number, used to detect the blocks reorged out since they were indexed.
+	sealerNumberPrefix = []byte("hybrid-sealer-n")
+)
+
+// sealerIndexBatch is the number of blocks indexed between database writes.
+const sealerIndexBatch = 1024
+
+// SealedBlock identifies a block sealed by a PoA signer.
+type SealedBlock struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+}
+
+// sealerIndex maps PoA signers to the canonical blocks they sealed. The index
+// is extended up to the chain head whenever the head changes, so queries only
+// read it.
+type sealerIndex struct {
+	db   ethdb.KeyValueStore
+	lock sync.Mutex
+}
+
+// init sets the database the index is persisted in, keeping it in memory if
+// no database is available.
+func (idx *sealerIndex) init(db ethdb.KeyValueStore) {
+	if db == nil {
+		db = memorydb.New()
+	}
+	idx.db = db
+}
+
+func sealerBlockKey(signer common.Address, number uint64) []byte {
+	key := append(append([]byte{}, sealerBlockPrefix...), signer.Bytes()...)
+	return binary.BigEndian.AppendUint64(key, number)
+}
+
+func sealerNumberKey(number uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, sealerNumberPrefix...), number)
+}
+
+// head returns the number of the last indexed block.
+func (idx *sealerIndex) head() (uint64, bool) {
+	blob, err := idx.db.Get(sealerHeadKey)
+	if err != nil || len(blob) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(blob), true
+}
+
+// update indexes the canonical PoA blocks not yet indexed. Blocks reorged out
+// since they were indexed are indexed again from their canonical replacement.
+func (idx *sealerIndex) update(chain consensus.ChainHeaderReader, engine consensus.Engine, transition uint64) error {
+	current := chain.CurrentHeader()
+	if current == nil || current.Number.Uint64() < transition {
+		return nil
+	}
+	next := transition
+	if head, ok := idx.head(); ok {
+		// Find the last indexed block still canonical
+		for number := min(head, current.Number.Uint64()); number >= transition; number-- {
+			hash, _ := idx.db.Get(sealerNumberKey(number))
+			if header := chain.GetHeaderByNumber(number); header != nil && common.BytesToHash(hash) == header.Hash() {
+				next = number + 1
+				break
+			}
+			if number == 0 {
+				break
+			}
+		}
+	}
+	batch := idx.db.NewBatch()
+	for number := next; number <= current.Number.Uint64(); number++ {
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return fmt.Errorf("%w: #%d", errUnknownBlock, number)
+		}
+		signer, err := engine.Author(header)
+		if err != nil {
+			return fmt.Errorf("failed to recover the sealer of block %d: %w", number, err)
+		}
+		hash := header.Hash()
+		batch.Put(sealerBlockKey(signer, number), hash.Bytes())
+		batch.Put(sealerNumberKey(number), hash.Bytes())
+		if (number-next+1)%sealerIndexBatch == 0 || number == current.Number.Uint64() {
+			batch.Put(sealerHeadKey, binary.BigEndian.AppendUint64(nil, number))
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if next <= current.Number.Uint64() {
+		log.Debug("Updated sealer index", "from", next, "to", current.Number)
+	}
+	return nil
+}
+
+// sealed returns the canonical blocks within [from, to] sealed by the signer.
+// Entries of blocks reorged out are skipped.
+func (idx *sealerIndex) sealed(chain consensus.ChainHeaderReader, signer common.Address, from, to uint64) []SealedBlock {
+	prefix := append(append([]byte{}, sealerBlockPrefix...), signer.Bytes()...)
+	it := idx.db.NewIterator(prefix, binary.BigEndian.AppendUint64(nil, from))
+	defer it.Release()
+
+	var blocks []SealedBlock
+	for it.Next() {
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			break
+		}
+		hash := common.BytesToHash(it.Value())
+		if header := chain.GetHeaderByNumber(number); header == nil || header.Hash() != hash {
+			continue
+		}
+		blocks = append(blocks, SealedBlock{Number: hexutil.Uint64(number), Hash: hash})
+	}
+	return blocks
+}
+
+// IndexSealers extends the sealer index up to the current head of the chain,
+// reindexing the blocks reorged out since the previous update. It is meant to
+// be called on every new chain head.
+func (h *Hybrid) IndexSealers(chain consensus.ChainHeaderReader) error {
+	h.sealers.lock.Lock()
+	defer h.sealers.lock.Unlock()
+
+	return h.sealers.update(chain, h.poaEngine, h.transitionBlock.Load())
+}
+
+// SealedBlocks returns the canonical PoA blocks sealed by the given signer in
+// the given range, which defaults to the transition block up to the current
+// head. Blocks the index did not catch up with yet are not reported.
+func (api *API) SealedBlocks(signer common.Address, from, to *rpc.BlockNumber) ([]SealedBlock, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil || head.Number.Uint64() < api.hybrid.transitionBlock.Load() {
+		return nil, fmt.Errorf("%w: no PoA blocks yet", ErrTransitionNotReached)
+	}
+	transition := api.hybrid.transitionBlock.Load()
+	start, end, err := resolveRange(from, to, transition, transition, head.Number.Uint64(), 0)
+	if err != nil {
+		return nil, err
+	}
+	blocks := api.hybrid.sealers.sealed(api.chain, signer, start, end)
+	if blocks == nil {
+		blocks = []SealedBlock{}
+	}
+	return blocks, nil
+}
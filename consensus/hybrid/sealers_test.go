@@ -0,0 +1,118 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestSealedBlocks(t *testing.T) {
+	var (
+		signerA = common.Address{0xaa}
+		signerB = common.Address{0xbb}
+		chain   = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	)
+	// extend adds blocks up to head, sealed by A at even and B at odd heights
+	// unless all are sealed by the given signer
+	extend := func(from, head uint64, sealer *common.Address) {
+		for i := from; i <= head; i++ {
+			header := &types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(0)}
+			if i > 0 {
+				header.ParentHash = chain.headers[i-1].Hash()
+			}
+			if i >= 10 {
+				header.Coinbase = signerA
+				if i%2 == 1 {
+					header.Coinbase = signerB
+				}
+				if sealer != nil {
+					header.Coinbase = *sealer
+				}
+			}
+			chain.headers[i] = header
+		}
+	}
+	numbers := func(blocks []SealedBlock) []uint64 {
+		var list []uint64
+		for _, block := range blocks {
+			if chain.headers[uint64(block.Number)].Hash() != block.Hash {
+				t.Fatalf("Block %d hash mismatch", block.Number)
+			}
+			list = append(list, uint64(block.Number))
+		}
+		return list
+	}
+	db := rawdb.NewMemoryDatabase()
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10, WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	api := &API{chain: chain, hybrid: h}
+
+	extend(0, 20, nil)
+	if blocks, _ := api.SealedBlocks(signerA, nil, nil); len(blocks) != 0 {
+		t.Fatalf("Blocks reported before indexing: %v", numbers(blocks))
+	}
+	if err := h.IndexSealers(chain); err != nil {
+		t.Fatalf("Failed to index sealers: %v", err)
+	}
+	blocks, err := api.SealedBlocks(signerA, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to look up sealed blocks: %v", err)
+	}
+	if have, want := numbers(blocks), []uint64{10, 12, 14, 16, 18, 20}; !slices.Equal(have, want) {
+		t.Fatalf("Blocks of A mismatch: have %v, want %v", have, want)
+	}
+	from, to := rpc.BlockNumber(12), rpc.BlockNumber(17)
+	if blocks, _ = api.SealedBlocks(signerB, &from, &to); !slices.Equal(numbers(blocks), []uint64{13, 15, 17}) {
+		t.Fatalf("Blocks of B in range mismatch: %v", numbers(blocks))
+	}
+	if head, _ := h.sealers.head(); head != 20 {
+		t.Fatalf("Index head mismatch: have %d, want 20", head)
+	}
+	// Replace the blocks from 17 on by ones sealed by B only, the index must be
+	// updated from the fork point after a restart
+	extend(17, 22, &signerB)
+	h, err = New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10, WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to recreate hybrid engine: %v", err)
+	}
+	api = &API{chain: chain, hybrid: h}
+
+	if err := h.IndexSealers(chain); err != nil {
+		t.Fatalf("Failed to index sealers: %v", err)
+	}
+	if blocks, _ = api.SealedBlocks(signerA, nil, nil); !slices.Equal(numbers(blocks), []uint64{10, 12, 14, 16}) {
+		t.Fatalf("Blocks of A after reorg mismatch: %v", numbers(blocks))
+	}
+	if blocks, _ = api.SealedBlocks(signerB, nil, nil); !slices.Equal(numbers(blocks), []uint64{11, 13, 15, 17, 18, 19, 20, 21, 22}) {
+		t.Fatalf("Blocks of B after reorg mismatch: %v", numbers(blocks))
+	}
+	// Ranges are validated
+	from, to = rpc.BlockNumber(15), rpc.BlockNumber(12)
+	if _, err := api.SealedBlocks(signerA, &from, &to); err == nil {
+		t.Fatal("Inverted range accepted")
+	}
+}
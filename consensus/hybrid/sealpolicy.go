@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrPoASealBeforeTransition is returned if a PoA styled block is to be
+	// sealed before the transition, while the beacon client drives the chain.
+	ErrPoASealBeforeTransition = errors.New("PoA sealing attempted before the transition")
+
+	// ErrPoSPayloadAfterTransition is returned if a PoS styled block is to be
+	// built or sealed after the transition, where only the PoA signers may
+	// produce blocks.
+	ErrPoSPayloadAfterTransition = errors.New("PoS payload building attempted after the transition")
+)
+
+// SealPolicy guards local block production against the style of the wrong era,
+// catching operator mistakes during the cutover, such as a PoA signer started
+// too early or a beacon client still requesting payloads past the boundary.
+type SealPolicy struct {
+	transitionBlock uint64
+}
+
+// sealPolicy returns the seal policy for the current transition block, which
+// may still be resolved at runtime.
+func (h *Hybrid) sealPolicy() SealPolicy {
+	return SealPolicy{transitionBlock: h.transitionBlock.Load()}
+}
+
+// CheckPrepare returns an error if the header is to be built as a PoS payload
+// after the transition. Such headers carry the beacon randomness in their mix
+// digest, which PoA headers leave empty.
+func (p SealPolicy) CheckPrepare(header *types.Header) error {
+	number := header.Number.Uint64()
+	if number < p.transitionBlock || header.MixDigest == (common.Hash{}) {
+		return nil
+	}
+	return p.posPayloadError(number)
+}
+
+// CheckSeal returns an error if the header is styled after the other era than
+// the one it belongs to. Before the transition a non-zero difficulty marks a
+// PoA seal once the chain is driven by the beacon client, after it a zero
+// difficulty marks a PoS payload.
+func (p SealPolicy) CheckSeal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	if number >= p.transitionBlock {
+		if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+			return p.posPayloadError(number)
+		}
+		return nil
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 || !posStarted(chain, header) {
+		return nil
+	}
+	return fmt.Errorf("%w: block %d is %d blocks ahead of PoA sealing at block %d, keep the signer idle until then or check the configured transition block",
+		ErrPoASealBeforeTransition, number, p.transitionBlock-number, p.transitionBlock)
+}
+
+// posPayloadError returns the error reported for PoS payloads after the transition.
+func (p SealPolicy) posPayloadError(number uint64) error {
+	return fmt.Errorf("%w: block %d is past the transition at block %d, detach the beacon client, PoA signers build the chain from there on",
+		ErrPoSPayloadAfterTransition, number, p.transitionBlock)
+}
+
+// posStarted reports whether the chain is driven by the beacon client at the
+// parent of the header, telling PoA seals apart from pre-merge ones.
+func posStarted(chain consensus.ChainHeaderReader, header *types.Header) bool {
+	if ttd := chain.Config().TerminalTotalDifficulty; ttd != nil && ttd.Sign() == 0 {
+		return true
+	}
+	number := header.Number.Uint64()
+	if number == 0 {
+		return false
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	return parent != nil && parent.Difficulty.Sign() == 0
+}
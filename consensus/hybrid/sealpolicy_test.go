@@ -0,0 +1,76 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSealPolicy(t *testing.T) {
+	var (
+		posEngine = newTrackingMockEngine("pos")
+		poaEngine = newTrackingMockEngine("poa")
+		chain     = newTestHeaderChain(params.TestChainConfig, 5, 1)
+		results   = make(chan *types.Block, 1)
+		stop      = make(chan struct{})
+	)
+	defer close(stop)
+
+	h, err := New(posEngine, poaEngine, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// A PoA signer sealing ahead of the transition is refused
+	block := types.NewBlock(&types.Header{Number: big.NewInt(6), ParentHash: chain.headers[5].Hash(), Difficulty: big.NewInt(2)}, &types.Body{}, nil, nil)
+	if err := h.Seal(chain, block, results, stop); !errors.Is(err, ErrPoASealBeforeTransition) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrPoASealBeforeTransition)
+	}
+	// PoS blocks before the transition are sealed as usual
+	block = types.NewBlock(&types.Header{Number: big.NewInt(6), ParentHash: chain.headers[5].Hash(), Difficulty: big.NewInt(0)}, &types.Body{}, nil, nil)
+	if err := h.Seal(chain, block, results, stop); err != nil {
+		t.Fatalf("Failed to seal PoS block: %v", err)
+	}
+	// So are pre-merge blocks of chains not driven by the beacon client yet
+	premerge := &configChainReader{config: params.TestChainConfig, headers: map[uint64]*types.Header{
+		5: {Number: big.NewInt(5), Difficulty: big.NewInt(1)},
+	}}
+	block = types.NewBlock(&types.Header{Number: big.NewInt(6), ParentHash: premerge.headers[5].Hash(), Difficulty: big.NewInt(2)}, &types.Body{}, nil, nil)
+	if err := h.Seal(premerge, block, results, stop); err != nil {
+		t.Fatalf("Failed to seal pre-merge block: %v", err)
+	}
+	if have := posEngine.getCallCount("Seal"); have != 2 {
+		t.Fatalf("PoS engine seal calls mismatch: have %d, want 2", have)
+	}
+	// PoS payloads past the transition are refused while building and sealing
+	header := &types.Header{Number: big.NewInt(10), MixDigest: common.Hash{0x01}, Difficulty: big.NewInt(0)}
+	if err := h.Prepare(chain, header); !errors.Is(err, ErrPoSPayloadAfterTransition) {
+		t.Fatalf("Prepare error mismatch: have %v, want %v", err, ErrPoSPayloadAfterTransition)
+	}
+	block = types.NewBlock(&types.Header{Number: big.NewInt(11), Difficulty: big.NewInt(0)}, &types.Body{}, nil, nil)
+	if err := h.Seal(chain, block, results, stop); !errors.Is(err, ErrPoSPayloadAfterTransition) {
+		t.Fatalf("Seal error mismatch: have %v, want %v", err, ErrPoSPayloadAfterTransition)
+	}
+	if have := poaEngine.getCallCount("Seal"); have != 0 {
+		t.Fatalf("PoA engine seal calls mismatch: have %d, want 0", have)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	shadowCheckedCounter  = metrics.NewRegisteredCounter("hybrid/shadow/checked", nil)
+	shadowRejectedCounter = metrics.NewRegisteredCounter("hybrid/shadow/rejected", nil)
+	shadowIssuesGauge     = metrics.NewRegisteredGauge("hybrid/shadow/issues", nil)
+)
+
+// ShadowReport summarises the shadow PoA verification of PoS blocks inside the
+// rehearsal window before the transition.
+type ShadowReport struct {
+	Window    uint64   `json:"window"`              // Number of blocks before the transition that are shadow verified
+	Checked   uint64   `json:"checked"`             // Number of PoS headers run through the PoA checks
+	Rejected  uint64   `json:"rejected"`            // Number of PoS headers the PoA engine would have rejected
+	LastBlock uint64   `json:"lastBlock"`           // Number of the most recently shadow verified header
+	LastError string   `json:"lastError,omitempty"` // Most recent rejection reported by the PoA engine
+	Issues    []string `json:"issues"`              // Distinct configuration issues detected so far
+}
+
+// shadowVerifier runs the PoA checks against PoS headers without affecting
+// their verification outcome, collecting the findings into a report.
+type shadowVerifier struct {
+	window uint64
+
+	report ShadowReport
+	issues map[string]struct{}
+	lock   sync.Mutex
+}
+
+func newShadowVerifier(window uint64) *shadowVerifier {
+	return &shadowVerifier{
+		window: window,
+		report: ShadowReport{Window: window, Issues: []string{}},
+		issues: make(map[string]struct{}),
+	}
+}
+
+// active returns whether the header number falls into the rehearsal window.
+func (s *shadowVerifier) active(number, transition uint64) bool {
+	return s != nil && number < transition && number+s.window >= transition
+}
+
+// addIssue records a configuration issue, logging it the first time it is seen.
+func (s *shadowVerifier) addIssue(number uint64, issue string) {
+	if _, ok := s.issues[issue]; ok {
+		return
+	}
+	s.issues[issue] = struct{}{}
+	s.report.Issues = append(s.report.Issues, issue)
+	shadowIssuesGauge.Update(int64(len(s.report.Issues)))
+
+	log.Warn("Shadow PoA verification detected a transition issue", "blockNumber", number, "issue", issue)
+}
+
+// snapshot returns a copy of the current report.
+func (s *shadowVerifier) snapshot() *ShadowReport {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	report := s.report
+	report.Issues = slices.Clone(s.report.Issues)
+	return &report
+}
+
+// shadowCheck runs the era-agnostic subset of the clique header checks against
+// a PoS header: the gas bounds and the minimum block period. The seal, the
+// difficulty and the extra-data layout are left out, as PoS headers never
+// satisfy them and would be rejected unconditionally.
+func shadowCheck(config *params.CliqueConfig, parent, header *types.Header) error {
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	if parent != nil && parent.Time+config.Period > header.Time {
+		return fmt.Errorf("invalid timestamp: block time %ds is below the clique period %ds", header.Time-parent.Time, config.Period)
+	}
+	return nil
+}
+
+// shadowVerify runs the era-agnostic PoA header checks against a PoS header
+// inside the rehearsal window and inspects the chain configuration for
+// settings that will break the PoA era. Findings are only reported, never
+// enforced.
+func (h *Hybrid) shadowVerify(chain consensus.ChainHeaderReader, header *types.Header) {
+	number := header.Number.Uint64()
+	if !h.shadow.active(number, h.transitionBlock.Load()) {
+		return
+	}
+	h.shadow.lock.Lock()
+	defer h.shadow.lock.Unlock()
+
+	// Check the configuration the PoA engine will run with at the transition
+	config := chain.Config()
+	if config.Clique == nil {
+		h.shadow.addIssue(number, "chain config has no clique section")
+		return
+	}
+	if epoch := config.Clique.Epoch; epoch != 0 && h.transitionBlock.Load()%epoch != 0 {
+		h.shadow.addIssue(number, fmt.Sprintf("transition block %d is not aligned to the clique epoch %d, its signer list will be rejected", h.transitionBlock.Load(), epoch))
+	}
+	if config.IsShanghai(header.Number, header.Time) {
+		h.shadow.addIssue(number, "shanghai is active, clique rejects withdrawals after the transition")
+	}
+	if config.IsCancun(header.Number, header.Time) {
+		h.shadow.addIssue(number, "cancun is active, clique rejects blob fields after the transition")
+	}
+	// Run the header through the checks the PoA era will enforce
+	var parent *types.Header
+	if number > 0 {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	shadowCheckedCounter.Inc(1)
+	h.shadow.report.Checked++
+	h.shadow.report.LastBlock = number
+	if err := shadowCheck(config.Clique, parent, header); err != nil {
+		shadowRejectedCounter.Inc(1)
+		h.shadow.report.Rejected++
+		h.shadow.report.LastError = err.Error()
+		log.Debug("Shadow PoA verification rejected PoS header", "blockNumber", number, "error", err)
+	}
+}
+
+// ShadowReport returns the findings of the shadow PoA verification, or nil if
+// the rehearsal window is disabled.
+func (h *Hybrid) ShadowReport() *ShadowReport {
+	if h.shadow == nil {
+		return nil
+	}
+	return h.shadow.snapshot()
+}
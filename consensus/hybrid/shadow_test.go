@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// configChainReader is a chain reader mock serving a custom chain config and a
+// set of canonical headers.
+type configChainReader struct {
+	config  *params.ChainConfig
+	headers map[uint64]*types.Header
+}
+
+func (c *configChainReader) Config() *params.ChainConfig { return c.config }
+func (c *configChainReader) CurrentHeader() *types.Header {
+	var head *types.Header
+	for _, header := range c.headers {
+		if head == nil || header.Number.Cmp(head.Number) > 0 {
+			head = header
+		}
+	}
+	return head
+}
+func (c *configChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[number]; header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+func (c *configChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	return c.headers[number]
+}
+func (c *configChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range c.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+func (c *configChainReader) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return nil
+}
+
+// newTestHeaderChain creates a chain of linked headers from genesis up to and
+// including the given number, spaced by the given block time. Headers from the
+// transition block of the config on are PoA styled.
+func newTestHeaderChain(config *params.ChainConfig, head uint64, blockTime uint64) *configChainReader {
+	chain := &configChainReader{config: config, headers: make(map[uint64]*types.Header)}
+	var parent common.Hash
+	for i := uint64(0); i <= head; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(i),
+			Time:       i * blockTime,
+			Difficulty: big.NewInt(0),
+		}
+		if config.IsPoSToPoATransition(header.Number) {
+			header.Difficulty = big.NewInt(2)
+		}
+		chain.headers[i] = header
+		parent = header.Hash()
+	}
+	return chain
+}
+
+func TestShadowVerification(t *testing.T) {
+	posEngine := newTrackingMockEngine("pos")
+	poaEngine := newTrackingMockEngine("poa")
+
+	config := &params.ChainConfig{
+		ChainID:                 big.NewInt(1337),
+		LondonBlock:             big.NewInt(0),
+		TerminalTotalDifficulty: big.NewInt(0),
+		PoSToPoATransitionBlock: big.NewInt(100),
+		Clique:                  &params.CliqueConfig{Period: 15, Epoch: 30},
+		ShanghaiTime:            new(uint64),
+	}
+	chain := newTestHeaderChain(config, 120, 12)
+
+	h, err := New(posEngine, poaEngine, 100, WithShadowWindow(10))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// Headers outside the window must not be shadow verified
+	for _, number := range []uint64{50, 89, 100, 110} {
+		h.VerifyHeader(chain, chain.headers[number])
+	}
+	if calls := poaEngine.getCallCount("VerifyHeader"); calls != 2 {
+		t.Fatalf("Expected only post-transition PoA verifications, got %d", calls)
+	}
+	// Headers inside the window are checked, but never rejected
+	posEngine.setError("VerifyHeader", nil)
+	for number := uint64(90); number < 100; number++ {
+		if err := h.VerifyHeader(chain, chain.headers[number]); err != nil {
+			t.Fatalf("Shadow verification leaked into the result of block %d: %v", number, err)
+		}
+	}
+	if calls := poaEngine.getCallCount("VerifyHeader"); calls != 2 {
+		t.Fatalf("Expected no PoA verification of PoS headers, got %d calls", calls)
+	}
+	report := h.ShadowReport()
+	if report.Checked != 10 || report.Rejected != 10 || report.LastBlock != 99 {
+		t.Errorf("Unexpected shadow report counters: %+v", report)
+	}
+	if !strings.HasPrefix(report.LastError, "invalid timestamp") {
+		t.Errorf("Expected the block time to be rejected, got %q", report.LastError)
+	}
+	// Misaligned epoch and shanghai must both be flagged once
+	if len(report.Issues) != 2 {
+		t.Errorf("Expected 2 distinct issues, got %d: %v", len(report.Issues), report.Issues)
+	}
+	// Batches inside the window are shadow verified too
+	h.VerifyHeaders(chain, []*types.Header{chain.headers[97], chain.headers[98]})
+	if report := h.ShadowReport(); report.Checked != 12 {
+		t.Errorf("Expected batch headers to be shadow verified, got %d checks", report.Checked)
+	}
+	// PoS headers honouring the PoA limits pass, despite lacking a seal
+	valid, _ := New(posEngine, poaEngine, 100, WithShadowWindow(10))
+	chain = newTestHeaderChain(config, 100, 15)
+	for number := uint64(90); number < 100; number++ {
+		valid.VerifyHeader(chain, chain.headers[number])
+	}
+	if report := valid.ShadowReport(); report.Checked != 10 || report.Rejected != 0 {
+		t.Errorf("Expected valid PoS headers to pass the shadow checks: %+v", report)
+	}
+	// Disabled shadow verification reports nothing
+	disabled, _ := New(posEngine, poaEngine, 100)
+	if disabled.ShadowReport() != nil {
+		t.Error("Expected no shadow report with the window disabled")
+	}
+}
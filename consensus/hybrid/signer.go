@@ -0,0 +1,101 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// signerKeyCheckWindow is the number of blocks before the transition in which
+// the availability of the local signer key is verified again.
+const signerKeyCheckWindow = 128
+
+// ErrMissingSignerKey is returned if the node is configured to seal PoA blocks
+// with one of the initial signers, but the key of that signer is not available.
+var ErrMissingSignerKey = errors.New("local PoA signer key not available")
+
+// ErrAuthorizeUnsupported is returned by Authorize if the PoA engine is not
+// clique and thus cannot be handed a sealing account.
+var ErrAuthorizeUnsupported = errors.New("PoA engine does not support signer authorization")
+
+// KeyChecker reports whether the key of the given account is available to the
+// node, either from the local keystore or an external signer.
+type KeyChecker func(signer common.Address) bool
+
+// InitialSigners returns a copy of the signer set that becomes authorized at
+// the transition block.
+func (h *Hybrid) InitialSigners() []common.Address {
+	return slices.Clone(h.initialSigners)
+}
+
+// Authorize injects the account to seal PoA blocks with, and the function to
+// sign them with, into the wrapped clique engine, building it if lazy, so the
+// authorization flow of clique networks works unchanged on hybrid nodes.
+func (h *Hybrid) Authorize(signer common.Address, signFn clique.SignerFn) error {
+	engine, ok := findCapability[*clique.Clique](h.poaEngine)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrAuthorizeUnsupported, h.poaEngine)
+	}
+	engine.Authorize(signer, signFn)
+	return nil
+}
+
+// checkSignerKey verifies that the keys of the local signers are available if
+// the node intends to seal PoA blocks as one of the initial signers.
+func (h *Hybrid) checkSignerKey() error {
+	if len(h.localSigners) == 0 || h.hasKey == nil {
+		return nil
+	}
+	for _, signer := range h.localSigners {
+		if !slices.Contains(h.initialSigners, signer) {
+			log.Warn("Local signer is not among the initial PoA signers", "signer", signer)
+			continue
+		}
+		if !h.hasKey(signer) {
+			return fmt.Errorf("%w: %v", ErrMissingSignerKey, signer)
+		}
+	}
+	return nil
+}
+
+// recheckSignerKey verifies the local signer keys again once the chain gets
+// close to the transition, so a key removed after startup is noticed before
+// the first PoA block needs to be sealed. Failures are logged loudly on every
+// block until the key becomes available again.
+func (h *Hybrid) recheckSignerKey(number uint64) {
+	if h.keyChecked.Load() || number >= h.transitionBlock.Load() || number+signerKeyCheckWindow < h.transitionBlock.Load() {
+		return
+	}
+	if err := h.checkSignerKey(); err != nil {
+		log.Error("LOCAL SIGNER KEY UNAVAILABLE, node will not be able to seal after the transition",
+			"signers", h.localSigners,
+			"blockNumber", number,
+			"transitionBlock", h.transitionBlock.Load(),
+			"blocksUntilTransition", h.transitionBlock.Load()-number,
+			"error", err)
+		return
+	}
+	h.keyChecked.Store(true)
+	log.Info("Verified local signer keys ahead of the transition", "signers", h.localSigners,
+		"blocksUntilTransition", h.transitionBlock.Load()-number)
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSignerKeyCheck(t *testing.T) {
+	posEngine := &mockEngine{name: "pos"}
+	poaEngine := &mockEngine{name: "poa"}
+
+	signer := defaultInitialSigners[0]
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+
+	available := true
+	hasKey := func(addr common.Address) bool { return available }
+
+	// Missing key of an initial signer must fail construction
+	available = false
+	if _, err := New(posEngine, poaEngine, 1000, WithLocalSigner(signer, hasKey)); !errors.Is(err, ErrMissingSignerKey) {
+		t.Fatalf("Expected ErrMissingSignerKey, got %v", err)
+	}
+	// Signers outside of the initial set and nodes without a signer are fine
+	if _, err := New(posEngine, poaEngine, 1000, WithLocalSigner(outsider, hasKey)); err != nil {
+		t.Fatalf("Expected no error for non-initial signer, got %v", err)
+	}
+	if _, err := New(posEngine, poaEngine, 1000, WithLocalSigner(common.Address{}, hasKey)); err != nil {
+		t.Fatalf("Expected no error without a local signer, got %v", err)
+	}
+	// Available key passes the startup check
+	available = true
+	h, err := New(posEngine, poaEngine, 1000, WithLocalSigner(signer, hasKey))
+	if err != nil {
+		t.Fatalf("Expected no error with available key, got %v", err)
+	}
+	// Key removed after startup: not rechecked outside the window
+	available = false
+	h.recheckSignerKey(1000 - signerKeyCheckWindow - 1)
+	if h.keyChecked.Load() {
+		t.Fatal("Key recheck ran outside of the window")
+	}
+	// Inside the window the failure is reported and the check retried
+	h.recheckSignerKey(1000 - signerKeyCheckWindow)
+	if h.keyChecked.Load() {
+		t.Fatal("Failed key recheck marked as done")
+	}
+	available = true
+	h.recheckSignerKey(999)
+	if !h.keyChecked.Load() {
+		t.Fatal("Successful key recheck not recorded")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	// Authorization is forwarded to a lazy clique engine, building it
+	var built bool
+	h, err := New(&mockEngine{name: "pos"}, Lazy("clique", func() consensus.Engine {
+		built = true
+		return clique.New(params.AllCliqueProtocolChanges.Clique, rawdb.NewMemoryDatabase())
+	}), 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	signFn := func(accounts.Account, string, []byte) ([]byte, error) { return nil, nil }
+	if err := h.Authorize(common.Address{0xaa}, signFn); err != nil || !built {
+		t.Fatalf("Failed to authorize clique signer: %v, built %v", err, built)
+	}
+	// Other PoA engines are rejected
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if err := h.Authorize(common.Address{0xaa}, signFn); !errors.Is(err, ErrAuthorizeUnsupported) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrAuthorizeUnsupported)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SignerLister is implemented by PoA engines tracking a set of authorized
+// signers, such as clique.
+type SignerLister interface {
+	// Signers returns the signers authorized after the given header.
+	Signers(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error)
+}
+
+// SignerSetChange describes a change of the authorized PoA signer set, such as
+// a vote reaching majority or a key rotation taking effect at a checkpoint.
+type SignerSetChange struct {
+	Number  uint64           `json:"number"` // Block the change took effect with
+	Hash    common.Hash      `json:"hash"`
+	Old     []common.Address `json:"old"` // Signers authorized before the block (empty at the transition)
+	New     []common.Address `json:"new"` // Signers authorized after the block
+	Added   []common.Address `json:"added"`
+	Removed []common.Address `json:"removed"`
+}
+
+// newSignerSetChange creates the change between two signer sets.
+func newSignerSetChange(header *types.Header, old, new []common.Address) SignerSetChange {
+	change := SignerSetChange{
+		Number:  header.Number.Uint64(),
+		Hash:    header.Hash(),
+		Old:     old,
+		New:     new,
+		Added:   []common.Address{},
+		Removed: []common.Address{},
+	}
+	for _, signer := range new {
+		if !slices.Contains(old, signer) {
+			change.Added = append(change.Added, signer)
+		}
+	}
+	for _, signer := range old {
+		if !slices.Contains(new, signer) {
+			change.Removed = append(change.Removed, signer)
+		}
+	}
+	return change
+}
+
+// watchSignerSet announces a change of the authorized signer set made by the
+// given processed PoA block. The transition block announces the initial set.
+func (h *Hybrid) watchSignerSet(chain consensus.ChainHeaderReader, header *types.Header) {
+	number := header.Number.Uint64()
+	if number < h.transitionBlock.Load() {
+		return
+	}
+	engine := h.poaEngine
+	if lazy, ok := engine.(*lazyEngine); ok {
+		engine = lazy.get()
+	}
+	lister, ok := engine.(SignerLister)
+	if !ok {
+		return
+	}
+	signers, err := lister.Signers(chain, header)
+	if err != nil {
+		log.Debug("Failed to retrieve PoA signers", "number", number, "hash", header.Hash(), "err", err)
+		return
+	}
+	old := []common.Address{}
+	if number > h.transitionBlock.Load() {
+		parent := chain.GetHeader(header.ParentHash, number-1)
+		if parent == nil {
+			return
+		}
+		if old, err = lister.Signers(chain, parent); err != nil {
+			log.Debug("Failed to retrieve PoA signers", "number", number-1, "hash", header.ParentHash, "err", err)
+			return
+		}
+		if slices.Equal(old, signers) {
+			return
+		}
+	}
+	change := newSignerSetChange(header, old, signers)
+	log.Info("Authorized PoA signer set changed", "number", number, "hash", change.Hash,
+		"signers", len(signers), "added", change.Added, "removed", change.Removed)
+	h.signerSets.Send(change)
+}
+
+// SubscribeSignerSetChanges subscribes to changes of the authorized PoA signer
+// set made by processed blocks.
+func (h *Hybrid) SubscribeSignerSetChanges(ch chan<- SignerSetChange) event.Subscription {
+	return h.signerSets.Subscribe(ch)
+}
+
+// SignerSetChanges streams the changes of the authorized PoA signer set, each
+// with the old and the new set.
+func (api *API) SignerSetChanges(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		rpcSub  = notifier.CreateSubscription()
+		changes = make(chan SignerSetChange, 16)
+		sub     = api.hybrid.SubscribeSignerSetChanges(changes)
+	)
+	go func() {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case change := <-changes:
+				notifier.Notify(rpcSub.ID, change)
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// listingMockEngine is a mock PoA engine whose signer set is taken from the
+// coinbase of checkpoint-like headers: a zero coinbase keeps the parent's set,
+// any other address is added to it.
+type listingMockEngine struct {
+	mockEngine
+	initial []common.Address
+}
+
+func (m *listingMockEngine) Signers(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error) {
+	signers := slices.Clone(m.initial)
+	for number := uint64(0); number <= header.Number.Uint64(); number++ {
+		h := header
+		if number < header.Number.Uint64() {
+			h = chain.GetHeaderByNumber(number)
+		}
+		if h.Coinbase != (common.Address{}) {
+			signers = append(signers, h.Coinbase)
+		}
+	}
+	slices.SortFunc(signers, common.Address.Cmp)
+	return signers, nil
+}
+
+func TestSignerSetChanges(t *testing.T) {
+	var (
+		initial = []common.Address{{0x01}, {0x02}, {0x03}}
+		chain   = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	)
+	h, err := New(&mockEngine{name: "pos"}, &listingMockEngine{initial: initial}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	for _, api := range h.APIs(chain) {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatalf("Failed to register API: %v", err)
+		}
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	changes := make(chan SignerSetChange, 4)
+	sub, err := client.Subscribe(context.Background(), "hybrid", changes, "signerSetChanges")
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	var parent common.Hash
+	for i := uint64(0); i <= 5; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), ParentHash: parent}
+		if i == 4 {
+			header.Coinbase = common.Address{0x04}
+		}
+		chain.headers[i] = header
+		h.Finalize(chain, header, nil, nil)
+		parent = header.Hash()
+	}
+	next := func() SignerSetChange {
+		t.Helper()
+		select {
+		case change := <-changes:
+			return change
+		case <-time.After(time.Second):
+			t.Fatal("Missing signer set change")
+		}
+		return SignerSetChange{}
+	}
+	if change := next(); change.Number != 2 || len(change.Old) != 0 || !slices.Equal(change.New, initial) || len(change.Added) != 3 {
+		t.Fatalf("Transition change mismatch: %+v", change)
+	}
+	change := next()
+	if change.Number != 4 || !slices.Equal(change.Old, initial) || len(change.New) != 4 {
+		t.Fatalf("Vote change mismatch: %+v", change)
+	}
+	if len(change.Added) != 1 || change.Added[0] != (common.Address{0x04}) || len(change.Removed) != 0 {
+		t.Fatalf("Vote change diff mismatch: added %v, removed %v", change.Added, change.Removed)
+	}
+	select {
+	case change := <-changes:
+		t.Fatalf("Unexpected signer set change at %d", change.Number)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSignersAPI(t *testing.T) {
+	initial := []common.Address{{0x01}, {0x02}, {0x03}}
+	h, err := New(&mockEngine{name: "pos"}, &listingMockEngine{initial: initial}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain := newTestHeaderChain(params.TestChainConfig, 3, 12)
+	api := &API{chain: chain, hybrid: h}
+
+	if signers, err := api.Signers(nil); err != nil || !slices.Equal(signers, initial) {
+		t.Fatalf("Head signers mismatch: have %v/%v, want %v", signers, err, initial)
+	}
+	number := rpc.BlockNumber(1)
+	if _, err := api.Signers(&number); !errors.Is(err, ErrTransitionNotReached) {
+		t.Fatalf("Signers before the transition: have %v, want %v", err, ErrTransitionNotReached)
+	}
+	number = 9
+	if _, err := api.Signers(&number); !errors.Is(err, errUnknownBlock) {
+		t.Fatalf("Signers of unknown block: have %v, want %v", err, errUnknownBlock)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Ways the blocks of a snapshot view are sealed.
+const (
+	SealingBeacon = "beacon" // Proposed through the beacon chain, without a seal
+	SealingClique = "clique" // Sealed by an authorized clique signer
+)
+
+// snapshotProvider is implemented by engines keeping voting snapshots, such as
+// clique.
+type snapshotProvider interface {
+	Snapshot(chain consensus.ChainHeaderReader, header *types.Header) (*clique.Snapshot, error)
+}
+
+// SnapshotView is the sealing state after a block, normalized across the eras,
+// telling who could seal the next block without caring about the engine.
+type SnapshotView struct {
+	Number  hexutil.Uint64 `json:"number"`
+	Hash    common.Hash    `json:"hash"`
+	Engine  string         `json:"engine"`  // "pos" or "poa"
+	Sealing string         `json:"sealing"` // SealingBeacon or SealingClique
+
+	// FeeRecipient is the account the beacon chain proposer of a PoS block
+	// chose, the only sealing data the block itself carries.
+	FeeRecipient *common.Address `json:"feeRecipient,omitempty"`
+
+	// Signers are the signers authorized to seal the next block, empty for
+	// beacon blocks. The voting state is reported if the engine keeps one.
+	Signers []common.Address                `json:"signers"`
+	Recents map[uint64]common.Address       `json:"recents,omitempty"` // Recent signers by the block they sealed
+	Votes   []*clique.Vote                  `json:"votes,omitempty"`
+	Tally   map[common.Address]clique.Tally `json:"tally,omitempty"`
+}
+
+// SnapshotAt returns the sealing state after the given header. PoS blocks of the
+// beacon chain report their fee recipient, clique blocks of either era, such as
+// ones sealed under the beacon engine before the merge, the clique snapshot.
+func (h *Hybrid) SnapshotAt(chain consensus.ChainHeaderReader, header *types.Header) (*SnapshotView, error) {
+	view := &SnapshotView{
+		Number:  hexutil.Uint64(header.Number.Uint64()),
+		Hash:    header.Hash(),
+		Engine:  "poa",
+		Sealing: SealingClique,
+		Signers: []common.Address{},
+	}
+	engine := h.poaEngine
+	if header.Number.Uint64() < h.transitionBlock.Load() || h.straggler(header) {
+		view.Engine, engine = "pos", h.posEngine
+		if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+			recipient := header.Coinbase
+			view.Sealing, view.FeeRecipient = SealingBeacon, &recipient
+			return view, nil
+		}
+	}
+	if provider, ok := findCapability[snapshotProvider](engine); ok {
+		snap, err := provider.Snapshot(chain, header)
+		if err != nil {
+			return nil, err
+		}
+		view.Signers = slices.SortedFunc(maps.Keys(snap.Signers), common.Address.Cmp)
+		view.Recents, view.Votes, view.Tally = snap.Recents, snap.Votes, snap.Tally
+		return view, nil
+	}
+	lister, ok := findCapability[SignerLister](engine)
+	if !ok {
+		return nil, errSignersUnsupported
+	}
+	signers, err := lister.Signers(chain, header)
+	if err != nil {
+		return nil, err
+	}
+	view.Signers = signers
+	return view, nil
+}
+
+// GetSnapshotAt returns the sealing state after the given block, defaulting to
+// the current head, in the same shape for both eras.
+func (api *API) GetSnapshotAt(number *rpc.BlockNumber) (*SnapshotView, error) {
+	var header *types.Header
+	if number == nil || *number < 0 {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(*number))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.hybrid.SnapshotAt(api.chain, header)
+}
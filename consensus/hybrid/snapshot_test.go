@@ -0,0 +1,176 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// snapshotMockEngine is a mock clique engine serving a fixed voting snapshot.
+type snapshotMockEngine struct {
+	mockEngine
+	snap *clique.Snapshot
+}
+
+func (m *snapshotMockEngine) Snapshot(chain consensus.ChainHeaderReader, header *types.Header) (*clique.Snapshot, error) {
+	return m.snap, nil
+}
+
+// fixedSignersMockEngine is a mock PoA engine only exposing a fixed signer set.
+type fixedSignersMockEngine struct {
+	mockEngine
+	signers []common.Address
+}
+
+func (m *fixedSignersMockEngine) Signers(chain consensus.ChainHeaderReader, header *types.Header) ([]common.Address, error) {
+	return m.signers, nil
+}
+
+// innerMockEngine is a mock wrapper engine, such as beacon.
+type innerMockEngine struct {
+	mockEngine
+	inner consensus.Engine
+}
+
+func (m *innerMockEngine) InnerEngine() consensus.Engine { return m.inner }
+
+func TestSnapshotAt(t *testing.T) {
+	const transition = 100
+	var (
+		a, b, c   = common.Address{0x0a}, common.Address{0x0b}, common.Address{0x0c}
+		recipient = common.Address{0xfe}
+		vote      = &clique.Vote{Signer: a, Block: 150, Address: c, Authorize: true}
+
+		posSnap = &clique.Snapshot{
+			Signers: map[common.Address]struct{}{a: {}},
+			Recents: map[uint64]common.Address{40: a},
+		}
+		poaSnap = &clique.Snapshot{
+			Signers: map[common.Address]struct{}{b: {}, a: {}},
+			Recents: map[uint64]common.Address{149: a, 150: b},
+			Votes:   []*clique.Vote{vote},
+			Tally:   map[common.Address]clique.Tally{c: {Authorize: true, Votes: 1}},
+		}
+		chain = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	)
+	for i := uint64(0); i <= 200; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(2)}
+		if i >= 50 && i < transition {
+			header.Difficulty, header.Coinbase = new(big.Int), recipient
+		}
+		chain.headers[i] = header
+	}
+	pos := &innerMockEngine{inner: &snapshotMockEngine{snap: posSnap}}
+
+	tests := []struct {
+		name   string
+		poa    consensus.Engine
+		number rpc.BlockNumber
+		want   func(hash common.Hash) *SnapshotView
+		err    error
+	}{
+		{
+			name:   "pre-merge",
+			poa:    &snapshotMockEngine{snap: poaSnap},
+			number: 40,
+			want: func(hash common.Hash) *SnapshotView {
+				return &SnapshotView{Number: 40, Hash: hash, Engine: "pos", Sealing: SealingClique,
+					Signers: []common.Address{a}, Recents: posSnap.Recents}
+			},
+		},
+		{
+			name:   "beacon",
+			poa:    &snapshotMockEngine{snap: poaSnap},
+			number: 60,
+			want: func(hash common.Hash) *SnapshotView {
+				return &SnapshotView{Number: 60, Hash: hash, Engine: "pos", Sealing: SealingBeacon,
+					FeeRecipient: &recipient, Signers: []common.Address{}}
+			},
+		},
+		{
+			name:   "poa",
+			poa:    &snapshotMockEngine{snap: poaSnap},
+			number: 150,
+			want: func(hash common.Hash) *SnapshotView {
+				return &SnapshotView{Number: 150, Hash: hash, Engine: "poa", Sealing: SealingClique,
+					Signers: []common.Address{a, b}, Recents: poaSnap.Recents, Votes: poaSnap.Votes, Tally: poaSnap.Tally}
+			},
+		},
+		{
+			name:   "head",
+			poa:    &snapshotMockEngine{snap: poaSnap},
+			number: rpc.LatestBlockNumber,
+			want: func(hash common.Hash) *SnapshotView {
+				return &SnapshotView{Number: 200, Hash: hash, Engine: "poa", Sealing: SealingClique,
+					Signers: []common.Address{a, b}, Recents: poaSnap.Recents, Votes: poaSnap.Votes, Tally: poaSnap.Tally}
+			},
+		},
+		{
+			name:   "signers only",
+			poa:    &fixedSignersMockEngine{signers: []common.Address{c, a}},
+			number: 150,
+			want: func(hash common.Hash) *SnapshotView {
+				return &SnapshotView{Number: 150, Hash: hash, Engine: "poa", Sealing: SealingClique,
+					Signers: []common.Address{c, a}}
+			},
+		},
+		{
+			name:   "unsupported",
+			poa:    &mockEngine{name: "poa"},
+			number: 150,
+			err:    errSignersUnsupported,
+		},
+		{
+			name:   "unknown",
+			poa:    &snapshotMockEngine{snap: poaSnap},
+			number: 300,
+			err:    errUnknownBlock,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h, err := New(pos, test.poa, transition)
+			if err != nil {
+				t.Fatalf("Failed to create hybrid engine: %v", err)
+			}
+			defer h.Close()
+
+			api := &API{chain: chain, hybrid: h}
+			view, err := api.GetSnapshotAt(&test.number)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Error mismatch: have %v, want %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+			number := uint64(view.Number)
+			if want := test.want(chain.headers[number].Hash()); !reflect.DeepEqual(view, want) {
+				t.Errorf("Snapshot view mismatch:\nhave %+v\nwant %+v", view, want)
+			}
+		})
+	}
+}
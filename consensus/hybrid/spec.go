@@ -0,0 +1,164 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ConsensusSpecVersion is the version of the ConsensusSpec format, bumped on
+// changes consumers have to adapt to.
+const ConsensusSpecVersion = 1
+
+// Transition triggers reported in the consensus spec.
+const (
+	TriggerBlockNumber   = "block-number"   // Transition at the configured block
+	TriggerTerminalBlock = "terminal-block" // Transition at the child of the terminal PoS block
+	TriggerManual        = "manual"         // Transition flipped by an operator at the head
+)
+
+// Difficulty schemes reported in the consensus spec.
+const (
+	DifficultyZero       = "zero"        // Always zero, the mix digest carries the beacon randomness
+	DifficultyCliqueTurn = "clique-turn" // 2 for in-turn, 1 for out-of-turn signers
+)
+
+// Signer sources reported in the consensus spec.
+const (
+	SignerSourceBeacon     = "beacon"           // Proposers are chosen by the consensus layer
+	SignerSourceCheckpoint = "transition-extra" // Initial signers listed in the transition block's extra-data, changed by votes later
+)
+
+// Finality rules reported in the consensus spec.
+const (
+	FinalityBeacon        = "beacon-finalized" // Finalized checkpoints of the consensus layer
+	FinalityConfirmations = "confirmations"    // Irreversible past a number of confirmations
+)
+
+// EraSpec describes the consensus rules of one era of the chain.
+type EraSpec struct {
+	Name              string           `json:"name"`                        // "pos" or "poa"
+	Engine            string           `json:"engine"`                      // Engine verifying the era
+	FirstBlock        *hexutil.Uint64  `json:"firstBlock"`                  // nil while the transition is pending
+	LastBlock         *hexutil.Uint64  `json:"lastBlock,omitempty"`         // nil for an open ended era
+	Difficulty        string           `json:"difficulty"`                  // Difficulty scheme of the headers
+	SignerSource      string           `json:"signerSource"`                // Where the block producers come from
+	InitialSigners    []common.Address `json:"initialSigners,omitempty"`    // Signers authorized at the start of the era
+	Finality          string           `json:"finality"`                    // Rule by which blocks become irreversible
+	ConfirmationDepth *hexutil.Uint64  `json:"confirmationDepth,omitempty"` // Confirmations of the confirmations rule
+	Period            *hexutil.Uint64  `json:"period,omitempty"`            // Seconds between PoA blocks
+	Epoch             *hexutil.Uint64  `json:"epoch,omitempty"`             // Blocks between PoA checkpoints
+}
+
+// ConsensusSpec is a machine-readable description of the consensus rules of the
+// chain, for block explorers and indexers to adapt their pipelines to the
+// transition without hardcoding the behaviour of the fork.
+type ConsensusSpec struct {
+	Version         int            `json:"version"`
+	TransitionBlock hexutil.Uint64 `json:"transitionBlock"`
+	Pending         bool           `json:"pending,omitempty"` // Whether the transition block awaits a runtime trigger
+	Trigger         string         `json:"trigger"`
+	TerminalHash    *common.Hash   `json:"terminalHash,omitempty"`
+	GraceWindow     hexutil.Uint64 `json:"graceWindow"` // Blocks from the transition on in which PoS blocks are tolerated
+	Eras            []EraSpec      `json:"eras"`
+}
+
+// engineName returns the name of the engine, preferring the one of the engine
+// type it was built from.
+func engineName(engine consensus.Engine) string {
+	if lazy, ok := engine.(*lazyEngine); ok {
+		return lazy.name
+	}
+	return fmt.Sprintf("%T", engine)
+}
+
+// ConsensusSpec describes the consensus rules of both eras. The clique config
+// contributes the block period and epoch of the PoA era, if known.
+func (h *Hybrid) ConsensusSpec(clique *params.CliqueConfig) *ConsensusSpec {
+	var (
+		transition = h.transitionBlock.Load()
+		pending    = transition == PendingTransition
+	)
+	spec := &ConsensusSpec{
+		Version:         ConsensusSpecVersion,
+		TransitionBlock: hexutil.Uint64(transition),
+		Pending:         pending,
+		Trigger:         TriggerBlockNumber,
+		GraceWindow:     hexutil.Uint64(h.graceWindow),
+	}
+	switch {
+	case h.terminalHash != (common.Hash{}):
+		hash := h.terminalHash
+		spec.Trigger, spec.TerminalHash = TriggerTerminalBlock, &hash
+	case h.manual:
+		spec.Trigger = TriggerManual
+	}
+	first := hexutil.Uint64(0)
+	pos := EraSpec{
+		Name:         "pos",
+		Engine:       engineName(h.posEngine),
+		FirstBlock:   &first,
+		Difficulty:   DifficultyZero,
+		SignerSource: SignerSourceBeacon,
+		Finality:     FinalityBeacon,
+	}
+	poa := EraSpec{
+		Name:           "poa",
+		Engine:         engineName(h.poaEngine),
+		Difficulty:     DifficultyCliqueTurn,
+		SignerSource:   SignerSourceCheckpoint,
+		InitialSigners: h.InitialSigners(),
+		Finality:       FinalityConfirmations,
+	}
+	if !pending {
+		last, start := hexutil.Uint64(transition-1), hexutil.Uint64(transition)
+		if transition > 0 {
+			pos.LastBlock = &last
+		}
+		poa.FirstBlock = &start
+	}
+	if h.confirmDepth > 0 {
+		depth := hexutil.Uint64(h.confirmDepth)
+		poa.ConfirmationDepth = &depth
+	}
+	if clique != nil {
+		period, epoch := hexutil.Uint64(clique.Period), hexutil.Uint64(clique.Epoch)
+		poa.Period, poa.Epoch = &period, &epoch
+	}
+	// A transition at genesis leaves no PoS era
+	if !pending && transition == 0 {
+		spec.Eras = []EraSpec{poa}
+	} else {
+		spec.Eras = []EraSpec{pos, poa}
+	}
+	return spec
+}
+
+// GetConsensusSpec returns a machine-readable description of the consensus
+// rules of both eras of the chain.
+func (api *API) GetConsensusSpec() *ConsensusSpec {
+	var clique *params.CliqueConfig
+	if config := api.chain.Config(); config != nil {
+		clique = config.Clique
+	}
+	return api.hybrid.ConsensusSpec(clique)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestConsensusSpec(t *testing.T) {
+	h, err := New(Lazy("beacon+clique", nil), Lazy("clique", nil), 100, WithConfirmationDepth(64))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	spec := h.ConsensusSpec(&params.CliqueConfig{Period: 5, Epoch: 30000})
+	if spec.Version != ConsensusSpecVersion || spec.Trigger != TriggerBlockNumber || spec.Pending || len(spec.Eras) != 2 {
+		t.Fatalf("Spec mismatch: %+v", spec)
+	}
+	pos, poa := spec.Eras[0], spec.Eras[1]
+	if pos.Engine != "beacon+clique" || *pos.FirstBlock != 0 || *pos.LastBlock != 99 || pos.Difficulty != DifficultyZero || pos.Finality != FinalityBeacon {
+		t.Errorf("PoS era mismatch: %+v", pos)
+	}
+	if poa.Engine != "clique" || *poa.FirstBlock != 100 || poa.LastBlock != nil || poa.Difficulty != DifficultyCliqueTurn ||
+		poa.Finality != FinalityConfirmations || *poa.ConfirmationDepth != 64 || *poa.Period != 5 || len(poa.InitialSigners) == 0 {
+		t.Errorf("PoA era mismatch: %+v", poa)
+	}
+	// Pending transitions leave the era boundaries open
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, PendingTransition, WithManualTransition(true))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	spec = h.ConsensusSpec(nil)
+	if spec.Trigger != TriggerManual || !spec.Pending || spec.Eras[0].LastBlock != nil || spec.Eras[1].FirstBlock != nil || spec.Eras[1].Period != nil {
+		t.Fatalf("Pending spec mismatch: %+v", spec)
+	}
+	// Transitions at genesis have no PoS era
+	h, err = New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if spec = h.ConsensusSpec(nil); len(spec.Eras) != 1 || spec.Eras[0].Name != "poa" {
+		t.Fatalf("Genesis spec mismatch: %+v", spec)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxStatsRange is the maximum number of blocks validator statistics can be
+// computed over in a single request.
+const maxStatsRange = 100_000
+
+// diffInTurn is the difficulty of blocks sealed by the in-turn clique signer.
+var diffInTurn = big.NewInt(2)
+
+// errUnknownStatsBlock is returned if a block of the requested range is missing.
+var errUnknownStatsBlock = errors.New("unknown block")
+
+// ValidatorStats summarises the blocks a single signer sealed within a range.
+type ValidatorStats struct {
+	Signer       common.Address `json:"signer"`
+	Sealed       hexutil.Uint64 `json:"sealed"`       // Number of blocks sealed
+	InTurn       hexutil.Uint64 `json:"inTurn"`       // Number of blocks sealed in-turn
+	InTurnRatio  float64        `json:"inTurnRatio"`  // Share of the sealed blocks that were in-turn
+	AverageDelay float64        `json:"averageDelay"` // Average seconds between the parent and the sealed blocks
+	LastSeen     hexutil.Uint64 `json:"lastSeen"`     // Number of the most recent block sealed
+}
+
+// ValidatorStatsReport is the per signer production summary of a block range.
+type ValidatorStatsReport struct {
+	From    hexutil.Uint64    `json:"from"`
+	To      hexutil.Uint64    `json:"to"`
+	Signers []*ValidatorStats `json:"signers"`
+}
+
+// ValidatorStats returns per signer production statistics of the PoA blocks in
+// the given range, which defaults to the transition block up to the current
+// head. The statistics are derived from the headers alone.
+func (api *API) ValidatorStats(from, to *rpc.BlockNumber) (*ValidatorStatsReport, error) {
+	head := api.chain.CurrentHeader()
+	if head == nil || head.Number.Uint64() < api.hybrid.transitionBlock.Load() {
+		return nil, fmt.Errorf("%w: no PoA blocks yet", ErrTransitionNotReached)
+	}
+	transition := api.hybrid.transitionBlock.Load()
+	start, end, err := resolveRange(from, to, transition, transition, head.Number.Uint64(), maxStatsRange)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.GetHeaderByNumber(end)
+	if header == nil {
+		return nil, fmt.Errorf("%w: #%d", errUnknownStatsBlock, end)
+	}
+	var (
+		stats  = make(map[common.Address]*ValidatorStats)
+		delays = make(map[common.Address]uint64)
+		timed  = make(map[common.Address]uint64)
+	)
+	// Walk the range backwards along the parent hashes, so it stays on a single
+	// chain even if the canonical one changes meanwhile.
+	for {
+		number := header.Number.Uint64()
+		var parent *types.Header
+		if number > 0 {
+			if parent = api.chain.GetHeader(header.ParentHash, number-1); parent == nil && number > start {
+				return nil, fmt.Errorf("%w: #%d [%x]", errUnknownStatsBlock, number-1, header.ParentHash)
+			}
+		}
+		if signer, err := api.hybrid.poaEngine.Author(header); err == nil {
+			s, ok := stats[signer]
+			if !ok {
+				s = &ValidatorStats{Signer: signer, LastSeen: hexutil.Uint64(number)}
+				stats[signer] = s
+			}
+			s.Sealed++
+			if header.Difficulty != nil && header.Difficulty.Cmp(diffInTurn) == 0 {
+				s.InTurn++
+			}
+			if parent != nil && header.Time >= parent.Time {
+				delays[signer] += header.Time - parent.Time
+				timed[signer]++
+			}
+		}
+		if number == start {
+			break
+		}
+		header = parent
+	}
+	report := &ValidatorStatsReport{
+		From:    hexutil.Uint64(start),
+		To:      hexutil.Uint64(end),
+		Signers: make([]*ValidatorStats, 0, len(stats)),
+	}
+	for signer, s := range stats {
+		s.InTurnRatio = float64(s.InTurn) / float64(s.Sealed)
+		if timed[signer] > 0 {
+			s.AverageDelay = float64(delays[signer]) / float64(timed[signer])
+		}
+		report.Signers = append(report.Signers, s)
+	}
+	slices.SortFunc(report.Signers, func(a, b *ValidatorStats) int {
+		return bytes.Compare(a.Signer[:], b.Signer[:])
+	})
+	return report, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestValidatorStats(t *testing.T) {
+	var (
+		signerA = common.Address{0xaa}
+		signerB = common.Address{0xbb}
+		chain   = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+		parent  *types.Header
+	)
+	// Blocks from 10 on alternate between an in-turn A sealing 5 seconds after
+	// its parent and an out-of-turn B sealing 7 seconds after it.
+	for i := uint64(0); i <= 20; i++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(i), Difficulty: big.NewInt(0)}
+		if parent != nil {
+			header.ParentHash, header.Time = parent.Hash(), parent.Time+12
+		}
+		if i >= 10 {
+			header.Coinbase, header.Difficulty, header.Time = signerA, big.NewInt(2), parent.Time+5
+			if i%2 == 1 {
+				header.Coinbase, header.Difficulty, header.Time = signerB, big.NewInt(1), parent.Time+7
+			}
+		}
+		chain.headers[i] = header
+		parent = header
+	}
+	h, err := New(&mockEngine{name: "pos"}, &coinbaseMockEngine{}, 10)
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	api := &API{chain: chain, hybrid: h}
+
+	report, err := api.ValidatorStats(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %v", err)
+	}
+	if report.From != 10 || report.To != 20 || len(report.Signers) != 2 {
+		t.Fatalf("Report mismatch: %d-%d with %d signers", report.From, report.To, len(report.Signers))
+	}
+	a, b := report.Signers[0], report.Signers[1]
+	if a.Signer != signerA || a.Sealed != 6 || a.InTurnRatio != 1 || a.AverageDelay != 5 || a.LastSeen != 20 {
+		t.Errorf("Stats of A mismatch: %+v", a)
+	}
+	if b.Signer != signerB || b.Sealed != 5 || b.InTurnRatio != 0 || b.AverageDelay != 7 || b.LastSeen != 19 {
+		t.Errorf("Stats of B mismatch: %+v", b)
+	}
+	// Ranges are clamped to the PoA era and validated
+	from, to := rpc.BlockNumber(2), rpc.BlockNumber(12)
+	if report, err = api.ValidatorStats(&from, &to); err != nil || report.From != 10 || report.To != 12 {
+		t.Fatalf("Clamped range mismatch: %v, %v", report, err)
+	}
+	from, to = rpc.BlockNumber(15), rpc.BlockNumber(12)
+	if _, err := api.ValidatorStats(&from, &to); err == nil {
+		t.Fatal("Inverted range accepted")
+	}
+	for i := uint64(10); i <= 20; i++ {
+		delete(chain.headers, i)
+	}
+	if _, err := api.ValidatorStats(nil, nil); !errors.Is(err, ErrTransitionNotReached) {
+		t.Fatalf("Stats before the transition: have %v, want %v", err, ErrTransitionNotReached)
+	}
+}
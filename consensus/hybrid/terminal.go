@@ -0,0 +1,104 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrTerminalHashMismatch is returned for transition blocks not descending from
+// the pinned terminal PoS block, and for children of the terminal block at a
+// height other than the configured transition block.
+var ErrTerminalHashMismatch = errors.New("transition block does not descend from the terminal PoS block")
+
+// TerminalHash returns the hash of the pinned terminal PoS block, or the zero
+// hash if none is pinned.
+func (h *Hybrid) TerminalHash() common.Hash {
+	return h.terminalHash
+}
+
+// checkTerminal resolves a pending transition at the first descendant of the
+// terminal PoS block, and verifies that the transition block descends from it.
+// The transition height is taken from the terminal block, which the chain must
+// already contain, never from the descendant, so that a forged header cannot
+// pin the transition at a height of its choosing. It does nothing if no
+// terminal block is pinned.
+func (h *Hybrid) checkTerminal(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if h.terminalHash == (common.Hash{}) {
+		return nil
+	}
+	number := header.Number.Uint64()
+	if header.ParentHash == h.terminalHash {
+		if h.TransitionPending() {
+			terminal := chain.GetHeaderByHash(h.terminalHash)
+			if terminal == nil {
+				return fmt.Errorf("%w: terminal block %x", consensus.ErrUnknownAncestor, h.terminalHash.Bytes()[:4])
+			}
+			if number != terminal.Number.Uint64()+1 {
+				return &TransitionError{Code: CodeInvalidCheckpoint, Err: fmt.Errorf("%w: block %d is the child of terminal block %d",
+					ErrTerminalHashMismatch, number, terminal.Number)}
+			}
+			h.resolveTransition(PendingTransition, number, "terminal block")
+		}
+		if transition := h.transitionBlock.Load(); number != transition {
+			return &TransitionError{Code: CodeInvalidCheckpoint, Err: fmt.Errorf("%w: block %d is the child of terminal block %x, transition is at block %d",
+				ErrTerminalHashMismatch, number, h.terminalHash.Bytes()[:4], transition)}
+		}
+		return nil
+	}
+	if number == h.transitionBlock.Load() {
+		return &TransitionError{Code: CodeInvalidCheckpoint, Err: fmt.Errorf("%w: transition block %d has parent %x, want terminal block %x",
+			ErrTerminalHashMismatch, number, header.ParentHash.Bytes()[:4], h.terminalHash.Bytes()[:4])}
+	}
+	return nil
+}
+
+// checkTerminals resolves a pending transition if one of the headers is the
+// first descendant of the terminal PoS block, found in the chain or ahead of it
+// in the batch. It reports whether any of them is subject to the terminal block
+// check, requiring per header verification.
+func (h *Hybrid) checkTerminals(chain consensus.ChainHeaderReader, headers []*types.Header) bool {
+	if h.terminalHash == (common.Hash{}) {
+		return false
+	}
+	for i, header := range headers {
+		if header.ParentHash == h.terminalHash {
+			if h.TransitionPending() {
+				var terminal *types.Header
+				if i > 0 && headers[i-1].Hash() == h.terminalHash {
+					terminal = headers[i-1]
+				} else {
+					terminal = chain.GetHeaderByHash(h.terminalHash)
+				}
+				// Descendants at the wrong height are rejected by checkTerminal
+				if terminal != nil && header.Number.Uint64() == terminal.Number.Uint64()+1 {
+					h.resolveTransition(PendingTransition, header.Number.Uint64(), "terminal block")
+				}
+			}
+			return true
+		}
+		if header.Number.Uint64() == h.transitionBlock.Load() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTerminalTransition(t *testing.T) {
+	var (
+		posEngine = newTrackingMockEngine("pos")
+		poaEngine = newTrackingMockEngine("poa")
+		db        = rawdb.NewMemoryDatabase()
+		parent    = &types.Header{Number: big.NewInt(5), Difficulty: big.NewInt(0)}
+		terminal  = parent.Hash()
+		chain     = &configChainReader{config: params.TestChainConfig, headers: make(map[uint64]*types.Header)}
+	)
+	h, err := New(posEngine, poaEngine, PendingTransition, WithTerminalHash(terminal), WithDatabase(db))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	header := func(number int64, parent common.Hash, difficulty int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), ParentHash: parent, Difficulty: big.NewInt(difficulty)}
+	}
+	// Until the terminal block is reached every block is a PoS one
+	if err := h.VerifyHeader(chain, header(6, common.Hash{0xbb}, 0)); err != nil {
+		t.Fatalf("PoS block rejected: %v", err)
+	}
+	if !h.TransitionPending() || h.UsesPoA(1_000_000) {
+		t.Fatal("Transition resolved before the terminal block")
+	}
+	// Children of a terminal block not yet in the chain cannot resolve it
+	if err := h.VerifyHeader(chain, header(6, terminal, 2)); !errors.Is(err, consensus.ErrUnknownAncestor) {
+		t.Fatalf("Error mismatch for unknown terminal block: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+	chain.headers[5] = parent
+
+	// Neither can forged ones claiming another height
+	if err := h.VerifyHeader(chain, header(9, terminal, 2)); !errors.Is(err, ErrTerminalHashMismatch) {
+		t.Fatalf("Error mismatch for forged child: have %v, want %v", err, ErrTerminalHashMismatch)
+	}
+	if !h.TransitionPending() {
+		t.Fatal("Transition resolved by a forged child of the terminal block")
+	}
+	if _, ok := rawdb.ReadResolvedTransition(db); ok {
+		t.Fatal("Transition persisted for a forged child of the terminal block")
+	}
+	// Its child is the transition block, from which on the PoA engine takes over
+	if err := h.VerifyHeader(chain, header(6, terminal, 2)); err != nil {
+		t.Fatalf("Transition block rejected: %v", err)
+	}
+	if h.TransitionPending() || !h.UsesPoA(6) || h.UsesPoA(5) {
+		t.Fatal("Transition not resolved at the child of the terminal block")
+	}
+	if posEngine.getCallCount("VerifyHeader") != 1 || poaEngine.getCallCount("VerifyHeader") != 1 {
+		t.Fatal("Transition block verified by the wrong engine")
+	}
+	if number, ok := rawdb.ReadResolvedTransition(db); !ok || number != 6 {
+		t.Fatalf("Persisted transition mismatch: have %d (%v), want 6", number, ok)
+	}
+	// Competing transition blocks not descending from the terminal block are rejected
+	if err := h.VerifyHeader(chain, header(6, common.Hash{0xbb}, 2)); !errors.Is(err, ErrTerminalHashMismatch) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, ErrTerminalHashMismatch)
+	}
+	if err := h.Prepare(chain, header(6, common.Hash{0xbb}, 2)); !errors.Is(err, ErrTerminalHashMismatch) {
+		t.Fatalf("Prepare error mismatch: have %v, want %v", err, ErrTerminalHashMismatch)
+	}
+	// A configured transition block must descend from a pinned terminal block too
+	h, err = New(posEngine, poaEngine, 10, WithTerminalHash(terminal))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	if err := h.VerifyHeader(chain, header(9, terminal, 0)); !errors.Is(err, ErrTerminalHashMismatch) {
+		t.Fatalf("Error mismatch for terminal block at wrong height: have %v, want %v", err, ErrTerminalHashMismatch)
+	}
+	if err := h.VerifyHeader(chain, header(10, terminal, 2)); err != nil {
+		t.Fatalf("Transition block rejected: %v", err)
+	}
+}
+
+func TestTerminalTransitionBatch(t *testing.T) {
+	var (
+		posEngine = newTrackingMockEngine("pos")
+		poaEngine = newTrackingMockEngine("poa")
+		parent    = &types.Header{Number: big.NewInt(5), Difficulty: big.NewInt(0)}
+		terminal  = parent.Hash()
+	)
+	h, err := New(posEngine, poaEngine, PendingTransition, WithTerminalHash(terminal))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	// The terminal block is taken from the batch, ahead of its child
+	headers := []*types.Header{
+		parent,
+		{Number: big.NewInt(6), ParentHash: terminal, Difficulty: big.NewInt(2)},
+		{Number: big.NewInt(7), Difficulty: big.NewInt(2)},
+	}
+	_, results := h.VerifyHeaders(&mockChainReader{}, headers)
+	for i := range headers {
+		if err := <-results; err != nil {
+			t.Fatalf("Header %d rejected: %v", i, err)
+		}
+	}
+	if posEngine.getCallCount("VerifyHeader") != 1 || poaEngine.getCallCount("VerifyHeader") != 2 {
+		t.Fatal("Batch not split at the child of the terminal block")
+	}
+}
+
+func TestConfiguredTransition(t *testing.T) {
+	var (
+		db       = rawdb.NewMemoryDatabase()
+		terminal = common.Hash{0xaa}
+		config   = &params.ChainConfig{TerminalPoSBlockHash: &terminal}
+	)
+	if have := configuredTransition(config, db); have != PendingTransition {
+		t.Fatalf("Transition mismatch before the terminal block: have %d, want pending", have)
+	}
+	rawdb.WriteHeaderNumber(db, terminal, 41)
+	if have := configuredTransition(config, db); have != 42 {
+		t.Fatalf("Transition mismatch after the terminal block: have %d, want 42", have)
+	}
+	config.PoSToPoATransitionBlock = big.NewInt(100)
+	if have := configuredTransition(config, db); have != 100 {
+		t.Fatalf("Configured transition mismatch: have %d, want 100", have)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// TransitionLogAddress is the address the synthetic transition log is
+	// attributed to, the system address also used for system calls.
+	TransitionLogAddress = params.SystemAddress
+
+	// TransitionLogTopic is the topic of the synthetic transition log, the
+	// signature hash of the event PoSToPoATransition(address[] signers).
+	TransitionLogTopic = crypto.Keccak256Hash([]byte("PoSToPoATransition(address[])"))
+)
+
+// TransitionLog returns the synthetic log recording the signer set established
+// by the transition block, with the signers ABI encoded as an address array.
+// The log is not part of the block; it is numbered as if emitted by a system
+// transaction following the txs transactions and logs logs of the block.
+func TransitionLog(header *types.Header, txs, logs uint) (*types.Log, error) {
+	signers, err := checkpointSigners(header.Extra)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, 64+32*len(signers))
+	data = append(data, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(int64(len(signers))).Bytes(), 32)...)
+	for _, signer := range signers {
+		data = append(data, common.LeftPadBytes(signer[:], 32)...)
+	}
+	return &types.Log{
+		Address:        TransitionLogAddress,
+		Topics:         []common.Hash{TransitionLogTopic},
+		Data:           data,
+		BlockNumber:    header.Number.Uint64(),
+		BlockHash:      header.Hash(),
+		BlockTimestamp: header.Time,
+		TxIndex:        txs,
+		Index:          logs,
+	}, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Tests that the synthetic transition log decodes as the signer set of the
+// transition block with the standard ABI.
+func TestTransitionLog(t *testing.T) {
+	signers := []common.Address{{0x01}, {0x02}, {0x03}}
+	header := &types.Header{Number: big.NewInt(100), Time: 1234, Extra: CheckpointExtra(signers)}
+
+	log, err := TransitionLog(header, 2, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Address != TransitionLogAddress || len(log.Topics) != 1 || log.Topics[0] != TransitionLogTopic {
+		t.Fatalf("unexpected log identity: %v %v", log.Address, log.Topics)
+	}
+	if log.BlockNumber != 100 || log.BlockHash != header.Hash() || log.TxIndex != 2 || log.Index != 5 {
+		t.Fatalf("unexpected log position: %+v", log)
+	}
+	addressArray, _ := abi.NewType("address[]", "", nil)
+	values, err := abi.Arguments{{Type: addressArray}}.Unpack(log.Data)
+	if err != nil {
+		t.Fatalf("failed to decode log data: %v", err)
+	}
+	decoded := values[0].([]common.Address)
+	if len(decoded) != len(signers) {
+		t.Fatalf("signer count mismatch: have %d, want %d", len(decoded), len(signers))
+	}
+	for i := range signers {
+		if decoded[i] != signers[i] {
+			t.Errorf("signer %d mismatch: have %v, want %v", i, decoded[i], signers[i])
+		}
+	}
+	// Blocks without a signer section have no transition log
+	if _, err := TransitionLog(&types.Header{Number: big.NewInt(100)}, 0, 0); err == nil {
+		t.Error("transition log built from a header without signers")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/schedule"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// batchChain is a chain reader that also resolves the headers of a batch being
+// verified, as none of them is part of the chain before verification ends.
+// Headers are looked up by position, sparing the batch an up-front index.
+type batchChain struct {
+	consensus.ChainHeaderReader
+	headers []*types.Header // Contiguous headers of the batch
+}
+
+// batchHeader returns the header of the batch with the given hash and number.
+func (c *batchChain) batchHeader(hash common.Hash, number uint64) *types.Header {
+	if len(c.headers) == 0 {
+		return nil
+	}
+	first := c.headers[0].Number.Uint64()
+	if number < first || number-first >= uint64(len(c.headers)) {
+		return nil
+	}
+	if header := c.headers[number-first]; header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+// GetHeader retrieves a header by hash and number, from the batch if present.
+func (c *batchChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.batchHeader(hash, number); header != nil {
+		return header
+	}
+	return c.ChainHeaderReader.GetHeader(hash, number)
+}
+
+// GetHeaderByHash retrieves a header by hash, from the batch if present.
+func (c *batchChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for i := len(c.headers) - 1; i >= 0; i-- {
+		if c.headers[i].Hash() == hash {
+			return c.headers[i]
+		}
+	}
+	return c.ChainHeaderReader.GetHeaderByHash(hash)
+}
+
+// resultSegment is a contiguous part of a verification batch whose results are
+// produced by one engine.
+type resultSegment struct {
+	offset  int          // Index of the first header of the segment in the batch
+	count   int          // Number of headers in the segment
+	results <-chan error // Results of the segment, in the order of its headers
+}
+
+// indexedResult is a verification result tagged with its index in the batch.
+type indexedResult struct {
+	index int
+	err   error
+}
+
+// mergeResults collects the results of the segments of a batch of n headers
+// into a single channel, delivering them 1:1 in the order of the batch however
+// the completion of the segments interleaves. A segment channel closing early
+// yields successes for its missing results, as reading it directly would. The
+// merge stops once quit is closed.
+func mergeResults(n int, segments []resultSegment, quit <-chan struct{}) <-chan error {
+	collected := make(chan indexedResult)
+	for _, segment := range segments {
+		go func(segment resultSegment) {
+			for i := 0; i < segment.count; i++ {
+				err := <-segment.results
+				select {
+				case collected <- indexedResult{index: segment.offset + i, err: err}:
+				case <-quit:
+					return
+				}
+			}
+		}(segment)
+	}
+	return orderResults(n, collected, quit)
+}
+
+// orderResults delivers the results of a batch of n headers collected in any
+// order 1:1 in the order of the batch, until all are delivered or quit is
+// closed.
+func orderResults(n int, collected <-chan indexedResult, quit <-chan struct{}) <-chan error {
+	out := make(chan error, n)
+	go func() {
+		defer close(out)
+
+		var (
+			errs = make([]error, n)
+			done = make([]bool, n)
+			next int
+		)
+		for next < n {
+			select {
+			case result := <-collected:
+				errs[result.index], done[result.index] = result.err, true
+				for ; next < n && done[next]; next++ {
+					out <- errs[next] // Buffered for the entire batch, never blocks
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// verifyParallel verifies a batch of headers one by one on a pool of the given
+// number of workers, delivering the results in the order of the batch. It is
+// used for batches that cannot be handed to the engines as a whole, such as
+// ones needing dual verification.
+func (h *Hybrid) verifyParallel(chain consensus.ChainHeaderReader, headers []*types.Header, workers int) (chan<- struct{}, <-chan error) {
+	var (
+		quit      = make(chan struct{})
+		indices   = make(chan int)
+		collected = make(chan indexedResult)
+		batch     = &batchChain{ChainHeaderReader: chain, headers: headers}
+	)
+	go func() {
+		defer close(indices)
+		for i := range headers {
+			select {
+			case indices <- i:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	for w := 0; w < min(workers, len(headers)); w++ {
+		go func() {
+			for i := range indices {
+				err := h.VerifyHeader(batch, headers[i])
+				select {
+				case collected <- indexedResult{index: i, err: err}:
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+	return quit, orderResults(len(headers), collected, quit)
+}
+
+// verifySplit verifies a batch of headers spanning the transition, handing the
+// PoS and the PoA part to their engines in one batch each. The PoA part is
+// verified concurrently with the PoS part, resolving its leading parents from
+// the batch.
+func (h *Hybrid) verifySplit(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	segments := schedule.Split(h, headers)
+	pos, poa := segments[phasePoS].Headers, segments[phasePoA].Headers
+	for _, header := range pos {
+		h.shadowVerify(chain, header)
+	}
+	h.doubleSign.observe(poa...)
+	h.watchTransition(chain, poa[0])
+	h.monitorForks(headers...)
+
+	labelDelegate(false, methodVerifyHeaders)
+	posAbort, posResults := h.posEngine.VerifyHeaders(chain, pos)
+
+	batch := &batchChain{ChainHeaderReader: chain, headers: pos}
+	labelDelegate(true, methodVerifyHeaders)
+	poaAbort, poaResults := h.poaEngine.VerifyHeaders(batch, poa)
+	unlabelDelegate()
+	if len(chain.Config().PoAGasCeilings) > 0 {
+		poaAbort, poaResults = verifyGasCeilings(batch, poa, poaAbort, poaResults)
+	}
+	quit := make(chan struct{})
+	go func() {
+		<-quit
+		close(posAbort)
+		close(poaAbort)
+	}()
+	return quit, mergeResults(len(headers), []resultSegment{
+		{offset: segments[phasePoS].Offset, count: len(pos), results: posResults},
+		{offset: segments[phasePoA].Offset, count: len(poa), results: poaResults},
+	}, quit)
+}
@@ -0,0 +1,273 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// interleavings returns every order in which a results of one segment and b
+// results of another can complete, as a sequence of segment indices.
+func interleavings(a, b int) [][]int {
+	if a == 0 && b == 0 {
+		return [][]int{nil}
+	}
+	var orders [][]int
+	if a > 0 {
+		for _, rest := range interleavings(a-1, b) {
+			orders = append(orders, append([]int{0}, rest...))
+		}
+	}
+	if b > 0 {
+		for _, rest := range interleavings(a, b-1) {
+			orders = append(orders, append([]int{1}, rest...))
+		}
+	}
+	return orders
+}
+
+func TestMergeResultsOrdering(t *testing.T) {
+	for a := 0; a <= 4; a++ {
+		for b := 0; b <= 4; b++ {
+			for _, order := range interleavings(a, b) {
+				var (
+					errs   = make([]error, a+b)
+					inputs = []chan error{make(chan error), make(chan error)}
+					quit   = make(chan struct{})
+				)
+				for i := range errs {
+					if i%2 == 0 {
+						errs[i] = fmt.Errorf("header %d", i)
+					}
+				}
+				out := mergeResults(a+b, []resultSegment{
+					{offset: 0, count: a, results: inputs[0]},
+					{offset: a, count: b, results: inputs[1]},
+				}, quit)
+
+				// Complete the segments in the given order, one result at a time
+				next := []int{0, a}
+				for _, segment := range order {
+					inputs[segment] <- errs[next[segment]]
+					next[segment]++
+				}
+				for i := range errs {
+					select {
+					case err := <-out:
+						if err != errs[i] {
+							t.Fatalf("segments %d+%d, order %v: result %d mismatch: have %v, want %v", a, b, order, i, err, errs[i])
+						}
+					case <-time.After(time.Second):
+						t.Fatalf("segments %d+%d, order %v: result %d missing", a, b, order, i)
+					}
+				}
+				if _, ok := <-out; ok {
+					t.Fatalf("segments %d+%d, order %v: surplus result", a, b, order)
+				}
+				close(quit)
+			}
+		}
+	}
+}
+
+func TestMergeResultsClosedSegment(t *testing.T) {
+	var (
+		failure = errors.New("failure")
+		closed  = make(chan error, 1)
+		full    = make(chan error, 2)
+	)
+	closed <- failure
+	close(closed)
+	full <- nil
+	full <- failure
+
+	quit := make(chan struct{})
+	defer close(quit)
+	out := mergeResults(5, []resultSegment{
+		{offset: 0, count: 3, results: closed},
+		{offset: 3, count: 2, results: full},
+	}, quit)
+
+	want := []error{failure, nil, nil, nil, failure}
+	for i := range want {
+		if err := <-out; err != want[i] {
+			t.Errorf("result %d mismatch: have %v, want %v", i, err, want[i])
+		}
+	}
+}
+
+func TestMergeResultsQuit(t *testing.T) {
+	quit := make(chan struct{})
+	out := mergeResults(2, []resultSegment{{offset: 0, count: 2, results: make(chan error)}}, quit)
+	close(quit)
+
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("Result delivered after quit")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Merge not stopped by quit")
+	}
+}
+
+// orderedVerifyEngine is a mock engine verifying batches after a delay, failing
+// the configured headers and the ones with unknown parents.
+type orderedVerifyEngine struct {
+	mockEngine
+	delay time.Duration
+	fail  map[uint64]bool
+	abort chan struct{}
+}
+
+func (e *orderedVerifyEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	var (
+		abort   = make(chan struct{})
+		results = make(chan error, len(headers))
+	)
+	e.abort = abort
+	go func() {
+		time.Sleep(e.delay)
+		for i, header := range headers {
+			var err error
+			switch number := header.Number.Uint64(); {
+			case e.fail[number]:
+				err = fmt.Errorf("header %d", number)
+			case i == 0 && chain.GetHeader(header.ParentHash, number-1) == nil:
+				err = consensus.ErrUnknownAncestor
+			}
+			results <- err
+		}
+	}()
+	return abort, results
+}
+
+func TestVerifySplitOrdering(t *testing.T) {
+	chain := newTestHeaderChain(params.TestChainConfig, 0, 1)
+	headers := make([]*types.Header, 12)
+	for i := range headers {
+		parent := chain.headers[0]
+		if i > 0 {
+			parent = headers[i-1]
+		}
+		headers[i] = &types.Header{Number: big.NewInt(int64(i + 1)), ParentHash: parent.Hash(), Time: uint64(i + 1)}
+	}
+	for _, slowPoS := range []bool{true, false} {
+		var (
+			pos = &orderedVerifyEngine{mockEngine: mockEngine{name: "pos"}, fail: map[uint64]bool{2: true, 5: true}}
+			poa = &orderedVerifyEngine{mockEngine: mockEngine{name: "poa"}, fail: map[uint64]bool{7: true, 12: true}}
+		)
+		if slowPoS {
+			pos.delay = 20 * time.Millisecond
+		} else {
+			poa.delay = 20 * time.Millisecond
+		}
+		h, err := New(pos, poa, 6)
+		if err != nil {
+			t.Fatalf("Failed to create hybrid engine: %v", err)
+		}
+		abort, results := h.VerifyHeaders(chain, headers)
+		for i, header := range headers {
+			number := header.Number.Uint64()
+			err := <-results
+			if want := pos.fail[number] || poa.fail[number]; (err != nil) != want {
+				t.Errorf("slow PoS %v: header %d: have %v, want failure %v", slowPoS, number, err, want)
+			} else if want && err.Error() != fmt.Sprintf("header %d", number) {
+				t.Errorf("slow PoS %v: result %d mapped to wrong header: %v", slowPoS, i, err)
+			}
+		}
+		close(abort)
+		for _, engine := range []*orderedVerifyEngine{pos, poa} {
+			select {
+			case <-engine.abort:
+			case <-time.After(time.Second):
+				t.Errorf("slow PoS %v: %s verification not aborted", slowPoS, engine.name)
+			}
+		}
+	}
+}
+
+// jitterVerifyEngine is a mock engine verifying single headers after a random
+// delay, failing the configured headers and the ones with unknown parents.
+type jitterVerifyEngine struct {
+	mockEngine
+	fail map[uint64]bool
+}
+
+func (e *jitterVerifyEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header) error {
+	time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+
+	number := header.Number.Uint64()
+	if e.fail[number] {
+		return fmt.Errorf("header %d", number)
+	}
+	if chain.GetHeader(header.ParentHash, number-1) == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	return nil
+}
+
+func TestVerifyParallelOrdering(t *testing.T) {
+	chain := newTestHeaderChain(params.TestChainConfig, 0, 1)
+	headers := make([]*types.Header, 64)
+	for i := range headers {
+		parent := chain.headers[0]
+		if i > 0 {
+			parent = headers[i-1]
+		}
+		headers[i] = &types.Header{Number: big.NewInt(int64(i + 1)), ParentHash: parent.Hash(), Time: uint64(i + 1), Difficulty: big.NewInt(2)}
+	}
+	var (
+		pos = &jitterVerifyEngine{mockEngine: mockEngine{name: "pos"}, fail: map[uint64]bool{3: true, 31: true}}
+		poa = &jitterVerifyEngine{mockEngine: mockEngine{name: "poa"}, fail: map[uint64]bool{32: true, 60: true}}
+	)
+	h, err := New(pos, poa, 32, WithDualVerifyWindow(8))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	for _, workers := range []int{1, 4, 64, 128} {
+		abort, results := h.verifyParallel(chain, headers, workers)
+		for _, header := range headers {
+			number := header.Number.Uint64()
+			err := <-results
+			if want := pos.fail[number] || poa.fail[number]; (err != nil) != want {
+				t.Errorf("%d workers: header %d: have %v, want failure %v", workers, number, err, want)
+			} else if want && err.Error() != fmt.Sprintf("header %d", number) {
+				t.Errorf("%d workers: result mapped to wrong header %d: %v", workers, number, err)
+			}
+		}
+		if _, ok := <-results; ok {
+			t.Errorf("%d workers: surplus result", workers)
+		}
+		close(abort)
+	}
+	// Aborting stops the remaining verifications
+	abort, results := h.verifyParallel(chain, headers, 2)
+	<-results
+	close(abort)
+	for range results {
+	}
+}
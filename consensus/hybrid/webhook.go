@@ -0,0 +1,221 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	webhookQueue    = 64               // Alerts queued for delivery before dropping new ones
+	webhookAttempts = 5                // Delivery attempts of an alert before giving up
+	webhookTimeout  = 10 * time.Second // Timeout of a single delivery attempt
+)
+
+// webhookBackoff is the delay before the first retry of a failed delivery,
+// doubling with every further attempt.
+var webhookBackoff = time.Second
+
+// ErrInsecureWebhook is returned if the alert webhook is not an HTTPS endpoint.
+var ErrInsecureWebhook = errors.New("alert webhook must use https")
+
+var (
+	webhookDeliveredCounter = metrics.NewRegisteredCounter("hybrid/webhook/delivered", nil)
+	webhookFailedCounter    = metrics.NewRegisteredCounter("hybrid/webhook/failed", nil)
+	webhookDroppedCounter   = metrics.NewRegisteredCounter("hybrid/webhook/dropped", nil)
+)
+
+// WebhookSignature returns the value of the X-Hybrid-Signature header of an
+// alert delivery, the hex encoded HMAC-SHA256 of the timestamp header, a dot
+// and the body, keyed with the shared secret. Receivers recompute it to check
+// that the alert is authentic.
+func WebhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookStatusError is returned for deliveries rejected by the endpoint.
+type webhookStatusError struct {
+	status int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.status)
+}
+
+// retryable returns whether the endpoint may accept the delivery later.
+func (e *webhookStatusError) retryable() bool {
+	return e.status >= 500 || e.status == http.StatusTooManyRequests
+}
+
+// Webhook delivers alerts as signed JSON POST requests to an HTTPS endpoint,
+// such as an incident management integration, retrying failed deliveries with
+// exponential backoff.
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+
+	queue chan Alert
+	sub   event.Subscription
+	quit  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewWebhook creates a webhook delivering alerts to the given HTTPS endpoint,
+// signed with the shared secret.
+func NewWebhook(endpoint string, secret []byte) (*Webhook, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s", ErrInsecureWebhook, u.Redacted())
+	}
+	return &Webhook{
+		url:    endpoint,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+		queue:  make(chan Alert, webhookQueue),
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// start subscribes to the alerts and launches their delivery.
+func (w *Webhook) start(subscribe func(chan<- Alert) event.Subscription) {
+	alerts := make(chan Alert)
+	w.sub = subscribe(alerts)
+
+	w.wg.Add(2)
+	go w.collect(alerts)
+	go w.loop()
+}
+
+// stop terminates the delivery, abandoning undelivered alerts.
+func (w *Webhook) stop() {
+	w.sub.Unsubscribe()
+	close(w.quit)
+	w.wg.Wait()
+}
+
+// collect queues alerts for delivery, so a slow endpoint never holds up the
+// engine raising them.
+func (w *Webhook) collect(alerts <-chan Alert) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case alert := <-alerts:
+			select {
+			case w.queue <- alert:
+			default:
+				webhookDroppedCounter.Inc(1)
+				log.Warn("Dropped alert, webhook delivery is falling behind", "kind", alert.Kind, "number", alert.Number)
+			}
+		case <-w.sub.Err():
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+func (w *Webhook) loop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case alert := <-w.queue:
+			w.deliver(alert)
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// deliver posts the alert to the endpoint, retrying transient failures.
+func (w *Webhook) deliver(alert Alert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		log.Error("Failed to encode alert", "kind", alert.Kind, "err", err)
+		return
+	}
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err := w.post(body)
+		if err == nil {
+			webhookDeliveredCounter.Inc(1)
+			return
+		}
+		var status *webhookStatusError
+		if attempt == webhookAttempts || (errors.As(err, &status) && !status.retryable()) {
+			webhookFailedCounter.Inc(1)
+			log.Error("Failed to deliver alert to webhook", "kind", alert.Kind, "number", alert.Number, "attempts", attempt, "err", err)
+			return
+		}
+		log.Debug("Retrying alert delivery to webhook", "kind", alert.Kind, "attempt", attempt, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-w.quit:
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// post sends a single signed delivery of the encoded alert.
+func (w *Webhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hybrid-Timestamp", timestamp)
+	req.Header.Set("X-Hybrid-Signature", WebhookSignature(w.secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &webhookStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
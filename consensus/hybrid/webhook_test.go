@@ -0,0 +1,83 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybrid
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAlertWebhook(t *testing.T) {
+	defer func(backoff time.Duration) { webhookBackoff = backoff }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+
+	if _, err := NewWebhook("http://example.com/alerts", nil); !errors.Is(err, ErrInsecureWebhook) {
+		t.Fatalf("Plain HTTP webhook: have %v, want %v", err, ErrInsecureWebhook)
+	}
+	var (
+		secret    = []byte("secret")
+		attempts  int
+		delivered = make(chan Alert, 1)
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first attempt to exercise the retries
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if have, want := r.Header.Get("X-Hybrid-Signature"), WebhookSignature(secret, r.Header.Get("X-Hybrid-Timestamp"), body); have != want {
+			t.Errorf("Signature mismatch: have %s, want %s", have, want)
+		}
+		var alert Alert
+		if err := json.Unmarshal(body, &alert); err != nil {
+			t.Errorf("Failed to decode alert: %v", err)
+		}
+		delivered <- alert
+	}))
+	defer server.Close()
+
+	webhook, err := NewWebhook(server.URL, secret)
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	webhook.client = server.Client()
+
+	h, err := New(&mockEngine{name: "pos"}, &mockEngine{name: "poa"}, 100, WithAlertWebhook(webhook))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	defer h.Close()
+
+	h.raiseAlert(Alert{Kind: AlertTransitionExecuted, Number: 100, Message: "test"})
+	select {
+	case alert := <-delivered:
+		if alert.Kind != AlertTransitionExecuted || alert.Number != 100 || alert.Time == 0 {
+			t.Fatalf("Delivered alert mismatch: %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Alert not delivered")
+	}
+	if attempts != 2 {
+		t.Fatalf("Delivery attempts mismatch: have %d, want 2", attempts)
+	}
+}
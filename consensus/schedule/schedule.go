@@ -0,0 +1,159 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package schedule routes the consensus calls of a chain migrating between
+// engines to the engine governing each block.
+//
+// A schedule is a sequence of phases, each governed by one engine. The first
+// phase governs the chain from genesis, every later one from its activation on,
+// taking precedence over the earlier phases. Activations have to be monotonic:
+// once a phase is active at a block, it is active at all later ones.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrNoPhases is returned if a schedule is created without any phase.
+	ErrNoPhases = errors.New("schedule without phases")
+
+	// ErrMissingEngine is returned if a phase of a schedule has no engine.
+	ErrMissingEngine = errors.New("schedule phase without engine")
+
+	// ErrMissingActivation is returned if a phase following the first one of a
+	// schedule has no activation.
+	ErrMissingActivation = errors.New("schedule phase without activation")
+)
+
+// Schedule is a sequence of engines governing consecutive phases of a chain.
+type Schedule interface {
+	// Phases returns the number of phases of the schedule.
+	Phases() int
+
+	// PhaseEngine returns the engine governing the given phase.
+	PhaseEngine(phase int) consensus.Engine
+
+	// Activated reports whether the given phase is active at the block with
+	// the given number. The first phase is always active.
+	Activated(phase int, number uint64) bool
+}
+
+// PhaseAt returns the phase governing the block with the given number, the
+// latest phase active at it.
+func PhaseAt(s Schedule, number uint64) int {
+	for phase := s.Phases() - 1; phase > 0; phase-- {
+		if s.Activated(phase, number) {
+			return phase
+		}
+	}
+	return 0
+}
+
+// EngineAt returns the engine governing the block with the given number.
+func EngineAt(s Schedule, number uint64) consensus.Engine {
+	return s.PhaseEngine(PhaseAt(s, number))
+}
+
+// IsActivation reports whether the block with the given number is the first one
+// of its phase, i.e. the block at which the chain switches engines.
+func IsActivation(s Schedule, number uint64) bool {
+	return number > 0 && PhaseAt(s, number) != PhaseAt(s, number-1)
+}
+
+// Segment is a contiguous run of headers governed by the same phase.
+type Segment struct {
+	Phase   int             // Phase governing the headers
+	Offset  int             // Index of the first header of the segment in the batch
+	Headers []*types.Header // Headers of the segment
+}
+
+// Split divides a batch of consecutive headers into the segments governed by
+// the same phase, in the order of the batch, so that each engine can verify its
+// part in one go.
+func Split(s Schedule, headers []*types.Header) []Segment {
+	var segments []Segment
+	for i, header := range headers {
+		phase := PhaseAt(s, header.Number.Uint64())
+		if n := len(segments); n > 0 && segments[n-1].Phase == phase {
+			segments[n-1].Headers = headers[segments[n-1].Offset : i+1]
+			continue
+		}
+		segments = append(segments, Segment{Phase: phase, Offset: i, Headers: headers[i : i+1]})
+	}
+	return segments
+}
+
+// Activation reports whether a phase is active at the block with the given
+// number.
+type Activation func(number uint64) bool
+
+// AtBlock returns the activation of a phase starting at the given block.
+func AtBlock(first uint64) Activation {
+	return func(number uint64) bool { return number >= first }
+}
+
+// AtBlockFunc returns the activation of a phase starting at the block returned
+// by the given function, for activation points resolved at runtime.
+func AtBlockFunc(first func() uint64) Activation {
+	return func(number uint64) bool { return number >= first() }
+}
+
+// Phase is a phase of a static schedule.
+type Phase struct {
+	Name       string           // Human readable name of the phase
+	Engine     consensus.Engine // Engine governing the phase
+	Activation Activation       // Activation of the phase, ignored for the first one
+}
+
+// Static is a schedule of a fixed set of phases.
+type Static []Phase
+
+// New creates a static schedule of the given phases.
+func New(phases ...Phase) (Static, error) {
+	if len(phases) == 0 {
+		return nil, ErrNoPhases
+	}
+	for i, phase := range phases {
+		if phase.Engine == nil {
+			return nil, fmt.Errorf("%w: phase %d (%s)", ErrMissingEngine, i, phase.Name)
+		}
+		if i > 0 && phase.Activation == nil {
+			return nil, fmt.Errorf("%w: phase %d (%s)", ErrMissingActivation, i, phase.Name)
+		}
+	}
+	return Static(phases), nil
+}
+
+// Phases implements Schedule, returning the number of phases.
+func (s Static) Phases() int {
+	return len(s)
+}
+
+// PhaseEngine implements Schedule, returning the engine of the given phase.
+func (s Static) PhaseEngine(phase int) consensus.Engine {
+	return s[phase].Engine
+}
+
+// Activated implements Schedule, reporting whether the given phase is active at
+// the block with the given number.
+func (s Static) Activated(phase int, number uint64) bool {
+	return phase == 0 || s[phase].Activation(number)
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package schedule
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func newHeaders(first, last uint64) []*types.Header {
+	var headers []*types.Header
+	for number := first; number <= last; number++ {
+		headers = append(headers, &types.Header{Number: new(big.Int).SetUint64(number)})
+	}
+	return headers
+}
+
+func TestNew(t *testing.T) {
+	engine := ethash.NewFaker()
+	tests := []struct {
+		phases []Phase
+		err    error
+	}{
+		{nil, ErrNoPhases},
+		{[]Phase{{Name: "genesis", Engine: engine}}, nil},
+		{[]Phase{{Name: "genesis"}}, ErrMissingEngine},
+		{[]Phase{{Name: "genesis", Engine: engine}, {Name: "next", Engine: engine}}, ErrMissingActivation},
+		{[]Phase{{Name: "genesis", Engine: engine}, {Name: "next", Activation: AtBlock(10)}}, ErrMissingEngine},
+		{[]Phase{{Name: "genesis", Engine: engine}, {Name: "next", Engine: engine, Activation: AtBlock(10)}}, nil},
+	}
+	for i, test := range tests {
+		if _, err := New(test.phases...); !errors.Is(err, test.err) {
+			t.Errorf("Test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestRouting(t *testing.T) {
+	var (
+		engines = []consensus.Engine{ethash.NewFaker(), ethash.NewFaker(), ethash.NewFaker()}
+		third   atomic.Uint64
+	)
+	third.Store(math.MaxUint64) // Resolved at runtime
+	s, err := New(
+		Phase{Name: "first", Engine: engines[0]},
+		Phase{Name: "second", Engine: engines[1], Activation: AtBlock(10)},
+		Phase{Name: "third", Engine: engines[2], Activation: AtBlockFunc(third.Load)},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	check := func(number uint64, phase int, activation bool) {
+		t.Helper()
+		if have := PhaseAt(s, number); have != phase {
+			t.Errorf("Block %d: phase mismatch: have %d, want %d", number, have, phase)
+		}
+		if have := EngineAt(s, number); have != engines[phase] {
+			t.Errorf("Block %d: engine mismatch: have %p, want %p", number, have, engines[phase])
+		}
+		if have := IsActivation(s, number); have != activation {
+			t.Errorf("Block %d: activation mismatch: have %v, want %v", number, have, activation)
+		}
+	}
+	check(0, 0, false)
+	check(9, 0, false)
+	check(10, 1, true)
+	check(1_000_000, 1, false)
+
+	third.Store(20)
+	check(19, 1, false)
+	check(20, 2, true)
+	check(21, 2, false)
+
+	// A later phase activating first takes precedence over an earlier one
+	third.Store(5)
+	check(4, 0, false)
+	check(5, 2, true)
+	check(10, 2, false)
+}
+
+func TestSplit(t *testing.T) {
+	engine := ethash.NewFaker()
+	s, err := New(
+		Phase{Name: "first", Engine: engine},
+		Phase{Name: "second", Engine: engine, Activation: AtBlock(10)},
+		Phase{Name: "third", Engine: engine, Activation: AtBlock(12)},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	tests := []struct {
+		first, last uint64
+		want        []Segment // Headers given as their number range via offsets
+		sizes       []int
+	}{
+		{0, 5, []Segment{{Phase: 0, Offset: 0}}, []int{6}},
+		{12, 20, []Segment{{Phase: 2, Offset: 0}}, []int{9}},
+		{8, 11, []Segment{{Phase: 0, Offset: 0}, {Phase: 1, Offset: 2}}, []int{2, 2}},
+		{5, 15, []Segment{{Phase: 0, Offset: 0}, {Phase: 1, Offset: 5}, {Phase: 2, Offset: 7}}, []int{5, 2, 4}},
+	}
+	for i, test := range tests {
+		headers := newHeaders(test.first, test.last)
+		segments := Split(s, headers)
+		if len(segments) != len(test.want) {
+			t.Fatalf("Test %d: segment count mismatch: have %d, want %d", i, len(segments), len(test.want))
+		}
+		for j, segment := range segments {
+			want := test.want[j]
+			if segment.Phase != want.Phase || segment.Offset != want.Offset || len(segment.Headers) != test.sizes[j] {
+				t.Errorf("Test %d segment %d: have phase %d offset %d size %d, want phase %d offset %d size %d",
+					i, j, segment.Phase, segment.Offset, len(segment.Headers), want.Phase, want.Offset, test.sizes[j])
+			}
+			if segment.Headers[0] != headers[segment.Offset] {
+				t.Errorf("Test %d segment %d: headers not taken from the batch", i, j)
+			}
+		}
+	}
+	if segments := Split(s, nil); len(segments) != 0 {
+		t.Errorf("Empty batch split into %d segments", len(segments))
+	}
+}
@@ -193,6 +193,11 @@ type BlockChainConfig struct {
 	// If the value is zero, all transactions of the entire chain will be indexed.
 	// If the value is -1, indexing is disabled.
 	TxLookupLimit int64
+
+	// TxLookupFloor optionally reports the first block whose transactions may
+	// be indexed, restricting indexing within the TxLookupLimit further. It is
+	// consulted on every indexing run, so the floor may move up over time.
+	TxLookupFloor func() (uint64, bool)
 }
 
 // DefaultConfig returns the default config.
@@ -357,6 +362,10 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 	if err != nil {
 		return nil, err
 	}
+	// Let the chain rules follow transitions the engine resolves at runtime
+	if resolver, ok := engine.(params.TransitionResolver); ok && chainConfig.HasPoSToPoATransition() {
+		chainConfig.Transition = resolver
+	}
 	log.Info("")
 	log.Info(strings.Repeat("-", 153))
 	for _, line := range strings.Split(chainConfig.Description(), "\n") {
@@ -521,7 +530,7 @@ func NewBlockChain(db ethdb.Database, genesis *Genesis, engine consensus.Engine,
 
 	// Start tx indexer if it's enabled.
 	if bc.cfg.TxLookupLimit >= 0 {
-		bc.txIndexer = newTxIndexer(uint64(bc.cfg.TxLookupLimit), bc)
+		bc.txIndexer = newTxIndexer(uint64(bc.cfg.TxLookupLimit), bc.cfg.TxLookupFloor, bc)
 	}
 	return bc, nil
 }
@@ -733,7 +742,7 @@ func (bc *BlockChain) SetHead(head uint64) error {
 			return fmt.Errorf("current block missing: #%d [%x..]", header.Number, header.Hash().Bytes()[:4])
 		}
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Header: header})
+	bc.chainHeadFeed.Send(bc.headEvent(header))
 	return nil
 }
 
@@ -758,7 +767,7 @@ func (bc *BlockChain) SetHeadWithTimestamp(timestamp uint64) error {
 			return fmt.Errorf("current block missing: #%d [%x..]", header.Number, header.Hash().Bytes()[:4])
 		}
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Header: header})
+	bc.chainHeadFeed.Send(bc.headEvent(header))
 	return nil
 }
 
@@ -1087,7 +1096,14 @@ func (bc *BlockChain) setHeadBeyondRoot(head uint64, time uint64, root common.Ha
 		log.Error("SetHead invalidated finalized block")
 		bc.SetFinalized(nil)
 	}
-	return rootNumber, bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return rootNumber, err
+	}
+	// Let the consensus engine reset the state derived from the dropped blocks
+	if rewinder, ok := bc.engine.(consensus.Rewinder); ok {
+		rewinder.Rewound(bc.CurrentHeader())
+	}
+	return rootNumber, nil
 }
 
 // SnapSyncCommitHead sets the current head block to the one defined by the hash
@@ -1650,11 +1666,26 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 // writeBlockAndSetHead is the internal implementation of WriteBlockAndSetHead.
 // This function expects the chain mutex to be held.
 func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB, emitHeadEvent bool) (status WriteStatus, err error) {
+	currentBlock := bc.CurrentBlock()
+
+	// Let the engine veto switching to a branch it does not prefer
+	if block.ParentHash() != currentBlock.Hash() {
+		if chooser, ok := bc.engine.(consensus.ForkChooser); ok {
+			reorg, err := chooser.ReorgNeeded(currentBlock, block.Header())
+			if err != nil {
+				return NonStatTy, err
+			}
+			if !reorg {
+				if err := bc.writeBlockWithState(block, receipts, state); err != nil {
+					return NonStatTy, err
+				}
+				return SideStatTy, nil
+			}
+		}
+	}
 	if err := bc.writeBlockWithState(block, receipts, state); err != nil {
 		return NonStatTy, err
 	}
-	currentBlock := bc.CurrentBlock()
-
 	// Reorganise the chain if the parent is not the head block
 	if block.ParentHash() != currentBlock.Hash() {
 		if err := bc.reorg(currentBlock, block.Header()); err != nil {
@@ -1675,7 +1706,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 	// we will fire an accumulated ChainHeadEvent and disable fire
 	// event here.
 	if emitHeadEvent {
-		bc.chainHeadFeed.Send(ChainHeadEvent{Header: block.Header()})
+		bc.chainHeadFeed.Send(bc.headEvent(block.Header()))
 	}
 	return CanonStatTy, nil
 }
@@ -1711,10 +1742,31 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	}
 	defer bc.chainmu.Unlock()
 
+	// Batches spanning a consensus transition are imported era by era, so the
+	// headers of each are verified in parallel by a single engine
+	if split := transitionSplit(bc.engine, chain[0].NumberU64(), len(chain)); split < len(chain) {
+		log.Debug("Splitting import batch at consensus transition", "number", chain[split].Number(), "blocks", len(chain))
+		if _, n, err := bc.insertChain(chain[:split], true, false); err != nil {
+			return n, err
+		}
+		_, n, err := bc.insertChain(chain[split:], true, false)
+		return split + n, err
+	}
 	_, n, err := bc.insertChain(chain, true, false) // No witness collection for mass inserts (would get super large)
 	return n, err
 }
 
+// transitionSplit returns the index of the first PoA block in a contiguous batch
+// of n blocks starting at the given number, if the engine switches from PoS to
+// PoA within it. Otherwise n is returned.
+func transitionSplit(engine consensus.Engine, first uint64, n int) int {
+	transitioner, ok := engine.(consensus.Transitioner)
+	if !ok || n < 2 || transitioner.UsesPoA(first) || !transitioner.UsesPoA(first+uint64(n)-1) {
+		return n
+	}
+	return sort.Search(n, func(i int) bool { return transitioner.UsesPoA(first + uint64(i)) })
+}
+
 // insertChain is the internal implementation of InsertChain, which assumes that
 // 1) chains are contiguous, and 2) The chain mutex is held.
 //
@@ -1748,7 +1800,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool, makeWitness
 	// Fire a single chain head event if we've progressed the chain
 	defer func() {
 		if lastCanon != nil && bc.CurrentBlock().Hash() == lastCanon.Hash() {
-			bc.chainHeadFeed.Send(ChainHeadEvent{Header: lastCanon.Header()})
+			bc.chainHeadFeed.Send(bc.headEvent(lastCanon.Header()))
 		}
 	}()
 	// Start the parallel header verifier
@@ -2398,6 +2450,10 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Header) error
 		blockReorgAddMeter.Mark(int64(len(newChain)))
 		blockReorgDropMeter.Mark(int64(len(oldChain)))
 		blockReorgMeter.Mark(1)
+
+		if observer, ok := bc.engine.(consensus.ReorgObserver); ok {
+			observer.ObserveReorg(commonBlock, oldChain, newChain)
+		}
 	} else if len(newChain) > 0 {
 		// Special case happens in the post merge stage that current head is
 		// the ancestor of new head while these two blocks are not consecutive
@@ -2564,7 +2620,7 @@ func (bc *BlockChain) SetCanonical(head *types.Block) (common.Hash, error) {
 	if len(logs) > 0 {
 		bc.logsFeed.Send(logs)
 	}
-	bc.chainHeadFeed.Send(ChainHeadEvent{Header: head.Header()})
+	bc.chainHeadFeed.Send(bc.headEvent(head.Header()))
 
 	context := []interface{}{
 		"number", head.Number(),
@@ -2687,6 +2743,24 @@ func (bc *BlockChain) InsertHeaderChain(chain []*types.Header) (int, error) {
 	if len(chain) == 0 {
 		return 0, nil
 	}
+	// Batches spanning a consensus transition are inserted era by era, so the
+	// headers of each are verified in parallel by a single engine. The second
+	// era can only be verified once the headers of the first are known.
+	if split := transitionSplit(bc.engine, chain[0].Number.Uint64(), len(chain)); split < len(chain) {
+		log.Debug("Splitting header batch at consensus transition", "number", chain[split].Number, "headers", len(chain))
+		if n, err := bc.insertHeaderChain(chain[:split]); err != nil {
+			return n, err
+		}
+		if n, err := bc.insertHeaderChain(chain[split:]); err != nil {
+			return split + n, err
+		}
+		return 0, nil
+	}
+	return bc.insertHeaderChain(chain)
+}
+
+// insertHeaderChain validates and inserts a batch of headers.
+func (bc *BlockChain) insertHeaderChain(chain []*types.Header) (int, error) {
 	start := time.Now()
 	if i, err := bc.hc.ValidateHeaderChain(chain); err != nil {
 		return i, err
@@ -507,6 +507,16 @@ func (bc *BlockChain) SubscribeChainEvent(ch chan<- ChainEvent) event.Subscripti
 	return bc.scope.Track(bc.chainFeed.Subscribe(ch))
 }
 
+// headEvent assembles the chain head event for the given header, annotating
+// it with the consensus era of the next block if the engine switches eras.
+func (bc *BlockChain) headEvent(header *types.Header) ChainHeadEvent {
+	event := ChainHeadEvent{Header: header}
+	if engine, ok := bc.engine.(consensus.Transitioner); ok {
+		event.PoA = engine.UsesPoA(header.Number.Uint64() + 1)
+	}
+	return event
+}
+
 // SubscribeChainHeadEvent registers a subscription of ChainHeadEvent.
 func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription {
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
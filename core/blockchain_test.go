@@ -4515,3 +4515,194 @@ func TestGetCanonicalReceipt(t *testing.T) {
 		}
 	}
 }
+
+// transitionEngine is a test engine switching to PoA rules at a given block,
+// recording the header batches it is asked to verify.
+type transitionEngine struct {
+	consensus.Engine
+	transition uint64
+	batches    [][2]uint64 // First and last number of every verified batch
+	lock       sync.Mutex
+}
+
+func (e *transitionEngine) UsesPoA(number uint64) bool {
+	return number >= e.transition
+}
+
+func (e *transitionEngine) TransitionBlock() (uint64, bool) {
+	return e.transition, true
+}
+
+func (e *transitionEngine) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header) (chan<- struct{}, <-chan error) {
+	e.lock.Lock()
+	e.batches = append(e.batches, [2]uint64{headers[0].Number.Uint64(), headers[len(headers)-1].Number.Uint64()})
+	e.lock.Unlock()
+	return e.Engine.VerifyHeaders(chain, headers)
+}
+
+// Tests that import batches spanning a consensus transition are verified era
+// by era, and that errors are reported at their index in the full batch.
+func TestInsertChainTransitionSplit(t *testing.T) {
+	gspec := &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, nil)
+
+	engine := &transitionEngine{Engine: ethash.NewFaker(), transition: 5}
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	if want := [][2]uint64{{1, 4}, {5, 10}}; !reflect.DeepEqual(engine.batches, want) {
+		t.Errorf("verified batches mismatch: have %v, want %v", engine.batches, want)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 10 {
+		t.Errorf("head mismatch: have %d, want 10", head)
+	}
+	// Failures past the transition are reported at their index in the batch
+	failing := &transitionEngine{Engine: ethash.NewFakeFailer(7), transition: 5}
+	chain, err = NewBlockChain(rawdb.NewMemoryDatabase(), gspec, failing, DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err == nil || n != 6 {
+		t.Errorf("failing insert mismatch: have index %d error %v, want index 6", n, err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 6 {
+		t.Errorf("head mismatch after failure: have %d, want 6", head)
+	}
+}
+
+// Tests that header batches spanning a consensus transition are verified era
+// by era, and that errors are reported at their index in the full batch.
+func TestInsertHeaderChainTransitionSplit(t *testing.T) {
+	gspec := &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 10, nil)
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	engine := &transitionEngine{Engine: ethash.NewFaker(), transition: 3}
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if want := [][2]uint64{{1, 2}, {3, 10}}; !reflect.DeepEqual(engine.batches, want) {
+		t.Errorf("verified batches mismatch: have %v, want %v", engine.batches, want)
+	}
+	if head := chain.CurrentHeader().Number.Uint64(); head != 10 {
+		t.Errorf("head header mismatch: have %d, want 10", head)
+	}
+	// Failures past the transition are reported at their index in the batch
+	failing := &transitionEngine{Engine: ethash.NewFakeFailer(8), transition: 3}
+	chain, err = NewBlockChain(rawdb.NewMemoryDatabase(), gspec, failing, DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertHeaderChain(headers); err == nil || n != 7 {
+		t.Errorf("failing insert mismatch: have index %d error %v, want index 7", n, err)
+	}
+}
+
+// reorgEngine is a test engine recording the reorgs it is notified of.
+type reorgEngine struct {
+	consensus.Engine
+	reorgs [][3]int // Ancestor number, dropped and added block counts
+}
+
+func (e *reorgEngine) ObserveReorg(ancestor *types.Header, dropped, added []*types.Header) {
+	e.reorgs = append(e.reorgs, [3]int{int(ancestor.Number.Uint64()), len(dropped), len(added)})
+}
+
+// Tests that engines observing reorgs are notified of canonical chain switches.
+func TestReorgObserver(t *testing.T) {
+	gspec := &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 6, nil)
+	fork, _ := GenerateChain(gspec.Config, blocks[2], ethash.NewFaker(), genDb, 5, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	engine := &reorgEngine{Engine: ethash.NewFaker()}
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	if n, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork block %d: %v", n, err)
+	}
+	if len(engine.reorgs) != 1 || engine.reorgs[0][0] != 3 || engine.reorgs[0][1] != 3 || engine.reorgs[0][2] == 0 {
+		t.Errorf("observed reorgs mismatch: have %v, want one from ancestor 3 dropping 3 blocks", engine.reorgs)
+	}
+}
+
+// forkChoiceEngine is a test engine refusing to switch to branches forking off
+// before the given block.
+type forkChoiceEngine struct {
+	consensus.Engine
+	pinned uint64
+	reject bool
+}
+
+func (e *forkChoiceEngine) ReorgNeeded(current, extern *types.Header) (bool, error) {
+	if extern.Number.Uint64() > e.pinned {
+		return true, nil
+	}
+	if e.reject {
+		return false, errors.New("branch rejected")
+	}
+	return false, nil
+}
+
+// Tests that engines choosing forks can keep branches out of the canonical chain.
+func TestForkChooser(t *testing.T) {
+	gspec := &Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), 6, nil)
+	fork, _ := GenerateChain(gspec.Config, blocks[2], ethash.NewFaker(), genDb, 5, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	for _, reject := range []bool{false, true} {
+		engine := &forkChoiceEngine{Engine: ethash.NewFaker(), pinned: 6, reject: reject}
+		chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), gspec, engine, DefaultConfig())
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("failed to insert block %d: %v", n, err)
+		}
+		// Fork blocks up to the pinned height are not preferred, the one past it is
+		n, err := chain.InsertChain(fork)
+		switch {
+		case reject && (err == nil || n != 0):
+			t.Errorf("rejected fork insert mismatch: have index %d error %v, want index 0", n, err)
+		case !reject && err != nil:
+			t.Errorf("failed to insert fork block %d: %v", n, err)
+		}
+		want := fork[len(fork)-1].Hash()
+		if reject {
+			want = blocks[len(blocks)-1].Hash()
+		}
+		if head := chain.CurrentBlock().Hash(); head != want {
+			t.Errorf("reject %v: head mismatch: have %x, want %x", reject, head, want)
+		}
+		if !reject && chain.GetBlockByHash(fork[0].Hash()) == nil {
+			t.Errorf("side chain block missing")
+		}
+		chain.Stop()
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// Tests that the path-based state scheme rolls back across the PoS to PoA
+// transition, and that the rewound blocks resync afterwards. Both eras leave
+// the state of empty blocks untouched, so state roots repeat around the
+// boundary.
+func TestPathRewindAcrossTransition(t *testing.T) {
+	const transition = 32
+	for _, tt := range []struct {
+		name    string
+		sethead uint64
+		flatten bool // Whether to flush all diff layers into the disk layer before rewinding
+		freeze  bool // Whether to move the chain until past the transition into the freezer
+		restart bool // Whether to reopen the chain between the rewind and the resync
+	}{
+		{name: "poa", sethead: transition + 8},
+		{name: "exact", sethead: transition},
+		{name: "below", sethead: transition - 1},
+		{name: "far", sethead: 2},
+		{name: "exact-disk", sethead: transition, flatten: true},
+		{name: "below-disk", sethead: transition - 1, flatten: true},
+		{name: "far-disk", sethead: 2, flatten: true},
+		{name: "below-frozen", sethead: transition - 1, freeze: true},
+		{name: "below-restart", sethead: transition - 1, restart: true},
+		{name: "below-disk-restart", sethead: transition - 1, flatten: true, restart: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			testPathRewindAcrossTransition(t, transition, tt.sethead, tt.flatten, tt.freeze, tt.restart)
+		})
+	}
+}
+
+func testPathRewindAcrossTransition(t *testing.T, transition uint64, sethead uint64, flatten, freeze, restart bool) {
+	datadir := t.TempDir()
+
+	pdb, err := pebble.New(datadir, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Failed to create persistent key-value database: %v", err)
+	}
+	db, err := rawdb.Open(pdb, rawdb.OpenOptions{Ancient: filepath.Join(datadir, "ancient")})
+	if err != nil {
+		t.Fatalf("Failed to create persistent freezer database: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		signer = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = newTransitionGenesis(transition, signer)
+		canon  = makeTransitionChain(t, gspec, key, int(transition)*2)
+		head   = canon[len(canon)-1]
+	)
+	options := DefaultConfig().WithStateScheme(rawdb.PathScheme)
+	options.SnapshotWait = true
+
+	chain := newTransitionChain(t, db, gspec, options)
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("Failed to import chain: %v", err)
+	}
+	if freeze {
+		chain.SetFinalized(canon[transition].Header())
+		if err := db.(interface{ Freeze() error }).Freeze(); err != nil {
+			t.Fatalf("Failed to freeze chain: %v", err)
+		}
+		if frozen, _ := db.Ancients(); frozen <= transition {
+			t.Fatalf("Transition not frozen: %d blocks frozen", frozen)
+		}
+	}
+	if flatten {
+		if err := chain.triedb.Commit(head.Root(), false); err != nil {
+			t.Fatalf("Failed to flatten state: %v", err)
+		}
+	}
+	if err := chain.SetHead(sethead); err != nil {
+		t.Fatalf("Failed to rewind chain: %v", err)
+	}
+	checkTransitionHead(t, chain, canon[sethead-1])
+
+	// The engine must forget the transition execution if it was rewound
+	engine := chain.Engine().(*hybrid.Hybrid)
+	if executed := engine.RuntimeState().Executed != (common.Hash{}); executed != (sethead >= transition) {
+		t.Errorf("Transition execution after rewind mismatch: have %v, want %v", executed, sethead >= transition)
+	}
+	if restart {
+		chain.Stop()
+		chain = newTransitionChain(t, db, gspec, options)
+		checkTransitionHead(t, chain, canon[sethead-1])
+	}
+	defer chain.Stop()
+
+	// Resync the rewound blocks, from the PoS era back into the PoA one
+	if _, err := chain.InsertChain(canon[sethead:]); err != nil {
+		t.Fatalf("Failed to resync chain: %v", err)
+	}
+	checkTransitionHead(t, chain, head)
+
+	if executed := chain.Engine().(*hybrid.Hybrid).RuntimeState().Executed; executed != canon[transition-1].Hash() {
+		t.Errorf("Executed transition mismatch: have %x, want %x", executed, canon[transition-1].Hash())
+	}
+}
+
+// Tests that a node crashing in the PoA era with its persisted state still in
+// the PoS era rolls the chain back into the PoS era on restart, and resyncs.
+func TestPathCrashRecoveryAcrossTransition(t *testing.T) {
+	const transition = 32
+
+	datadir := t.TempDir()
+	ancient := filepath.Join(datadir, "ancient")
+
+	pdb, err := pebble.New(datadir, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Failed to create persistent key-value database: %v", err)
+	}
+	db, err := rawdb.Open(pdb, rawdb.OpenOptions{Ancient: ancient})
+	if err != nil {
+		t.Fatalf("Failed to create persistent freezer database: %v", err)
+	}
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		gspec  = newTransitionGenesis(transition, crypto.PubkeyToAddress(key.PublicKey))
+		canon  = makeTransitionChain(t, gspec, key, transition*2)
+		commit = canon[transition-3] // Block #30, the last PoS block changing the state
+		head   = canon[transition-2] // Block #31, empty, so sharing the state of #30
+	)
+	options := DefaultConfig().WithStateScheme(rawdb.PathScheme)
+	options.SnapshotWait = true
+
+	chain := newTransitionChain(t, db, gspec, options)
+	if _, err := chain.InsertChain(canon[:commit.NumberU64()]); err != nil {
+		t.Fatalf("Failed to import PoS era: %v", err)
+	}
+	if err := chain.triedb.Commit(commit.Root(), false); err != nil {
+		t.Fatalf("Failed to flatten state: %v", err)
+	}
+	if _, err := chain.InsertChain(canon[commit.NumberU64():]); err != nil {
+		t.Fatalf("Failed to import PoA era: %v", err)
+	}
+	// Pull the plug on the database, losing the in-memory PoA era state
+	chain.triedb.Close()
+	db.Close()
+	chain.stopWithoutSaving()
+
+	if pdb, err = pebble.New(datadir, 0, 0, "", false); err != nil {
+		t.Fatalf("Failed to reopen persistent key-value database: %v", err)
+	}
+	if db, err = rawdb.Open(pdb, rawdb.OpenOptions{Ancient: ancient}); err != nil {
+		t.Fatalf("Failed to reopen persistent freezer database: %v", err)
+	}
+	defer db.Close()
+
+	chain = newTransitionChain(t, db, gspec, options)
+	defer chain.Stop()
+
+	checkTransitionHead(t, chain, head)
+	if _, err := chain.InsertChain(canon[head.NumberU64():]); err != nil {
+		t.Fatalf("Failed to resync chain: %v", err)
+	}
+	checkTransitionHead(t, chain, canon[len(canon)-1])
+}
+
+// Tests that the chain rules follow a transition flipped manually at runtime,
+// which the chain config alone knows nothing about.
+func TestRulesFollowManualTransition(t *testing.T) {
+	gspec := newTransitionGenesis(0, common.Address{0x01})
+	gspec.Config.PoSToPoATransitionBlock = nil
+	gspec.Config.PoSToPoAManualTransition = true
+
+	chain := newTransitionChain(t, rawdb.NewMemoryDatabase(), gspec, nil)
+	defer chain.Stop()
+
+	config := chain.Config()
+	if config.Rules(big.NewInt(42), true, 0).IsPoSToPoATransitioned {
+		t.Fatal("Block 42 in the PoA era before the flip")
+	}
+	if _, err := chain.Engine().(*hybrid.Hybrid).FlipToPoA(41); err != nil {
+		t.Fatalf("Failed to flip to PoA: %v", err)
+	}
+	if config.Rules(big.NewInt(41), true, 0).IsPoSToPoATransitioned {
+		t.Error("Block 41 in the PoA era after the flip")
+	}
+	if !config.Rules(big.NewInt(42), true, 0).IsPoSToPoATransitioned {
+		t.Error("Block 42 not in the PoA era after the flip")
+	}
+}
+
+// Tests that a manual flip survives a restart: the chain rules of the restarted
+// node and the compatibility checks of its genesis setup follow the flip.
+func TestManualTransitionRestart(t *testing.T) {
+	gspec := newTransitionGenesis(0, common.Address{0x01})
+	gspec.Config.PoSToPoATransitionBlock = nil
+	gspec.Config.PoSToPoAManualTransition = true
+
+	db := rawdb.NewMemoryDatabase()
+	chain := newTransitionChain(t, db, gspec, nil)
+	if _, err := chain.Engine().(*hybrid.Hybrid).FlipToPoA(10); err != nil {
+		t.Fatalf("Failed to flip to PoA: %v", err)
+	}
+	chain.Stop()
+
+	// The restarted chain starts in the PoA era from the flip on
+	chain = newTransitionChain(t, db, gspec, nil)
+	if !chain.Config().IsPoAEra(big.NewInt(11)) || chain.Config().IsPoAEra(big.NewInt(10)) {
+		t.Error("Restarted chain not following the flip at block 11")
+	}
+	chain.Stop()
+
+	// Changing the grace window is incompatible with the chain past the flip
+	changed := *gspec
+	config := *gspec.Config
+	config.TransitionGraceWindow = new(uint64)
+	*config.TransitionGraceWindow = 4
+	changed.Config = &config
+
+	header := &types.Header{Number: big.NewInt(12), Difficulty: common.Big2}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+
+	_, _, compatErr, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), &changed)
+	if err != nil {
+		t.Fatalf("Failed to set up genesis: %v", err)
+	}
+	if compatErr == nil || compatErr.What != "PoS to PoA grace window" || compatErr.RewindToBlock != 10 {
+		t.Fatalf("Compatibility error mismatch: have %v, want changed grace window", compatErr)
+	}
+}
+
+// newTransitionGenesis returns the genesis of a network switching from PoS to
+// PoA at the given block, sealed by a single signer afterwards.
+func newTransitionGenesis(transition uint64, signer common.Address) *Genesis {
+	config := *params.AllCliqueProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.PoSToPoATransitionBlock = new(big.Int).SetUint64(transition)
+	config.PoAInitialSigners = []common.Address{signer}
+
+	return &Genesis{
+		Config:     &config,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+		Alloc:      types.GenesisAlloc{signer: {Balance: big.NewInt(params.Ether)}},
+	}
+}
+
+// newTransitionChain creates a chain on the database, verified by the hybrid
+// engine of the genesis config.
+func newTransitionChain(t *testing.T, db ethdb.Database, gspec *Genesis, options *BlockChainConfig) *BlockChain {
+	t.Helper()
+
+	engine, err := hybrid.NewFromChainConfig(gspec.Config, db, hybrid.WithStrict(false))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	chain, err := NewBlockChain(db, gspec, engine, options)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	return chain
+}
+
+// makeTransitionChain generates n blocks across the transition, sealing the PoA
+// ones with the key of the single signer. Every third block is left empty.
+func makeTransitionChain(t *testing.T, gspec *Genesis, key *ecdsa.PrivateKey, n int) types.Blocks {
+	t.Helper()
+
+	engine, err := hybrid.NewFromChainConfig(gspec.Config, rawdb.NewMemoryDatabase(), hybrid.WithStrict(false))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	var (
+		from       = crypto.PubkeyToAddress(key.PublicKey)
+		signer     = types.LatestSigner(gspec.Config)
+		transition = gspec.Config.PoSToPoATransitionBlock.Uint64()
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, n, func(i int, b *BlockGen) {
+		if i%3 == 0 {
+			return
+		}
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(from), common.Address{0xaa}, big.NewInt(1), params.TxGas, b.header.BaseFee, nil), signer, key)
+		b.AddTx(tx)
+	})
+	// Seal the PoA blocks, relinking them as the seal changes their hashes
+	for i := int(transition) - 1; i < n; i++ {
+		header := blocks[i].Header()
+		header.ParentHash = blocks[i-1].Hash()
+		header.Difficulty = big.NewInt(2)
+		header.Extra = make([]byte, 32+crypto.SignatureLength)
+		sig, _ := crypto.Sign(engine.SealHash(header).Bytes(), key)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+		blocks[i] = blocks[i].WithSeal(header)
+	}
+	return blocks
+}
+
+// checkTransitionHead checks that the chain head is the given block, with its
+// state available.
+func checkTransitionHead(t *testing.T, chain *BlockChain, want *types.Block) {
+	t.Helper()
+
+	if head := chain.CurrentBlock(); head.Hash() != want.Hash() {
+		t.Fatalf("Head mismatch: have #%d [%x], want #%d [%x]", head.Number, head.Hash().Bytes()[:4], want.Number(), want.Hash().Bytes()[:4])
+	}
+	if !chain.HasState(want.Root()) {
+		t.Fatalf("State of head #%d missing", want.Number())
+	}
+	if _, err := chain.State(); err != nil {
+		t.Fatalf("Failed to open head state: %v", err)
+	}
+}
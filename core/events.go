@@ -32,4 +32,5 @@ type ChainEvent struct {
 
 type ChainHeadEvent struct {
 	Header *types.Header
+	PoA    bool // Whether the block following the head is subject to the PoA rules of a hybrid engine
 }
@@ -19,6 +19,7 @@ package filtermaps
 import (
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"slices"
 	"sync"
@@ -268,7 +269,7 @@ func NewFilterMaps(db ethdb.KeyValueStore, initView *ChainView, historyCutoff, f
 		lvPointerCache:      lru.NewCache[uint64, uint64](cachedLvPointers),
 		renderSnapshots:     lru.NewCache[uint64, *renderedMap](cachedRenderSnapshots),
 	}
-	f.checkRevertRange() // revert maps that are inconsistent with the current chain view
+	f.checkRevertRange(math.MaxUint64) // revert maps that are inconsistent with the current chain view
 
 	if f.indexedRange.hasIndexedBlocks() {
 		log.Info("Initialized log indexer",
@@ -299,8 +300,9 @@ func (f *FilterMaps) Stop() {
 }
 
 // checkRevertRange checks whether the existing index is consistent with the
-// current indexed view and reverts inconsistent maps if necessary.
-func (f *FilterMaps) checkRevertRange() {
+// current indexed view and reverts inconsistent maps if necessary. Maps covering
+// any block from the given number on are reverted as well.
+func (f *FilterMaps) checkRevertRange(from uint64) {
 	if f.indexedRange.maps.Count() == 0 {
 		return
 	}
@@ -311,7 +313,7 @@ func (f *FilterMaps) checkRevertRange() {
 		f.reset()
 		return
 	}
-	for lastBlockNumber > f.indexedView.HeadNumber() || f.indexedView.BlockId(lastBlockNumber) != lastBlockId {
+	for lastBlockNumber >= from || lastBlockNumber > f.indexedView.HeadNumber() || f.indexedView.BlockId(lastBlockNumber) != lastBlockId {
 		// revert last map
 		if f.indexedRange.maps.Count() == 1 {
 			f.reset() // reset database if no rendered maps remained
@@ -334,6 +336,14 @@ func (f *FilterMaps) checkRevertRange() {
 	}
 }
 
+// RevertFrom discards the maps covering the given block and the ones after it,
+// leaving them to be rendered again by the indexer. This repairs the index of a
+// chain section without rebuilding the entire index. It should be called before
+// Start.
+func (f *FilterMaps) RevertFrom(number uint64) {
+	f.checkRevertRange(number)
+}
+
 // reset un-initializes the FilterMaps structure and removes all related data from
 // the database.
 // Note that in case of leveldb database the fallback implementation of DeleteRange
@@ -337,6 +337,41 @@ func TestIndexerCompareDb(t *testing.T) {
 	ts.checkDbHash("no index")
 }
 
+func TestIndexerRevertFrom(t *testing.T) {
+	ts := newTestSetup(t)
+	defer ts.close()
+
+	ts.chain.addBlocks(1000, 5, 2, 4, false)
+	ts.setHistory(0, false)
+	ts.fm.WaitIdle()
+	ts.storeDbHash("chain [0, 1000]")
+	matcherHash := ts.matcherViewHash()
+
+	// Revert the index from block 500 on without starting the indexer
+	ts.fm.Stop()
+	head := ts.chain.CurrentBlock()
+	view := NewChainView(ts.chain, head.Number.Uint64(), head.Hash())
+	fm, err := NewFilterMaps(ts.db, view, 0, 0, ts.params, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm.RevertFrom(500)
+	if fm.indexedRange.blocks.AfterLast() > 500 {
+		t.Fatalf("Block %d still indexed after revert", fm.indexedRange.blocks.Last())
+	}
+	if fm.indexedRange.headIndexed {
+		t.Fatalf("Head still indexed after revert")
+	}
+	// Rendering the reverted section again should yield the same index
+	ts.fm = nil
+	ts.setHistory(0, false)
+	ts.fm.WaitIdle()
+	ts.checkDbHash("chain [0, 1000]")
+	if ts.matcherViewHash() != matcherHash {
+		t.Fatalf("Matcher view hash mismatch after rebuilding the reverted section")
+	}
+}
+
 type testSetup struct {
 	t                    *testing.T
 	fm                   *FilterMaps
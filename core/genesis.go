@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -44,7 +45,13 @@ import (
 
 //go:generate go run github.com/fjl/gencodec -type Genesis -field-override genesisSpecMarshaling -out gen_genesis.go
 
-var errGenesisNoConfig = errors.New("genesis has no chain configuration")
+var (
+	errGenesisNoConfig = errors.New("genesis has no chain configuration")
+
+	// errTransitionReached is returned when overriding the PoS to PoA transition
+	// block of a chain whose head already reached the transition.
+	errTransitionReached = errors.New("PoS to PoA transition already reached")
+)
 
 // Deprecated: use types.Account instead.
 type GenesisAccount = types.Account
@@ -258,8 +265,9 @@ func (e *GenesisMismatchError) Error() string {
 
 // ChainOverrides contains the changes to chain config.
 type ChainOverrides struct {
-	OverrideOsaka  *uint64
-	OverrideVerkle *uint64
+	OverrideOsaka           *uint64
+	OverrideVerkle          *uint64
+	OverrideTransitionBlock *uint64
 }
 
 // apply applies the chain overrides on the supplied chain config.
@@ -273,9 +281,64 @@ func (o *ChainOverrides) apply(cfg *params.ChainConfig) error {
 	if o.OverrideVerkle != nil {
 		cfg.VerkleTime = o.OverrideVerkle
 	}
+	if o.OverrideTransitionBlock != nil {
+		block := new(big.Int).SetUint64(*o.OverrideTransitionBlock)
+		if cfg.PoSToPoATransitionBlock == nil || cfg.PoSToPoATransitionBlock.Cmp(block) != 0 {
+			log.Info("Hybrid setting overridden", "setting", "transition block", "value", block, "previous", cfg.PoSToPoATransitionBlock, "source", "flag")
+		}
+		cfg.PoSToPoATransitionBlock = block
+	}
 	return cfg.CheckConfigForkOrder()
 }
 
+// reportHybrid logs every consensus-critical hybrid setting of a new chain
+// config differing from the stored one, so operators can verify what the node
+// runs. The new settings are not reverted to the stored ones: whether they may
+// still change is decided by CheckCompatible against the head of the chain.
+func (o *ChainOverrides) reportHybrid(stored, cfg *params.ChainConfig) {
+	overridden := o != nil && o.OverrideTransitionBlock != nil
+	if old, block := stored.PoSToPoATransitionBlock, cfg.PoSToPoATransitionBlock; !overridden && ((old == nil) != (block == nil) || (old != nil && block != nil && old.Cmp(block) != 0)) {
+		log.Warn("Hybrid setting changed by genesis", "setting", "transition block", "stored", old, "genesis", block)
+	}
+	if old, signers := stored.PoAInitialSigners, cfg.PoAInitialSigners; !slices.Equal(old, signers) {
+		log.Warn("Hybrid setting changed by genesis", "setting", "initial signers", "stored", old, "genesis", signers)
+	}
+	if old, depth := stored.TransitionConfirmationDepth, cfg.TransitionConfirmationDepth; (old == nil) != (depth == nil) || (old != nil && depth != nil && *old != *depth) {
+		log.Warn("Hybrid setting changed by genesis", "setting", "confirmation depth", "stored", optionalUint64(old), "genesis", optionalUint64(depth))
+	}
+}
+
+// optionalUint64 returns the value of an optional setting for logging.
+func optionalUint64(v *uint64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// checkTransition rejects moving the PoS to PoA transition once the head of the
+// chain reached either the stored or the overridden transition block. Unlike
+// other forks the transition cannot be undone by rewinding the chain, as PoA
+// signers may already have sealed blocks on top of it.
+func (o *ChainOverrides) checkTransition(stored *params.ChainConfig, head uint64) error {
+	if o == nil || o.OverrideTransitionBlock == nil {
+		return nil
+	}
+	block := *o.OverrideTransitionBlock
+	if current := stored.EffectiveTransitionBlock(); current != nil {
+		if current.Uint64() == block {
+			return nil
+		}
+		if current.Uint64() <= head {
+			return fmt.Errorf("%w: cannot move transition block %d reached by head %d", errTransitionReached, current, head)
+		}
+	}
+	if block <= head {
+		return fmt.Errorf("%w: overridden transition block %d is not ahead of head %d", errTransitionReached, block, head)
+	}
+	return nil
+}
+
 // SetupGenesisBlock writes or updates the genesis block in db.
 // The block that will be used is:
 //
@@ -364,7 +427,22 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *triedb.Database, g
 	if head == nil {
 		return nil, common.Hash{}, nil, errors.New("missing head header")
 	}
+	// Transitions resolved at runtime by a previous run are only recorded in
+	// the database, guard them like configured ones
+	if number, ok := rawdb.ReadResolvedTransition(db); ok {
+		storedCfg.Transition = params.ResolvedTransition(number)
+	}
+	if err := overrides.checkTransition(storedCfg, head.Number.Uint64()); err != nil {
+		return nil, common.Hash{}, nil, err
+	}
 	newCfg := genesis.chainConfigOrDefault(ghash, storedCfg)
+	if newCfg == storedCfg {
+		// Apply the overrides on a copy, so they are compared against and
+		// persisted over the stored config
+		cpy := *storedCfg
+		newCfg = &cpy
+	}
+	overrides.reportHybrid(storedCfg, newCfg)
 	if err := overrides.apply(newCfg); err != nil {
 		return nil, common.Hash{}, nil, err
 	}
@@ -538,7 +616,7 @@ func (g *Genesis) Commit(db ethdb.Database, triedb *triedb.Database) (*types.Blo
 	if config.Clique != nil && len(g.ExtraData) < 32+crypto.SignatureLength {
 		// Allow empty signers if PoS to PoA transition is configured
 		// In this case, signers will be set up during the transition block
-		if config.PoSToPoATransitionBlock == nil {
+		if !config.HasPoSToPoATransition() {
 			return nil, errors.New("can't start clique chain without signers")
 		}
 	}
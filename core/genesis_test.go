@@ -19,8 +19,10 @@ package core
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -337,3 +339,165 @@ func TestVerkleGenesisCommit(t *testing.T) {
 		t.Fatal("could not find node")
 	}
 }
+
+// Tests that the PoS to PoA transition block can be overridden as long as the
+// chain has not reached it yet.
+func TestTransitionBlockOverride(t *testing.T) {
+	config := *params.AllCliqueProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.PoSToPoATransitionBlock = big.NewInt(100)
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &Genesis{Config: &config, Difficulty: common.Big0, ExtraData: make([]byte, 97)}
+	)
+	if _, _, _, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), genesis); err != nil {
+		t.Fatalf("Failed to set up genesis: %v", err)
+	}
+	setHead := func(number uint64) {
+		header := &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: common.Big0}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteHeadHeaderHash(db, header.Hash())
+	}
+	override := func(block uint64) (*params.ChainConfig, error) {
+		cfg, _, compatErr, err := SetupGenesisBlockWithOverride(db, triedb.NewDatabase(db, nil), nil, &ChainOverrides{OverrideTransitionBlock: &block})
+		if compatErr != nil {
+			t.Fatalf("Unexpected compatibility error: %v", compatErr)
+		}
+		return cfg, err
+	}
+	stored := func() uint64 {
+		return rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0)).PoSToPoATransitionBlock.Uint64()
+	}
+	// Move the transition ahead of a head before it
+	setHead(50)
+	if cfg, err := override(200); err != nil {
+		t.Fatalf("Failed to override transition block: %v", err)
+	} else if cfg.PoSToPoATransitionBlock.Uint64() != 200 || stored() != 200 {
+		t.Fatalf("Transition block mismatch: have %v, stored %d, want 200", cfg.PoSToPoATransitionBlock, stored())
+	}
+	// Moving it to or below the head is rejected
+	if _, err := override(50); !errors.Is(err, errTransitionReached) {
+		t.Fatalf("Expected transition reached error, got %v", err)
+	}
+	// Reaching the transition freezes it, unless overridden with the same block
+	setHead(200)
+	if _, err := override(300); !errors.Is(err, errTransitionReached) {
+		t.Fatalf("Expected transition reached error, got %v", err)
+	}
+	if _, err := override(200); err != nil {
+		t.Fatalf("Failed to override with the reached transition block: %v", err)
+	}
+	if stored() != 200 {
+		t.Fatalf("Stored transition block changed to %d", stored())
+	}
+}
+
+// Tests the precedence of the hybrid settings: overrides take precedence over
+// the genesis, whose settings replace the stored ones as long as the chain has
+// not reached the transition, after which changing them is incompatible.
+func TestHybridSettingsPrecedence(t *testing.T) {
+	config := *params.AllCliqueProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.PoSToPoATransitionBlock = big.NewInt(100)
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &Genesis{Config: &config, Difficulty: common.Big0, ExtraData: make([]byte, 97)}
+	)
+	if _, _, _, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), genesis); err != nil {
+		t.Fatalf("Failed to set up genesis: %v", err)
+	}
+	setup := func(genesis *Genesis, overrides *ChainOverrides) (*params.ChainConfig, *params.ConfigCompatError) {
+		cfg, _, compatErr, err := SetupGenesisBlockWithOverride(db, triedb.NewDatabase(db, nil), genesis, overrides)
+		if err != nil {
+			t.Fatalf("Failed to set up genesis: %v", err)
+		}
+		return cfg, compatErr
+	}
+	// A genesis rescheduling the transition ahead of the head, with new initial
+	// signers and confirmation depth, replaces the stored settings
+	var (
+		depth         = uint64(10)
+		updated       = *genesis
+		updatedConfig = config
+	)
+	updated.Config = &updatedConfig
+	updated.Config.PoSToPoATransitionBlock = big.NewInt(150)
+	updated.Config.PoAInitialSigners = []common.Address{{1}}
+	updated.Config.TransitionConfirmationDepth = &depth
+
+	cfg, compatErr := setup(&updated, nil)
+	if compatErr != nil {
+		t.Fatalf("Unexpected compatibility error: %v", compatErr)
+	}
+	if cfg.PoSToPoATransitionBlock.Uint64() != 150 {
+		t.Errorf("Transition block mismatch: have %v, want 150 from genesis", cfg.PoSToPoATransitionBlock)
+	}
+	if !slices.Equal(cfg.PoAInitialSigners, []common.Address{{1}}) || *cfg.TransitionConfirmationDepth != depth {
+		t.Errorf("Settings mismatch: have signers %v, depth %d, want genesis ones", cfg.PoAInitialSigners, *cfg.TransitionConfirmationDepth)
+	}
+	stored := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if stored.PoSToPoATransitionBlock.Uint64() != 150 || !slices.Equal(stored.PoAInitialSigners, []common.Address{{1}}) {
+		t.Errorf("Stored settings mismatch: have block %v, signers %v", stored.PoSToPoATransitionBlock, stored.PoAInitialSigners)
+	}
+	// An override beats the genesis
+	block := uint64(200)
+	if cfg, _ = setup(&updated, &ChainOverrides{OverrideTransitionBlock: &block}); cfg.PoSToPoATransitionBlock.Uint64() != block {
+		t.Errorf("Transition block mismatch: have %v, want %d from override", cfg.PoSToPoATransitionBlock, block)
+	}
+	// Once the head reached the transition, the genesis can no longer change
+	// the signers it checkpointed
+	setup(&updated, nil)
+	header := &types.Header{Number: big.NewInt(150), Difficulty: common.Big2}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+
+	updated.Config.PoAInitialSigners = []common.Address{{2}}
+	if _, compatErr = setup(&updated, nil); compatErr == nil || compatErr.What != "PoA initial signers" {
+		t.Fatalf("Compatibility error mismatch: have %v, want changed initial signers", compatErr)
+	}
+	if stored := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0)); !slices.Equal(stored.PoAInitialSigners, []common.Address{{1}}) {
+		t.Errorf("Stored signers changed to %v", stored.PoAInitialSigners)
+	}
+}
+
+// Tests that a transition resolved at runtime, without a configured transition
+// block, is guarded against incompatible changes like a configured one.
+func TestResolvedTransitionCompatibility(t *testing.T) {
+	config := *params.AllCliqueProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.PoSToPoAManualTransition = true
+	config.PoAInitialSigners = []common.Address{{1}}
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &Genesis{Config: &config, Difficulty: common.Big0, ExtraData: make([]byte, 97)}
+	)
+	if _, _, _, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), genesis); err != nil {
+		t.Fatalf("Failed to set up genesis: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(150), Difficulty: common.Big2}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteHeadHeaderHash(db, header.Hash())
+	rawdb.WriteResolvedTransition(db, 100)
+
+	changed := *genesis
+	changed.Config = &params.ChainConfig{}
+	*changed.Config = config
+	changed.Config.PoAInitialSigners = []common.Address{{2}}
+
+	_, _, compatErr, err := SetupGenesisBlock(db, triedb.NewDatabase(db, nil), &changed)
+	if err != nil {
+		t.Fatalf("Failed to set up genesis: %v", err)
+	}
+	if compatErr == nil || compatErr.What != "PoA initial signers" || compatErr.RewindToBlock != 99 {
+		t.Fatalf("Compatibility error mismatch: have %v, want changed initial signers", compatErr)
+	}
+	// The resolved transition cannot be moved by an override either
+	block := uint64(200)
+	_, _, _, err = SetupGenesisBlockWithOverride(db, triedb.NewDatabase(db, nil), genesis, &ChainOverrides{OverrideTransitionBlock: &block})
+	if !errors.Is(err, errTransitionReached) {
+		t.Fatalf("Error mismatch: have %v, want %v", err, errTransitionReached)
+	}
+}
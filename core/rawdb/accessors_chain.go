@@ -285,6 +285,32 @@ func DeleteTxIndexTail(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadFreezeHold retrieves the number of the first block the chain freezer must
+// not move into the ancient store, if any.
+func ReadFreezeHold(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(freezeHoldKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteFreezeHold stores the number of the first block the chain freezer must
+// not move into the ancient store.
+func WriteFreezeHold(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(freezeHoldKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the freeze hold", "err", err)
+	}
+}
+
+// DeleteFreezeHold deletes the freeze hold, letting the chain freezer proceed.
+func DeleteFreezeHold(db ethdb.KeyValueWriter) {
+	if err := db.Delete(freezeHoldKey); err != nil {
+		log.Crit("Failed to delete the freeze hold", "err", err)
+	}
+}
+
 // ReadHeaderRange returns the rlp-encoded headers, starting at 'number', and going
 // backwards towards genesis. This method assumes that the caller already has
 // placed a cap on count, to prevent DoS issues.
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"time"
 
@@ -187,3 +188,21 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// ReadResolvedTransition retrieves the PoS to PoA transition block resolved at
+// runtime, through the terminal PoS block or a manual flip.
+func ReadResolvedTransition(db ethdb.KeyValueReader) (uint64, bool) {
+	data, _ := db.Get(resolvedTransitionKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteResolvedTransition stores the PoS to PoA transition block resolved at
+// runtime to the database.
+func WriteResolvedTransition(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(resolvedTransitionKey, binary.BigEndian.AppendUint64(nil, number)); err != nil {
+		log.Crit("Failed to store the resolved transition block", "err", err)
+	}
+}
@@ -131,7 +131,8 @@ func (f *chainFreezer) readFinalizedNumber(db ethdb.KeyValueReader) uint64 {
 }
 
 // freezeThreshold returns the threshold for chain freezing. It's determined
-// by formula: max(finality, HEAD-params.FullImmutabilityThreshold).
+// by formula: max(finality, HEAD-params.FullImmutabilityThreshold), capped
+// below the freeze hold if one is set.
 func (f *chainFreezer) freezeThreshold(db ethdb.KeyValueReader) (uint64, error) {
 	var (
 		head      = f.readHeadNumber(db)
@@ -144,10 +145,16 @@ func (f *chainFreezer) freezeThreshold(db ethdb.KeyValueReader) (uint64, error)
 	if final == 0 && headLimit == 0 {
 		return 0, errors.New("freezing threshold is not available")
 	}
-	if final > headLimit {
-		return final, nil
+	threshold := max(final, headLimit)
+
+	// Blocks from the hold on may still be reorged, keep them in the live database
+	if hold := ReadFreezeHold(db); hold != nil && threshold >= *hold {
+		if *hold == 0 {
+			return 0, errors.New("freezing is held at genesis")
+		}
+		threshold = *hold - 1
 	}
-	return headLimit, nil
+	return threshold, nil
 }
 
 // freeze is a background thread that periodically checks the blockchain for any
@@ -0,0 +1,59 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the freeze hold caps the freezing threshold.
+func TestFreezeThresholdHold(t *testing.T) {
+	f, err := newChainFreezer("", "", "", false)
+	if err != nil {
+		t.Fatalf("Failed to create chain freezer: %v", err)
+	}
+	defer f.Close()
+
+	db := memorydb.New()
+	head := common.Hash{0x01}
+	WriteHeadBlockHash(db, head)
+	WriteHeaderNumber(db, head, params.FullImmutabilityThreshold+1000)
+
+	if threshold, err := f.freezeThreshold(db); err != nil || threshold != 1000 {
+		t.Fatalf("Unheld threshold mismatch: have %d, %v, want %d", threshold, err, 1000)
+	}
+	WriteFreezeHold(db, 500)
+	if threshold, err := f.freezeThreshold(db); err != nil || threshold != 499 {
+		t.Fatalf("Held threshold mismatch: have %d, %v, want %d", threshold, err, 499)
+	}
+	WriteFreezeHold(db, 2000)
+	if threshold, err := f.freezeThreshold(db); err != nil || threshold != 1000 {
+		t.Fatalf("Threshold below hold mismatch: have %d, %v, want %d", threshold, err, 1000)
+	}
+	WriteFreezeHold(db, 0)
+	if _, err := f.freezeThreshold(db); err == nil {
+		t.Fatal("Freezing not held at genesis")
+	}
+	DeleteFreezeHold(db)
+	if threshold, err := f.freezeThreshold(db); err != nil || threshold != 1000 {
+		t.Fatalf("Released threshold mismatch: have %d, %v, want %d", threshold, err, 1000)
+	}
+}
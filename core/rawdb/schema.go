@@ -83,6 +83,9 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// freezeHoldKey tracks the first block which must be kept out of the freezer.
+	freezeHoldKey = []byte("FreezeHold")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	// This flag is deprecated, it's kept to avoid reporting errors when inspect
 	// database.
@@ -97,6 +100,10 @@ var (
 	// transitionStatusKey tracks the eth2 transition status.
 	transitionStatusKey = []byte("eth2-transition")
 
+	// resolvedTransitionKey tracks the PoS to PoA transition block resolved at
+	// runtime.
+	resolvedTransitionKey = []byte("hybrid-resolved-transition")
+
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
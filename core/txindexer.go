@@ -61,17 +61,24 @@ type txIndexer struct {
 	// cutoff denotes the block number before which the chain segment should
 	// be pruned and not available locally.
 	cutoff uint64
+
+	// floor optionally reports the first block whose transactions may be
+	// indexed, on top of the cutoff. Unlike the cutoff, the floor may move up
+	// while the indexer is running, unindexing the blocks left behind.
+	floor func() (uint64, bool)
+
 	db     ethdb.Database
 	term   chan chan struct{}
 	closed chan struct{}
 }
 
 // newTxIndexer initializes the transaction indexer.
-func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
+func newTxIndexer(limit uint64, floor func() (uint64, bool), chain *BlockChain) *txIndexer {
 	cutoff, _ := chain.HistoryPruningCutoff()
 	indexer := &txIndexer{
 		limit:  limit,
 		cutoff: cutoff,
+		floor:  floor,
 		db:     chain.db,
 		term:   make(chan chan struct{}),
 		closed: make(chan struct{}),
@@ -110,6 +117,10 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	if head == 0 || head < indexer.cutoff {
 		return
 	}
+	cutoff, ok := indexer.indexCutoff(head)
+	if !ok {
+		return // The floor is above the head, wait for the chain to reach it
+	}
 	// The tail flag is not existent, it means the node is just initialized
 	// and all blocks in the chain (part of them may from ancient store) are
 	// not indexed yet, index the chain according to the configured limit.
@@ -121,23 +132,25 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 		if indexer.limit != 0 && head >= indexer.limit {
 			from = head - indexer.limit + 1
 		}
-		from = max(from, indexer.cutoff)
+		from = max(from, cutoff)
 		rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
 		return
 	}
 	// The tail flag is existent (which means indexes in [tail, head] should be
 	// present), while the whole chain are requested for indexing.
 	if indexer.limit == 0 || head < indexer.limit {
-		if *tail > 0 {
-			from := max(uint64(0), indexer.cutoff)
-			rawdb.IndexTransactions(indexer.db, from, *tail, stop, true)
+		if cutoff < *tail {
+			rawdb.IndexTransactions(indexer.db, cutoff, *tail, stop, true)
+		} else if cutoff > *tail {
+			// The floor moved up, unindex the blocks below it
+			rawdb.UnindexTransactions(indexer.db, *tail, cutoff, stop, false)
 		}
 		return
 	}
 	// The tail flag is existent, adjust the index range according to configured
 	// limit and the latest chain head.
 	from := head - indexer.limit + 1
-	from = max(from, indexer.cutoff)
+	from = max(from, cutoff)
 	if from < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
 		rawdb.IndexTransactions(indexer.db, from, *tail, stop, true)
@@ -147,6 +160,21 @@ func (indexer *txIndexer) run(head uint64, stop chan struct{}, done chan struct{
 	}
 }
 
+// indexCutoff returns the first block whose transactions are to be indexed at
+// the given chain head, raising the history cutoff to the floor if one is set.
+// False is returned if the floor is above the head, e.g. while the chain is
+// rewound below it, in which case the indexes are to be left untouched.
+func (indexer *txIndexer) indexCutoff(head uint64) (uint64, bool) {
+	if indexer.floor == nil {
+		return indexer.cutoff, true
+	}
+	floor, ok := indexer.floor()
+	if !ok {
+		return indexer.cutoff, true
+	}
+	return max(indexer.cutoff, floor), floor <= head
+}
+
 // repair ensures that transaction indexes are in a valid state and invalidates
 // them if they are not. The following cases are considered invalid:
 // * The index tail is higher than the chain head.
@@ -280,9 +308,10 @@ func (indexer *txIndexer) loop(chain *BlockChain) {
 
 // report returns the tx indexing progress.
 func (indexer *txIndexer) report(head uint64, tail *uint64) TxIndexProgress {
-	// Special case if the head is even below the cutoff,
-	// nothing to index.
-	if head < indexer.cutoff {
+	// Special case if the head is even below the cutoff or
+	// the floor, nothing to index.
+	cutoff, ok := indexer.indexCutoff(head)
+	if !ok || head < cutoff {
 		return TxIndexProgress{
 			Indexed:   0,
 			Remaining: 0,
@@ -293,7 +322,7 @@ func (indexer *txIndexer) report(head uint64, tail *uint64) TxIndexProgress {
 	if indexer.limit == 0 || total > head {
 		total = head + 1 // genesis included
 	}
-	length := head - indexer.cutoff + 1 // all available chain for indexing
+	length := head - cutoff + 1 // all available chain for indexing
 	if total > length {
 		total = length
 	}
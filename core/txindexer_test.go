@@ -133,6 +133,76 @@ func TestTxIndexer(t *testing.T) {
 	}
 }
 
+// TestTxIndexerFloor tests that the transactions below a floor are left
+// unindexed, and unindexed once the floor moves up.
+func TestTxIndexerFloor(t *testing.T) {
+	var (
+		testBankKey, _  = crypto.GenerateKey()
+		testBankAddress = crypto.PubkeyToAddress(testBankKey.PublicKey)
+		testBankFunds   = big.NewInt(1000000000000000000)
+
+		gspec = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine    = ethash.NewFaker()
+		nonce     = uint64(0)
+		chainHead = uint64(128)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, int(chainHead), func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.HexToAddress("0xdeadbeef"), big.NewInt(1000), params.TxGas, big.NewInt(10*params.InitialBaseFee), nil), types.HomesteadSigner{}, testBankKey)
+		gen.AddTx(tx)
+		nonce += 1
+	})
+	var cases = []struct {
+		limit  uint64
+		floors []int64 // -1 means no floor reported
+		tails  []uint64
+	}{
+		{
+			limit:  0,
+			floors: []int64{64, 100, -1},
+			tails:  []uint64{64, 100, 0},
+		},
+		{
+			limit:  0,
+			floors: []int64{-1, 64, 200},
+			tails:  []uint64{0, 64, 64}, // a floor above the head leaves the index untouched
+		},
+		{
+			limit:  32,
+			floors: []int64{64, 110, 64},
+			tails:  []uint64{97, 110, 97},
+		},
+		{
+			limit:  100,
+			floors: []int64{-1, 64, 10},
+			tails:  []uint64{29, 64, 29},
+		},
+	}
+	for i, c := range cases {
+		db, _ := rawdb.Open(rawdb.NewMemoryDatabase(), rawdb.OpenOptions{})
+		rawdb.WriteAncientBlocks(db, append([]*types.Block{gspec.ToBlock()}, blocks...), types.EncodeBlockReceiptLists(append([]types.Receipts{{}}, receipts...)))
+
+		var floor int64
+		indexer := &txIndexer{
+			limit: c.limit,
+			floor: func() (uint64, bool) { return uint64(floor), floor >= 0 },
+			db:    db,
+		}
+		for j := range c.floors {
+			floor = c.floors[j]
+			indexer.run(chainHead, make(chan struct{}), make(chan struct{}))
+			if tail := rawdb.ReadTxIndexTail(db); tail == nil || *tail != c.tails[j] {
+				t.Fatalf("case %d, step %d: tail mismatch, have %v, want %d", i, j, tail, c.tails[j])
+			}
+			verify(t, db, blocks, c.tails[j])
+		}
+		db.Close()
+	}
+}
+
 func TestTxIndexerRepair(t *testing.T) {
 	var (
 		testBankKey, _  = crypto.GenerateKey()
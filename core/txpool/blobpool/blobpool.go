@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -328,6 +329,8 @@ type BlobPool struct {
 	insertFeed   event.Feed // Event feed to send out new tx events on pool inclusion (reorg included)
 
 	lock sync.RWMutex // Mutex protecting the pool during reorg handling
+
+	poa atomic.Bool // Whether the PoA era acceptance policy is active
 }
 
 // New creates a new blob transaction pool to gather, sort and filter inbound
@@ -350,9 +353,22 @@ func New(config Config, chain BlockChain, hasPendingAuth func(common.Address) bo
 
 // Filter returns whether the given transaction can be consumed by the blob pool.
 func (p *BlobPool) Filter(tx *types.Transaction) bool {
+	if p.config.PoADisabled && p.poa.Load() {
+		return false
+	}
 	return tx.Type() == types.BlobTxType
 }
 
+// SetPoA implements txpool.EraSwitcher. If configured, new blob transactions are
+// rejected in the PoA era, as the PoA engine does not carry blobs. Already pooled
+// transactions are kept, so they can be included again after a reorg back
+// across the transition.
+func (p *BlobPool) SetPoA(poa bool) {
+	if p.poa.Swap(poa) != poa && p.config.PoADisabled {
+		log.Info("Blob pool switched era policy", "poa", poa, "accepting", !poa)
+	}
+}
+
 // Init sets the gas price needed to keep a transaction in the pool and the chain
 // head to allow balance / nonce checks. The transaction journal will be loaded
 // from disk and filtered based on the provided starting settings.
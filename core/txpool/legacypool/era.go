@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// EraConfig overrides the acceptance policy of the pool for blocks after a PoS
+// to PoA transition. Zero values keep the base configuration.
+type EraConfig struct {
+	PriceLimit   uint64 // Minimum gas tip to enforce for acceptance into the pool
+	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
+}
+
+// SetPoA implements txpool.EraSwitcher, switching the pool to the acceptance
+// policy of the PoA era or back to the base one. A lowered queue limit takes
+// effect with the next reset, which the pool runs right after the switch.
+func (pool *LegacyPool) SetPoA(poa bool) {
+	pool.mu.Lock()
+	if pool.poa == poa {
+		pool.mu.Unlock()
+		return
+	}
+	pool.poa = poa
+
+	var tip *big.Int
+	if era := pool.config.PoA; era != nil && era.PriceLimit != 0 {
+		if poa {
+			pool.baseTip = pool.gasTip.Load().ToBig()
+			tip = new(big.Int).SetUint64(era.PriceLimit)
+		} else {
+			tip, pool.baseTip = pool.baseTip, nil
+		}
+	}
+	queue := pool.accountQueue()
+	pool.mu.Unlock()
+
+	log.Info("Legacy pool switched era policy", "poa", poa, "accountqueue", queue)
+	if tip != nil {
+		pool.SetGasTip(tip)
+	}
+}
+
+// accountQueue returns the per-account limit of non-executable transactions of
+// the active era. The caller must hold pool.mu.
+func (pool *LegacyPool) accountQueue() uint64 {
+	if era := pool.config.PoA; pool.poa && era != nil && era.AccountQueue != 0 {
+		return era.AccountQueue
+	}
+	return pool.config.AccountQueue
+}
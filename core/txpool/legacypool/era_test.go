@@ -0,0 +1,82 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package legacypool
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the pool switches to the PoA era acceptance policy and back.
+func TestEraPolicySwitch(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	blockchain := newTestBlockChain(params.TestChainConfig, 10000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.PoA = &EraConfig{PriceLimit: 10, AccountQueue: 2}
+	pool := New(config, blockchain)
+	if err := pool.Init(config.PriceLimit, blockchain.CurrentBlock(), newReserver()); err != nil {
+		t.Fatalf("failed to init pool: %v", err)
+	}
+	defer pool.Close()
+	<-pool.initDoneCh
+
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000))
+
+	// Queue up a few future transactions under the base policy
+	for i := uint64(1); i <= 4; i++ {
+		if err := pool.addRemoteSync(pricedTransaction(i, 100000, big.NewInt(100), key)); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	// Switching to PoA raises the tip and lowers the queue limit
+	pool.SetPoA(true)
+	<-pool.requestPromoteExecutables(newAccountSet(pool.signer, account))
+
+	if have := pool.gasTip.Load().Uint64(); have != 10 {
+		t.Fatalf("PoA gas tip mismatch: have %d, want 10", have)
+	}
+	if have := pool.queue[account].Len(); have != 2 {
+		t.Fatalf("PoA queue limit mismatch: have %d, want 2", have)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(5, 100000, big.NewInt(5), key)); !errors.Is(err, txpool.ErrTxGasPriceTooLow) {
+		t.Fatalf("underpriced PoA transaction error mismatch: have %v, want %v", err, txpool.ErrTxGasPriceTooLow)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Switching back restores the base policy
+	pool.SetPoA(false)
+	if have := pool.gasTip.Load().Uint64(); have != config.PriceLimit {
+		t.Fatalf("restored gas tip mismatch: have %d, want %d", have, config.PriceLimit)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(5, 100000, big.NewInt(5), key)); err != nil {
+		t.Fatalf("failed to add transaction after switching back: %v", err)
+	}
+}
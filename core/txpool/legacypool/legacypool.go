@@ -150,6 +150,8 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	PoA *EraConfig `toml:",omitempty"` // Overrides after a PoS to PoA transition
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -253,6 +255,9 @@ type LegacyPool struct {
 	initDoneCh      chan struct{}  // is closed once the pool is initialized (for tests)
 
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
+
+	poa     bool     // Whether the PoA era acceptance policy is active
+	baseTip *big.Int // Gas tip in force before switching to the PoA era policy
 }
 
 type txpoolResetRequest struct {
@@ -1471,7 +1476,7 @@ func (pool *LegacyPool) promoteExecutables(accounts []common.Address) []*types.T
 		queuedGauge.Dec(int64(len(readies)))
 
 		// Drop all transactions over the allowed limit
-		var caps = list.Cap(int(pool.config.AccountQueue))
+		var caps = list.Cap(int(pool.accountQueue()))
 		for _, tx := range caps {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
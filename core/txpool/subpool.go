@@ -179,3 +179,12 @@ type SubPool interface {
 	// Clear removes all tracked transactions from the pool
 	Clear()
 }
+
+// EraSwitcher is an optional interface implemented by subpools whose acceptance
+// policy differs between the PoS and PoA eras of a network transitioning from
+// PoS to PoA consensus.
+type EraSwitcher interface {
+	// SetPoA switches the subpool to the acceptance policy of the PoA era, or
+	// back to the base policy, e.g. after a reorg across the transition.
+	SetPoA(poa bool)
+}
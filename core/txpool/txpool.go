@@ -113,10 +113,27 @@ func New(gasTip uint64, chain BlockChain, subpools []SubPool) (*TxPool, error) {
 			return nil, err
 		}
 	}
-	go pool.loop(head)
+	// Apply the era policy of the next block, including transitions resolved
+	// at runtime, later switches are driven by the era annotation of the chain
+	// head events.
+	poa := chain.Config().IsPoAEra(new(big.Int).Add(head.Number, common.Big1))
+	if poa {
+		pool.setPoA(poa)
+	}
+	go pool.loop(head, poa)
 	return pool, nil
 }
 
+// setPoA switches all era aware subpools to the acceptance policy of the PoA
+// era, or back to the base policy.
+func (p *TxPool) setPoA(poa bool) {
+	for _, subpool := range p.subpools {
+		if switcher, ok := subpool.(EraSwitcher); ok {
+			switcher.SetPoA(poa)
+		}
+	}
+}
+
 // Close terminates the transaction pool and all its subpools.
 func (p *TxPool) Close() error {
 	var errs []error
@@ -145,7 +162,7 @@ func (p *TxPool) Close() error {
 // loop is the transaction pool's main event loop, waiting for and reacting to
 // outside blockchain events as well as for various reporting and transaction
 // eviction events.
-func (p *TxPool) loop(head *types.Header) {
+func (p *TxPool) loop(head *types.Header, poa bool) {
 	// Close the termination marker when the pool stops
 	defer close(p.term)
 
@@ -225,6 +242,14 @@ func (p *TxPool) loop(head *types.Header) {
 			// Chain moved forward, store the head for later consumption
 			newHead = event.Header
 
+			// Switch the acceptance policy before the reset if the next block
+			// belongs to a different consensus era
+			if event.PoA != poa {
+				log.Info("Switching transaction pool policy", "poa", event.PoA, "number", event.Header.Number)
+				poa = event.PoA
+				p.setPoA(poa)
+			}
+
 		case head := <-resetDone:
 			// Previous reset finished, update the old head and allow a new reset
 			oldHead = head
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/filtermaps"
@@ -290,6 +291,51 @@ func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number ui
 	return rawdb.ReadLogs(b.eth.chainDb, hash, number), nil
 }
 
+// UsesPoA reports whether the given block is sealed by the PoA engine of a PoS
+// to PoA transition network, consulting the engine for transitions triggered at
+// runtime.
+func (b *EthAPIBackend) UsesPoA(number uint64) bool {
+	if engine, ok := b.eth.engine.(*hybrid.Hybrid); ok {
+		return engine.UsesPoA(number)
+	}
+	return b.ChainConfig().IsPoSToPoATransition(new(big.Int).SetUint64(number))
+}
+
+// TransitionBlock returns the first block sealed by the PoA engine of a PoS to
+// PoA transition network, or false if it is not known yet. The engine is
+// consulted for transitions triggered at runtime.
+func (b *EthAPIBackend) TransitionBlock() (uint64, bool) {
+	if engine, ok := b.eth.engine.(*hybrid.Hybrid); ok {
+		return engine.TransitionBlock()
+	}
+	transition := b.ChainConfig().PoSToPoATransitionBlock
+	if transition == nil || !transition.IsUint64() {
+		return 0, false
+	}
+	return transition.Uint64(), true
+}
+
+// TransitionLog returns the synthetic log recording the signer set established
+// by the PoS to PoA transition block, or nil if the header is not the transition
+// block or synthetic transition logs are disabled.
+func (b *EthAPIBackend) TransitionLog(ctx context.Context, header *types.Header) (*types.Log, error) {
+	if !b.eth.config.Hybrid.TransitionLog {
+		return nil, nil
+	}
+	if transition, ok := b.TransitionBlock(); !ok || header.Number.Uint64() != transition {
+		return nil, nil
+	}
+	logs, err := b.GetLogs(ctx, header.Hash(), header.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	var count int
+	for _, txLogs := range logs {
+		count += len(txLogs)
+	}
+	return hybrid.TransitionLog(header, uint(len(logs)), uint(count))
+}
+
 func (b *EthAPIBackend) GetEVM(ctx context.Context, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext) *vm.EVM {
 	if vmConfig == nil {
 		vmConfig = b.eth.blockchain.GetVMConfig()
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// HybridAPI provides push notifications about the PoS to PoA transition of a
+// hybrid chain in the eth namespace.
+type HybridAPI struct {
+	engine *hybrid.Hybrid
+	chain  *core.BlockChain
+}
+
+// NewHybridAPI creates a new HybridAPI instance.
+func NewHybridAPI(engine *hybrid.Hybrid, chain *core.BlockChain) *HybridAPI {
+	return &HybridAPI{engine: engine, chain: chain}
+}
+
+// TransitionStatus streams the transition progress, the current status first
+// and an update for every new head of the chain afterwards.
+func (api *HybridAPI) TransitionStatus(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		heads := make(chan core.ChainHeadEvent, 16)
+		sub := api.chain.SubscribeChainHeadEvent(heads)
+		defer sub.Unsubscribe()
+
+		notifier.Notify(rpcSub.ID, api.engine.Status(api.chain.CurrentHeader().Number.Uint64()))
+		for {
+			select {
+			case head := <-heads:
+				notifier.Notify(rpcSub.ID, api.engine.Status(head.Header.Number.Uint64()))
+			case <-rpcSub.Err():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestTransitionStatusSubscription(t *testing.T) {
+	gspec := &core.Genesis{Config: params.TestChainConfig}
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 3, nil)
+
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), gspec, ethash.NewFaker(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	engine, err := hybrid.New(ethash.NewFaker(), ethash.NewFaker(), 3,
+		hybrid.WithInitialSigners([]common.Address{{0x01}, {0x02}, {0x03}}))
+	if err != nil {
+		t.Fatalf("Failed to create hybrid engine: %v", err)
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewHybridAPI(engine, chain)); err != nil {
+		t.Fatalf("Failed to register API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	statuses := make(chan *hybrid.Status, 4)
+	sub, err := client.EthSubscribe(context.Background(), statuses, "transitionStatus")
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	next := func() *hybrid.Status {
+		t.Helper()
+		select {
+		case status := <-statuses:
+			return status
+		case err := <-sub.Err():
+			t.Fatalf("Subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Missing status notification")
+		}
+		return nil
+	}
+	// The current status is delivered right away
+	if status := next(); status.CurrentBlock != 0 || status.BlocksRemaining != 2 || status.Mode != "pos" || !status.Armed {
+		t.Fatalf("Initial status mismatch: %+v", status)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to insert chain: %v", err)
+	}
+	// Batch inserts only announce the final head
+	if status := next(); status.CurrentBlock != 3 || status.Mode != "poa" || !status.Executed || status.Armed {
+		t.Fatalf("Status after the transition mismatch: %+v", status)
+	}
+}
@@ -18,11 +18,13 @@
 package eth
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
+	"os"
 	"runtime"
 	"sync"
 	"time"
@@ -31,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/filtermaps"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -45,6 +48,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/eth/protocols/heartbeat"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -97,9 +101,10 @@ type Ethereum struct {
 	localTxTracker *locals.TxTracker
 	blockchain     *core.BlockChain
 
-	handler *handler
-	discmix *enode.FairMix
-	dropper *dropper
+	handler    *handler
+	discmix    *enode.FairMix
+	dropper    *dropper
+	heartbeats *heartbeat.Service // Signer liveness announcements (nil = not a hybrid chain)
 
 	// DB interfaces
 	chainDb ethdb.Database // Block chain database
@@ -111,6 +116,8 @@ type Ethereum struct {
 	filterMaps      *filtermaps.FilterMaps
 	closeFilterMaps chan chan struct{}
 
+	closeSealerIndex chan chan struct{} // Stops the sealer index updates (nil = not started)
+
 	APIBackend *EthAPIBackend
 
 	miner    *miner.Miner
@@ -179,7 +186,62 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	engine, err := ethconfig.CreateConsensusEngine(chainConfig, chainDb)
+	// The overridden transition block is only persisted once the chain is set
+	// up, but the consensus engine needs to switch at it already.
+	if config.OverrideTransitionBlock != nil {
+		overridden := *chainConfig
+		overridden.PoSToPoATransitionBlock = new(big.Int).SetUint64(*config.OverrideTransitionBlock)
+		chainConfig = &overridden
+	}
+	// Fresh nodes may learn a transition triggered at runtime from a signed record
+	var proof *hybrid.TransitionRecord
+	if config.Hybrid.TransitionProof != "" {
+		if proof, err = hybrid.ReadTransitionProof(config.Hybrid.TransitionProof); err != nil {
+			return nil, err
+		}
+	}
+	signers, err := config.Hybrid.LocalSigners()
+	if err != nil {
+		return nil, err
+	}
+	// Critical events of the transition are optionally posted to a webhook
+	var webhook *hybrid.Webhook
+	if config.Hybrid.Webhook != "" {
+		var secret []byte
+		if config.Hybrid.WebhookSecret != "" {
+			blob, err := os.ReadFile(config.Hybrid.WebhookSecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read alert webhook secret: %w", err)
+			}
+			secret = bytes.TrimSpace(blob)
+		} else {
+			log.Warn("Alert webhook payloads are signed without a secret", "webhook", config.Hybrid.Webhook)
+		}
+		if webhook, err = hybrid.NewWebhook(config.Hybrid.Webhook, secret); err != nil {
+			return nil, err
+		}
+	}
+	if !config.Hybrid.Metrics {
+		hybrid.UnregisterMetrics()
+	}
+	engine, err := ethconfig.CreateConsensusEngineWithTypes(chainConfig, chainDb, config.Hybrid.EngineTypes(),
+		hybrid.WithAlertWebhook(webhook),
+		hybrid.WithMissedSlotAlert(config.Hybrid.MissedSlots),
+		hybrid.WithConsistencyCheck(config.Hybrid.ConsistencyCheck),
+		hybrid.WithTransitionProof(proof),
+		hybrid.WithStrict(config.Hybrid.Strict),
+		hybrid.WithMinSigners(config.Hybrid.MinSigners),
+		hybrid.WithCountdownWindow(config.Hybrid.CountdownWindow),
+		hybrid.WithShadowWindow(config.Hybrid.ShadowWindow),
+		hybrid.WithDualVerifyWindow(config.Hybrid.DualWindow),
+		hybrid.WithForkMonitorWindow(config.Hybrid.ForkWindow),
+		hybrid.WithReorgReportWindow(config.Hybrid.ReorgWindow),
+		hybrid.WithFreezeWindow(config.Hybrid.FreezeWindow),
+		hybrid.WithLocalSigners(signers, func(signer common.Address) bool {
+			_, err := stack.AccountManager().Find(accounts.Account{Address: signer})
+			return err == nil
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -262,8 +324,20 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideVerkle != nil {
 		overrides.OverrideVerkle = config.OverrideVerkle
 	}
+	if config.OverrideTransitionBlock != nil {
+		overrides.OverrideTransitionBlock = config.OverrideTransitionBlock
+	}
 	options.Overrides = &overrides
 
+	// Restrict the transaction index to the PoA era once the engine recorded
+	// the transition as processed.
+	if config.Hybrid.TxIndexPoAOnly {
+		if engine, ok := eth.engine.(*hybrid.Hybrid); ok {
+			options.TxLookupFloor = engine.ExecutedTransition
+		} else {
+			log.Warn("PoA-only transaction indexing requires a PoS to PoA transition network")
+		}
+	}
 	eth.blockchain, err = core.NewBlockChain(chainDb, config.Genesis, eth.engine, options)
 	if err != nil {
 		return nil, err
@@ -346,10 +420,22 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
 
+	// Nodes on a hybrid chain exchange the heartbeats of PoA signers
+	if engine, ok := eth.engine.(*hybrid.Hybrid); ok {
+		eth.heartbeats = heartbeat.New(&heartbeatHandler{
+			engine:   engine,
+			chain:    eth.blockchain,
+			accounts: stack.AccountManager(),
+			signers:  signers,
+		}, hybrid.HeartbeatInterval)
+	}
+
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
+	stack.RegisterHandler("Readiness probe", "/readyz", healthHandler(eth.ready))
+	stack.RegisterHandler("Liveness probe", "/livez", healthHandler(eth.live))
 
 	// Successful startup; push a marker and check previous unclean shutdowns.
 	eth.shutdownTracker.MarkStartup()
@@ -379,6 +465,19 @@ func makeExtraData(extra []byte) []byte {
 func (s *Ethereum) APIs() []rpc.API {
 	apis := ethapi.GetAPIs(s.APIBackend)
 
+	// Append any APIs exposed explicitly by the consensus engine
+	if engine, ok := s.engine.(interface {
+		APIs(chain consensus.ChainHeaderReader) []rpc.API
+	}); ok {
+		apis = append(apis, engine.APIs(s.BlockChain())...)
+	}
+	if engine, ok := s.engine.(*hybrid.Hybrid); ok {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Service:   NewHybridAPI(engine, s.blockchain),
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -425,6 +524,9 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 	if s.config.SnapshotCache > 0 {
 		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler))...)
 	}
+	if s.heartbeats != nil {
+		protos = append(protos, s.heartbeats.Protocols()...)
+	}
 	return protos
 }
 
@@ -444,6 +546,16 @@ func (s *Ethereum) Start() error {
 	// Start the connection manager
 	s.dropper.Start(s.p2pServer, func() bool { return !s.Synced() })
 
+	if s.heartbeats != nil {
+		s.heartbeats.Start()
+	}
+	if engine, ok := s.engine.(*hybrid.Hybrid); ok {
+		engine.StartConsistencyCheck(s.blockchain)
+
+		s.closeSealerIndex = make(chan chan struct{})
+		go s.updateSealerIndex(engine)
+	}
+
 	// start log indexer
 	s.filterMaps.Start()
 	go s.updateFilterMapsHeads()
@@ -508,6 +620,46 @@ func (s *Ethereum) updateFilterMapsHeads() {
 	}
 }
 
+// updateSealerIndex extends the sealer index of the hybrid engine whenever the
+// chain head changes, catching up with the existing chain first. Updates run
+// in the background, so a long catch-up never holds up the head events.
+func (s *Ethereum) updateSealerIndex(engine *hybrid.Hybrid) {
+	headCh := make(chan core.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	var (
+		wakeCh = make(chan struct{}, 1)
+		doneCh = make(chan struct{})
+	)
+	go func() {
+		defer close(doneCh)
+		for range wakeCh {
+			if err := engine.IndexSealers(s.blockchain); err != nil {
+				log.Warn("Failed to update sealer index", "err", err)
+			}
+		}
+	}()
+	wake := func() {
+		select {
+		case wakeCh <- struct{}{}:
+		default:
+		}
+	}
+	wake()
+	for {
+		select {
+		case <-headCh:
+			wake()
+		case ch := <-s.closeSealerIndex:
+			close(wakeCh)
+			<-doneCh
+			close(ch)
+			return
+		}
+	}
+}
+
 func (s *Ethereum) setupDiscovery() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
@@ -564,11 +716,19 @@ func (s *Ethereum) Stop() error {
 	s.discmix.Close()
 	s.dropper.Stop()
 	s.handler.Stop()
+	if s.heartbeats != nil {
+		s.heartbeats.Stop()
+	}
 
 	// Then stop everything else.
 	ch := make(chan struct{})
 	s.closeFilterMaps <- ch
 	<-ch
+	if s.closeSealerIndex != nil {
+		ch := make(chan struct{})
+		s.closeSealerIndex <- ch
+		<-ch
+	}
 	s.filterMaps.Stop()
 	s.txPool.Close()
 	s.blockchain.Stop()
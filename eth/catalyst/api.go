@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
@@ -236,6 +237,10 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 	// Stash away the last update to warn the user if the beacon client goes offline
 	api.lastForkchoiceUpdate.Store(time.Now().Unix())
 
+	// Once the chain switched to PoA, the beacon client has no say over it
+	if err := api.checkRetired(0); err != nil {
+		return engine.STATUS_INVALID, err
+	}
 	// Check whether we have the block yet in our database or not. If not, we'll
 	// need to either trigger a sync, or to reject this forkchoice update for a
 	// reason.
@@ -260,6 +265,9 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 			api.remoteBlocks.put(retrievedHead.Hash(), retrievedHead)
 			header = retrievedHead
 		}
+		if err := api.checkRetired(header.Number.Uint64()); err != nil {
+			return engine.STATUS_INVALID, err
+		}
 		// If the finalized hash is known, we can direct the downloader to move
 		// potentially more data to the freezer from the get go.
 		finalized := api.remoteBlocks.get(update.FinalizedBlockHash)
@@ -279,8 +287,13 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 		}
 		return engine.STATUS_SYNCING, nil
 	}
-	// Block is known locally, just sanity check that the beacon client does not
-	// attempt to push us back to before the merge.
+	// Block is known locally, make sure it's not a PoA block the beacon client
+	// picked up from the network
+	if err := api.checkRetired(block.NumberU64()); err != nil {
+		return engine.STATUS_INVALID, err
+	}
+	// Sanity check that the beacon client does not attempt to push us back to
+	// before the merge.
 	if block.Difficulty().BitLen() > 0 && block.NumberU64() > 0 {
 		ph := api.eth.BlockChain().GetHeader(block.ParentHash(), block.NumberU64()-1)
 		if ph == nil {
@@ -350,6 +363,9 @@ func (api *ConsensusAPI) forkchoiceUpdated(update engine.ForkchoiceStateV1, payl
 	// sealed by the beacon client. The payload will be requested later, and we
 	// will replace it arbitrarily many times in between.
 	if payloadAttributes != nil {
+		if err := api.checkRetired(block.NumberU64() + 1); err != nil {
+			return valid(nil), err
+		}
 		args := &miner.BuildPayloadArgs{
 			Parent:       update.HeadBlockHash,
 			Timestamp:    payloadAttributes.Timestamp,
@@ -454,6 +470,9 @@ func (api *ConsensusAPI) getPayload(payloadID engine.PayloadID, full bool) (*eng
 	if data == nil {
 		return nil, engine.UnknownPayload
 	}
+	if err := api.checkRetired(data.ExecutionPayload.Number); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
@@ -661,6 +680,9 @@ func (api *ConsensusAPI) newPayload(params engine.ExecutableData, versionedHashe
 	defer api.newPayloadLock.Unlock()
 
 	log.Trace("Engine API request received", "method", "NewPayload", "number", params.Number, "hash", params.BlockHash)
+	if err := api.checkRetired(params.Number); err != nil {
+		return invalidStatus, err
+	}
 	block, err := engine.ExecutableDataToBlock(params, versionedHashes, beaconRoot, requests)
 	if err != nil {
 		bgu := "nil"
@@ -1085,6 +1107,24 @@ func attributesErr(msg string) error {
 	return engine.InvalidPayloadAttributes.With(errors.New(msg))
 }
 
+// checkRetired returns a ConsensusRetired Engine API error if the chain switched
+// from PoS to PoA consensus, or if the block with the given number is past the
+// switch. Chains that never switch are unaffected.
+func (api *ConsensusAPI) checkRetired(number uint64) error {
+	transitioner, ok := api.eth.Engine().(consensus.Transitioner)
+	if !ok {
+		return nil
+	}
+	head := api.eth.BlockChain().CurrentBlock().Number.Uint64()
+	switch {
+	case transitioner.UsesPoA(head):
+		return engine.ConsensusRetired.With(fmt.Errorf("chain head %d is under PoA consensus", head))
+	case transitioner.UsesPoA(number):
+		return engine.ConsensusRetired.With(fmt.Errorf("block %d is under PoA consensus", number))
+	}
+	return nil
+}
+
 // unsupportedForkErr is a helper function for creating an UnsupportedFork
 // Engine API error.
 func unsupportedForkErr(msg string) error {
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
@@ -41,6 +42,10 @@ import (
 
 const devEpochLength = 32
 
+// errBeaconAbandoned is returned when a block is requested from a simulated
+// beacon that abandoned the chain.
+var errBeaconAbandoned = errors.New("simulated beacon abandoned the chain")
+
 // withdrawalQueue implements a FIFO queue which holds withdrawals that are
 // pending inclusion.
 type withdrawalQueue struct {
@@ -96,6 +101,10 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	abandonAt     atomic.Uint64 // Head block after which no more blocks are produced (0 = never)
+	abandoned     chan struct{} // Closed once the beacon went silent
+	abandonedOnce sync.Once
 }
 
 func payloadVersion(config *params.ChainConfig, time uint64) engine.PayloadVersion {
@@ -137,9 +146,24 @@ func NewSimulatedBeacon(period uint64, feeRecipient common.Address, eth *eth.Eth
 		lastBlockTime:      block.Time,
 		curForkchoiceState: current,
 		feeRecipient:       feeRecipient,
+		abandoned:          make(chan struct{}),
 	}, nil
 }
 
+// AbandonAt makes the simulated beacon go silent once the head reaches the given
+// block, never producing or announcing another block through the engine API. It
+// leaves the execution layer alone as if the beacon chain had died, which is the
+// event a PoS to PoA transition network falls back to its signers for. Zero
+// keeps producing blocks.
+func (c *SimulatedBeacon) AbandonAt(number uint64) {
+	c.abandonAt.Store(number)
+}
+
+// Abandoned returns a channel closed once the simulated beacon went silent.
+func (c *SimulatedBeacon) Abandoned() <-chan struct{} {
+	return c.abandoned
+}
+
 func (c *SimulatedBeacon) setFeeRecipient(feeRecipient common.Address) {
 	c.feeRecipientLock.Lock()
 	c.feeRecipient = feeRecipient
@@ -167,6 +191,13 @@ func (c *SimulatedBeacon) Stop() error {
 // sealBlock initiates payload building for a new block and creates a new block
 // with the completed payload.
 func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp uint64) error {
+	if at := c.abandonAt.Load(); at != 0 && c.eth.BlockChain().CurrentBlock().Number.Uint64() >= at {
+		c.abandonedOnce.Do(func() {
+			log.Warn("Simulated beacon abandoned the chain", "number", at)
+			close(c.abandoned)
+		})
+		return errBeaconAbandoned
+	}
 	if timestamp <= c.lastBlockTime {
 		timestamp = c.lastBlockTime + 1
 	}
@@ -278,7 +309,9 @@ func (c *SimulatedBeacon) loop() {
 		case <-c.shutdownCh:
 			return
 		case <-timer.C:
-			if err := c.sealBlock(c.withdrawals.pop(10), uint64(time.Now().Unix())); err != nil {
+			if err := c.sealBlock(c.withdrawals.pop(10), uint64(time.Now().Unix())); errors.Is(err, errBeaconAbandoned) {
+				return
+			} else if err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			} else {
 				timer.Reset(time.Second * time.Duration(c.period))
@@ -315,7 +348,7 @@ func (c *SimulatedBeacon) setCurrentState(headHash, finalizedHash common.Hash) {
 // Commit seals a block on demand.
 func (c *SimulatedBeacon) Commit() common.Hash {
 	withdrawals := c.withdrawals.pop(10)
-	if err := c.sealBlock(withdrawals, uint64(time.Now().Unix())); err != nil {
+	if err := c.sealBlock(withdrawals, uint64(time.Now().Unix())); err != nil && !errors.Is(err, errBeaconAbandoned) {
 		log.Warn("Error performing sealing work", "err", err)
 	}
 	return c.eth.BlockChain().CurrentBlock().Hash()
@@ -18,12 +18,17 @@ package catalyst
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -208,3 +213,106 @@ func TestOnDemandSpam(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a simulated beacon driving a PoS to PoA transition network goes
+// silent at the chosen height and that the signers take over from there.
+func TestSimulatedBeaconAbandonsHybridChain(t *testing.T) {
+	var (
+		signerKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		signerAddr   = crypto.PubkeyToAddress(signerKey.PublicKey)
+
+		transition = uint64(8)
+		config     = *params.AllCliqueProtocolChanges
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: transition}
+	config.PoSToPoATransitionBlock = new(big.Int).SetUint64(transition)
+	config.PoAInitialSigners = []common.Address{signerAddr}
+
+	genesis := &core.Genesis{
+		Config:     &config,
+		Timestamp:  uint64(time.Now().Add(-time.Hour).Unix()),
+		GasLimit:   params.GenesisGasLimit,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+		Alloc:      types.GenesisAlloc{signerAddr: {Balance: big.NewInt(params.Ether)}},
+	}
+	node, ethService, mock := startSimulatedBeaconEthService(t, genesis, 0)
+	defer node.Close()
+
+	hybridEngine, ok := ethService.Engine().(*hybrid.Hybrid)
+	if !ok {
+		t.Fatalf("node runs %T instead of the hybrid engine", ethService.Engine())
+	}
+	// Drive the chain up to the last PoS block and keep asking for more. Blocks
+	// are a second apart from the genesis, so they don't end up in the future.
+	mock.AbandonAt(transition - 1)
+	for i := uint64(1); i < transition+2; i++ {
+		err := mock.AdjustTime(time.Second)
+		if i < transition && err != nil {
+			t.Fatalf("block %d: failed to produce: %v", i, err)
+		}
+		if i >= transition && !errors.Is(err, errBeaconAbandoned) {
+			t.Fatalf("block %d: unexpected error: have %v, want %v", i, err, errBeaconAbandoned)
+		}
+	}
+	select {
+	case <-mock.Abandoned():
+	default:
+		t.Fatal("simulated beacon did not go silent")
+	}
+	head := ethService.BlockChain().CurrentBlock()
+	if head.Number.Uint64() != transition-1 {
+		t.Fatalf("head mismatch: have %d, want %d", head.Number, transition-1)
+	}
+	if status := hybridEngine.Status(head.Number.Uint64()); status.Mode != "poa" || status.Executed {
+		t.Fatalf("unexpected transition status: mode %s, executed %v", status.Mode, status.Executed)
+	}
+	// A beacon client still around may no longer build the transition block
+	fcState := engine.ForkchoiceStateV1{HeadBlockHash: head.Hash(), SafeBlockHash: head.Hash(), FinalizedBlockHash: head.Hash()}
+	attributes := &engine.PayloadAttributes{Timestamp: head.Time + 1}
+	if _, err := mock.engineAPI.ForkchoiceUpdatedV2(fcState, attributes); !isConsensusRetired(err) {
+		t.Fatalf("building the transition block: have error %v, want %v", err, engine.ConsensusRetired)
+	}
+	// The signer takes over with the transition block
+	header := &types.Header{
+		ParentHash:  head.Hash(),
+		UncleHash:   types.EmptyUncleHash,
+		Root:        head.Root,
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  big.NewInt(2),
+		Number:      new(big.Int).SetUint64(transition),
+		GasLimit:    head.GasLimit,
+		Time:        head.Time + 1,
+		Extra:       hybrid.CheckpointExtra(config.PoAInitialSigners),
+		BaseFee:     eip1559.CalcBaseFee(&config, head),
+	}
+	sig, err := crypto.Sign(clique.SealHash(header).Bytes(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+
+	if _, err := ethService.BlockChain().InsertChain(types.Blocks{types.NewBlockWithHeader(header)}); err != nil {
+		t.Fatalf("failed to import transition block: %v", err)
+	}
+	if status := hybridEngine.Status(ethService.BlockChain().CurrentBlock().Number.Uint64()); !status.Executed {
+		t.Fatal("transition not executed after the signers took over")
+	}
+	// From then on every engine API call is refused
+	if _, err := mock.engineAPI.ForkchoiceUpdatedV2(fcState, nil); !isConsensusRetired(err) {
+		t.Fatalf("forkchoice update past the transition: have error %v, want %v", err, engine.ConsensusRetired)
+	}
+	payload := engine.ExecutableData{Number: transition + 1, ParentHash: header.Hash(), BaseFeePerGas: header.BaseFee}
+	if _, err := mock.engineAPI.NewPayloadV2(payload); !isConsensusRetired(err) {
+		t.Fatalf("new payload past the transition: have error %v, want %v", err, engine.ConsensusRetired)
+	}
+}
+
+// isConsensusRetired reports whether err is the engine API error signalling
+// that the chain switched to PoA consensus.
+func isConsensusRetired(err error) bool {
+	var apiErr *engine.EngineAPIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == engine.ConsensusRetired.ErrorCode()
+}
@@ -240,6 +240,7 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, dropPeer
 	}
 	// Create the post-merge skeleton syncer and start the process
 	dl.skeleton = newSkeleton(stateDb, dl.peers, dropPeer, newBeaconBackfiller(dl, success))
+	dl.skeleton.era = newEraScorer(chain)
 
 	go dl.stateFetcher()
 	return dl
@@ -324,6 +325,7 @@ func (d *Downloader) UnregisterPeer(id string) error {
 		return err
 	}
 	d.queue.Revoke(id)
+	d.skeleton.era.forget(id)
 
 	return nil
 }
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// eraStrikeLimit is the number of header batches violating the era rules a
+// peer may deliver before it is dropped.
+const eraStrikeLimit = 3
+
+// eraChecker is implemented by consensus engines with era rules that can be
+// checked without chain context, such as the hybrid PoS to PoA engine.
+type eraChecker interface {
+	CheckEra(header *types.Header) error
+}
+
+// eraScorer tracks the peers serving headers that violate the local era rules,
+// dropping the ones doing so repeatedly.
+type eraScorer struct {
+	checker eraChecker
+	strikes map[string]int // Era violations per peer since it connected
+	lock    sync.Mutex
+}
+
+// newEraScorer creates an era scorer if the consensus engine of the chain has
+// era rules, or returns nil otherwise.
+func newEraScorer(chain BlockChain) *eraScorer {
+	engined, ok := chain.(interface{ Engine() consensus.Engine })
+	if !ok {
+		return nil
+	}
+	checker, ok := engined.Engine().(eraChecker)
+	if !ok {
+		return nil
+	}
+	return &eraScorer{checker: checker, strikes: make(map[string]int)}
+}
+
+// score checks the headers delivered by a peer, returning whether the peer has
+// exhausted its strikes and should be dropped, and the first violation found.
+func (s *eraScorer) score(peer string, headers []*types.Header) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	for _, header := range headers {
+		err := s.checker.CheckEra(header)
+		if err == nil {
+			continue
+		}
+		reason := eraReason(err)
+		metrics.GetOrRegisterMeter("eth/downloader/era/violation/"+reason, nil).Mark(1)
+
+		s.lock.Lock()
+		s.strikes[peer]++
+		strikes := s.strikes[peer]
+		if strikes >= eraStrikeLimit {
+			delete(s.strikes, peer)
+		}
+		s.lock.Unlock()
+
+		if strikes < eraStrikeLimit {
+			log.Debug("Peer delivered headers violating era rules", "peer", peer, "strikes", strikes, "err", err)
+			return false, err
+		}
+		log.Warn("Dropping peer repeatedly violating era rules", "peer", peer, "reason", reason, "err", err)
+		metrics.GetOrRegisterMeter("eth/downloader/era/drop/"+reason, nil).Mark(1)
+		return true, err
+	}
+	return false, nil
+}
+
+// forget discards the strikes of a disconnected peer.
+func (s *eraScorer) forget(peer string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	delete(s.strikes, peer)
+	s.lock.Unlock()
+}
+
+// eraReason returns the metric label of an era violation.
+func eraReason(err error) string {
+	var reasoned interface{ Reason() string }
+	if errors.As(err, &reasoned) {
+		return reasoned.Reason()
+	}
+	return "other"
+}
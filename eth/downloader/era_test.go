@@ -0,0 +1,86 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// eraError is an era violation naming the violated rule.
+type eraError string
+
+func (e eraError) Error() string  { return "era violation: " + string(e) }
+func (e eraError) Reason() string { return string(e) }
+
+// eraTestChecker rejects all headers from the given number on.
+type eraTestChecker uint64
+
+func (c eraTestChecker) CheckEra(header *types.Header) error {
+	if header.Number.Uint64() >= uint64(c) {
+		return eraError("test-rule")
+	}
+	return nil
+}
+
+// Tests that peers serving headers violating the era rules are only dropped
+// after exhausting their strikes, and that disconnecting resets them.
+func TestEraScorer(t *testing.T) {
+	metrics.Enable()
+	scorer := &eraScorer{checker: eraTestChecker(10), strikes: make(map[string]int)}
+	drops := metrics.GetOrRegisterMeter("eth/downloader/era/drop/test-rule", nil)
+	dropped := drops.Snapshot().Count()
+
+	var (
+		valid   = []*types.Header{{Number: big.NewInt(8)}, {Number: big.NewInt(9)}}
+		invalid = []*types.Header{{Number: big.NewInt(9)}, {Number: big.NewInt(10)}}
+	)
+	if drop, err := scorer.score("good", valid); drop || err != nil {
+		t.Fatalf("valid headers penalized: drop %v, err %v", drop, err)
+	}
+	for i := 1; i < eraStrikeLimit; i++ {
+		if drop, err := scorer.score("bad", invalid); drop || err == nil {
+			t.Fatalf("strike %d: drop %v, err %v", i, drop, err)
+		}
+	}
+	// A reconnected peer starts over
+	scorer.forget("bad")
+	for i := 1; i < eraStrikeLimit; i++ {
+		if drop, _ := scorer.score("bad", invalid); drop {
+			t.Fatalf("strike %d after reconnect: peer dropped", i)
+		}
+	}
+	drop, err := scorer.score("bad", invalid)
+	if !drop || !errors.Is(err, eraError("test-rule")) {
+		t.Fatalf("last strike: drop %v, err %v", drop, err)
+	}
+	if have := drops.Snapshot().Count() - dropped; have != 1 {
+		t.Errorf("dropped peer count mismatch: have %d, want 1", have)
+	}
+	if len(scorer.strikes) != 0 {
+		t.Errorf("strikes of dropped peer retained: %v", scorer.strikes)
+	}
+	// Engines without era rules disable scoring
+	var disabled *eraScorer
+	if drop, err := disabled.score("bad", invalid); drop || err != nil {
+		t.Errorf("disabled scorer penalized: drop %v, err %v", drop, err)
+	}
+}
@@ -205,6 +205,7 @@ type skeleton struct {
 	peers *peerSet                   // Set of peers we can sync from
 	idles map[string]*peerConnection // Set of idle peers in the current sync cycle
 	drop  peerDropFn                 // Drops a peer for misbehaving
+	era   *eraScorer                 // Drops peers violating the era rules (nil = no era rules)
 
 	progress *skeletonProgress // Sync progress tracker for resumption and metrics
 	started  time.Time         // Timestamp when the skeleton syncer was created
@@ -839,6 +840,16 @@ func (s *skeleton) executeTask(peer *peerConnection, req *headerRequest) {
 					return
 				}
 			}
+			// Reject headers styled after the wrong consensus era, dropping
+			// peers that keep serving them
+			if drop, err := s.era.score(peer.id, headers); err != nil {
+				res.Done <- err
+				s.scheduleRevertRequest(req)
+				if drop {
+					s.drop(peer.id)
+				}
+				return
+			}
 			// Hash chain is valid. The delivery might still be junk as we're
 			// downloading batches concurrently (so no way to link the headers
 			// until gaps are filled); in that case, we'll nuke the peer when
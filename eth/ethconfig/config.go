@@ -70,6 +70,7 @@ var Defaults = Config{
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
+	Hybrid:             DefaultHybridConfig,
 }
 
 //go:generate go run github.com/fjl/gencodec -type Config -formats toml -out gen_config.go
@@ -159,17 +160,33 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// Hybrid contains the settings of the hybrid engine of PoS to PoA
+	// transition networks.
+	Hybrid HybridConfig
+
 	// OverrideOsaka (TODO: remove after the fork)
 	OverrideOsaka *uint64 `toml:",omitempty"`
 
 	// OverrideVerkle (TODO: remove after the fork)
 	OverrideVerkle *uint64 `toml:",omitempty"`
+
+	// OverrideTransitionBlock moves the PoS to PoA transition block configured
+	// in the genesis, as long as the chain has not reached it yet.
+	OverrideTransitionBlock *uint64 `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
 // Clique is allowed for now to live standalone, but ethash is forbidden and can
-// only exist on already merged networks.
-func CreateConsensusEngine(config *params.ChainConfig, db ethdb.Database) (consensus.Engine, error) {
+// only exist on already merged networks. The optional hybrid options are only
+// applied if the config schedules a PoS to PoA transition, which is backed by
+// clique in both eras.
+func CreateConsensusEngine(config *params.ChainConfig, db ethdb.Database, opts ...hybrid.Option) (consensus.Engine, error) {
+	return CreateConsensusEngineWithTypes(config, db, hybrid.DefaultEngineTypes, opts...)
+}
+
+// CreateConsensusEngineWithTypes is like CreateConsensusEngine, backing the two
+// eras of a PoS to PoA transition network with the given engine types.
+func CreateConsensusEngineWithTypes(config *params.ChainConfig, db ethdb.Database, types hybrid.EngineTypes, opts ...hybrid.Option) (consensus.Engine, error) {
 	if config.TerminalTotalDifficulty == nil {
 		log.Error("Geth only supports PoS networks. Please transition legacy networks using Geth v1.13.x.")
 		return nil, errors.New("'terminalTotalDifficulty' is not set in genesis block")
@@ -178,8 +195,13 @@ func CreateConsensusEngine(config *params.ChainConfig, db ethdb.Database) (conse
 	// Wrap previously supported consensus engines into their post-merge counterpart
 	if config.Clique != nil {
 		// Check if PoS to PoA transition is configured
-		if config.PoSToPoATransitionBlock != nil {
-			transitionBlock := config.PoSToPoATransitionBlock.Uint64()
+		if config.HasPoSToPoATransition() {
+			// The transition block is nil if it is triggered by the terminal PoS block
+			transitionBlock := config.PoSToPoATransitionBlock
+			trigger := "block number"
+			if config.TerminalPoSBlockHash != nil {
+				trigger = "terminal block " + config.TerminalPoSBlockHash.Hex()
+			}
 
 			// Log startup configuration including transition parameters (Requirement 4.4)
 			log.Info("Configuring PoS to PoA consensus transition",
@@ -195,22 +217,13 @@ func CreateConsensusEngine(config *params.ChainConfig, db ethdb.Database) (conse
 				"currentConsensus", "PoS",
 				"futureConsensus", "PoA")
 
-			// Create hybrid engine that transitions from PoS to PoA at the specified block
+			// Create hybrid engine that transitions from PoS to PoA at the specified
+			// block, building both underlying engines from the chain config
 			log.Debug("Creating underlying consensus engines",
-				"posEngineType", "beacon+clique",
-				"poaEngineType", "clique")
-
-			posEngine := beacon.New(clique.New(config.Clique, db))
-			poaEngine := clique.New(config.Clique, db)
-
-			log.Info("Creating hybrid consensus engine with PoS to PoA transition",
-				"transitionBlock", transitionBlock,
-				"posEngine", "beacon+clique",
-				"poaEngine", "clique",
-				"cliquePeriod", config.Clique.Period,
-				"cliqueEpoch", config.Clique.Epoch)
+				"posEngineType", "beacon+"+types.PoS,
+				"poaEngineType", types.PoA)
 
-			engine, err := hybrid.New(posEngine, poaEngine, transitionBlock)
+			engine, err := hybrid.NewFromEngineTypes(config, db, types, opts...)
 			if err != nil {
 				// Log detailed error information for transition-related failures (Requirement 4.3)
 				log.Error("Failed to create hybrid consensus engine",
@@ -228,9 +241,9 @@ func CreateConsensusEngine(config *params.ChainConfig, db ethdb.Database) (conse
 
 			// Log operational information
 			log.Info("Hybrid consensus engine operational parameters",
-				"beforeTransition", "PoS (beacon+clique)",
-				"afterTransition", "PoA (clique)",
-				"transitionTrigger", "block number",
+				"beforeTransition", "PoS (beacon+"+types.PoS+")",
+				"afterTransition", "PoA ("+types.PoA+")",
+				"transitionTrigger", trigger,
 				"monitoringEnabled", true)
 
 			return engine, nil
@@ -17,13 +17,21 @@
 package ethconfig
 
 import (
+	"errors"
 	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/naoina/toml"
 )
 
 func TestCreateConsensusEngine(t *testing.T) {
@@ -326,3 +334,109 @@ func TestCreateConsensusEngineValidationIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateConsensusEngineStrict(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+
+	config := &params.ChainConfig{
+		ChainID:                 big.NewInt(1337),
+		TerminalTotalDifficulty: big.NewInt(0),
+		PoSToPoATransitionBlock: big.NewInt(1000),
+		Clique: &params.CliqueConfig{
+			Period: 15,
+			Epoch:  30000,
+		},
+	}
+	// Built-in placeholder signers must be rejected in strict mode
+	if _, err := CreateConsensusEngine(config, db, hybrid.WithStrict(true)); !errors.Is(err, hybrid.ErrPlaceholderSigners) {
+		t.Fatalf("Expected ErrPlaceholderSigners, got %v", err)
+	}
+	// Signers from the chain config replace the placeholders
+	config.PoAInitialSigners = []common.Address{
+		common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		common.HexToAddress("0x00000000000000000000000000000000000000a2"),
+		common.HexToAddress("0x00000000000000000000000000000000000000a3"),
+	}
+	if _, err := CreateConsensusEngine(config, db, hybrid.WithStrict(true)); err != nil {
+		t.Fatalf("Expected no error with configured signers, got %v", err)
+	}
+	if _, err := CreateConsensusEngine(config, db, hybrid.WithStrict(true), hybrid.WithMinSigners(4)); !errors.Is(err, hybrid.ErrTooFewSigners) {
+		t.Fatalf("Expected ErrTooFewSigners, got %v", err)
+	}
+}
+
+func TestHybridConfigLocalSigners(t *testing.T) {
+	var (
+		a = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		b = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	file := filepath.Join(t.TempDir(), "signers")
+	if err := os.WriteFile(file, []byte("# PoA signers\n"+a.Hex()+"\n\n  "+b.Hex()+"  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := HybridConfig{Signers: []common.Address{a}, SignerFile: file}
+	signers, err := config.LocalSigners()
+	if err != nil {
+		t.Fatalf("Failed to load signers: %v", err)
+	}
+	if want := []common.Address{a, b}; !slices.Equal(signers, want) {
+		t.Errorf("Signers mismatch: have %v, want %v", signers, want)
+	}
+	if len(config.Signers) != 1 {
+		t.Errorf("Configured signers modified: %v", config.Signers)
+	}
+	if err := os.WriteFile(file, []byte(a.Hex()+"\nnot-an-address\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.LocalSigners(); err == nil || !strings.Contains(err.Error(), ":2") {
+		t.Errorf("Expected error pointing at line 2, got %v", err)
+	}
+	config.SignerFile = filepath.Join(t.TempDir(), "missing")
+	if _, err := config.LocalSigners(); err == nil {
+		t.Error("Expected error for missing signer file")
+	}
+}
+
+func TestHybridConfigTOML(t *testing.T) {
+	config := Defaults
+	config.Hybrid.Strict = false
+	config.Hybrid.CountdownWindow = 32
+	config.Hybrid.Webhook = "https://alerts.example.com/hybrid"
+	config.Hybrid.Signers = []common.Address{common.HexToAddress("0x1000000000000000000000000000000000000001")}
+
+	settings := toml.Config{
+		NormFieldName: func(rt reflect.Type, key string) string { return key },
+		FieldToKey:    func(rt reflect.Type, field string) string { return field },
+	}
+	blob, err := settings.Marshal(&config)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	var decoded Config
+	if err := settings.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal config: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Hybrid, config.Hybrid) {
+		t.Errorf("Hybrid config mismatch: have %+v, want %+v", decoded.Hybrid, config.Hybrid)
+	}
+}
+
+func TestHybridConfigDiff(t *testing.T) {
+	base := DefaultHybridConfig
+	if changes := base.Diff(&DefaultHybridConfig); len(changes) != 0 {
+		t.Fatalf("Unexpected changes of identical configs: %v", changes)
+	}
+	config := DefaultHybridConfig
+	config.Strict = false
+	config.ForkWindow = 0
+	config.Signers = []common.Address{{1}}
+
+	want := []HybridChange{
+		{Setting: "Strict", Value: false, Previous: true},
+		{Setting: "Signers", Value: []common.Address{{1}}, Previous: []common.Address(nil)},
+		{Setting: "ForkWindow", Value: uint64(0), Previous: uint64(64)},
+	}
+	if changes := config.Diff(&base); !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes mismatch: have %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+)
+
+// DefaultHybridConfig contains the default settings of the hybrid engine of a
+// PoS to PoA transition network.
+var DefaultHybridConfig = HybridConfig{
+	Strict:          true,
+	MinSigners:      hybrid.DefaultMinSigners,
+	CountdownWindow: 128,
+	ShadowWindow:    256,
+	ForkWindow:      64,
+	ReorgWindow:     128,
+	FreezeWindow:    hybrid.DefaultFreezeWindow,
+	MissedSlots:     hybrid.DefaultMissedSlotAlert,
+	Metrics:         true,
+	PoSEngine:       hybrid.DefaultEngineTypes.PoS,
+	PoAEngine:       hybrid.DefaultEngineTypes.PoA,
+}
+
+// HybridConfig contains the operational settings of the hybrid engine of a PoS
+// to PoA transition network. They are ignored on any other network.
+type HybridConfig struct {
+	// Strict makes the node refuse to start if the initial signer set contains
+	// placeholder addresses or too few signers, or if its consensus-critical
+	// settings changed since the previous run.
+	Strict bool
+
+	// MinSigners is the minimum number of initial PoA signers required when
+	// Strict is enabled.
+	MinSigners int
+
+	// Signers are the accounts this node seals PoA blocks with after the
+	// transition. Keys of initial signers must be available from the keystore
+	// or an external signer.
+	Signers []common.Address `toml:",omitempty"`
+
+	// SignerFile is the path of a file listing further signer accounts, one
+	// address per line. Empty lines and lines starting with # are ignored.
+	SignerFile string `toml:",omitempty"`
+
+	// CountdownWindow is the number of blocks before the transition for which
+	// the remaining number of blocks is logged. Zero disables the countdown.
+	CountdownWindow uint64
+
+	// ShadowWindow is the number of blocks before the transition in which PoS
+	// blocks are additionally run through the PoA checks, reporting issues
+	// without rejecting anything. Zero disables the rehearsal.
+	ShadowWindow uint64
+
+	// DualWindow is the number of blocks on either side of the transition which
+	// are verified by both engines to detect routing bugs. Zero disables the
+	// dual verification.
+	DualWindow uint64
+
+	// ForkWindow is the number of blocks on either side of the transition in
+	// which all observed branches are tracked, to spot a network partitioning
+	// over the transition. Zero disables the fork monitor.
+	ForkWindow uint64
+
+	// ReorgWindow is the number of blocks on either side of the transition reorgs
+	// touching are reported in detail. Zero disables the reports.
+	ReorgWindow uint64
+
+	// FreezeWindow is the number of blocks before the transition which are kept
+	// out of the ancient store, along with all later ones, until the transition
+	// is final. Zero disables the hold.
+	FreezeWindow uint64
+
+	// MissedSlots is the number of consecutive in-turn slots a signer may miss
+	// before an alert is raised.
+	MissedSlots uint64
+
+	// ConsistencyCheck is the interval at which a random historical block,
+	// preferably one around the transition, is verified again to detect
+	// database corruption early. Zero disables the checker.
+	ConsistencyCheck time.Duration `toml:",omitempty"`
+
+	// Metrics enables reporting the metrics of the hybrid engine.
+	Metrics bool
+
+	// TransitionLog makes the RPC API report a synthetic log in the transition
+	// block, recording the initial signer set so indexers can detect the
+	// cutover. The log is not part of the consensus data of the block.
+	TransitionLog bool `toml:",omitempty"`
+
+	// TxIndexPoAOnly restricts the transaction index to the PoA era, bounding
+	// the disk usage of fallback validators. The boundary is taken from the
+	// transition record of the engine, so nothing is unindexed before the
+	// transition block was processed. The TransactionHistory limit still
+	// applies on top.
+	TxIndexPoAOnly bool `toml:",omitempty"`
+
+	// Webhook is the HTTPS endpoint critical events of the transition and the
+	// PoA network are posted to, signed with the secret read from the
+	// WebhookSecret file. Empty disables the webhook.
+	Webhook       string `toml:",omitempty"`
+	WebhookSecret string `toml:",omitempty"`
+
+	// TransitionProof is the path of a JSON file holding the transition record
+	// co-signed by the initial signers, as served by hybrid_transitionRecord.
+	// Fresh nodes verify it against the configured signers and switch to PoA
+	// at the attested block, without having witnessed the transition trigger.
+	TransitionProof string `toml:",omitempty"`
+
+	// PoSEngine and PoAEngine select the concrete engines backing the two eras
+	// of the network. Both default to clique.
+	PoSEngine hybrid.EngineType `toml:",omitempty"`
+	PoAEngine hybrid.EngineType `toml:",omitempty"`
+}
+
+// HybridChange is a hybrid setting whose value differs from the one of a source
+// of lower precedence.
+type HybridChange struct {
+	Setting  string // Name of the setting, as used in the config file
+	Value    any    // Value in effect
+	Previous any    // Value of the lower precedence source
+}
+
+// Diff returns the settings whose values differ from the ones in base, in the
+// order they are declared in. Hybrid settings are resolved with the precedence
+// CLI flags > config file > built-in defaults, diffing the adjacent levels
+// tells which source each effective value comes from.
+func (c *HybridConfig) Diff(base *HybridConfig) []HybridChange {
+	var (
+		have    = reflect.ValueOf(c).Elem()
+		want    = reflect.ValueOf(base).Elem()
+		changes []HybridChange
+	)
+	for i := 0; i < have.NumField(); i++ {
+		value, previous := have.Field(i).Interface(), want.Field(i).Interface()
+		if !reflect.DeepEqual(value, previous) {
+			changes = append(changes, HybridChange{Setting: have.Type().Field(i).Name, Value: value, Previous: previous})
+		}
+	}
+	return changes
+}
+
+// EngineTypes returns the engines selected to back the two eras.
+func (c *HybridConfig) EngineTypes() hybrid.EngineTypes {
+	return hybrid.EngineTypes{PoS: c.PoSEngine, PoA: c.PoAEngine}
+}
+
+// LocalSigners returns the configured signer accounts, extended by the ones
+// listed in the signer file, if any.
+func (c *HybridConfig) LocalSigners() ([]common.Address, error) {
+	signers := slices.Clone(c.Signers)
+	if c.SignerFile == "" {
+		return signers, nil
+	}
+	file, err := os.Open(c.SignerFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signer file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		account := strings.TrimSpace(scanner.Text())
+		if account == "" || strings.HasPrefix(account, "#") {
+			continue
+		}
+		if !common.IsHexAddress(account) {
+			return nil, fmt.Errorf("invalid signer address %q in %s:%d", account, c.SignerFile, line)
+		}
+		if signer := common.HexToAddress(account); !slices.Contains(signers, signer) {
+			signers = append(signers, signer)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read signer file: %w", err)
+	}
+	return signers, nil
+}
@@ -90,7 +90,11 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		if header.Number.Uint64() < f.sys.backend.HistoryPruningCutoff() {
 			return nil, &history.PrunedHistoryError{}
 		}
-		return f.blockLogs(ctx, header)
+		logs, err := f.blockLogs(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		return f.appendTransitionLog(ctx, logs, header)
 	}
 
 	// Disallow pending logs.
@@ -142,7 +146,51 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if err != nil {
 		return nil, err
 	}
-	return f.rangeLogs(ctx, begin, end)
+	logs, err := f.rangeLogs(ctx, begin, end)
+	if err != nil {
+		return nil, err
+	}
+	// Report the synthetic transition log if the range covers the transition
+	backend, ok := f.sys.backend.(transitionLogBackend)
+	if !ok {
+		return logs, nil
+	}
+	if number, ok := backend.TransitionBlock(); ok && begin <= number && number <= end {
+		header, _ := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header != nil {
+			return f.appendTransitionLog(ctx, logs, header)
+		}
+	}
+	return logs, nil
+}
+
+// transitionLogBackend is implemented by backends reporting a synthetic log in
+// the PoS to PoA transition block, recording the signers taking over.
+type transitionLogBackend interface {
+	TransitionBlock() (uint64, bool)
+	TransitionLog(ctx context.Context, header *types.Header) (*types.Log, error)
+}
+
+// appendTransitionLog inserts the synthetic transition log of the given header
+// into the block ordered logs, if the backend reports one and it matches the
+// filter criteria. The log follows all the logs of its block.
+func (f *Filter) appendTransitionLog(ctx context.Context, logs []*types.Log, header *types.Header) ([]*types.Log, error) {
+	backend, ok := f.sys.backend.(transitionLogBackend)
+	if !ok {
+		return logs, nil
+	}
+	translog, err := backend.TransitionLog(ctx, header)
+	if err != nil || translog == nil {
+		return logs, err
+	}
+	if len(filterLogs([]*types.Log{translog}, nil, nil, f.addresses, f.topics)) == 0 {
+		return logs, nil
+	}
+	index := slices.IndexFunc(logs, func(l *types.Log) bool { return l.BlockNumber > translog.BlockNumber })
+	if index < 0 {
+		index = len(logs)
+	}
+	return slices.Insert(logs, index, translog), nil
 }
 
 const (
@@ -604,3 +604,82 @@ func TestRangeLogs(t *testing.T) {
 	expEvent(rangeLogsTestReorg, 400, 901)
 	expEvent(rangeLogsTestDone, 0, 0)
 }
+
+// transitionLogTestBackend reports a fixed synthetic log in the transition block.
+type transitionLogTestBackend struct {
+	*testBackend
+	transition uint64
+	log        *types.Log
+}
+
+func (b *transitionLogTestBackend) TransitionBlock() (uint64, bool) {
+	return b.transition, true
+}
+
+func (b *transitionLogTestBackend) TransitionLog(ctx context.Context, header *types.Header) (*types.Log, error) {
+	if header.Number.Uint64() != b.transition {
+		return nil, nil
+	}
+	return b.log, nil
+}
+
+// TestTransitionLog tests that the synthetic transition log reported by the
+// backend is returned by the filters covering the transition block.
+func TestTransitionLog(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		topic = common.HexToHash("0x01")
+	)
+	_, err := gspec.Commit(db, triedb.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
+	bc, err := core.NewBlockChain(db, gspec, ethash.NewFaker(), core.DefaultConfig().WithStateScheme(rawdb.HashScheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	translog := &types.Log{
+		Address:     params.SystemAddress,
+		Topics:      []common.Hash{topic},
+		BlockNumber: 5,
+		BlockHash:   chain[4].Hash(),
+	}
+	backend := &transitionLogTestBackend{testBackend: &testBackend{db: db}, transition: 5, log: translog}
+	sys := NewFilterSystem(backend, Config{})
+
+	backend.startFilterMaps(0, false, filtermaps.DefaultParams)
+	defer backend.stopFilterMaps()
+
+	for i, tc := range []struct {
+		f    *Filter
+		want int
+	}{
+		{f: sys.NewRangeFilter(0, int64(rpc.LatestBlockNumber), nil, nil), want: 1},
+		{f: sys.NewRangeFilter(5, 5, []common.Address{params.SystemAddress}, [][]common.Hash{{topic}}), want: 1},
+		{f: sys.NewRangeFilter(6, int64(rpc.LatestBlockNumber), nil, nil), want: 0},
+		{f: sys.NewRangeFilter(0, 4, nil, nil), want: 0},
+		{f: sys.NewRangeFilter(0, 10, []common.Address{{0xfe}}, nil), want: 0},
+		{f: sys.NewRangeFilter(0, 10, nil, [][]common.Hash{{common.HexToHash("0x02")}}), want: 0},
+		{f: sys.NewBlockFilter(chain[4].Hash(), nil, nil), want: 1},
+		{f: sys.NewBlockFilter(chain[3].Hash(), nil, nil), want: 0},
+	} {
+		logs, err := tc.f.Logs(context.Background())
+		if err != nil {
+			t.Fatalf("test %d, unexpected error: %v", i, err)
+		}
+		if len(logs) != tc.want {
+			t.Fatalf("test %d, have %d logs, want %d", i, len(logs), tc.want)
+		}
+		if tc.want > 0 && logs[0] != translog {
+			t.Fatalf("test %d, unexpected log returned: %v", i, logs[0])
+		}
+	}
+}
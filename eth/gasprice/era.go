@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// posSlotTime is the number of seconds between PoS blocks the number of sampled
+// blocks is tuned for.
+const posSlotTime = 12
+
+// maxPoACheckBlocks caps the number of blocks sampled in the PoA era, as short
+// block periods would otherwise fetch hundreds of blocks per suggestion.
+const maxPoACheckBlocks = 100
+
+// EraConfig overrides the gas price oracle settings for the blocks after a PoS
+// to PoA transition. Zero values are derived from the chain config.
+type EraConfig struct {
+	Blocks int      // Number of recent PoA blocks to sample (0 = the wall clock span of Blocks PoS slots)
+	MinTip *big.Int `toml:",omitempty"` // Lowest tip the PoA signers include, never suggesting less
+}
+
+// sampling returns the number of blocks to sample and the tip floor of the
+// suggestions made on top of the given head, switching to the PoA settings once
+// the next block is sealed by the PoA engine.
+func (oracle *Oracle) sampling(head uint64) (blocks int, floor *big.Int, poa bool) {
+	if !oracle.usesPoA(head + 1) {
+		return oracle.checkBlocks, nil, false
+	}
+	if oracle.poaSampling.CompareAndSwap(false, true) {
+		log.Info("Switched gas price oracle to PoA parameters", "number", head+1, "blocks", oracle.poaCheckBlocks, "mintip", oracle.poaMinTip)
+	}
+	return oracle.poaCheckBlocks, oracle.poaMinTip, true
+}
+
+// poaCheckBlocks returns the number of blocks to sample in the PoA era. Unless
+// configured, it covers the wall clock span of the PoS sample at the block
+// period of the PoA engine.
+func poaCheckBlocks(config Config, posBlocks int, period uint64) int {
+	if config.PoA != nil && config.PoA.Blocks > 0 {
+		return config.PoA.Blocks
+	}
+	if period == 0 {
+		return posBlocks
+	}
+	blocks := int(uint64(posBlocks) * posSlotTime / period)
+	return max(1, min(blocks, maxPoACheckBlocks))
+}
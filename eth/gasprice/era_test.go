@@ -0,0 +1,71 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSuggestTipCapPoA(t *testing.T) {
+	var cases = []struct {
+		transition uint64
+		era        *EraConfig
+		expect     *big.Int
+	}{
+		{40, nil, big.NewInt(params.GWei * 30)},                                              // Before the transition the PoS sample applies
+		{31, nil, big.NewInt(params.GWei * 31)},                                              // PoS blocks are not sampled after the transition
+		{31, &EraConfig{MinTip: big.NewInt(params.GWei * 40)}, big.NewInt(params.GWei * 40)}, // Suggestions respect the tip floor
+		{40, &EraConfig{MinTip: big.NewInt(params.GWei * 40)}, big.NewInt(params.GWei * 30)}, // The floor only applies after the transition
+	}
+	for i, c := range cases {
+		backend := &poaTestBackend{testBackend: newTestBackend(t, big.NewInt(0), nil, false), transition: c.transition}
+		oracle := NewOracle(backend, Config{Blocks: 3, Percentile: 60, PoA: c.era}, big.NewInt(params.GWei))
+
+		got, err := oracle.SuggestTipCap(context.Background())
+		backend.teardown()
+		if err != nil {
+			t.Fatalf("Test case %d: failed to retrieve recommended gas price: %v", i, err)
+		}
+		if got.Cmp(c.expect) != 0 {
+			t.Errorf("Test case %d: gas price mismatch, want %d, got %d", i, c.expect, got)
+		}
+	}
+}
+
+func TestPoACheckBlocks(t *testing.T) {
+	var cases = []struct {
+		config Config
+		period uint64
+		expect int
+	}{
+		{Config{}, 0, 20},                           // Without a period the PoS sample is kept
+		{Config{}, 12, 20},                          // Same period, same sample
+		{Config{}, 15, 16},                          // Longer periods sample fewer blocks
+		{Config{}, 1, maxPoACheckBlocks},            // Short periods are capped
+		{Config{}, 600, 1},                          // At least one block is sampled
+		{Config{PoA: &EraConfig{Blocks: 7}}, 15, 7}, // Configured samples win
+	}
+	for i, c := range cases {
+		if have := poaCheckBlocks(c.config, 20, c.period); have != c.expect {
+			t.Errorf("Test case %d: sample size mismatch, want %d, got %d", i, c.expect, have)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"math"
 	"math/big"
 	"slices"
+	"sort"
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -238,6 +239,12 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, reqEnd rpc.BlockNum
 //
 // Note: baseFee and blobBaseFee both include the next block after the newest of the returned range,
 // because this value can be derived from the newest block.
+//
+// On PoS to PoA transition networks the rewards of PoA blocks are the priority fees actually paid,
+// like before the transition, as signers order transactions by tip just like block builders do.
+// Rewards of the two eras are not comparable though, so if rewards are requested for a range
+// ending in the PoA era, the range is clamped to start at the transition block. The base fee
+// follows EIP-1559 across the transition, so header-only requests are not clamped.
 func (oracle *Oracle) FeeHistory(ctx context.Context, blocks uint64, unresolvedLastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, []*big.Int, []float64, error) {
 	if blocks < 1 {
 		return common.Big0, nil, nil, nil, nil, nil, nil // returning with no data and no error means there are no retrievable blocks
@@ -271,6 +278,14 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks uint64, unresolvedL
 		return common.Big0, nil, nil, nil, nil, nil, err
 	}
 	oldestBlock := lastBlock + 1 - blocks
+	if len(rewardPercentiles) != 0 && oracle.usesPoA(lastBlock) && !oracle.usesPoA(oldestBlock) {
+		// Skip the PoS blocks, searching for the transition block in the range
+		transition := oldestBlock + uint64(sort.Search(int(blocks), func(i int) bool {
+			return oracle.usesPoA(oldestBlock + uint64(i))
+		}))
+		log.Debug("Clamping fee history to the PoA era", "requested", oldestBlock, "transition", transition)
+		oldestBlock, blocks = transition, lastBlock+1-transition
+	}
 
 	var next atomic.Uint64
 	next.Store(oldestBlock)
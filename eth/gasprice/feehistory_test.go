@@ -105,3 +105,46 @@ func TestFeeHistory(t *testing.T) {
 		}
 	}
 }
+
+// poaTestBackend is a test backend of a PoS to PoA transition network.
+type poaTestBackend struct {
+	*testBackend
+	transition uint64
+}
+
+func (b *poaTestBackend) UsesPoA(number uint64) bool {
+	return number >= b.transition
+}
+
+func TestFeeHistoryTransition(t *testing.T) {
+	var cases = []struct {
+		last     rpc.BlockNumber
+		percent  []float64
+		expFirst uint64
+		expCount int
+	}{
+		{30, []float64{0, 10}, 25, 6},  // Rewards spanning the transition are clamped to the PoA era
+		{30, nil, 21, 10},              // Base fees are reported across the transition
+		{24, []float64{0, 10}, 15, 10}, // Ranges ending before the transition are not clamped
+		{rpc.LatestBlockNumber, []float64{50}, 25, 8},
+	}
+	for i, c := range cases {
+		backend := &poaTestBackend{testBackend: newTestBackend(t, big.NewInt(16), big.NewInt(28), false), transition: 25}
+		oracle := NewOracle(backend, Config{MaxHeaderHistory: 1000, MaxBlockHistory: 1000}, nil)
+
+		first, reward, baseFee, _, _, _, err := oracle.FeeHistory(context.Background(), 10, c.last, c.percent)
+		backend.teardown()
+		if err != nil {
+			t.Fatalf("Test case %d: failed to retrieve fee history: %v", i, err)
+		}
+		if first.Uint64() != c.expFirst {
+			t.Errorf("Test case %d: first block mismatch, want %d, got %d", i, c.expFirst, first)
+		}
+		if len(baseFee) != c.expCount+1 {
+			t.Errorf("Test case %d: baseFee array length mismatch, want %d, got %d", i, c.expCount+1, len(baseFee))
+		}
+		if len(c.percent) != 0 && len(reward) != c.expCount {
+			t.Errorf("Test case %d: reward array length mismatch, want %d, got %d", i, c.expCount, len(reward))
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"slices"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/lru"
@@ -47,6 +48,8 @@ type Config struct {
 	MaxBlockHistory  uint64
 	MaxPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
+
+	PoA *EraConfig `toml:",omitempty"` // Overrides for blocks after a PoS to PoA transition
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -59,6 +62,13 @@ type OracleBackend interface {
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
 
+// eraBackend is implemented by backends of PoS to PoA transition networks,
+// telling apart the blocks sealed by the PoA engine, also if the transition is
+// triggered at runtime rather than configured at a fixed block.
+type eraBackend interface {
+	UsesPoA(number uint64) bool
+}
+
 // Oracle recommends gas prices based on the content of recent
 // blocks. Suitable for both light and full clients.
 type Oracle struct {
@@ -74,6 +84,10 @@ type Oracle struct {
 	maxHeaderHistory, maxBlockHistory uint64
 
 	historyCache *lru.Cache[cacheKey, processedFees]
+
+	poaCheckBlocks int         // Number of blocks sampled in the PoA era
+	poaMinTip      *big.Int    // Tip floor of suggestions in the PoA era (nil = none)
+	poaSampling    atomic.Bool // Whether suggestions switched to the PoA era settings
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -118,6 +132,15 @@ func NewOracle(backend OracleBackend, params Config, startPrice *big.Int) *Oracl
 		startPrice = new(big.Int)
 	}
 
+	var period uint64
+	if clique := backend.ChainConfig().Clique; clique != nil {
+		period = clique.Period
+	}
+	var poaMinTip *big.Int
+	if params.PoA != nil && params.PoA.MinTip != nil && params.PoA.MinTip.Sign() > 0 {
+		poaMinTip = new(big.Int).Set(params.PoA.MinTip)
+	}
+
 	cache := lru.NewCache[cacheKey, processedFees](2048)
 	headEvent := make(chan core.ChainHeadEvent, 1)
 	sub := backend.SubscribeChainHeadEvent(headEvent)
@@ -148,6 +171,8 @@ func NewOracle(backend OracleBackend, params Config, startPrice *big.Int) *Oracl
 		maxHeaderHistory: maxHeaderHistory,
 		maxBlockHistory:  maxBlockHistory,
 		historyCache:     cache,
+		poaCheckBlocks:   poaCheckBlocks(params, blocks, period),
+		poaMinTip:        poaMinTip,
 	}
 }
 
@@ -178,14 +203,19 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	if headHash == lastHead {
 		return new(big.Int).Set(lastPrice), nil
 	}
+	// After the transition only PoA blocks are sampled, at the PoA settings
+	checkBlocks, floor, poa := oracle.sampling(head.Number.Uint64())
 	var (
 		sent, exp int
 		number    = head.Number.Uint64()
-		result    = make(chan results, oracle.checkBlocks)
+		result    = make(chan results, checkBlocks)
 		quit      = make(chan struct{})
 		results   []*big.Int
 	)
-	for sent < oracle.checkBlocks && number > 0 {
+	sampled := func(number uint64) bool {
+		return number > 0 && (!poa || oracle.usesPoA(number))
+	}
+	for sent < checkBlocks && sampled(number) {
 		go oracle.getBlockValues(ctx, number, sampleNumber, oracle.ignorePrice, result, quit)
 		sent++
 		exp++
@@ -208,7 +238,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 		// Besides, in order to collect enough data for sampling, if nothing
 		// meaningful returned, try to query more blocks. But the maximum
 		// is 2*checkBlocks.
-		if len(res.values) == 1 && len(results)+1+exp < oracle.checkBlocks*2 && number > 0 {
+		if len(res.values) == 1 && len(results)+1+exp < checkBlocks*2 && sampled(number) {
 			go oracle.getBlockValues(ctx, number, sampleNumber, oracle.ignorePrice, result, quit)
 			sent++
 			exp++
@@ -224,6 +254,9 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	if price.Cmp(oracle.maxPrice) > 0 {
 		price = new(big.Int).Set(oracle.maxPrice)
 	}
+	if floor != nil && price.Cmp(floor) < 0 {
+		price = new(big.Int).Set(floor)
+	}
 	oracle.cacheLock.Lock()
 	oracle.lastHead = headHash
 	oracle.lastPrice = price
@@ -284,3 +317,12 @@ func (oracle *Oracle) getBlockValues(ctx context.Context, blockNum uint64, limit
 	case <-quit:
 	}
 }
+
+// usesPoA reports whether the given block is sealed by the PoA engine of a PoS
+// to PoA transition network.
+func (oracle *Oracle) usesPoA(number uint64) bool {
+	if backend, ok := oracle.backend.(eraBackend); ok {
+		return backend.UsesPoA(number)
+	}
+	return oracle.backend.ChainConfig().IsPoSToPoATransition(new(big.Int).SetUint64(number))
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// heartbeatHandler implements the heartbeat.Backend interface, signing the
+// heartbeats and transition attestations of the local signers and feeding
+// received ones to the engine.
+type heartbeatHandler struct {
+	engine   *hybrid.Hybrid
+	chain    *core.BlockChain
+	accounts *accounts.Manager
+	signers  []common.Address
+}
+
+// LocalHeartbeats signs a heartbeat announcing the current head for every local
+// signer whose key is available.
+func (h *heartbeatHandler) LocalHeartbeats() []*hybrid.Heartbeat {
+	head := h.chain.CurrentHeader()
+	h.engine.CheckSignerPresence(head.Number.Uint64())
+
+	var heartbeats []*hybrid.Heartbeat
+	for _, signer := range h.signers {
+		hb, err := hybrid.NewHeartbeat(signer, head, h.sign)
+		if err != nil {
+			log.Debug("Failed to sign heartbeat", "signer", signer, "err", err)
+			continue
+		}
+		// Local signers are part of the network health view too
+		h.engine.AddHeartbeat(hb)
+		heartbeats = append(heartbeats, hb)
+	}
+	return heartbeats
+}
+
+// HandleHeartbeat feeds a heartbeat received from a peer to the engine.
+func (h *heartbeatHandler) HandleHeartbeat(hb *hybrid.Heartbeat) (bool, error) {
+	return h.engine.AddHeartbeat(hb)
+}
+
+// LocalAttestations attests the executed transition by every local initial
+// signer whose key is available and who did not attest it yet. Only transition
+// blocks on the canonical chain are attested.
+func (h *heartbeatHandler) LocalAttestations() []*hybrid.TransitionAttestation {
+	number, ok := h.engine.ExecutedTransition()
+	if !ok {
+		return nil
+	}
+	hash := h.engine.RuntimeState().Executed
+	if h.chain.GetCanonicalHash(number) != hash {
+		return nil
+	}
+	var (
+		initial      = h.engine.InitialSigners()
+		attestations []*hybrid.TransitionAttestation
+	)
+	for _, signer := range h.signers {
+		if !slices.Contains(initial, signer) || h.engine.HasAttested(signer, number, hash) {
+			continue
+		}
+		a, err := hybrid.NewTransitionAttestation(signer, number, hash, initial, h.sign)
+		if err != nil {
+			log.Debug("Failed to sign transition attestation", "signer", signer, "err", err)
+			continue
+		}
+		if _, err := h.engine.AddTransitionAttestation(a); err != nil {
+			log.Error("Failed to record local transition attestation", "signer", signer, "err", err)
+			continue
+		}
+		log.Info("Attested executed transition", "signer", signer, "number", number, "hash", hash)
+		attestations = append(attestations, a)
+	}
+	return attestations
+}
+
+// TransitionAttestations returns all transition attestations known to the engine.
+func (h *heartbeatHandler) TransitionAttestations() []*hybrid.TransitionAttestation {
+	return h.engine.TransitionAttestations()
+}
+
+// HandleAttestation feeds a transition attestation received from a peer to the
+// engine.
+func (h *heartbeatHandler) HandleAttestation(a *hybrid.TransitionAttestation) (bool, error) {
+	return h.engine.AddTransitionAttestation(a)
+}
+
+// sign signs heartbeat and attestation data with the key of a local signer.
+func (h *heartbeatHandler) sign(signer common.Address, data []byte) ([]byte, error) {
+	account := accounts.Account{Address: signer}
+	wallet, err := h.accounts.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.SignData(account, accounts.MimetypeClique, data)
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+)
+
+// errSyncing is returned by the readiness check while the node is syncing.
+var errSyncing = errors.New("syncing")
+
+// healthHandler serves a readiness or liveness probe, responding with 200 OK
+// if the check passes and 503 Service Unavailable with the reason otherwise.
+type healthHandler func() error
+
+func (check healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := check(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, err)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// ready reports whether the node is synced and, on a hybrid chain, set up to
+// follow and seal the current era.
+func (s *Ethereum) ready() error {
+	if !s.Synced() {
+		return errSyncing
+	}
+	if engine, ok := s.engine.(*hybrid.Hybrid); ok {
+		return engine.Ready(s.blockchain)
+	}
+	return nil
+}
+
+// live reports whether the node is functioning. A synced node of a hybrid chain
+// whose head stopped progressing after the transition is not.
+func (s *Ethereum) live() error {
+	if engine, ok := s.engine.(*hybrid.Hybrid); ok && s.Synced() {
+		return engine.Live(s.blockchain, time.Now())
+	}
+	return nil
+}
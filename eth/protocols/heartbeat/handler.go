@@ -0,0 +1,273 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package heartbeat
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// maxQueuedMessages is the number of messages queued for sending to a peer
+// before further ones are dropped.
+const maxQueuedMessages = 64
+
+var errMsgTooLarge = errors.New("message too long")
+
+var (
+	relayedCounter = metrics.NewRegisteredCounter("hbt/relayed", nil)
+	droppedCounter = metrics.NewRegisteredCounter("hbt/dropped", nil)
+)
+
+// Backend defines the callbacks the protocol uses to obtain local heartbeats
+// and to consume the ones received from the network.
+type Backend interface {
+	// LocalHeartbeats returns freshly signed heartbeats of the local signers.
+	LocalHeartbeats() []*hybrid.Heartbeat
+
+	// HandleHeartbeat consumes a heartbeat received from a peer, returning an
+	// error if it is invalid and whether it is new and should be relayed.
+	HandleHeartbeat(hb *hybrid.Heartbeat) (bool, error)
+
+	// LocalAttestations returns the transition attestations of the local
+	// signers made since the last call.
+	LocalAttestations() []*hybrid.TransitionAttestation
+
+	// TransitionAttestations returns all known transition attestations, which
+	// are handed to newly connected peers.
+	TransitionAttestations() []*hybrid.TransitionAttestation
+
+	// HandleAttestation consumes a transition attestation received from a
+	// peer, returning an error if it is invalid and whether it is new and
+	// should be relayed.
+	HandleAttestation(a *hybrid.TransitionAttestation) (bool, error)
+}
+
+// message is a protocol message queued for sending to a peer.
+type message struct {
+	code uint64
+	data interface{}
+}
+
+// peer is a remote node speaking the `hbt` protocol.
+type peer struct {
+	*p2p.Peer
+	rw      p2p.MsgReadWriter
+	version uint // Protocol version negotiated
+	queue   chan message
+}
+
+// Service runs the `hbt` protocol, periodically broadcasting the heartbeats of
+// the local signers and relaying new heartbeats of others. Since hbt/2 the
+// attestations of the executed transition are spread the same way.
+type Service struct {
+	backend  Backend
+	interval time.Duration
+
+	peers map[enode.ID]*peer
+	lock  sync.RWMutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates the heartbeat service, announcing local heartbeats at the given
+// interval.
+func New(backend Backend, interval time.Duration) *Service {
+	return &Service{
+		backend:  backend,
+		interval: interval,
+		peers:    make(map[enode.ID]*peer),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Protocols returns the P2P protocol definitions for `hbt`.
+func (s *Service) Protocols() []p2p.Protocol {
+	protocols := make([]p2p.Protocol, len(ProtocolVersions))
+	for i, version := range ProtocolVersions {
+		protocols[i] = p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return s.runPeer(p, rw, version)
+			},
+		}
+	}
+	return protocols
+}
+
+// Start launches the periodic broadcast of local heartbeats.
+func (s *Service) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop terminates the broadcast loop.
+func (s *Service) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+func (s *Service) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, hb := range s.backend.LocalHeartbeats() {
+			s.broadcast(message{HeartbeatMsg, hb}, enode.ID{})
+		}
+		for _, a := range s.backend.LocalAttestations() {
+			s.broadcast(message{TransitionAttestationMsg, a}, enode.ID{})
+		}
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// broadcast queues the message for sending to all peers but the origin that
+// support it.
+func (s *Service) broadcast(msg message, origin enode.ID) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for id, p := range s.peers {
+		if id == origin {
+			continue
+		}
+		p.send(msg)
+	}
+}
+
+// runPeer is invoked when a peer joins on the `hbt` protocol and handles it for
+// the lifetime of the connection.
+func (s *Service) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter, version uint) error {
+	peer := &peer{Peer: p, rw: rw, version: version, queue: make(chan message, maxQueuedMessages)}
+
+	// Peers joining late learn about the executed transition right away
+	for _, a := range s.backend.TransitionAttestations() {
+		peer.send(message{TransitionAttestationMsg, a})
+	}
+	s.lock.Lock()
+	s.peers[p.ID()] = peer
+	s.lock.Unlock()
+
+	defer func() {
+		s.lock.Lock()
+		delete(s.peers, p.ID())
+		s.lock.Unlock()
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go peer.sendLoop(done)
+
+	for {
+		if err := s.handleMessage(peer); err != nil {
+			peer.Log().Debug("Message handling failed in `hbt`", "err", err)
+			return err
+		}
+	}
+}
+
+// handleMessage reads and processes the next message from the peer.
+func (s *Service) handleMessage(peer *peer) error {
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+
+	switch {
+	case msg.Code == HeartbeatMsg:
+		hb := new(HeartbeatPacket)
+		if err := msg.Decode(hb); err != nil {
+			return fmt.Errorf("message %v: %v", msg, err)
+		}
+		relay, err := s.backend.HandleHeartbeat(hb)
+		if err != nil {
+			return fmt.Errorf("heartbeat of %v: %w", hb.Signer, err)
+		}
+		if relay {
+			relayedCounter.Inc(1)
+			s.broadcast(message{HeartbeatMsg, hb}, peer.ID())
+		}
+		return nil
+
+	case peer.version >= HBT2 && msg.Code == TransitionAttestationMsg:
+		a := new(TransitionAttestationPacket)
+		if err := msg.Decode(a); err != nil {
+			return fmt.Errorf("message %v: %v", msg, err)
+		}
+		relay, err := s.backend.HandleAttestation(a)
+		if err != nil {
+			return fmt.Errorf("transition attestation of %v: %w", a.Signer, err)
+		}
+		if relay {
+			relayedCounter.Inc(1)
+			s.broadcast(message{TransitionAttestationMsg, a}, peer.ID())
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("invalid message code %v", msg.Code)
+	}
+}
+
+// send queues the message for sending to the peer, dropping it if the queue
+// is full or the peer does not support it.
+func (p *peer) send(msg message) {
+	if msg.code == TransitionAttestationMsg && p.version < HBT2 {
+		return
+	}
+	select {
+	case p.queue <- msg:
+	default:
+		droppedCounter.Inc(1)
+	}
+}
+
+// sendLoop writes queued messages to the peer until done is closed.
+func (p *peer) sendLoop(done <-chan struct{}) {
+	for {
+		select {
+		case msg := <-p.queue:
+			if err := p2p.Send(p.rw, msg.code, msg.data); err != nil {
+				log.Debug("Failed to send message", "peer", p.ID(), "code", msg.code, "err", err)
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package heartbeat
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// testBackend relays heartbeats and attestations of a single valid signer and
+// rejects others.
+type testBackend struct {
+	valid common.Address
+	known []*hybrid.TransitionAttestation
+}
+
+func (b *testBackend) LocalHeartbeats() []*hybrid.Heartbeat { return nil }
+
+func (b *testBackend) HandleHeartbeat(hb *hybrid.Heartbeat) (bool, error) {
+	if hb.Signer != b.valid {
+		return false, hybrid.ErrInvalidHeartbeat
+	}
+	return true, nil
+}
+
+func (b *testBackend) LocalAttestations() []*hybrid.TransitionAttestation { return nil }
+
+func (b *testBackend) TransitionAttestations() []*hybrid.TransitionAttestation { return b.known }
+
+func (b *testBackend) HandleAttestation(a *hybrid.TransitionAttestation) (bool, error) {
+	if a.Signer != b.valid {
+		return false, hybrid.ErrInvalidAttestation
+	}
+	return true, nil
+}
+
+// connect runs a new peer of the given protocol version on the service,
+// returning the remote end of the pipe and the channel the protocol result is
+// delivered on.
+func connect(s *Service, id byte, version uint) (*p2p.MsgPipeRW, chan error) {
+	local, remote := p2p.MsgPipe()
+	peer := p2p.NewPeer(enode.ID{id}, "test", nil)
+
+	errc := make(chan error, 1)
+	go func() { errc <- s.runPeer(peer, local, version) }()
+	return remote, errc
+}
+
+// waitPeers waits until the given number of peers is registered.
+func waitPeers(s *Service, n int) {
+	for {
+		s.lock.RLock()
+		have := len(s.peers)
+		s.lock.RUnlock()
+		if have == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHeartbeatRelay(t *testing.T) {
+	signer := common.Address{0xaa}
+	s := New(&testBackend{valid: signer}, time.Hour)
+
+	origin, originErr := connect(s, 1, HBT2)
+	other, _ := connect(s, 2, HBT1)
+	defer origin.Close()
+	defer other.Close()
+
+	// Wait for both peers to be registered before relaying
+	waitPeers(s, 2)
+	hb := &hybrid.Heartbeat{Signer: signer, Number: 7, Time: 1}
+	if err := p2p.Send(origin, HeartbeatMsg, hb); err != nil {
+		t.Fatalf("Failed to send heartbeat: %v", err)
+	}
+	if err := p2p.ExpectMsg(other, HeartbeatMsg, hb); err != nil {
+		t.Fatalf("Heartbeat not relayed: %v", err)
+	}
+	// Invalid heartbeats disconnect the sender
+	if err := p2p.Send(origin, HeartbeatMsg, &hybrid.Heartbeat{Signer: common.Address{0xbb}}); err != nil {
+		t.Fatalf("Failed to send heartbeat: %v", err)
+	}
+	select {
+	case err := <-originErr:
+		if !errors.Is(err, hybrid.ErrInvalidHeartbeat) {
+			t.Fatalf("Disconnect reason mismatch: have %v, want %v", err, hybrid.ErrInvalidHeartbeat)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Peer sending invalid heartbeat not dropped")
+	}
+}
+
+func TestAttestationRelay(t *testing.T) {
+	var (
+		signer = common.Address{0xaa}
+		known  = &hybrid.TransitionAttestation{Number: 5, Signers: []common.Address{signer}, Signer: signer}
+		s      = New(&testBackend{valid: signer, known: []*hybrid.TransitionAttestation{known}}, time.Hour)
+	)
+	// Newly connected peers receive the known attestations
+	origin, originErr := connect(s, 1, HBT2)
+	defer origin.Close()
+	if err := p2p.ExpectMsg(origin, TransitionAttestationMsg, known); err != nil {
+		t.Fatalf("Known attestation not sent: %v", err)
+	}
+	legacy, _ := connect(s, 2, HBT1)
+	other, _ := connect(s, 3, HBT2)
+	defer legacy.Close()
+	defer other.Close()
+	if err := p2p.ExpectMsg(other, TransitionAttestationMsg, known); err != nil {
+		t.Fatalf("Known attestation not sent: %v", err)
+	}
+	waitPeers(s, 3)
+
+	// New attestations are relayed to peers supporting them only
+	a := &hybrid.TransitionAttestation{Number: 6, Signers: []common.Address{signer}, Signer: signer}
+	if err := p2p.Send(origin, TransitionAttestationMsg, a); err != nil {
+		t.Fatalf("Failed to send attestation: %v", err)
+	}
+	if err := p2p.ExpectMsg(other, TransitionAttestationMsg, a); err != nil {
+		t.Fatalf("Attestation not relayed: %v", err)
+	}
+	hb := &hybrid.Heartbeat{Signer: signer, Number: 7, Time: 1}
+	if err := p2p.Send(origin, HeartbeatMsg, hb); err != nil {
+		t.Fatalf("Failed to send heartbeat: %v", err)
+	}
+	if err := p2p.ExpectMsg(legacy, HeartbeatMsg, hb); err != nil {
+		t.Fatalf("hbt/1 peer received attestation or missed heartbeat: %v", err)
+	}
+	// Invalid attestations disconnect the sender
+	if err := p2p.Send(origin, TransitionAttestationMsg, &hybrid.TransitionAttestation{Signer: common.Address{0xbb}}); err != nil {
+		t.Fatalf("Failed to send attestation: %v", err)
+	}
+	select {
+	case err := <-originErr:
+		if !errors.Is(err, hybrid.ErrInvalidAttestation) {
+			t.Fatalf("Disconnect reason mismatch: have %v, want %v", err, hybrid.ErrInvalidAttestation)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Peer sending invalid attestation not dropped")
+	}
+}
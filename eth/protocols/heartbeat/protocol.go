@@ -0,0 +1,58 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package heartbeat implements the devp2p protocol PoA signers use to announce
+// their liveness ahead of and after the PoS to PoA transition, and to spread
+// their attestations of the executed transition.
+package heartbeat
+
+import (
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	HBT1 = 1
+	HBT2 = 2
+)
+
+// ProtocolName is the official short name of the `hbt` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "hbt"
+
+// ProtocolVersions are the supported versions of the `hbt` protocol (first
+// is primary).
+var ProtocolVersions = []uint{HBT2, HBT1}
+
+// protocolLengths are the number of implemented messages corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{HBT2: 2, HBT1: 1}
+
+// maxMessageSize is the maximum cap on the size of a protocol message, leaving
+// room for attestations naming a few dozen initial signers.
+const maxMessageSize = 4 * 1024
+
+const (
+	HeartbeatMsg             = 0x00
+	TransitionAttestationMsg = 0x01 // Introduced in hbt/2
+)
+
+// HeartbeatPacket is the network packet announcing the liveness of a signer.
+type HeartbeatPacket = hybrid.Heartbeat
+
+// TransitionAttestationPacket is the network packet carrying the attestation
+// of the executed transition by an initial signer.
+type TransitionAttestationPacket = hybrid.TransitionAttestation
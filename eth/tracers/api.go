@@ -159,6 +159,9 @@ type TraceConfig struct {
 	// Config specific to given tracer. Note struct logger
 	// config are historically embedded in main object.
 	TracerConfig json.RawMessage
+	// Consensus annotates the results of block tracing with the consensus the
+	// block was produced under.
+	Consensus bool
 }
 
 // TraceCallConfig is the config for traceCall API. It holds one more
@@ -179,9 +182,24 @@ type StdTraceConfig struct {
 
 // txTraceResult is the result of a single transaction trace.
 type txTraceResult struct {
-	TxHash common.Hash `json:"txHash"`           // transaction hash
-	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
-	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+	TxHash    common.Hash    `json:"txHash"`              // transaction hash
+	Result    interface{}    `json:"result,omitempty"`    // Trace results produced by the tracer
+	Error     string         `json:"error,omitempty"`     // Trace failure produced by the tracer
+	Consensus *consensusInfo `json:"consensus,omitempty"` // Consensus metadata of the block, if requested
+}
+
+// consensusInfo describes the consensus a traced block was produced under.
+type consensusInfo struct {
+	Engine     string          `json:"engine"`               // Engine responsible for the block
+	Era        string          `json:"era,omitempty"`        // Consensus era, "pos" or "poa", on chains switching eras
+	Sealer     *common.Address `json:"sealer,omitempty"`     // Account that produced the block
+	Transition bool            `json:"transition,omitempty"` // Whether the block is the first one of the PoA era
+}
+
+// engineNamer is implemented by consensus engines delegating blocks to other
+// engines, naming the one responsible for a block.
+type engineNamer interface {
+	EngineName(number uint64) string
 }
 
 // blockTraceTask represents a single block trace task when an entire chain is
@@ -614,7 +632,12 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	// in separate worker threads.
 	if config != nil && config.Tracer != nil && *config.Tracer != "" {
 		if isJS := DefaultDirectory.IsJS(*config.Tracer); isJS {
-			return api.traceBlockParallel(ctx, block, statedb, config)
+			results, err := api.traceBlockParallel(ctx, block, statedb, config)
+			if err != nil {
+				return nil, err
+			}
+			api.annotateConsensus(block, config, results)
+			return results, nil
 		}
 	}
 	// Native tracers have low overhead
@@ -639,9 +662,39 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		}
 		results[i] = &txTraceResult{TxHash: tx.Hash(), Result: res}
 	}
+	api.annotateConsensus(block, config, results)
 	return results, nil
 }
 
+// annotateConsensus attaches the consensus metadata of the block to each of its
+// trace results, if the trace config requests it.
+func (api *API) annotateConsensus(block *types.Block, config *TraceConfig, results []*txTraceResult) {
+	if config == nil || !config.Consensus {
+		return
+	}
+	var (
+		engine = api.backend.Engine()
+		number = block.NumberU64()
+		info   = &consensusInfo{Engine: fmt.Sprintf("%T", engine)}
+	)
+	if namer, ok := engine.(engineNamer); ok {
+		info.Engine = namer.EngineName(number)
+	}
+	if transitioner, ok := engine.(consensus.Transitioner); ok {
+		info.Era = "pos"
+		if transitioner.UsesPoA(number) {
+			info.Era = "poa"
+			info.Transition = number == 0 || !transitioner.UsesPoA(number-1)
+		}
+	}
+	if sealer, err := engine.Author(block.Header()); err == nil {
+		info.Sealer = &sealer
+	}
+	for _, result := range results {
+		result.Consensus = info
+	}
+}
+
 // traceBlockParallel is for tracers that have a high overhead (read JS tracers). One thread
 // runs along and executes txes without tracing enabled to generate their prestate.
 // Worker threads take the tasks and the prestate and trace them.
@@ -34,7 +34,9 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -643,6 +645,99 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// Tests that blocks on both sides of the PoS to PoA transition, including the
+// transition block itself, are traced and annotated with their consensus.
+func TestTraceBlockAcrossTransition(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts   = newAccounts(2)
+		signerKey  = accounts[1].key
+		transition = uint64(2)
+		config     = *params.AllCliqueProtocolChanges
+	)
+	config.TerminalTotalDifficulty = common.Big0
+	config.Clique = &params.CliqueConfig{Period: 0, Epoch: transition}
+	config.PoSToPoATransitionBlock = new(big.Int).SetUint64(transition)
+	config.PoAInitialSigners = []common.Address{accounts[1].addr}
+
+	genesis := &core.Genesis{
+		Config:     &config,
+		Timestamp:  uint64(time.Now().Add(-time.Hour).Unix()),
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: common.Big0,
+		Alloc:      types.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	engine, err := hybrid.NewFromChainConfig(&config, rawdb.NewMemoryDatabase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSigner(&config)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, 3, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		if b.Number().Uint64() >= transition {
+			b.SetDifficulty(big.NewInt(2))
+		}
+	})
+	// Seal the PoA blocks with the only signer
+	for i, block := range blocks {
+		if block.NumberU64() < transition {
+			continue
+		}
+		header := block.Header()
+		header.ParentHash = blocks[i-1].Hash()
+		if block.NumberU64()%config.Clique.Epoch == 0 {
+			header.Extra = hybrid.CheckpointExtra(config.PoAInitialSigners)
+		} else {
+			header.Extra = make([]byte, 32+crypto.SignatureLength)
+		}
+		sig, _ := crypto.Sign(clique.SealHash(header).Bytes(), signerKey)
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+		blocks[i] = block.WithSeal(header)
+	}
+	backend := &testBackend{chainConfig: &config, chaindb: rawdb.NewMemoryDatabase()}
+	if backend.engine, err = hybrid.NewFromChainConfig(&config, backend.chaindb); err != nil {
+		t.Fatal(err)
+	}
+	if backend.chain, err = core.NewBlockChain(backend.chaindb, genesis, backend.engine, &core.BlockChainConfig{ArchiveMode: true}); err != nil {
+		t.Fatal(err)
+	}
+	defer backend.chain.Stop()
+	if n, err := backend.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	api := NewAPI(backend)
+
+	for _, tt := range []struct {
+		number uint64
+		want   consensusInfo
+	}{
+		{1, consensusInfo{Engine: "beacon+clique", Era: "pos", Sealer: &common.Address{}}},
+		{2, consensusInfo{Engine: "clique", Era: "poa", Sealer: &accounts[1].addr, Transition: true}},
+		{3, consensusInfo{Engine: "clique", Era: "poa", Sealer: &accounts[1].addr}},
+	} {
+		results, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(tt.number), &TraceConfig{Consensus: true})
+		if err != nil {
+			t.Fatalf("block %d: failed to trace: %v", tt.number, err)
+		}
+		if len(results) != 1 || results[0].Error != "" {
+			t.Fatalf("block %d: unexpected results: %v", tt.number, results)
+		}
+		if have := results[0].Consensus; have == nil || !reflect.DeepEqual(*have, tt.want) {
+			t.Errorf("block %d: consensus mismatch: have %+v, want %+v", tt.number, have, tt.want)
+		}
+	}
+	// Without asking for it, no consensus metadata is attached
+	results, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(transition), nil)
+	if err != nil {
+		t.Fatalf("failed to trace the transition block: %v", err)
+	}
+	if results[0].Consensus != nil {
+		t.Errorf("unrequested consensus metadata: %+v", results[0].Consensus)
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hybridclient provides an RPC client for the APIs of nodes running the
+// hybrid PoS to PoA consensus engine.
+package hybridclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client is a wrapper around rpc.Client that implements the hybrid namespace.
+//
+// If you want to use the standardized Ethereum RPC functionality, use ethclient.Client instead.
+type Client struct {
+	c *rpc.Client
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// TransitionStatus returns the progress of the PoS to PoA transition as seen
+// from the current head of the node.
+func (hc *Client) TransitionStatus(ctx context.Context) (*hybrid.Status, error) {
+	var status *hybrid.Status
+	if err := hc.c.CallContext(ctx, &status, "hybrid_status"); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SignersAt returns the PoA signers authorized at the given block. The current
+// head is used if number is nil.
+func (hc *Client) SignersAt(ctx context.Context, number *big.Int) ([]common.Address, error) {
+	var signers []common.Address
+	if err := hc.c.CallContext(ctx, &signers, "hybrid_signers", toBlockNumArg(number)); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+// ValidatorStats returns per signer production statistics of the PoA blocks in
+// the range [from, to]. A nil from defaults to the transition block, a nil to
+// to the current head.
+func (hc *Client) ValidatorStats(ctx context.Context, from, to *big.Int) (*hybrid.ValidatorStatsReport, error) {
+	var report *hybrid.ValidatorStatsReport
+	if err := hc.c.CallContext(ctx, &report, "hybrid_validatorStats", toRangeArg(from), toRangeArg(to)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SealedBlocks returns the canonical PoA blocks sealed by the given signer in
+// the range [from, to], with the same defaults as ValidatorStats.
+func (hc *Client) SealedBlocks(ctx context.Context, signer common.Address, from, to *big.Int) ([]hybrid.SealedBlock, error) {
+	var blocks []hybrid.SealedBlock
+	if err := hc.c.CallContext(ctx, &blocks, "hybrid_sealedBlocks", signer, toRangeArg(from), toRangeArg(to)); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() >= 0 {
+		return hexutil.EncodeBig(number)
+	}
+	// It's negative.
+	if number.IsInt64() {
+		return rpc.BlockNumber(number.Int64()).String()
+	}
+	// It's negative and large, which is invalid.
+	return fmt.Sprintf("<invalid %d>", number)
+}
+
+// toRangeArg encodes a range bound, leaving it out if nil so the node applies
+// its default.
+func toRangeArg(number *big.Int) interface{} {
+	if number == nil {
+		return nil
+	}
+	return toBlockNumArg(number)
+}
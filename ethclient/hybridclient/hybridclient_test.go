@@ -0,0 +1,134 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hybridclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/hybrid"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var testSigners = []common.Address{{0xaa}, {0xbb}}
+
+// testService mimics the hybrid namespace of a node whose head is block 20,
+// past the transition at block 10.
+type testService struct{}
+
+func (s *testService) Status() *hybrid.Status {
+	return &hybrid.Status{TransitionBlock: 10, CurrentBlock: 20, Mode: "poa", InitialSigners: testSigners, Executed: true}
+}
+
+func (s *testService) Signers(number *rpc.BlockNumber) ([]common.Address, error) {
+	if number != nil && *number >= 0 && *number < 10 {
+		return nil, errors.New("transition not reached")
+	}
+	return testSigners, nil
+}
+
+func (s *testService) ValidatorStats(from, to *rpc.BlockNumber) (*hybrid.ValidatorStatsReport, error) {
+	report := &hybrid.ValidatorStatsReport{From: 10, To: 20}
+	if from != nil {
+		report.From = hexutil.Uint64(*from)
+	}
+	if to != nil {
+		report.To = hexutil.Uint64(*to)
+	}
+	for _, signer := range testSigners {
+		report.Signers = append(report.Signers, &hybrid.ValidatorStats{Signer: signer, Sealed: 5})
+	}
+	return report, nil
+}
+
+func (s *testService) SealedBlocks(signer common.Address, from, to *rpc.BlockNumber) ([]hybrid.SealedBlock, error) {
+	if signer != testSigners[0] {
+		return []hybrid.SealedBlock{}, nil
+	}
+	return []hybrid.SealedBlock{{Number: 10, Hash: common.Hash{0x10}}, {Number: 12, Hash: common.Hash{0x12}}}, nil
+}
+
+func newTestClient(t *testing.T) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("hybrid", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	client := rpc.DialInProc(server)
+	t.Cleanup(client.Close)
+	return New(client)
+}
+
+func TestTransitionStatus(t *testing.T) {
+	status, err := newTestClient(t).TransitionStatus(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to retrieve status: %v", err)
+	}
+	if status.TransitionBlock != 10 || status.CurrentBlock != 20 || status.Mode != "poa" || !status.Executed || len(status.InitialSigners) != 2 {
+		t.Fatalf("Status mismatch: %+v", status)
+	}
+}
+
+func TestSignersAt(t *testing.T) {
+	client := newTestClient(t)
+
+	signers, err := client.SignersAt(context.Background(), nil)
+	if err != nil || len(signers) != 2 || signers[0] != testSigners[0] {
+		t.Fatalf("Head signers mismatch: %v, %v", signers, err)
+	}
+	if signers, err = client.SignersAt(context.Background(), big.NewInt(15)); err != nil || len(signers) != 2 {
+		t.Fatalf("Signers at 15 mismatch: %v, %v", signers, err)
+	}
+	if _, err := client.SignersAt(context.Background(), big.NewInt(5)); err == nil {
+		t.Fatal("Signers returned before the transition")
+	}
+}
+
+func TestValidatorStats(t *testing.T) {
+	client := newTestClient(t)
+
+	report, err := client.ValidatorStats(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to retrieve stats: %v", err)
+	}
+	if report.From != 10 || report.To != 20 || len(report.Signers) != 2 || report.Signers[1].Sealed != 5 {
+		t.Fatalf("Default range report mismatch: %+v", report)
+	}
+	if report, err = client.ValidatorStats(context.Background(), big.NewInt(12), big.NewInt(14)); err != nil || report.From != 12 || report.To != 14 {
+		t.Fatalf("Range report mismatch: %+v, %v", report, err)
+	}
+}
+
+func TestSealedBlocks(t *testing.T) {
+	client := newTestClient(t)
+
+	blocks, err := client.SealedBlocks(context.Background(), testSigners[0], nil, big.NewInt(20))
+	if err != nil {
+		t.Fatalf("Failed to retrieve sealed blocks: %v", err)
+	}
+	if len(blocks) != 2 || blocks[1].Number != 12 || blocks[1].Hash != (common.Hash{0x12}) {
+		t.Fatalf("Sealed blocks mismatch: %+v", blocks)
+	}
+	if blocks, err = client.SealedBlocks(context.Background(), testSigners[1], nil, nil); err != nil || len(blocks) != 0 {
+		t.Fatalf("Sealed blocks of idle signer mismatch: %+v, %v", blocks, err)
+	}
+}
@@ -54,7 +54,13 @@ import (
 // allowed to produce in order to speed up calculations.
 const estimateGasErrorRatio = 0.015
 
-var errBlobTxNotSupported = errors.New("signing blob transactions not supported")
+var (
+	errBlobTxNotSupported = errors.New("signing blob transactions not supported")
+
+	// errSetHeadCrossesTransition is returned by debug_setHead for rewinds from
+	// the PoA era back into the PoS one which are not forced.
+	errSetHeadCrossesTransition = errors.New("rewind crosses the PoS to PoA transition")
+)
 
 // EthereumAPI provides an API to access Ethereum related information.
 type EthereumAPI struct {
@@ -629,9 +635,58 @@ func (api *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rp
 	for i, receipt := range receipts {
 		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, txs[i], i)
 	}
+	// The synthetic transition log is reported as the receipt of a system
+	// transaction following the ones of the block
+	if backend, ok := api.b.(transitionLogBackend); ok {
+		log, err := backend.TransitionLog(ctx, block.Header())
+		if err != nil {
+			return nil, err
+		}
+		if log != nil {
+			result = append(result, marshalTransitionReceipt(log, receipts))
+		}
+	}
 	return result, nil
 }
 
+// transitionLogBackend is implemented by backends reporting a synthetic log in
+// the PoS to PoA transition block, recording the signers taking over.
+type transitionLogBackend interface {
+	TransitionLog(ctx context.Context, header *types.Header) (*types.Log, error)
+}
+
+// marshalTransitionReceipt marshals the synthetic transition log as the receipt
+// of a system transaction, which spends no gas.
+func marshalTransitionReceipt(log *types.Log, receipts types.Receipts) map[string]interface{} {
+	var (
+		cumulativeGas uint64
+		bloom         types.Bloom
+	)
+	if len(receipts) > 0 {
+		cumulativeGas = receipts[len(receipts)-1].CumulativeGasUsed
+	}
+	bloom.Add(log.Address.Bytes())
+	for _, topic := range log.Topics {
+		bloom.Add(topic.Bytes())
+	}
+	return map[string]interface{}{
+		"blockHash":         log.BlockHash,
+		"blockNumber":       hexutil.Uint64(log.BlockNumber),
+		"transactionHash":   log.TxHash,
+		"transactionIndex":  hexutil.Uint64(log.TxIndex),
+		"from":              log.Address,
+		"to":                nil,
+		"gasUsed":           hexutil.Uint64(0),
+		"cumulativeGasUsed": hexutil.Uint64(cumulativeGas),
+		"contractAddress":   nil,
+		"logs":              []*types.Log{log},
+		"logsBloom":         bloom,
+		"type":              hexutil.Uint(types.LegacyTxType),
+		"effectiveGasPrice": (*hexutil.Big)(new(big.Int)),
+		"status":            hexutil.Uint(types.ReceiptStatusSuccessful),
+	}
+}
+
 // ChainContextBackend provides methods required to implement ChainContext.
 type ChainContextBackend interface {
 	Engine() consensus.Engine
@@ -1219,6 +1274,62 @@ func (api *BlockChainAPI) Config(ctx context.Context) (*configResponse, error) {
 	return &resp, nil
 }
 
+// Consensus modes reported by eth_consensusMode.
+const (
+	consensusModePoS           = "pos"
+	consensusModeTransitioning = "transitioning" // PoA block, transition not yet confirmed
+	consensusModePoA           = "poa"
+)
+
+// consensusModeResult describes the consensus governing a block of a chain
+// switching from PoS to PoA consensus. The transition fields are omitted while
+// the engine waits for a runtime trigger to learn the transition block.
+type consensusModeResult struct {
+	Number          hexutil.Uint64  `json:"number"`
+	Mode            string          `json:"mode"`
+	TransitionBlock *hexutil.Uint64 `json:"transitionBlock,omitempty"`
+	ConfirmedBlock  *hexutil.Uint64 `json:"confirmedBlock,omitempty"` // First block at which the transition is final
+}
+
+// consensusMode returns the consensus mode of the block with the given number,
+// as reported by the engine switching the chain to PoA consensus.
+func consensusMode(engine consensus.Transitioner, confirmations uint64, number uint64) *consensusModeResult {
+	result := &consensusModeResult{Number: hexutil.Uint64(number), Mode: consensusModePoS}
+	transition, ok := engine.TransitionBlock()
+	if !ok {
+		return result
+	}
+	confirmed := transition + confirmations
+	result.TransitionBlock = (*hexutil.Uint64)(&transition)
+	result.ConfirmedBlock = (*hexutil.Uint64)(&confirmed)
+
+	switch {
+	case !engine.UsesPoA(number):
+		result.Mode = consensusModePoS
+	case number < confirmed:
+		result.Mode = consensusModeTransitioning
+	default:
+		result.Mode = consensusModePoA
+	}
+	return result
+}
+
+// ConsensusMode returns whether the given block is governed by PoS or PoA
+// consensus, or is a PoA block whose transition is not confirmed yet, along
+// with the transition block of the chain. Wallets and bridges can adapt their
+// confirmation policies accordingly.
+func (api *BlockChainAPI) ConsensusMode(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*consensusModeResult, error) {
+	header, err := api.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	engine, ok := api.b.Engine().(consensus.Transitioner)
+	if !ok {
+		return nil, errors.New("chain does not switch to PoA consensus")
+	}
+	return consensusMode(engine, api.b.ChainConfig().TransitionConfirmations(), header.Number.Uint64()), nil
+}
+
 // AccessList creates an access list for the given transaction.
 // If the accesslist creation fails an error is returned.
 // If the transaction itself fails, an vmErr is returned.
@@ -1925,14 +2036,29 @@ func (api *DebugAPI) ChaindbCompact() error {
 }
 
 // SetHead rewinds the head of the blockchain to a previous block.
-func (api *DebugAPI) SetHead(number hexutil.Uint64) error {
+//
+// Rewinding a chain that switched to PoA consensus back into the PoS era is
+// refused unless forced. The node then follows the PoS era again, which needs
+// a consensus client to progress, drops off the PoA network until it imports
+// the transition anew, and the local signers lose track of the blocks they
+// sealed after it.
+func (api *DebugAPI) SetHead(number hexutil.Uint64, force *bool) error {
 	header := api.b.CurrentHeader()
 	if header == nil {
 		return errors.New("current header is not available")
 	}
-	if header.Number.Uint64() <= uint64(number) {
+	head := header.Number.Uint64()
+	if head <= uint64(number) {
 		return errors.New("not allowed to rewind to a future block")
 	}
+	if engine, ok := api.b.Engine().(consensus.Transitioner); ok && engine.UsesPoA(head) && !engine.UsesPoA(uint64(number)) {
+		if force == nil || !*force {
+			return fmt.Errorf("%w: rewinding block #%d to #%d drops the PoA era, isolating the node from its PoA peers "+
+				"until it re-imports the transition block, and resets the transition state of the local signers; "+
+				"pass force=true to rewind anyway", errSetHeadCrossesTransition, head, number)
+		}
+		log.Warn("Forcing rewind across the PoS to PoA transition", "head", head, "target", uint64(number))
+	}
 	api.b.SetHead(uint64(number))
 	return nil
 }
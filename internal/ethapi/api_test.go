@@ -3885,3 +3885,93 @@ func (b configTimeBackend) HeaderByNumber(_ context.Context, n rpc.BlockNumber)
 func (b configTimeBackend) CurrentHeader() *types.Header {
 	return &types.Header{Time: b.time}
 }
+
+func TestConsensusMode(t *testing.T) {
+	t.Parallel()
+
+	engine := &transitionEngine{transition: 10}
+	for _, tt := range []struct {
+		number uint64
+		mode   string
+	}{
+		{0, consensusModePoS},
+		{9, consensusModePoS},
+		{10, consensusModeTransitioning},
+		{12, consensusModeTransitioning},
+		{13, consensusModePoA},
+		{1000, consensusModePoA},
+	} {
+		result := consensusMode(engine, 3, tt.number)
+		if result.Mode != tt.mode {
+			t.Errorf("block %d: mode mismatch: have %s, want %s", tt.number, result.Mode, tt.mode)
+		}
+		if result.TransitionBlock == nil || *result.TransitionBlock != 10 || result.ConfirmedBlock == nil || *result.ConfirmedBlock != 13 {
+			t.Errorf("block %d: transition mismatch: have %v/%v, want 10/13", tt.number, result.TransitionBlock, result.ConfirmedBlock)
+		}
+	}
+	// A pending transition keeps every block under PoS consensus
+	result := consensusMode(&pendingTransitionEngine{}, 3, 1000)
+	if result.Mode != consensusModePoS || result.TransitionBlock != nil || result.ConfirmedBlock != nil {
+		t.Errorf("pending transition mismatch: %+v", result)
+	}
+}
+
+// pendingTransitionEngine is a consensus engine waiting for a runtime trigger
+// to switch to PoA.
+type pendingTransitionEngine struct {
+	consensus.Engine
+}
+
+func (e *pendingTransitionEngine) UsesPoA(number uint64) bool      { return false }
+func (e *pendingTransitionEngine) TransitionBlock() (uint64, bool) { return 0, false }
+
+// transitionEngine is a consensus engine switching to PoA at a given block.
+type transitionEngine struct {
+	consensus.Engine
+	transition uint64
+}
+
+func (e *transitionEngine) UsesPoA(number uint64) bool      { return number >= e.transition }
+func (e *transitionEngine) TransitionBlock() (uint64, bool) { return e.transition, true }
+
+// setHeadBackend is a backend recording the rewinds of its chain.
+type setHeadBackend struct {
+	testBackend
+	head   *types.Header
+	engine consensus.Engine
+	rewind *uint64
+}
+
+func (b *setHeadBackend) CurrentHeader() *types.Header { return b.head }
+func (b *setHeadBackend) Engine() consensus.Engine     { return b.engine }
+func (b *setHeadBackend) SetHead(number uint64)        { b.rewind = &number }
+
+func TestSetHeadAcrossTransition(t *testing.T) {
+	t.Parallel()
+
+	force := true
+	for _, tt := range []struct {
+		name   string
+		head   uint64
+		target uint64
+		force  *bool
+		err    error
+	}{
+		{"poa", 20, 10, nil, nil},
+		{"pos", 9, 5, nil, nil},
+		{"crossing", 20, 9, nil, errSetHeadCrossesTransition},
+		{"forced", 20, 9, &force, nil},
+	} {
+		backend := &setHeadBackend{
+			head:   &types.Header{Number: new(big.Int).SetUint64(tt.head)},
+			engine: &transitionEngine{Engine: ethash.NewFaker(), transition: 10},
+		}
+		err := NewDebugAPI(backend).SetHead(hexutil.Uint64(tt.target), tt.force)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error mismatch: have %v, want %v", tt.name, err, tt.err)
+		}
+		if rewound := backend.rewind != nil; rewound != (tt.err == nil) {
+			t.Errorf("%s: rewind mismatch: have %v, want %v", tt.name, rewound, tt.err == nil)
+		}
+	}
+}
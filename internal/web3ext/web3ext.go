@@ -22,6 +22,7 @@ var Modules = map[string]string{
 	"clique": CliqueJs,
 	"debug":  DebugJs,
 	"eth":    EthJs,
+	"hybrid": HybridJs,
 	"miner":  MinerJs,
 	"net":    NetJs,
 	"rpc":    RpcJs,
@@ -86,6 +87,136 @@ web3._extend({
 });
 `
 
+const HybridJs = `
+web3._extend({
+	property: 'hybrid',
+	methods: [
+		new web3._extend.Method({
+			name: 'status',
+			call: 'hybrid_status',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'signers',
+			call: 'hybrid_signers',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'signersAt',
+			call: 'hybrid_signers',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'propose',
+			call: 'hybrid_propose',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'discard',
+			call: 'hybrid_discard',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'rotateSigner',
+			call: 'hybrid_rotateSigner',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'flipToPoA',
+			call: 'hybrid_flipToPoA',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'buildCheckpointExtra',
+			call: 'hybrid_buildCheckpointExtra',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'validatorStats',
+			call: 'hybrid_validatorStats',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'sealedBlocks',
+			call: 'hybrid_sealedBlocks',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getConsensusSpec',
+			call: 'hybrid_getConsensusSpec',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'consensusOf',
+			call: 'hybrid_consensusOf',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'verifyRange',
+			call: 'hybrid_verifyRange',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getSnapshotAt',
+			call: 'hybrid_getSnapshotAt',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'epochDiffs',
+			call: 'hybrid_epochDiffs',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'doubleSignEvidence',
+			call: 'hybrid_doubleSignEvidence',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'proposals',
+			getter: 'hybrid_proposals'
+		}),
+		new web3._extend.Property({
+			name: 'networkHealth',
+			getter: 'hybrid_networkHealth'
+		}),
+		new web3._extend.Property({
+			name: 'shadowReport',
+			getter: 'hybrid_shadowReport'
+		}),
+		new web3._extend.Property({
+			name: 'dualReport',
+			getter: 'hybrid_dualReport'
+		}),
+		new web3._extend.Property({
+			name: 'forkEvidence',
+			getter: 'hybrid_forkEvidence'
+		}),
+		new web3._extend.Property({
+			name: 'forks',
+			getter: 'hybrid_forks'
+		}),
+		new web3._extend.Property({
+			name: 'reorgReports',
+			getter: 'hybrid_reorgReports'
+		}),
+		new web3._extend.Property({
+			name: 'transitionRecord',
+			getter: 'hybrid_transitionRecord'
+		}),
+	]
+});
+`
+
 const AdminJs = `
 web3._extend({
 	property: 'admin',
@@ -215,7 +346,8 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'setHead',
 			call: 'debug_setHead',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'dumpBlock',
@@ -599,6 +731,12 @@ web3._extend({
 			name: 'config',
 			call: 'eth_config',
 			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'consensusMode',
+			call: 'eth_consensusMode',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
 		})
 	],
 	properties: [
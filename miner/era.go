@@ -0,0 +1,89 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// EraConfig overrides block building parameters for the blocks of one consensus
+// era of a network transitioning from PoS to PoA. Zero values inherit the base
+// miner configuration.
+type EraConfig struct {
+	GasCeil        uint64        // Target gas ceiling for blocks of the era
+	Recommit       time.Duration // Time interval to recreate the block being built
+	NoEmptyPayload bool          // Build the initial payload with transactions instead of an empty one
+}
+
+// buildConfig is the effective set of block building parameters for a block.
+type buildConfig struct {
+	gasCeil        uint64
+	recommit       time.Duration
+	noEmptyPayload bool
+}
+
+// buildConfig returns the block building parameters applying to the block with
+// the given number.
+func (miner *Miner) buildConfig(number *big.Int) buildConfig {
+	miner.confMu.RLock()
+	defer miner.confMu.RUnlock()
+
+	return miner.buildConfigLocked(number)
+}
+
+// buildConfigLocked is the lock-free version of buildConfig, the caller must
+// hold confMu.
+func (miner *Miner) buildConfigLocked(number *big.Int) buildConfig {
+	cfg := buildConfig{
+		gasCeil:  miner.config.GasCeil,
+		recommit: miner.config.Recommit,
+	}
+	if !miner.usesPoA(number) {
+		return cfg
+	}
+	if era := miner.config.PoA; era != nil {
+		if era.GasCeil != 0 {
+			cfg.gasCeil = era.GasCeil
+		}
+		if era.Recommit != 0 {
+			cfg.recommit = era.Recommit
+		}
+		cfg.noEmptyPayload = era.NoEmptyPayload
+	}
+	// Never target a gas limit above the ceiling the chain enforces
+	if ceiling, ok := miner.chainConfig.PoAGasCeiling(number); ok && cfg.gasCeil > ceiling {
+		cfg.gasCeil = ceiling
+	}
+	if miner.poaBuilding.CompareAndSwap(false, true) {
+		log.Info("Switched block building to PoA parameters", "number", number,
+			"gasceil", cfg.gasCeil, "recommit", cfg.recommit, "noempty", cfg.noEmptyPayload)
+	}
+	return cfg
+}
+
+// usesPoA reports whether the block with the given number is built under the
+// PoA rules, consulting the engine for transitions triggered at runtime.
+func (miner *Miner) usesPoA(number *big.Int) bool {
+	if engine, ok := miner.engine.(consensus.Transitioner); ok {
+		return engine.UsesPoA(number.Uint64())
+	}
+	return miner.chainConfig.IsPoSToPoATransition(number)
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that the first block after the PoS to PoA transition is built with the
+// PoA era parameters, while blocks before it keep the base ones.
+func TestBuildPayloadEraSwitch(t *testing.T) {
+	poa := &EraConfig{
+		GasCeil:        params.GenesisGasLimit / 2,
+		Recommit:       3 * time.Second,
+		NoEmptyPayload: true,
+	}
+	for _, tt := range []struct {
+		transition int64
+		wantPoA    bool
+	}{
+		{transition: 2, wantPoA: false},
+		{transition: 1, wantPoA: true},
+	} {
+		chainConfig := *params.TestChainConfig
+		chainConfig.PoSToPoATransitionBlock = big.NewInt(tt.transition)
+		chainConfig.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+		backend := newTestWorkerBackend(t, &chainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+		backend.txPool.Add(pendingTxs, true)
+		config := testConfig
+		config.PoA = poa
+		w := New(backend, config, ethash.NewFaker())
+
+		parent := backend.chain.CurrentBlock()
+		build := w.buildConfig(new(big.Int).Add(parent.Number, common.Big1))
+		if want := tt.wantPoA; (build.recommit == poa.Recommit) != want || build.noEmptyPayload != want {
+			t.Fatalf("transition %d: unexpected build config %+v", tt.transition, build)
+		}
+		payload, err := w.buildPayload(&BuildPayloadArgs{
+			Parent:       parent.Hash(),
+			Timestamp:    parent.Time + 1,
+			FeeRecipient: common.HexToAddress("0xdeadbeef"),
+		}, false)
+		if err != nil {
+			t.Fatalf("transition %d: failed to build payload: %v", tt.transition, err)
+		}
+		initial := payload.ResolveEmpty().ExecutionPayload
+		payload.Resolve()
+
+		if tt.wantPoA {
+			if initial.GasLimit >= parent.GasLimit {
+				t.Errorf("transition %d: gas limit not moving to the PoA ceiling: have %d, parent %d", tt.transition, initial.GasLimit, parent.GasLimit)
+			}
+			if len(initial.Transactions) != len(pendingTxs) {
+				t.Errorf("transition %d: initial payload has %d txs, want %d", tt.transition, len(initial.Transactions), len(pendingTxs))
+			}
+		} else {
+			if initial.GasLimit != parent.GasLimit {
+				t.Errorf("transition %d: gas limit changed before the transition: have %d, parent %d", tt.transition, initial.GasLimit, parent.GasLimit)
+			}
+			if len(initial.Transactions) != 0 {
+				t.Errorf("transition %d: initial payload has %d txs, want empty", tt.transition, len(initial.Transactions))
+			}
+		}
+	}
+}
+
+// Tests that the miner never targets a gas limit above the PoA gas ceiling
+// scheduled in the chain config.
+func TestBuildConfigGasCeiling(t *testing.T) {
+	chainConfig := *params.TestChainConfig
+	chainConfig.PoSToPoATransitionBlock = big.NewInt(10)
+	chainConfig.PoAGasCeilings = []params.GasCeiling{{Block: big.NewInt(20), Ceiling: 1_000_000}}
+
+	config := testConfig
+	config.GasCeil = 30_000_000
+	config.PoA = &EraConfig{GasCeil: 20_000_000}
+	w := &Miner{config: &config, chainConfig: &chainConfig}
+
+	for _, tt := range []struct {
+		number int64
+		want   uint64
+	}{
+		{9, 30_000_000}, {10, 20_000_000}, {20, 1_000_000},
+	} {
+		if have := w.buildConfig(big.NewInt(tt.number)).gasCeil; have != tt.want {
+			t.Errorf("block %d: gas ceiling mismatch: have %d, want %d", tt.number, have, tt.want)
+		}
+	}
+}
+
+// transitionEngine is a consensus engine switching to PoA at a given block,
+// which may differ from the configured one if resolved at runtime.
+type transitionEngine struct {
+	consensus.Engine
+	transition uint64
+}
+
+func (e *transitionEngine) UsesPoA(number uint64) bool      { return number >= e.transition }
+func (e *transitionEngine) TransitionBlock() (uint64, bool) { return e.transition, true }
+
+// Tests that the era parameters follow the transition reported by the engine
+// rather than the one in the chain config.
+func TestBuildConfigEngineTransition(t *testing.T) {
+	chainConfig := *params.TestChainConfig
+	chainConfig.PoSToPoATransitionBlock = big.NewInt(100)
+
+	config := testConfig
+	config.GasCeil = 30_000_000
+	config.PoA = &EraConfig{GasCeil: 20_000_000}
+	w := &Miner{config: &config, chainConfig: &chainConfig, engine: &transitionEngine{transition: 10}}
+
+	for _, tt := range []struct {
+		number int64
+		want   uint64
+	}{
+		{9, 30_000_000}, {10, 20_000_000}, {100, 20_000_000},
+	} {
+		if have := w.buildConfig(big.NewInt(tt.number)).gasCeil; have != tt.want {
+			t.Errorf("block %d: gas ceiling mismatch: have %d, want %d", tt.number, have, tt.want)
+		}
+	}
+}
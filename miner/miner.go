@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -48,6 +49,8 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+
+	PoA *EraConfig `toml:",omitempty"` // Overrides for blocks after a PoS to PoA transition
 }
 
 // DefaultConfig contains default settings for miner.
@@ -73,7 +76,8 @@ type Miner struct {
 	prio        []common.Address // A list of senders to prioritize
 	chain       *core.BlockChain
 	pending     *pending
-	pendingMu   sync.Mutex // Lock protects the pending block
+	pendingMu   sync.Mutex  // Lock protects the pending block
+	poaBuilding atomic.Bool // Whether block building switched to the PoA era parameters
 }
 
 // New creates a new miner with provided config.
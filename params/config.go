@@ -19,8 +19,10 @@ package params
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params/forks"
@@ -440,6 +442,47 @@ type ChainConfig struct {
 	PoSToPoATransitionBlock *big.Int         `json:"posToPoaTransitionBlock,omitempty"` // Block number to switch from PoS to PoA
 	PoAInitialSigners       []common.Address `json:"poaInitialSigners,omitempty"`       // Initial signers for PoA after transition
 
+	// TerminalPoSBlockHash pins the last block of the PoS era, analogous to the
+	// terminal block hash of the merge. The transition happens at its first
+	// descendant, which has to be its child. Without a transition block the
+	// height is resolved once the terminal block is reached, otherwise the
+	// transition block must descend from it.
+	TerminalPoSBlockHash *common.Hash `json:"terminalPoSBlockHash,omitempty"`
+
+	// PoSToPoAManualTransition lets node operators flip to PoA at the current
+	// head through an authenticated RPC call, for small networks coordinating
+	// the cutover out-of-band. The chosen height is recorded in the database and
+	// applies as if configured from then on. A configured transition block is
+	// the latest the flip can happen at.
+	PoSToPoAManualTransition bool `json:"posToPoaManualTransition,omitempty"`
+
+	// TransitionConfirmationDepth is the number of blocks the transition block
+	// needs to be buried under before it is considered final. Every operation
+	// crossing the transition boundary (engine retirement, reorg limits, history
+	// pruning) waits for this depth. Nil selects DefaultTransitionConfirmationDepth.
+	TransitionConfirmationDepth *uint64 `json:"transitionConfirmationDepth,omitempty"`
+
+	// TransitionGraceWindow is the number of blocks starting at the transition
+	// block in which PoS blocks straggling in from the beacon chain are still
+	// accepted as side chain blocks. Later ones are invalid. Nil tolerates none.
+	TransitionGraceWindow *uint64 `json:"transitionGraceWindow,omitempty"`
+
+	// PoAGasCeilings schedules upper bounds for the block gas limit of the PoA
+	// era, e.g. to run a lower ceiling on a small validator set and raise it
+	// later. Entries are ordered by block and apply from their block onwards.
+	PoAGasCeilings []GasCeiling `json:"poaGasCeilings,omitempty"`
+
+	// PoAActivations schedules named protocol activations relative to the
+	// transition block, e.g. {"feature": 10000} activates "feature" 10000 blocks
+	// after the transition. They are resolved to absolute block numbers against
+	// the configured transition block.
+	PoAActivations map[string]uint64 `json:"poaActivations,omitempty"`
+
+	// Transition resolves the era of a block when the transition block is only
+	// learned at runtime, through the terminal PoS block or a manual flip. It is
+	// set by the chain from its consensus engine and never persisted.
+	Transition TransitionResolver `json:"-"`
+
 	// Various consensus engines
 	Ethash             *EthashConfig       `json:"ethash,omitempty"`
 	Clique             *CliqueConfig       `json:"clique,omitempty"`
@@ -454,6 +497,40 @@ func (c EthashConfig) String() string {
 	return "ethash"
 }
 
+// GasCeiling caps the block gas limit from a given block onwards. The block is
+// either given absolutely or as an offset from the PoS to PoA transition block.
+type GasCeiling struct {
+	Block   *big.Int `json:"block,omitempty"`  // First block the ceiling applies to
+	Offset  *uint64  `json:"offset,omitempty"` // First block the ceiling applies to, relative to the transition
+	Ceiling uint64   `json:"ceiling"`          // Maximum gas limit of the blocks
+}
+
+// At resolves the first block the ceiling applies to against the given
+// transition block. Nil is returned if the ceiling cannot be resolved.
+func (g GasCeiling) At(transition *big.Int) *big.Int {
+	switch {
+	case g.Block != nil:
+		return g.Block
+	case g.Offset != nil && transition != nil:
+		return new(big.Int).Add(transition, new(big.Int).SetUint64(*g.Offset))
+	default:
+		return nil
+	}
+}
+
+// String implements the stringer interface, returning the ceiling and the block
+// it takes effect at, relative to the transition if scheduled that way.
+func (g GasCeiling) String() string {
+	switch {
+	case g.Block != nil:
+		return fmt.Sprintf("%d from #%v", g.Ceiling, g.Block)
+	case g.Offset != nil:
+		return fmt.Sprintf("%d from transition+%d", g.Ceiling, *g.Offset)
+	default:
+		return fmt.Sprintf("%d (unscheduled)", g.Ceiling)
+	}
+}
+
 // CliqueConfig is the consensus engine configs for proof-of-authority based sealing.
 type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
@@ -476,6 +553,8 @@ func (c *ChainConfig) Description() string {
 	}
 	banner += fmt.Sprintf("Chain ID:  %v (%s)\n", c.ChainID, network)
 	switch {
+	case c.PoSToPoATransitionBlock != nil:
+		banner += fmt.Sprintf("Consensus: Beacon (proof-of-stake), transitioning to Clique (proof-of-authority) at #%v\n", c.PoSToPoATransitionBlock)
 	case c.Ethash != nil:
 		banner += "Consensus: Beacon (proof-of-stake), merged from Ethash (proof-of-work)\n"
 	case c.Clique != nil:
@@ -555,6 +634,50 @@ func (c *ChainConfig) Description() string {
 	if c.BPO5Time != nil {
 		banner += fmt.Sprintf(" - BPO5:                      @%-10v\n", *c.BPO5Time)
 	}
+	banner += c.transitionDescription()
+	return banner
+}
+
+// transitionDescription returns the banner section describing the PoS to PoA
+// transition, or an empty string if no transition is configured.
+func (c *ChainConfig) transitionDescription() string {
+	if !c.HasPoSToPoATransition() {
+		return ""
+	}
+	var banner string
+	if c.PoSToPoATransitionBlock != nil {
+		banner = "\nPoS to PoA transition (block based):\n"
+		banner += fmt.Sprintf(" - Transition block:            #%-8v\n", c.PoSToPoATransitionBlock)
+	} else if c.TerminalPoSBlockHash != nil {
+		banner = "\nPoS to PoA transition (terminal block based):\n"
+	} else {
+		banner = "\nPoS to PoA transition (manual):\n"
+	}
+	if c.PoSToPoAManualTransition {
+		banner += " - Manual transition:           enabled\n"
+	}
+	if c.TerminalPoSBlockHash != nil {
+		banner += fmt.Sprintf(" - Terminal PoS block:          %v\n", c.TerminalPoSBlockHash.Hex())
+	}
+	if len(c.PoAInitialSigners) == 0 {
+		banner += " - Initial signers:             none configured, placeholder defaults apply\n"
+	} else {
+		banner += fmt.Sprintf(" - Initial signers:             %d %v\n", len(c.PoAInitialSigners), c.PoAInitialSigners)
+	}
+	banner += fmt.Sprintf(" - Confirmation depth:          %d blocks\n", c.TransitionConfirmations())
+	if grace := c.TransitionGrace(); grace > 0 {
+		banner += fmt.Sprintf(" - Grace window:                %d blocks\n", grace)
+	}
+	if c.Clique != nil {
+		banner += fmt.Sprintf(" - Clique:                      %v\n", c.Clique)
+	}
+	for _, ceiling := range c.PoAGasCeilings {
+		banner += fmt.Sprintf(" - Gas ceiling:                 %v\n", ceiling)
+	}
+	names := slices.Sorted(maps.Keys(c.PoAActivations))
+	for _, name := range names {
+		banner += fmt.Sprintf(" - %-28s #%-8v (transition+%d)\n", name+":", c.PoAActivationBlock(name), c.PoAActivations[name])
+	}
 	return banner
 }
 
@@ -650,11 +773,117 @@ func (c *ChainConfig) IsGrayGlacier(num *big.Int) bool {
 	return isBlockForked(c.GrayGlacierBlock, num)
 }
 
+// TransitionConfirmations returns the number of blocks the PoS to PoA transition
+// block needs to be buried under before it is considered final.
+func (c *ChainConfig) TransitionConfirmations() uint64 {
+	if c.TransitionConfirmationDepth == nil {
+		return DefaultTransitionConfirmationDepth
+	}
+	return *c.TransitionConfirmationDepth
+}
+
+// TransitionGrace returns the number of blocks starting at the PoS to PoA
+// transition block in which straggling PoS blocks are tolerated.
+func (c *ChainConfig) TransitionGrace() uint64 {
+	if c.TransitionGraceWindow == nil {
+		return 0
+	}
+	return *c.TransitionGraceWindow
+}
+
+// PoAGasCeiling returns the gas limit ceiling scheduled for the block with the
+// given number, if the block belongs to the PoA era and a ceiling applies.
+func (c *ChainConfig) PoAGasCeiling(num *big.Int) (uint64, bool) {
+	if !c.IsPoSToPoATransition(num) {
+		return 0, false
+	}
+	for i := len(c.PoAGasCeilings) - 1; i >= 0; i-- {
+		if isBlockForked(c.PoAGasCeilings[i].At(c.PoSToPoATransitionBlock), num) {
+			return c.PoAGasCeilings[i].Ceiling, true
+		}
+	}
+	return 0, false
+}
+
+// PoASignerRotation is the name of the PoA activation from which on clique
+// signers may rotate their signing key through an announcement in the header.
+const PoASignerRotation = "signerRotation"
+
+// PoAActivationBlock returns the absolute block number of a protocol activation
+// scheduled relative to the transition, or nil if it is not scheduled.
+func (c *ChainConfig) PoAActivationBlock(name string) *big.Int {
+	offset, ok := c.PoAActivations[name]
+	if !ok || c.PoSToPoATransitionBlock == nil {
+		return nil
+	}
+	return new(big.Int).Add(c.PoSToPoATransitionBlock, new(big.Int).SetUint64(offset))
+}
+
+// IsPoAActivated returns whether the named protocol activation scheduled
+// relative to the transition is active at the given block.
+func (c *ChainConfig) IsPoAActivated(name string, num *big.Int) bool {
+	return isBlockForked(c.PoAActivationBlock(name), num)
+}
+
+// HasPoSToPoATransition reports whether a PoS to PoA transition is configured,
+// at a fixed block, at the first descendant of the terminal PoS block or
+// manually triggered.
+func (c *ChainConfig) HasPoSToPoATransition() bool {
+	return c.PoSToPoATransitionBlock != nil || c.TerminalPoSBlockHash != nil || c.PoSToPoAManualTransition
+}
+
 // IsPoSToPoATransition returns whether num is either equal to the PoS to PoA transition block or greater.
 func (c *ChainConfig) IsPoSToPoATransition(num *big.Int) bool {
 	return isBlockForked(c.PoSToPoATransitionBlock, num)
 }
 
+// TransitionResolver reports the era of the blocks of a PoS to PoA transition
+// network, including transitions resolved at runtime.
+type TransitionResolver interface {
+	// UsesPoA reports whether the block with the given number is sealed by the
+	// PoA engine.
+	UsesPoA(number uint64) bool
+
+	// TransitionBlock returns the first block sealed by the PoA engine, or
+	// false if it is not known yet.
+	TransitionBlock() (uint64, bool)
+}
+
+// ResolvedTransition is the resolver of a transition block resolved at runtime
+// by a previous run of the node.
+type ResolvedTransition uint64
+
+// UsesPoA implements TransitionResolver.
+func (t ResolvedTransition) UsesPoA(number uint64) bool {
+	return number >= uint64(t)
+}
+
+// TransitionBlock implements TransitionResolver.
+func (t ResolvedTransition) TransitionBlock() (uint64, bool) {
+	return uint64(t), true
+}
+
+// IsPoAEra returns whether num is sealed by the PoA engine, consulting the
+// transition resolver for transitions triggered at runtime.
+func (c *ChainConfig) IsPoAEra(num *big.Int) bool {
+	if c.Transition != nil {
+		return c.Transition.UsesPoA(num.Uint64())
+	}
+	return c.IsPoSToPoATransition(num)
+}
+
+// EffectiveTransitionBlock returns the transition block known to the transition
+// resolver, falling back to the configured one. It is nil while a transition
+// triggered at runtime is pending.
+func (c *ChainConfig) EffectiveTransitionBlock() *big.Int {
+	if c.Transition != nil {
+		if number, ok := c.Transition.TransitionBlock(); ok {
+			return new(big.Int).SetUint64(number)
+		}
+	}
+	return c.PoSToPoATransitionBlock
+}
+
 // IsTerminalPoWBlock returns whether the given block is the last block of PoW stage.
 func (c *ChainConfig) IsTerminalPoWBlock(parentTotalDiff *big.Int, totalDiff *big.Int) bool {
 	if c.TerminalTotalDifficulty == nil {
@@ -884,20 +1113,90 @@ func (bc *BlobConfig) validate() error {
 
 // validatePoSToPoATransition validates the PoS to PoA transition configuration
 func (c *ChainConfig) validatePoSToPoATransition() error {
-	if c.PoSToPoATransitionBlock == nil {
+	if !c.HasPoSToPoATransition() {
+		if c.TransitionConfirmationDepth != nil {
+			return errors.New("transition confirmation depth set without a PoS to PoA transition block")
+		}
+		if c.TransitionGraceWindow != nil {
+			return errors.New("transition grace window set without a PoS to PoA transition block")
+		}
+		if len(c.PoAGasCeilings) > 0 {
+			return errors.New("PoA gas ceilings set without a PoS to PoA transition block")
+		}
+		if len(c.PoAActivations) > 0 {
+			return errors.New("PoA activations set without a PoS to PoA transition block")
+		}
 		return nil // No transition configured, which is valid
 	}
 
-	// Validate transition block number
-	if c.PoSToPoATransitionBlock.Sign() < 0 {
+	if c.PoSToPoATransitionBlock == nil {
+		// The height of a transition triggered by the terminal PoS block or by the
+		// operator is only known once reached, nothing can be scheduled relative to it
+		if len(c.PoAGasCeilings) > 0 {
+			return errors.New("PoA gas ceilings set without a fixed PoS to PoA transition block")
+		}
+		if len(c.PoAActivations) > 0 {
+			return errors.New("PoA activations set without a fixed PoS to PoA transition block")
+		}
+	} else if c.PoSToPoATransitionBlock.Sign() < 0 {
 		return errors.New("PoS to PoA transition block cannot be negative")
 	}
+	if hash := c.TerminalPoSBlockHash; hash != nil {
+		if *hash == (common.Hash{}) {
+			return errors.New("terminal PoS block hash cannot be zero")
+		}
+		if c.PoSToPoATransitionBlock != nil && c.PoSToPoATransitionBlock.Sign() == 0 {
+			return errors.New("terminal PoS block hash set for a transition at genesis")
+		}
+		// A manual flip at an arbitrary head cannot descend from the terminal block
+		if c.PoSToPoAManualTransition {
+			return errors.New("manual PoS to PoA transition set along with a terminal PoS block hash")
+		}
+	}
 
 	// If transition is configured, Clique configuration must be present
 	if c.Clique == nil {
 		return errors.New("PoS to PoA transition requires Clique configuration")
 	}
 
+	// An explicit initial signer set must consist of unique, non-zero addresses.
+	// An absent list is valid, the engine falls back to its built-in defaults.
+	seen := make(map[common.Address]struct{}, len(c.PoAInitialSigners))
+	for i, signer := range c.PoAInitialSigners {
+		if signer == (common.Address{}) {
+			return fmt.Errorf("PoA initial signer %d is the zero address", i)
+		}
+		if _, ok := seen[signer]; ok {
+			return fmt.Errorf("duplicate PoA initial signer %v", signer)
+		}
+		seen[signer] = struct{}{}
+	}
+	if c.TransitionConfirmationDepth != nil && *c.TransitionConfirmationDepth == 0 {
+		return errors.New("transition confirmation depth must be positive")
+	}
+	// Stragglers past the finality of the transition are refused by fork choice
+	// anyway, so tolerating them any longer is meaningless
+	if grace, depth := c.TransitionGrace(), c.TransitionConfirmations(); grace > depth {
+		return fmt.Errorf("transition grace window of %d blocks exceeds the confirmation depth of %d", grace, depth)
+	}
+	// Gas ceilings must be ordered and scheduled within the PoA era
+	var prev *big.Int
+	for i, ceiling := range c.PoAGasCeilings {
+		if (ceiling.Block == nil) == (ceiling.Offset == nil) {
+			return fmt.Errorf("PoA gas ceiling %d needs exactly one of block or offset", i)
+		}
+		block := ceiling.At(c.PoSToPoATransitionBlock)
+		if block.Cmp(c.PoSToPoATransitionBlock) < 0 {
+			return fmt.Errorf("PoA gas ceiling %d scheduled at block %v, before the transition at %v", i, block, c.PoSToPoATransitionBlock)
+		}
+		if prev != nil && block.Cmp(prev) <= 0 {
+			return fmt.Errorf("PoA gas ceiling %d at block %v not after the previous one at %v", i, block, prev)
+		}
+		prev = block
+		if ceiling.Ceiling < MinGasLimit {
+			return fmt.Errorf("PoA gas ceiling %d of %d below the minimum gas limit %d", i, ceiling.Ceiling, MinGasLimit)
+		}
+	}
 	return nil
 }
 
@@ -960,6 +1259,39 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkBlockIncompatible(c.PoSToPoATransitionBlock, newcfg.PoSToPoATransitionBlock, headNumber) {
 		return newBlockCompatError("PoS to PoA transition block", c.PoSToPoATransitionBlock, newcfg.PoSToPoATransitionBlock)
 	}
+	// The transition may have been resolved at runtime, through the terminal
+	// block or a manual flip, without being configured
+	transition := c.EffectiveTransitionBlock()
+	if isBlockForked(transition, headNumber) && !slices.Equal(c.PoAInitialSigners, newcfg.PoAInitialSigners) {
+		// The transition block checkpoints the initial signers, so they cannot be
+		// changed once it has been processed.
+		return newBlockCompatError("PoA initial signers", transition, transition)
+	}
+	if isBlockForked(transition, headNumber) && !equalHashes(c.TerminalPoSBlockHash, newcfg.TerminalPoSBlockHash) {
+		// The transition block was verified to descend from the terminal block,
+		// so the pinned hash cannot be changed once it has been processed.
+		return newBlockCompatError("terminal PoS block hash", transition, transition)
+	}
+	if isBlockForked(transition, headNumber) && c.TransitionGrace() != newcfg.TransitionGrace() {
+		// The grace window decides on the validity of blocks from the transition
+		// on, so it cannot be changed once the transition has been processed.
+		return newBlockCompatError("PoS to PoA grace window", transition, transition)
+	}
+	if stored, updated, ok := gasCeilingsIncompatible(c, newcfg, headNumber); ok {
+		return newBlockCompatError("PoA gas ceiling", stored, updated)
+	}
+	names := slices.Collect(maps.Keys(c.PoAActivations))
+	for name := range newcfg.PoAActivations {
+		if _, ok := c.PoAActivations[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if isForkBlockIncompatible(c.PoAActivationBlock(name), newcfg.PoAActivationBlock(name), headNumber) {
+			return newBlockCompatError(fmt.Sprintf("PoA activation %q", name), c.PoAActivationBlock(name), newcfg.PoAActivationBlock(name))
+		}
+	}
 	if isForkTimestampIncompatible(c.ShanghaiTime, newcfg.ShanghaiTime, headTimestamp) {
 		return newTimestampCompatError("Shanghai fork timestamp", c.ShanghaiTime, newcfg.ShanghaiTime)
 	}
@@ -1079,6 +1411,38 @@ func isForkBlockIncompatible(s1, s2, head *big.Int) bool {
 	return (isBlockForked(s1, head) || isBlockForked(s2, head)) && !configBlockEqual(s1, s2)
 }
 
+// equalHashes reports whether two optional hashes are both unset or equal.
+func equalHashes(a, b *common.Hash) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// gasCeilingsIncompatible reports whether the gas ceiling schedules of two
+// configs differ in any entry already active at the given head, returning the
+// resolved blocks of the first differing entries.
+func gasCeilingsIncompatible(stored, updated *ChainConfig, head *big.Int) (*big.Int, *big.Int, bool) {
+	active := func(c *ChainConfig, i int) (*big.Int, uint64) {
+		if i < len(c.PoAGasCeilings) {
+			if block := c.PoAGasCeilings[i].At(c.PoSToPoATransitionBlock); isBlockForked(block, head) {
+				return block, c.PoAGasCeilings[i].Ceiling
+			}
+		}
+		return nil, 0
+	}
+	for i := 0; ; i++ {
+		s, sc := active(stored, i)
+		u, uc := active(updated, i)
+		if s == nil && u == nil {
+			return nil, nil, false
+		}
+		if !configBlockEqual(s, u) || sc != uc {
+			return s, u, true
+		}
+	}
+}
+
 // isBlockForked returns whether a fork scheduled at block s is active at the
 // given head block. Whilst this method is the same as isTimestampForked, they
 // are explicitly separate for clearer reading.
@@ -1215,6 +1579,7 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague, IsOsaka        bool
 	IsVerkle                                                bool
+	IsPoSToPoATransitioned                                  bool // Block is sealed by the PoA engine
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -1246,5 +1611,7 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsOsaka:          isMerge && c.IsOsaka(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+
+		IsPoSToPoATransitioned: c.IsPoAEra(num),
 	}
 }
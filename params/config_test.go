@@ -21,9 +21,11 @@ import (
 	"math"
 	"math/big"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -138,6 +140,24 @@ func TestConfigRules(t *testing.T) {
 	if r := c.Rules(big.NewInt(0), true, stamp); !r.IsShanghai {
 		t.Errorf("expected %v to be shanghai", stamp)
 	}
+	if r := c.Rules(big.NewInt(1000), true, stamp); r.IsPoSToPoATransitioned {
+		t.Errorf("expected block 1000 to be pre-transition without a transition block")
+	}
+	c.PoSToPoATransitionBlock = big.NewInt(1000)
+	if r := c.Rules(big.NewInt(999), true, stamp); r.IsPoSToPoATransitioned {
+		t.Errorf("expected block 999 to be pre-transition")
+	}
+	if r := c.Rules(big.NewInt(1000), false, stamp); !r.IsPoSToPoATransitioned {
+		t.Errorf("expected block 1000 to be post-transition")
+	}
+	// A transition resolved at runtime takes precedence over the configured one
+	c.Transition = ResolvedTransition(500)
+	if r := c.Rules(big.NewInt(499), true, stamp); r.IsPoSToPoATransitioned {
+		t.Errorf("expected block 499 to be pre-transition")
+	}
+	if r := c.Rules(big.NewInt(500), true, stamp); !r.IsPoSToPoATransitioned {
+		t.Errorf("expected block 500 to be post-transition")
+	}
 }
 
 func TestTimestampCompatError(t *testing.T) {
@@ -208,6 +228,171 @@ func TestPoSToPoATransitionValidation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "PoS to PoA transition requires Clique configuration",
 		},
+		{
+			name: "valid initial signers",
+			config: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(1000),
+				Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+				PoAInitialSigners:       []common.Address{{0x01}, {0x02}, {0x03}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero initial signer",
+			config: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(1000),
+				Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+				PoAInitialSigners:       []common.Address{{0x01}, {}},
+			},
+			wantErr: true,
+			errMsg:  "PoA initial signer 1 is the zero address",
+		},
+		{
+			name: "duplicate initial signer",
+			config: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(1000),
+				Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+				PoAInitialSigners:       []common.Address{{0x01}, {0x02}, {0x01}},
+			},
+			wantErr: true,
+			errMsg:  "duplicate PoA initial signer",
+		},
+		{
+			name: "valid confirmation depth",
+			config: &ChainConfig{
+				ChainID:                     big.NewInt(1),
+				PoSToPoATransitionBlock:     big.NewInt(1000),
+				Clique:                      &CliqueConfig{Period: 15, Epoch: 30000},
+				TransitionConfirmationDepth: newUint64(64),
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero confirmation depth",
+			config: &ChainConfig{
+				ChainID:                     big.NewInt(1),
+				PoSToPoATransitionBlock:     big.NewInt(1000),
+				Clique:                      &CliqueConfig{Period: 15, Epoch: 30000},
+				TransitionConfirmationDepth: newUint64(0),
+			},
+			wantErr: true,
+			errMsg:  "transition confirmation depth must be positive",
+		},
+		{
+			name: "grace window beyond confirmation depth",
+			config: &ChainConfig{
+				ChainID:                     big.NewInt(1),
+				PoSToPoATransitionBlock:     big.NewInt(1000),
+				Clique:                      &CliqueConfig{Period: 15, Epoch: 30000},
+				TransitionConfirmationDepth: newUint64(64),
+				TransitionGraceWindow:       newUint64(65),
+			},
+			wantErr: true,
+			errMsg:  "transition grace window of 65 blocks exceeds the confirmation depth of 64",
+		},
+		{
+			name: "grace window without transition",
+			config: &ChainConfig{
+				ChainID:               big.NewInt(1),
+				TransitionGraceWindow: newUint64(16),
+			},
+			wantErr: true,
+			errMsg:  "transition grace window set without a PoS to PoA transition block",
+		},
+		{
+			name: "terminal block without transition block",
+			config: &ChainConfig{
+				ChainID:              big.NewInt(1),
+				TerminalPoSBlockHash: &common.Hash{0x01},
+				Clique:               &CliqueConfig{Period: 15, Epoch: 30000},
+			},
+			wantErr: false,
+		},
+		{
+			name: "terminal block without clique",
+			config: &ChainConfig{
+				ChainID:              big.NewInt(1),
+				TerminalPoSBlockHash: &common.Hash{0x01},
+			},
+			wantErr: true,
+			errMsg:  "PoS to PoA transition requires Clique configuration",
+		},
+		{
+			name: "zero terminal block hash",
+			config: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(1000),
+				TerminalPoSBlockHash:    &common.Hash{},
+				Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+			},
+			wantErr: true,
+			errMsg:  "terminal PoS block hash cannot be zero",
+		},
+		{
+			name: "terminal block for genesis transition",
+			config: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(0),
+				TerminalPoSBlockHash:    &common.Hash{0x01},
+				Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+			},
+			wantErr: true,
+			errMsg:  "terminal PoS block hash set for a transition at genesis",
+		},
+		{
+			name: "gas ceilings relative to terminal block",
+			config: &ChainConfig{
+				ChainID:              big.NewInt(1),
+				TerminalPoSBlockHash: &common.Hash{0x01},
+				Clique:               &CliqueConfig{Period: 15, Epoch: 30000},
+				PoAGasCeilings:       []GasCeiling{{Offset: newUint64(10), Ceiling: 30_000_000}},
+			},
+			wantErr: true,
+			errMsg:  "PoA gas ceilings set without a fixed PoS to PoA transition block",
+		},
+		{
+			name: "activations relative to terminal block",
+			config: &ChainConfig{
+				ChainID:              big.NewInt(1),
+				TerminalPoSBlockHash: &common.Hash{0x01},
+				Clique:               &CliqueConfig{Period: 15, Epoch: 30000},
+				PoAActivations:       map[string]uint64{"feature": 100},
+			},
+			wantErr: true,
+			errMsg:  "PoA activations set without a fixed PoS to PoA transition block",
+		},
+		{
+			name: "manual transition",
+			config: &ChainConfig{
+				ChainID:                  big.NewInt(1),
+				PoSToPoAManualTransition: true,
+				Clique:                   &CliqueConfig{Period: 15, Epoch: 30000},
+			},
+			wantErr: false,
+		},
+		{
+			name: "manual transition with terminal block",
+			config: &ChainConfig{
+				ChainID:                  big.NewInt(1),
+				PoSToPoAManualTransition: true,
+				TerminalPoSBlockHash:     &common.Hash{0x01},
+				Clique:                   &CliqueConfig{Period: 15, Epoch: 30000},
+			},
+			wantErr: true,
+			errMsg:  "manual PoS to PoA transition set along with a terminal PoS block hash",
+		},
+		{
+			name: "confirmation depth without transition",
+			config: &ChainConfig{
+				ChainID:                     big.NewInt(1),
+				TransitionConfirmationDepth: newUint64(64),
+			},
+			wantErr: true,
+			errMsg:  "transition confirmation depth set without a PoS to PoA transition block",
+		},
 	}
 
 	for _, tt := range tests {
@@ -275,6 +460,67 @@ func TestPoSToPoATransitionCompatibility(t *testing.T) {
 			headBlock: 500,
 			wantErr:   nil,
 		},
+		{
+			name:      "unreached transition moved earlier",
+			stored:    &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(2000)},
+			new:       &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)},
+			headBlock: 999,
+			wantErr:   nil,
+		},
+		{
+			name:      "unreached transition added",
+			stored:    &ChainConfig{ChainID: big.NewInt(1)},
+			new:       &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)},
+			headBlock: 500,
+			wantErr:   nil,
+		},
+		{
+			name:      "unreached transition removed",
+			stored:    &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)},
+			new:       &ChainConfig{ChainID: big.NewInt(1)},
+			headBlock: 500,
+			wantErr:   nil,
+		},
+		{
+			name: "unreached relative schedule follows the transition",
+			stored: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(1000),
+				PoAGasCeilings:          []GasCeiling{{Offset: newUint64(0), Ceiling: 10_000_000}},
+				PoAActivations:          map[string]uint64{"feature": 10},
+			},
+			new: &ChainConfig{
+				ChainID:                 big.NewInt(1),
+				PoSToPoATransitionBlock: big.NewInt(2000),
+				PoAGasCeilings:          []GasCeiling{{Offset: newUint64(0), Ceiling: 10_000_000}},
+				PoAActivations:          map[string]uint64{"feature": 10},
+			},
+			headBlock: 999,
+			wantErr:   nil,
+		},
+		{
+			name:      "transition moved below head",
+			stored:    &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(2000)},
+			new:       &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)},
+			headBlock: 1500,
+			wantErr: &ConfigCompatError{
+				What:          "PoS to PoA transition block",
+				StoredBlock:   big.NewInt(2000),
+				NewBlock:      big.NewInt(1000),
+				RewindToBlock: 999,
+			},
+		},
+		{
+			name:      "reached transition removed",
+			stored:    &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)},
+			new:       &ChainConfig{ChainID: big.NewInt(1)},
+			headBlock: 1000,
+			wantErr: &ConfigCompatError{
+				What:          "PoS to PoA transition block",
+				StoredBlock:   big.NewInt(1000),
+				RewindToBlock: 999,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -417,3 +663,242 @@ func TestCheckConfigForkOrderWithPoSToPoATransition(t *testing.T) {
 		})
 	}
 }
+
+func TestPoAGasCeilings(t *testing.T) {
+	config := &ChainConfig{
+		ChainID:                 big.NewInt(1),
+		PoSToPoATransitionBlock: big.NewInt(100),
+		Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+		PoAGasCeilings: []GasCeiling{
+			{Block: big.NewInt(100), Ceiling: 10_000_000},
+			{Block: big.NewInt(200), Ceiling: 20_000_000},
+		},
+	}
+	require.NoError(t, config.validatePoSToPoATransition())
+
+	for _, tt := range []struct {
+		number  int64
+		ceiling uint64
+		ok      bool
+	}{
+		{99, 0, false}, {100, 10_000_000, true}, {199, 10_000_000, true}, {200, 20_000_000, true}, {1000, 20_000_000, true},
+	} {
+		ceiling, ok := config.PoAGasCeiling(big.NewInt(tt.number))
+		require.Equal(t, tt.ok, ok, "block %d", tt.number)
+		require.Equal(t, tt.ceiling, ceiling, "block %d", tt.number)
+	}
+	// Invalid schedules
+	for _, tt := range []struct {
+		ceilings []GasCeiling
+		errMsg   string
+	}{
+		{[]GasCeiling{{Ceiling: 10_000_000}}, "needs exactly one of block or offset"},
+		{[]GasCeiling{{Block: big.NewInt(100), Offset: newUint64(0), Ceiling: 10_000_000}}, "needs exactly one of block or offset"},
+		{[]GasCeiling{{Offset: newUint64(100), Ceiling: 10_000_000}, {Block: big.NewInt(150), Ceiling: 20_000_000}}, "not after the previous one"},
+		{[]GasCeiling{{Block: big.NewInt(50), Ceiling: 10_000_000}}, "before the transition"},
+		{[]GasCeiling{{Block: big.NewInt(200), Ceiling: 10_000_000}, {Block: big.NewInt(200), Ceiling: 20_000_000}}, "not after the previous one"},
+		{[]GasCeiling{{Block: big.NewInt(100), Ceiling: 1}}, "below the minimum gas limit"},
+	} {
+		invalid := *config
+		invalid.PoAGasCeilings = tt.ceilings
+		err := invalid.validatePoSToPoATransition()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tt.errMsg)
+	}
+	// Changing a future ceiling is compatible, changing an active one is not
+	future := *config
+	future.PoAGasCeilings = []GasCeiling{config.PoAGasCeilings[0], {Block: big.NewInt(300), Ceiling: 30_000_000}}
+	require.Nil(t, config.CheckCompatible(&future, 150, 0))
+
+	err := config.CheckCompatible(&future, 250, 0)
+	require.NotNil(t, err)
+	require.Equal(t, "PoA gas ceiling", err.What)
+	require.Equal(t, uint64(199), err.RewindToBlock)
+}
+
+func TestRelativeTransitionScheduling(t *testing.T) {
+	config := &ChainConfig{
+		ChainID:                 big.NewInt(1),
+		PoSToPoATransitionBlock: big.NewInt(1000),
+		Clique:                  &CliqueConfig{Period: 15, Epoch: 30000},
+		PoAGasCeilings:          []GasCeiling{{Offset: newUint64(0), Ceiling: 10_000_000}, {Offset: newUint64(500), Ceiling: 20_000_000}},
+		PoAActivations:          map[string]uint64{"feature": 100},
+	}
+	require.NoError(t, config.validatePoSToPoATransition())
+
+	// Relative schedules resolve against the transition block
+	require.Equal(t, big.NewInt(1100), config.PoAActivationBlock("feature"))
+	require.Nil(t, config.PoAActivationBlock("unknown"))
+	require.False(t, config.IsPoAActivated("feature", big.NewInt(1099)))
+	require.True(t, config.IsPoAActivated("feature", big.NewInt(1100)))
+
+	ceiling, ok := config.PoAGasCeiling(big.NewInt(1499))
+	require.True(t, ok)
+	require.Equal(t, uint64(10_000_000), ceiling)
+	ceiling, _ = config.PoAGasCeiling(big.NewInt(1500))
+	require.Equal(t, uint64(20_000_000), ceiling)
+
+	// Relative schedules need a transition
+	orphan := &ChainConfig{ChainID: big.NewInt(1), PoAActivations: map[string]uint64{"feature": 100}}
+	require.Error(t, orphan.validatePoSToPoATransition())
+
+	// Rescheduling a pending activation is fine, moving an active one is not
+	moved := *config
+	moved.PoAActivations = map[string]uint64{"feature": 200}
+	require.Nil(t, config.CheckCompatible(&moved, 1050, 0))
+
+	err := config.CheckCompatible(&moved, 1150, 0)
+	require.NotNil(t, err)
+	require.Equal(t, `PoA activation "feature"`, err.What)
+	require.Equal(t, uint64(1099), err.RewindToBlock)
+
+	// Adding a new activation in the past is incompatible as well
+	added := *config
+	added.PoAActivations = map[string]uint64{"feature": 100, "other": 10}
+	err = config.CheckCompatible(&added, 1150, 0)
+	require.NotNil(t, err)
+	require.Equal(t, `PoA activation "other"`, err.What)
+
+	// Switching an active ceiling between absolute and relative form is fine if
+	// it resolves to the same block
+	absolute := *config
+	absolute.PoAGasCeilings = []GasCeiling{{Block: big.NewInt(1000), Ceiling: 10_000_000}, {Offset: newUint64(500), Ceiling: 20_000_000}}
+	require.Nil(t, config.CheckCompatible(&absolute, 1600, 0))
+}
+
+func TestTransitionDescription(t *testing.T) {
+	config := &ChainConfig{
+		ChainID:                 big.NewInt(1337),
+		Clique:                  &CliqueConfig{Period: 5, Epoch: 30000},
+		PoSToPoATransitionBlock: big.NewInt(1000),
+		PoAInitialSigners:       []common.Address{{0x01}, {0x02}},
+		PoAGasCeilings:          []GasCeiling{{Offset: newUint64(10), Ceiling: 30_000_000}},
+		PoAActivations:          map[string]uint64{"feature": 100},
+		TransitionGraceWindow:   newUint64(16),
+	}
+	desc := config.Description()
+	for _, want := range []string{
+		"Grace window:                16 blocks",
+		"transitioning to Clique (proof-of-authority) at #1000",
+		"Transition block:            #1000",
+		"Initial signers:             2 [0x0100000000000000000000000000000000000000 0x0200000000000000000000000000000000000000]",
+		"Confirmation depth:          1024 blocks",
+		"clique(period: 5, epoch: 30000)",
+		"30000000 from transition+10",
+		"feature:",
+		"#1100     (transition+100)",
+	} {
+		require.Contains(t, desc, want)
+	}
+	config.PoAInitialSigners = nil
+	require.Contains(t, config.Description(), "Initial signers:             none configured, placeholder defaults apply")
+
+	config.PoSToPoATransitionBlock = nil
+	require.NotContains(t, config.Description(), "PoS to PoA transition")
+
+	config.TerminalPoSBlockHash = &common.Hash{0xaa}
+	desc = config.Description()
+	require.Contains(t, desc, "PoS to PoA transition (terminal block based)")
+	require.Contains(t, desc, "Terminal PoS block:          0xaa00")
+	require.NotContains(t, desc, "Transition block:")
+
+	config.TerminalPoSBlockHash, config.PoSToPoAManualTransition = nil, true
+	desc = config.Description()
+	require.Contains(t, desc, "PoS to PoA transition (manual)")
+	require.Contains(t, desc, "Manual transition:           enabled")
+}
+
+func TestPoAInitialSignersCompatibility(t *testing.T) {
+	var (
+		signers = []common.Address{{0x01}, {0x02}}
+		stored  = &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000), PoAInitialSigners: signers}
+	)
+	changed := *stored
+	changed.PoAInitialSigners = []common.Address{{0x01}, {0x03}}
+
+	// Signers may change freely before the transition has executed
+	require.Nil(t, stored.CheckCompatible(&changed, 999, 0))
+
+	// Reordering or replacing the signers afterwards requires a rewind
+	err := stored.CheckCompatible(&changed, 1000, 0)
+	require.NotNil(t, err)
+	require.Equal(t, "PoA initial signers", err.What)
+	require.Equal(t, uint64(999), err.RewindToBlock)
+
+	reordered := *stored
+	reordered.PoAInitialSigners = []common.Address{{0x02}, {0x01}}
+	require.NotNil(t, stored.CheckCompatible(&reordered, 2000, 0))
+
+	same := *stored
+	same.PoAInitialSigners = slices.Clone(signers)
+	require.Nil(t, stored.CheckCompatible(&same, 2000, 0))
+}
+
+func TestTransitionGraceWindowCompatibility(t *testing.T) {
+	stored := &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)}
+	changed := *stored
+	changed.TransitionGraceWindow = newUint64(16)
+
+	// The window may change freely before the transition has executed
+	require.Nil(t, stored.CheckCompatible(&changed, 999, 0))
+
+	// Changing it afterwards requires a rewind to before the transition
+	err := stored.CheckCompatible(&changed, 1000, 0)
+	require.NotNil(t, err)
+	require.Equal(t, "PoS to PoA grace window", err.What)
+	require.Equal(t, uint64(999), err.RewindToBlock)
+
+	// An explicit zero window equals an absent one
+	changed.TransitionGraceWindow = newUint64(0)
+	require.Nil(t, stored.CheckCompatible(&changed, 2000, 0))
+}
+
+func TestTerminalPoSBlockHashCompatibility(t *testing.T) {
+	stored := &ChainConfig{ChainID: big.NewInt(1), PoSToPoATransitionBlock: big.NewInt(1000)}
+	changed := *stored
+	changed.TerminalPoSBlockHash = &common.Hash{0x01}
+
+	// The terminal block may be pinned before the transition has executed
+	require.Nil(t, stored.CheckCompatible(&changed, 999, 0))
+
+	// Pinning or changing it afterwards requires a rewind to before the transition
+	err := stored.CheckCompatible(&changed, 1000, 0)
+	require.NotNil(t, err)
+	require.Equal(t, "terminal PoS block hash", err.What)
+	require.Equal(t, uint64(999), err.RewindToBlock)
+
+	same := changed
+	same.TerminalPoSBlockHash = &common.Hash{0x01}
+	require.Nil(t, changed.CheckCompatible(&same, 2000, 0))
+}
+
+func TestResolvedTransitionCompatibility(t *testing.T) {
+	// Transitions triggered by the terminal block or manually are resolved at
+	// runtime, without a configured transition block
+	for _, stored := range []*ChainConfig{
+		{ChainID: big.NewInt(1), TerminalPoSBlockHash: &common.Hash{0x01}, PoAInitialSigners: []common.Address{{0x01}}},
+		{ChainID: big.NewInt(1), PoSToPoAManualTransition: true, PoAInitialSigners: []common.Address{{0x01}}},
+	} {
+		changed := *stored
+		changed.PoAInitialSigners = []common.Address{{0x02}}
+		changed.TransitionGraceWindow = newUint64(16)
+
+		// Nothing is guarded while the transition is pending
+		require.Nil(t, stored.CheckCompatible(&changed, 2000, 0))
+
+		// Once resolved, the guards apply from the resolved block on
+		stored.Transition = ResolvedTransition(1000)
+		require.Nil(t, stored.CheckCompatible(&changed, 999, 0))
+
+		err := stored.CheckCompatible(&changed, 1000, 0)
+		require.NotNil(t, err)
+		require.Equal(t, "PoA initial signers", err.What)
+		require.Equal(t, uint64(999), err.RewindToBlock)
+
+		changed.PoAInitialSigners = stored.PoAInitialSigners
+		err = stored.CheckCompatible(&changed, 1000, 0)
+		require.NotNil(t, err)
+		require.Equal(t, "PoS to PoA grace window", err.What)
+		require.Equal(t, uint64(999), err.RewindToBlock)
+	}
+}